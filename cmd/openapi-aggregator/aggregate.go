@@ -0,0 +1,328 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/RastBast/docs12121/pkg/config"
+	"github.com/RastBast/docs12121/pkg/dispatch"
+	"github.com/RastBast/docs12121/pkg/git"
+	"github.com/RastBast/docs12121/pkg/gitea"
+	"github.com/RastBast/docs12121/pkg/prose"
+	"github.com/RastBast/docs12121/pkg/report"
+	"github.com/RastBast/docs12121/pkg/spec"
+)
+
+type specFetcher func(ctx context.Context, repo string, specPaths []string) ([][]byte, error)
+
+func aggregateDocs(ctx context.Context, cfg config.Config, args []string) error {
+	fs := newFlagSet("aggregate")
+	ref := fs.String("ref", "", "ветка или тег в репозиториях сервисов (по умолчанию ветка по умолчанию)")
+	source := fs.String("source", "api", "откуда брать спецификации: api (Gitea API) или clone (git clone)")
+	commit := fs.Bool("commit", false, "закоммитить изменения в репозиторий документации")
+	push := fs.Bool("push", false, "отправить коммит в удалённый репозиторий документации и разослать события о публикации (с --commit)")
+	batchOpts := addBatchFlags(fs, "aggregate")
+	docsRepo := docsRepoFlag(fs, cfg)
+	stateDir := stateDirFlag(fs)
+	glossaryPath := fs.String("glossary", "glossary.yaml", "глоссарий предпочтительных терминов: замечания выводятся, уровень error блокирует публикацию")
+	fs.Parse(args)
+
+	dir := "."
+	if fs.NArg() > 0 {
+		dir = fs.Arg(0)
+	}
+	if err := cfg.Validate(); err != nil {
+		return fail(exitConfigInvalid, "Некорректная конфигурация: %v", err)
+	}
+	if *push && !*commit {
+		return fail(exitConfigInvalid, "--push используется только вместе с --commit")
+	}
+	glossary, err := loadGlossary(*glossaryPath)
+	if err != nil {
+		return err
+	}
+	token := os.Getenv("GITEA_TOKEN")
+	if token == "" {
+		return fail(exitConfigInvalid, "Не задан GITEA_TOKEN")
+	}
+
+	client := gitea.NewClient(cfg.GiteaHost, token)
+	var fetch specFetcher
+	switch *source {
+	case "api":
+		fetch = apiFetcher(client, cfg, *ref)
+	case "clone":
+		fetch = cloneFetcher(cfg, token, *ref)
+	default:
+		return fail(exitConfigInvalid, "Неизвестный источник %q (доступны: api, clone)", *source)
+	}
+
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var sum report.Summary
+	var mu sync.Mutex
+	updated := map[string][]string{}
+	repos, err := routedRepos(cfg, *docsRepo)
+	if err != nil {
+		return err
+	}
+	runner, err := batchOpts.runner()
+	if err != nil {
+		return err
+	}
+	err = runner.Run(ctx, repos, &sum, func(ctx context.Context, repo string) error {
+		prefix, err := docsPrefix(ctx, client, cfg, dir, repo, *ref)
+		if err != nil {
+			return err
+		}
+		changed, err := aggregateRepo(ctx, fetch, dir, prefix, cfg, cfg.Repo(repo), glossary, printInfo)
+		if err != nil {
+			return err
+		}
+		if len(changed) > 0 {
+			mu.Lock()
+			updated[repo] = changed
+			mu.Unlock()
+			printOK("%s: обновлено: %s", repo, strings.Join(changed, ", "))
+		} else {
+			printOK("%s: без изменений", repo)
+		}
+		return nil
+	})
+	printSummary(&sum)
+	if *stateDir == "" {
+		*stateDir = ".openapi-aggregator"
+	}
+	if err := trackFailures(ctx, client, cfg, *stateDir, sum.Results(), printInfo); err != nil {
+		printFail("Issue о сбоях агрегации не обновлены: %v", err)
+	}
+	if err != nil {
+		return fail(exitCodeFor(err, exitError), "Агрегация прервана: %v", err)
+	}
+
+	if *commit && len(updated) > 0 && !skipInDryRun("изменения не будут закоммичены в %s", dir) {
+		var repos []string
+		// Only the published specs and the manifest are staged: the state
+		// directory and stray files in the checkout stay out of the commit.
+		paths := []string{spec.ManifestFile}
+		n := 0
+		for repo, changed := range updated {
+			repos = append(repos, repo)
+			paths = append(paths, changed...)
+			n += len(changed)
+		}
+		sort.Strings(repos)
+		author := git.Author{Name: "OpenAPI Aggregator Bot", Email: "openapi-bot@" + cfg.GiteaHost}
+		if err := git.Run(ctx, dir, append([]string{"add", "--"}, paths...)...); err != nil {
+			return fail(exitError, "Ошибка добавления файлов: %v", err)
+		}
+		if err := git.Commit(ctx, dir, author, cfg.Tracking.CommitMessage(fmt.Sprintf("Aggregate OpenAPI docs (%d updated)", n))); err != nil {
+			return fail(exitError, "Ошибка коммита: %v", err)
+		}
+		printOK("Изменения закоммичены в %s", dir)
+		var d *dispatch.Dispatcher
+		if *push {
+			if err := git.PushRebased(ctx, dir, author); err != nil {
+				return fail(exitError, "Ошибка отправки изменений: %v", err)
+			}
+			printOK("Изменения отправлены в удалённый репозиторий")
+			d = newDispatcher(cfg, *stateDir, printInfo)
+		}
+		// Metrics and events describe published updates, so they go out
+		// only once the commit (and the push, if requested) succeeded.
+		for _, repo := range repos {
+			if err := pushMetrics(ctx, cfg, dir, repo, *ref, updated[repo]); err != nil {
+				printFail("%s: метрики не отправлены: %v", repo, err)
+			}
+			if d == nil {
+				continue
+			}
+			if err := publishEvent(ctx, d, dir, repo, *ref, updated[repo]); err != nil {
+				printFail("%s: не все события о публикации доставлены: %v", repo, err)
+			}
+		}
+	}
+	if err := sum.Err(); err != nil {
+		return fail(exitCodeFor(err, exitAPI), "%v", err)
+	}
+	return nil
+}
+
+func aggregateRepo(ctx context.Context, fetch specFetcher, dir, prefix string, cfg config.Config, rc config.RepoConfig, glossary *prose.Glossary, logf func(format string, args ...any)) ([]string, error) {
+	apis, contents, err := fetchSpecs(ctx, fetch, cfg, rc)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkGlossary(glossary, rc, apis, contents, logf); err != nil {
+		return nil, err
+	}
+	return publishSpecs(dir, prefix, rc, apis, contents)
+}
+
+func docsPrefix(ctx context.Context, client *gitea.Client, cfg config.Config, dir, repo, branch string) (string, error) {
+	if len(cfg.BranchEnvironments) == 0 {
+		return "", nil
+	}
+	if branch == "" {
+		r, err := client.GetRepo(ctx, cfg.Organization, repo)
+		if err != nil {
+			return "", err
+		}
+		branch = r.DefaultBranch
+	}
+	docsBranch, prefix := cfg.DocsTarget(branch, "")
+	if current, err := git.Output(ctx, dir, "rev-parse", "--abbrev-ref", "HEAD"); err == nil && strings.TrimSpace(current) != docsBranch {
+		return "", fmt.Errorf("ветка %s публикуется в ветку %s репозитория документации, а %s на ветке %s", branch, docsBranch, dir, strings.TrimSpace(current))
+	}
+	return prefix, nil
+}
+
+func fetchSpecs(ctx context.Context, fetch specFetcher, cfg config.Config, rc config.RepoConfig) ([]config.APIConfig, [][]byte, error) {
+	apis := rc.Specs()
+	if err := cfg.Limits.CheckFiles(rc.Name, len(apis)); err != nil {
+		return nil, nil, err
+	}
+	paths := make([]string, len(apis))
+	for i, api := range apis {
+		paths[i] = api.SpecPath
+	}
+	contents, err := fetch(ctx, rc.Name, paths)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i, api := range apis {
+		data := contents[i]
+		if err := cfg.Limits.CheckSpec(api.SpecPath, len(data)); err != nil {
+			return nil, nil, err
+		}
+		if spec.IsSwagger2(data) {
+			if cfg.Swagger2 == "fail" {
+				return nil, nil, fmt.Errorf("%s: %w (swagger2: fail)", api.SpecPath, spec.ErrSwagger2)
+			}
+			if data, err = spec.ConvertSwagger2(data); err != nil {
+				return nil, nil, fmt.Errorf("%s: преобразование Swagger 2.0: %w", api.SpecPath, err)
+			}
+			if err := cfg.Limits.CheckSpec(api.SpecPath, len(data)); err != nil {
+				return nil, nil, err
+			}
+		}
+		if _, err := spec.ParseDocument(data); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", api.SpecPath, err)
+		}
+		contents[i] = data
+	}
+	return apis, contents, nil
+}
+
+// publishedPath is where api of rc is published in the docs repo, relative to
+// its root; prefix is the environment directory from docsPrefix.
+func publishedPath(prefix string, rc config.RepoConfig, api config.APIConfig) string {
+	return prefix + rc.DocsName(api) + "/openapi.yaml"
+}
+
+func publishSpecs(dir, prefix string, rc config.RepoConfig, apis []config.APIConfig, contents [][]byte) ([]string, error) {
+	var changed []string
+	for i, api := range apis {
+		rel := publishedPath(prefix, rc, api)
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if current, err := os.ReadFile(path); err == nil && bytes.Equal(current, contents[i]) {
+			continue
+		}
+		if err := writeFile(path, contents[i], 0o644); err != nil {
+			return nil, err
+		}
+		changed = append(changed, rel)
+	}
+	if len(changed) == 0 {
+		return nil, nil
+	}
+	if skipInDryRun("будет обновлён %s", filepath.Join(dir, spec.ManifestFile)) {
+		return changed, nil
+	}
+	return changed, spec.UpdateManifest(dir, changed)
+}
+
+func apiFetcher(client *gitea.Client, cfg config.Config, ref string) specFetcher {
+	return func(ctx context.Context, repo string, specPaths []string) ([][]byte, error) {
+		contents := make([][]byte, len(specPaths))
+		for i, specPath := range specPaths {
+			data, _, err := client.GetFile(ctx, cfg.Organization, repo, specPath, ref)
+			var apiErr *gitea.APIError
+			if errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound {
+				return nil, fmt.Errorf("%s не найден", specPath)
+			}
+			if err != nil {
+				return nil, err
+			}
+			contents[i] = data
+		}
+		return contents, nil
+	}
+}
+
+func cloneFetcher(cfg config.Config, token, ref string) specFetcher {
+	return func(ctx context.Context, repo string, specPaths []string) ([][]byte, error) {
+		tmp, err := os.MkdirTemp("", "openapi-aggregate-")
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(tmp)
+
+		if err := cloneRepo(ctx, cfg, token, repo, ref, filepath.Join(tmp, "src")); err != nil {
+			return nil, err
+		}
+		contents := make([][]byte, len(specPaths))
+		for i, specPath := range specPaths {
+			data, err := os.ReadFile(filepath.Join(tmp, "src", filepath.FromSlash(specPath)))
+			if os.IsNotExist(err) {
+				return nil, fmt.Errorf("%s не найден", specPath)
+			}
+			if err != nil {
+				return nil, err
+			}
+			contents[i] = data
+		}
+		return contents, nil
+	}
+}
+
+// cloneRepo makes a shallow clone of repo at ref in dest, hiding the token
+// from the error.
+func cloneRepo(ctx context.Context, cfg config.Config, token, repo, ref, dest string) error {
+	scheme, host, ok := strings.Cut(cfg.GiteaHost, "://")
+	if !ok {
+		scheme, host = "https", cfg.GiteaHost
+	}
+	remote := fmt.Sprintf("%s://%s@%s/%s/%s.git", scheme, token, strings.TrimSuffix(host, "/"), cfg.Organization, repo)
+	args := []string{"clone", "-q", "--depth", "1"}
+	if ref != "" {
+		args = append(args, "--branch", ref)
+	}
+	if err := git.Run(ctx, filepath.Dir(dest), append(args, remote, filepath.Base(dest))...); err != nil {
+		return errors.New(strings.ReplaceAll(err.Error(), token, "***"))
+	}
+	return nil
+}
+
+func docsRepoFlag(fs *flag.FlagSet, cfg config.Config) *string {
+	return fs.String("docs-repo", cfg.DocsRepo, "репозиторий документации: обрабатываются только направленные в него репозитории (docs_routes)")
+}
+
+func routedRepos(cfg config.Config, docsRepo string) ([]string, error) {
+	repos := cfg.ReposFor(docsRepo)
+	if len(repos) == 0 {
+		return nil, fail(exitConfigInvalid, "Ни один репозиторий не направлен в %s (доступны: %s)", docsRepo, strings.Join(cfg.DocsRepos(), ", "))
+	}
+	return repos, nil
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/RastBast/docs12121/pkg/batch"
+	"github.com/RastBast/docs12121/pkg/config"
+	"github.com/RastBast/docs12121/pkg/git"
+	"github.com/RastBast/docs12121/pkg/gitea"
+	"github.com/RastBast/docs12121/pkg/report"
+	"github.com/RastBast/docs12121/pkg/spec"
+)
+
+const (
+	DriftMissing = "missing"
+	DriftStale   = "stale"
+	DriftManual  = "manual"
+)
+
+type Drift struct {
+	Repo     string        `json:"repo"`
+	Name     string        `json:"name"`
+	SpecPath string        `json:"spec_path"`
+	Kind     string        `json:"kind"`
+	Author   string        `json:"author,omitempty"`
+	Changes  []spec.Change `json:"changes,omitempty"`
+	Fixed    bool          `json:"fixed,omitempty"`
+}
+
+func (d Drift) Describe() string {
+	switch d.Kind {
+	case DriftMissing:
+		return "не опубликована"
+	case DriftManual:
+		return "опубликованная версия изменена вручную (" + d.Author + ")"
+	default:
+		return "опубликована устаревшая версия (вероятно, пропущен вебхук)"
+	}
+}
+
+func auditDocs(ctx context.Context, cfg config.Config, args []string) error {
+	fs := newFlagSet("audit")
+	ref := fs.String("ref", "", "ветка или тег в репозиториях сервисов (по умолчанию ветка по умолчанию)")
+	source := fs.String("source", "api", "откуда брать спецификации: api (Gitea API) или clone (git clone)")
+	fix := fs.Bool("fix", false, "заменить расходящиеся спецификации версиями из репозиториев сервисов")
+	commit := fs.Bool("commit", false, "закоммитить исправления в репозиторий документации (с --fix)")
+	push := fs.Bool("push", false, "отправить коммит с исправлениями в удалённый репозиторий документации (с --commit)")
+	format := fs.String("format", "text", "формат отчёта: text или json")
+	every := fs.Duration("every", 0, "повторять проверку с этим интервалом, например 24h (0 — один раз)")
+	docsRepo := docsRepoFlag(fs, cfg)
+	fs.Parse(args)
+
+	dir := argOrDefault(fs.Args(), 0, ".")
+	if err := cfg.Validate(); err != nil {
+		return fail(exitConfigInvalid, "Некорректная конфигурация: %v", err)
+	}
+	if *format != "text" && *format != "json" {
+		return fail(exitConfigInvalid, "Неизвестный формат %q. Доступные форматы: text, json", *format)
+	}
+	if *commit && !*fix {
+		return fail(exitConfigInvalid, "--commit используется только вместе с --fix")
+	}
+	if *push && !*commit {
+		return fail(exitConfigInvalid, "--push используется только вместе с --commit")
+	}
+	token := os.Getenv("GITEA_TOKEN")
+	if token == "" {
+		return fail(exitConfigInvalid, "Не задан GITEA_TOKEN")
+	}
+
+	client := gitea.NewClient(cfg.GiteaHost, token)
+	var fetch specFetcher
+	switch *source {
+	case "api":
+		fetch = apiFetcher(client, cfg, *ref)
+	case "clone":
+		fetch = cloneFetcher(cfg, token, *ref)
+	default:
+		return fail(exitConfigInvalid, "Неизвестный источник %q (доступны: api, clone)", *source)
+	}
+	repos, err := routedRepos(cfg, *docsRepo)
+	if err != nil {
+		return err
+	}
+
+	run := func() ([]Drift, error) {
+		ctx, cancel := withTimeout(ctx)
+		defer cancel()
+		target := func(ctx context.Context, repo string) (string, error) {
+			return docsPrefix(ctx, client, cfg, dir, repo, *ref)
+		}
+		return auditRun(ctx, cfg, fetch, target, dir, repos, *fix, *commit, *push, *format == "text")
+	}
+	if *every <= 0 {
+		drifts, err := run()
+		if *format == "json" {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(drifts); err != nil {
+				return fail(exitError, "Ошибка записи: %v", err)
+			}
+		}
+		if err != nil {
+			return fail(exitCodeFor(err, exitAPI), "%v", err)
+		}
+		for _, d := range drifts {
+			if !d.Fixed {
+				return fail(exitValidation, "Опубликованные спецификации расходятся с источниками: %d", len(drifts))
+			}
+		}
+		return nil
+	}
+
+	printStart("Проверка согласованности каждые %s", *every)
+	ticker := time.NewTicker(*every)
+	defer ticker.Stop()
+	for {
+		if _, err := run(); err != nil {
+			printFail("Проверка завершилась с ошибками: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func auditRun(ctx context.Context, cfg config.Config, fetch specFetcher, target func(ctx context.Context, repo string) (string, error), dir string, repos []string, fix, commit, push, verbose bool) ([]Drift, error) {
+	var sum report.Summary
+	var drifts []Drift
+	healed := 0
+	runner := &batch.Runner{}
+	if verbose {
+		runner.Logf = printInfo
+	}
+	err := runner.Run(ctx, repos, &sum, func(ctx context.Context, repo string) error {
+		rc := cfg.Repo(repo)
+		prefix, err := target(ctx, repo)
+		if err != nil {
+			return err
+		}
+		apis, contents, err := fetchSpecs(ctx, fetch, cfg, rc)
+		if err != nil {
+			return err
+		}
+		found, err := auditRepo(ctx, cfg, dir, prefix, rc, apis, contents)
+		if err != nil {
+			return err
+		}
+		if fix && len(found) > 0 {
+			changed, err := publishSpecs(dir, prefix, rc, apis, contents)
+			if err != nil {
+				return err
+			}
+			healed += len(changed)
+			for i := range found {
+				found[i].Fixed = true
+			}
+		}
+		if verbose {
+			for _, d := range found {
+				line := fmt.Sprintf("%s: %s", d.Name, d.Describe())
+				if len(d.Changes) > 0 {
+					line += fmt.Sprintf(", изменений: %d", len(d.Changes))
+				}
+				if d.Fixed {
+					printOK("%s — исправлено", line)
+				} else {
+					printFail("%s", line)
+				}
+			}
+			if len(found) == 0 {
+				printOK("%s: совпадает с источником", repo)
+			}
+		}
+		drifts = append(drifts, found...)
+		return nil
+	})
+	if verbose {
+		printSummary(&sum)
+	}
+	if err != nil {
+		return drifts, fmt.Errorf("проверка прервана: %w", err)
+	}
+
+	if commit && healed > 0 && !skipInDryRun("исправления не будут закоммичены в %s", dir) {
+		author := git.Author{Name: "OpenAPI Aggregator Bot", Email: "openapi-bot@" + cfg.GiteaHost}
+		if err := git.Run(ctx, dir, "add", "."); err != nil {
+			return drifts, fmt.Errorf("ошибка добавления файлов: %w", err)
+		}
+		if err := git.Commit(ctx, dir, author, cfg.Tracking.CommitMessage(fmt.Sprintf("Restore OpenAPI docs from sources (%d fixed)", healed))); err != nil {
+			return drifts, fmt.Errorf("ошибка коммита: %w", err)
+		}
+		if verbose {
+			printOK("Исправления закоммичены в %s", dir)
+		}
+		if push {
+			if err := git.PushRebased(ctx, dir, author); err != nil {
+				return drifts, fmt.Errorf("ошибка отправки исправлений: %w", err)
+			}
+			if verbose {
+				printOK("Исправления отправлены в удалённый репозиторий")
+			}
+		}
+	}
+	return drifts, sum.Err()
+}
+
+func auditRepo(ctx context.Context, cfg config.Config, dir, prefix string, rc config.RepoConfig, apis []config.APIConfig, contents [][]byte) ([]Drift, error) {
+	var drifts []Drift
+	for i, api := range apis {
+		d := Drift{Repo: rc.Name, Name: prefix + rc.DocsName(api), SpecPath: api.SpecPath}
+		rel := d.Name + "/openapi.yaml"
+		published, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(rel)))
+		switch {
+		case os.IsNotExist(err):
+			d.Kind = DriftMissing
+			drifts = append(drifts, d)
+			continue
+		case err != nil:
+			return nil, err
+		case bytes.Equal(published, contents[i]):
+			continue
+		}
+
+		d.Kind = DriftStale
+		if author, err := git.Output(ctx, dir, "log", "-1", "--format=%ae", "--", rel); err == nil {
+			author = strings.TrimSpace(author)
+			if author != "" && author != "openapi-bot@"+cfg.GiteaHost {
+				d.Kind, d.Author = DriftManual, author
+			}
+		}
+		if from, err := spec.ParseDocument(published); err == nil {
+			to, _ := spec.ParseDocument(contents[i])
+			d.Changes = spec.Diff(from, to)
+		}
+		drifts = append(drifts, d)
+	}
+	return drifts, nil
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"flag"
+	"time"
+
+	"github.com/RastBast/docs12121/pkg/batch"
+)
+
+type batchFlags struct {
+	size       *int
+	pause      *time.Duration
+	checkpoint *string
+}
+
+func addBatchFlags(fs *flag.FlagSet, command string) *batchFlags {
+	return &batchFlags{
+		size:       fs.Int("batch-size", 0, "сколько репозиториев обрабатывать за пакет (0 — все сразу)"),
+		pause:      fs.Duration("pause", 0, "пауза между пакетами"),
+		checkpoint: fs.String("checkpoint", ".openapi-aggregator/"+command+".checkpoint", "файл контрольной точки для продолжения прерванного запуска"),
+	}
+}
+
+func (f *batchFlags) runner() (*batch.Runner, error) {
+	cp, err := batch.LoadCheckpoint(*f.checkpoint)
+	if err != nil {
+		return nil, fail(exitError, "Ошибка чтения контрольной точки: %v", err)
+	}
+	return &batch.Runner{
+		BatchSize:  *f.size,
+		Pause:      *f.pause,
+		Checkpoint: cp,
+		Logf:       printInfo,
+	}, nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/RastBast/docs12121/pkg/config"
+	"github.com/RastBast/docs12121/pkg/spec"
+)
+
+func bundleSpec(ctx context.Context, cfg config.Config, args []string) error {
+	fs := newFlagSet("bundle")
+	dir := fs.String("dir", ".", "локальная копия репозитория документации")
+	shared := fs.String("shared-repo", cfg.SharedRepo, "репозиторий с общими компонентами")
+	output := fs.String("output", "-", "куда записать собранную спецификацию (- для stdout)")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		return fail(exitConfigInvalid, "Использование: bundle [--dir каталог] [--shared-repo имя] [--output файл] <репозиторий>")
+	}
+	if *shared == "" {
+		return fail(exitConfigInvalid, "Не указан репозиторий общих компонентов: задайте SHARED_REPO или --shared-repo")
+	}
+	repo := fs.Arg(0)
+
+	data, err := os.ReadFile(filepath.Join(*dir, repo, "openapi.yaml"))
+	if err != nil {
+		return fail(exitError, "Ошибка чтения спецификации: %v", err)
+	}
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	bundled, version, err := spec.Bundle(data, *shared, spec.SharedFromDocs(ctx, *dir, *shared))
+	if err != nil {
+		return fail(exitValidation, "Ошибка сборки %s: %v", repo, err)
+	}
+
+	if *output == "-" {
+		os.Stdout.Write(bundled)
+		return nil
+	}
+	if err := writeFile(*output, bundled, 0o644); err != nil {
+		return fail(exitError, "Ошибка записи %s: %v", *output, err)
+	}
+	if version == "" {
+		printOK("%s не ссылается на общие компоненты, записан без изменений: %s", repo, *output)
+		return nil
+	}
+	printOK("%s собран с %s@%s: %s", repo, *shared, version, *output)
+	return nil
+}
@@ -0,0 +1,365 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/RastBast/docs12121/pkg/config"
+	"github.com/RastBast/docs12121/pkg/gitea"
+	"github.com/RastBast/docs12121/pkg/server"
+	"github.com/RastBast/docs12121/pkg/spec"
+)
+
+const catalogUsage = "Использование: catalog list [--format text|json] | catalog show [--format text|json] <api> | catalog open [--spec] <api>"
+
+type catalogSource struct {
+	server *string
+	token  *string
+}
+
+func addCatalogFlags(fs *flag.FlagSet) catalogSource {
+	return catalogSource{
+		server: fs.String("server", os.Getenv("AGGREGATOR_SERVER"), "адрес serve (по умолчанию каталог читается из репозитория документации в Gitea)"),
+		token:  fs.String("token", os.Getenv("AGGREGATOR_SERVER_TOKEN"), "Bearer-токен для сервера с OIDC"),
+	}
+}
+
+func runCatalogCommand(ctx context.Context, cfg config.Config, args []string) error {
+	if len(args) == 0 {
+		return fail(exitConfigInvalid, catalogUsage)
+	}
+	switch args[0] {
+	case "list":
+		return listCatalog(ctx, cfg, args[1:])
+	case "show":
+		return showCatalogAPI(ctx, cfg, args[1:])
+	case "open":
+		return openCatalogAPI(ctx, cfg, args[1:])
+	default:
+		return fail(exitConfigInvalid, "Неизвестная подкоманда %q. Доступные подкоманды: list, show, open", args[0])
+	}
+}
+
+func listCatalog(ctx context.Context, cfg config.Config, args []string) error {
+	fs := newFlagSet("catalog list")
+	src := addCatalogFlags(fs)
+	format := fs.String("format", "text", "формат вывода: text или json")
+	fs.Parse(args)
+
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	entries, err := src.load(ctx, cfg)
+	if err != nil {
+		return fail(exitAPI, "Ошибка получения каталога: %v", err)
+	}
+	switch *format {
+	case "json":
+		return printJSON(entries)
+	case "text":
+		for _, e := range entries {
+			version := e.Version
+			if version == "" {
+				version = "-"
+			}
+			fmt.Printf("%-24s %-10s %s\n", e.Name, version, e.Title)
+		}
+	default:
+		return fail(exitConfigInvalid, "Неизвестный формат %q. Доступные форматы: text, json", *format)
+	}
+	return nil
+}
+
+func showCatalogAPI(ctx context.Context, cfg config.Config, args []string) error {
+	fs := newFlagSet("catalog show")
+	src := addCatalogFlags(fs)
+	format := fs.String("format", "text", "формат вывода: text или json")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fail(exitConfigInvalid, "Использование: catalog show [--format text|json] <api>")
+	}
+
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	entries, err := src.load(ctx, cfg)
+	if err != nil {
+		return fail(exitAPI, "Ошибка получения каталога: %v", err)
+	}
+	e, err := findCatalogEntry(entries, fs.Arg(0))
+	if err != nil {
+		return fail(exitError, "%v", err)
+	}
+	if e, err = src.pages(ctx, cfg, e); err != nil {
+		return fail(exitAPI, "Ошибка получения каталога: %v", err)
+	}
+	versions, err := src.versions(ctx, e.Name)
+	if err != nil {
+		printFail("%s: история версий не получена: %v", e.Name, err)
+	}
+
+	switch *format {
+	case "json":
+		return printJSON(struct {
+			server.APIEntry
+			Versions []spec.Version `json:"versions,omitempty"`
+		}{e, versions})
+	case "text":
+		if e.Title != "" {
+			fmt.Printf("%s — %s\n", e.Name, e.Title)
+		} else {
+			fmt.Println(e.Name)
+		}
+		for _, field := range []struct{ label, value string }{
+			{"Версия", e.Version},
+			{"Описание", strings.Join(strings.Fields(e.Description), " ")},
+			{"Спецификация", e.SpecURL},
+			{"Документация", e.DocsURL},
+			{"История", e.HistoryURL},
+		} {
+			if field.value != "" {
+				fmt.Printf("  %-13s %s\n", field.label+":", field.value)
+			}
+		}
+		if len(versions) > 0 {
+			fmt.Println("  Версии:")
+			for _, v := range versions {
+				fmt.Printf("    %-10s %s  %.7s\n", v.Version, v.Date.Local().Format("2006-01-02"), v.Commit)
+			}
+		}
+	default:
+		return fail(exitConfigInvalid, "Неизвестный формат %q. Доступные форматы: text, json", *format)
+	}
+	return nil
+}
+
+func openCatalogAPI(ctx context.Context, cfg config.Config, args []string) error {
+	fs := newFlagSet("catalog open")
+	src := addCatalogFlags(fs)
+	openSpec := fs.Bool("spec", false, "открыть спецификацию вместо страницы документации")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fail(exitConfigInvalid, "Использование: catalog open [--spec] <api>")
+	}
+
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	entries, err := src.load(ctx, cfg)
+	if err != nil {
+		return fail(exitAPI, "Ошибка получения каталога: %v", err)
+	}
+	e, err := findCatalogEntry(entries, fs.Arg(0))
+	if err != nil {
+		return fail(exitError, "%v", err)
+	}
+	if e, err = src.pages(ctx, cfg, e); err != nil {
+		return fail(exitAPI, "Ошибка получения каталога: %v", err)
+	}
+	target := e.DocsURL
+	if *openSpec || target == "" {
+		target = e.SpecURL
+	}
+	if skipInDryRun("будет открыт %s", target) {
+		return nil
+	}
+	if err := openBrowser(target); err != nil {
+		printInfo("Браузер не запущен (%v), откройте ссылку вручную:", err)
+		fmt.Println(target)
+		return nil
+	}
+	printOK("%s: открыт %s", e.Name, target)
+	return nil
+}
+
+func (s catalogSource) load(ctx context.Context, cfg config.Config) ([]server.APIEntry, error) {
+	if *s.server != "" {
+		return serverCatalog(ctx, *s.server, *s.token)
+	}
+	return docsRepoCatalog(ctx, cfg)
+}
+
+// pages replaces the Gitea links of a docs-repo entry with the portal pages
+// that exist for it; server entries already carry their own links.
+func (s catalogSource) pages(ctx context.Context, cfg config.Config, e server.APIEntry) (server.APIEntry, error) {
+	base := cfg.PortalBase()
+	if *s.server != "" || !strings.Contains(base, "://") {
+		return e, nil
+	}
+	client := gitea.NewClient(cfg.GiteaHost, os.Getenv("GITEA_TOKEN"))
+	for _, page := range []struct {
+		dir    string
+		target *string
+	}{
+		{"interactive", &e.DocsURL},
+		{"static", &e.DocsURL},
+		{"history", &e.HistoryURL},
+	} {
+		if strings.HasPrefix(*page.target, base) {
+			continue
+		}
+		file := page.dir + "/" + e.Name + "/index.html"
+		ok, err := client.FileExists(ctx, cfg.Organization, cfg.DocsRepo, file, cfg.DocsBranchName())
+		if err != nil {
+			return e, fmt.Errorf("%s: %w", file, err)
+		}
+		if ok {
+			*page.target = base + file
+		}
+	}
+	return e, nil
+}
+
+func (s catalogSource) versions(ctx context.Context, name string) ([]spec.Version, error) {
+	if *s.server == "" {
+		return nil, nil
+	}
+	client := runsClient{base: strings.TrimSuffix(*s.server, "/"), token: *s.token}
+	var versions []spec.Version
+	err := client.getJSON(ctx, "/apis/"+url.PathEscape(name)+"/versions", &versions)
+	return versions, err
+}
+
+func serverCatalog(ctx context.Context, serverURL, token string) ([]server.APIEntry, error) {
+	base, err := url.Parse(strings.TrimSuffix(serverURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("некорректный адрес сервера %q: %w", serverURL, err)
+	}
+	client := runsClient{base: base.String(), token: token}
+	var entries []server.APIEntry
+	if err := client.getJSON(ctx, "/apis", &entries); err != nil {
+		return nil, err
+	}
+	public := *base
+	public.User = nil
+	for i := range entries {
+		e := &entries[i]
+		for _, link := range []*string{&e.SpecURL, &e.DocsURL, &e.VersionsURL, &e.HistoryURL} {
+			if ref, err := url.Parse(*link); err == nil && *link != "" {
+				*link = public.ResolveReference(ref).String()
+			}
+		}
+	}
+	return entries, nil
+}
+
+func docsRepoCatalog(ctx context.Context, cfg config.Config) ([]server.APIEntry, error) {
+	paths, err := configPaths()
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 && os.Getenv("GITEA_HOST") == "" {
+		return nil, fmt.Errorf("конфигурация не найдена (%s, --config или GITEA_HOST); укажите её или адрес сервера через --server", config.DefaultFile)
+	}
+	if cfg.GiteaHost == "" || cfg.Organization == "" || cfg.DocsRepo == "" {
+		return nil, errors.New("в конфигурации не заданы gitea_host, organization или docs_repo; укажите их или адрес сервера через --server")
+	}
+	client := gitea.NewClient(cfg.GiteaHost, os.Getenv("GITEA_TOKEN"))
+	branch := cfg.DocsBranchName()
+	data, _, err := client.GetFile(ctx, cfg.Organization, cfg.DocsRepo, spec.ManifestFile, branch)
+	if err != nil {
+		return nil, fmt.Errorf("чтение %s из %s/%s: %w", spec.ManifestFile, cfg.Organization, cfg.DocsRepo, err)
+	}
+	manifest, err := spec.ParseManifest(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for path := range manifest {
+		name, ok := strings.CutSuffix(path, "/openapi.yaml")
+		if _, rest := spec.SplitEnvironment(name); ok && strings.Count(rest, "/") <= 1 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	base := cfg.PortalBase()
+	portal := strings.Contains(base, "://")
+	entries := []server.APIEntry{}
+	for _, name := range names {
+		rel := name + "/openapi.yaml"
+		data, _, err := client.GetFile(ctx, cfg.Organization, cfg.DocsRepo, rel, branch)
+		if err != nil {
+			printFail("%s пропущен: %v", rel, err)
+			continue
+		}
+		info, err := spec.ParseInfo(data)
+		if err != nil {
+			printFail("%s пропущен: %v", rel, err)
+			continue
+		}
+		e := server.APIEntry{
+			Name:        name,
+			Title:       info.Title,
+			Version:     info.Version,
+			Description: info.Description,
+			SpecURL:     docsRawURL(cfg, rel),
+			DocsURL:     docsRepoURL(cfg, "src", rel),
+			HistoryURL:  docsRepoURL(cfg, "commits", rel),
+		}
+		if portal {
+			e.SpecURL = base + rel
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func findCatalogEntry(entries []server.APIEntry, name string) (server.APIEntry, error) {
+	var matches []server.APIEntry
+	for _, e := range entries {
+		if e.Name == name {
+			return e, nil
+		}
+		if spec.SpecRepo(e.Name) == name {
+			matches = append(matches, e)
+		}
+	}
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		names := make([]string, 0, len(entries))
+		for _, e := range entries {
+			names = append(names, e.Name)
+		}
+		return server.APIEntry{}, fmt.Errorf("API %q нет в каталоге. Доступные API: %s", name, strings.Join(names, ", "))
+	}
+	names := make([]string, 0, len(matches))
+	for _, e := range matches {
+		names = append(names, e.Name)
+	}
+	return server.APIEntry{}, fmt.Errorf("в %s несколько API, уточните: %s", name, strings.Join(names, ", "))
+}
+
+func openBrowser(target string) error {
+	if u, err := url.Parse(target); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("открываются только ссылки http(s), получено %q", target)
+	}
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", target)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", target)
+	default:
+		cmd = exec.Command("xdg-open", target)
+	}
+	return cmd.Run()
+}
+
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fail(exitError, "Ошибка записи: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/RastBast/docs12121/pkg/config"
+)
+
+type command struct {
+	name        string
+	summary     string
+	subcommands []string
+	config      bool
+	run         func(ctx context.Context, cfg config.Config, args []string) error
+}
+
+var sharedFlags = []string{"config", "env"}
+
+func commandTable() []command {
+	return []command{
+		{name: "setup", summary: "настроить проект: .env, воркфлоу и README", run: func(ctx context.Context, _ config.Config, args []string) error { return setupProject(ctx, args) }},
+		{name: "generate", summary: "сгенерировать воркфлоу и README по конфигурации", config: true, run: func(_ context.Context, cfg config.Config, args []string) error { return generateWorkflows(cfg, args) }},
+		{name: "onboard", summary: "подключить репозиторий: спецификация, воркфлоу, секреты, конфигурация, каталог", config: true, run: onboardRepo},
+		{name: "discover", summary: "найти в организации репозитории со спецификациями", config: true, run: discoverRepos},
+		{name: "deploy", summary: "установить воркфлоу в репозитории сервисов", config: true, run: deployWorkflows},
+		{name: "sync", summary: "сверить воркфлоу в репозиториях с шаблоном и открыть pull request с обновлением", config: true, run: syncWorkflows},
+		{name: "upgrade", summary: "обновить воркфлоу в репозиториях сервисов (псевдоним sync)", config: true, run: upgradeWorkflows},
+		{name: "secrets", summary: "задать секреты воркфлоу в репозиториях сервисов", config: true, run: provisionSecrets},
+		{name: "install-hooks", summary: "установить git-хуки проверки спецификации", config: true, run: installHooks},
+		{name: "aggregate", summary: "собрать спецификации в локальную копию документации", config: true, run: aggregateDocs},
+		{name: "audit", summary: "сверить опубликованные спецификации с источниками", config: true, run: auditDocs},
+		{name: "status", summary: "показать состояние публикации по репозиториям", config: true, run: docsStatus},
+		{name: "verify", summary: "проверить документацию на правки в обход агрегатора", run: func(_ context.Context, _ config.Config, args []string) error {
+			fs := newFlagSet("verify")
+			fs.Parse(args)
+			return verifyDocs(argOrDefault(fs.Args(), 0, "."))
+		}},
+		{name: "validate", summary: "проверить спецификации на соответствие OpenAPI", run: func(_ context.Context, _ config.Config, args []string) error { return validateSpecs(args) }},
+		{name: "lint", summary: "проверить спецификации по правилам стиля", run: func(_ context.Context, _ config.Config, args []string) error { return lintSpecs(args) }},
+		{name: "lint-prose", summary: "проверить тексты спецификаций: термины, глоссарий, орфография", run: func(_ context.Context, _ config.Config, args []string) error { return lintProse(args) }},
+		{name: "diff", summary: "сравнить спецификацию с опубликованной и найти ломающие изменения", run: func(_ context.Context, _ config.Config, args []string) error { return diffSpecs(args) }},
+		{name: "check-version", summary: "проверить, что info.version соответствует изменениям", run: func(_ context.Context, _ config.Config, args []string) error { return checkVersion(args) }},
+		{name: "spec", summary: "предпросмотр, конвертация и заготовка спецификации", subcommands: []string{"preview", "convert", "scaffold"}, config: true, run: runSpecCommand},
+		{name: "bundle", summary: "собрать спецификацию вместе с общими компонентами", config: true, run: bundleSpec},
+		{name: "merge", summary: "объединить спецификации в одну", config: true, run: mergeSpecs},
+		{name: "code-samples", summary: "добавить в спецификацию примеры вызовов", run: func(_ context.Context, _ config.Config, args []string) error { return generateCodeSamples(args) }},
+		{name: "translate", summary: "собрать локализованные спецификации", run: func(_ context.Context, _ config.Config, args []string) error { return translateSpecs(args) }},
+		{name: "render", summary: "собрать сайт документации", config: true, run: func(_ context.Context, cfg config.Config, args []string) error { return renderSite(cfg, args) }},
+		{name: "portal", summary: "обновить страницу портала со списком API", config: true, run: func(_ context.Context, cfg config.Config, args []string) error { return generatePortal(cfg, args) }},
+		{name: "readme", summary: "собрать README репозитория документации с таблицей API и значками версий", config: true, run: generateDocsReadme},
+		{name: "history-pages", summary: "собрать страницы истории версий", config: true, run: generateHistoryPages},
+		{name: "compare-pages", summary: "собрать страницу сравнения версий и окружений", config: true, run: generateComparePages},
+		{name: "onboarding-pages", summary: "собрать страницы «Начало работы»", config: true, run: func(_ context.Context, cfg config.Config, args []string) error {
+			return generateOnboardingPages(cfg, args)
+		}},
+		{name: "digest", summary: "подготовить еженедельный дайджест изменений", config: true, run: weeklyDigest},
+		{name: "sunset-calendar", summary: "выгрузить календарь вывода операций из эксплуатации", run: func(_ context.Context, _ config.Config, args []string) error { return exportSunsetCalendar(args) }},
+		{name: "report", summary: "отчёты по спецификациям", subcommands: []string{"errors", "pagination", "duplicates", "webhooks", "oauth", "slo", "sdk"}, run: func(_ context.Context, _ config.Config, args []string) error { return runReportCommand(args) }},
+		{name: "sdk", summary: "учёт опубликованных SDK", subcommands: []string{"record"}, run: func(_ context.Context, _ config.Config, args []string) error { return runSDKCommand(args) }},
+		{name: "export", summary: "выгрузить каталог спецификаций в архив или описание для IaC", config: true, run: func(_ context.Context, cfg config.Config, args []string) error { return exportCatalog(cfg, args) }},
+		{name: "oci-push", summary: "опубликовать спецификацию в OCI-реестре", config: true, run: pushSpecOCI},
+		{name: "release-assets", summary: "приложить спецификации к релизу", config: true, run: attachReleaseAssets},
+		{name: "mirror", summary: "отзеркалировать документацию", config: true, run: func(ctx context.Context, cfg config.Config, args []string) error {
+			fs := newFlagSet("mirror")
+			fs.Parse(args)
+			return mirrorDocs(ctx, cfg, argOrDefault(fs.Args(), 0, "."))
+		}},
+		{name: "metrics", summary: "отправить метрики публикации", config: true, run: pushMetricsCommand},
+		{name: "dispatch", summary: "уведомления о публикации для подписчиков", subcommands: []string{"send", "log"}, config: true, run: runDispatchCommand},
+		{name: "serve", summary: "запустить сервер документации", run: func(ctx context.Context, _ config.Config, args []string) error { return serveDocs(ctx, args) }},
+		{name: "listen", summary: "принимать вебхуки Gitea и запускать сборку", config: true, run: listenWebhooks},
+		{name: "catalog", summary: "каталог опубликованных API: список, описание и ссылки на документацию", subcommands: []string{"list", "show", "open"}, config: true, run: runCatalogCommand},
+		{name: "runs", summary: "показать задачи сервера", run: func(ctx context.Context, _ config.Config, args []string) error { return listRuns(ctx, args) }},
+		{name: "gc", summary: "удалить устаревшие версии и задачи", config: true, run: collectGarbage},
+		{name: "state", summary: "выгрузить или восстановить состояние сервера", subcommands: []string{"export", "import"}, config: true, run: func(_ context.Context, cfg config.Config, args []string) error { return runStateCommand(cfg, args) }},
+		{name: "test", summary: "сквозная проверка в Gitea из Docker", subcommands: []string{"e2e"}, run: func(ctx context.Context, _ config.Config, args []string) error { return runTestCommand(ctx, args) }},
+		{name: "help", summary: "справка по команде", run: func(_ context.Context, _ config.Config, args []string) error { return showHelp(args) }},
+		{name: "completion", summary: "скрипт автодополнения для bash, zsh или fish", subcommands: []string{"bash", "zsh", "fish"}, run: func(_ context.Context, _ config.Config, args []string) error {
+			return writeCompletion(os.Stdout, args)
+		}},
+	}
+}
+
+func findCommand(name string) (command, bool) {
+	for _, c := range commandTable() {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return command{}, false
+}
+
+func commandNames() []string {
+	var names []string
+	for _, c := range commandTable() {
+		names = append(names, c.name)
+	}
+	return names
+}
+
+func printCommands(w io.Writer) {
+	for _, c := range commandTable() {
+		fmt.Fprintf(w, "  %-17s %s\n", c.name, c.summary)
+	}
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	for _, shared := range sharedFlags {
+		f := flag.Lookup(shared)
+		fs.String(f.Name, f.Value.String(), f.Usage)
+	}
+	fs.Usage = func() {
+		out := fs.Output()
+		fmt.Fprintf(out, "Использование: openapi-aggregator %s [флаги]\n", name)
+		if c, ok := findCommand(strings.Fields(name)[0]); ok {
+			fmt.Fprintf(out, "%s\n", c.summary)
+		}
+		fmt.Fprintln(out, "\nФлаги:")
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+func hoistSharedFlags(args []string) error {
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--" {
+			return nil
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(args[i], "-"), "=")
+		if !strings.HasPrefix(args[i], "-") || !slices.Contains(sharedFlags, name) {
+			continue
+		}
+		if !hasValue {
+			if i+1 == len(args) {
+				return fail(exitConfigInvalid, "Флагу -%s нужно значение", name)
+			}
+			i++
+			value = args[i]
+		}
+		if err := flag.Set(name, value); err != nil {
+			return fail(exitConfigInvalid, "-%s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// helpRequested reports a -h that the command's flag set would see: one
+// before the first positional argument, not counting the subcommand name.
+func helpRequested(args []string, subcommands bool) bool {
+	if subcommands && len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		args = args[1:]
+	}
+	for _, a := range args {
+		switch {
+		case a == "--":
+			return false
+		case a == "-h", a == "-help", a == "--help":
+			return true
+		case !strings.HasPrefix(a, "-"):
+			return false
+		}
+	}
+	return false
+}
+
+func showHelp(args []string) error {
+	if len(args) == 0 {
+		flag.CommandLine.SetOutput(os.Stdout)
+		flag.Usage()
+		return nil
+	}
+	c, ok := findCommand(args[0])
+	if !ok {
+		return fail(exitConfigInvalid, "Неизвестная команда %q. Доступные команды: %s", args[0], strings.Join(commandNames(), ", "))
+	}
+	if len(c.subcommands) > 0 && len(args) == 1 {
+		fmt.Printf("Использование: openapi-aggregator %s <%s> [флаги]\n%s\n\nПодробнее: openapi-aggregator help %s <подкоманда>\n",
+			c.name, strings.Join(c.subcommands, "|"), c.summary, c.name)
+		return nil
+	}
+	rest := append(args[1:len(args):len(args)], "-h")
+	return c.run(context.Background(), config.Config{}, rest)
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/RastBast/docs12121/pkg/config"
+	"github.com/RastBast/docs12121/pkg/report"
+	"github.com/RastBast/docs12121/pkg/spec"
+)
+
+func generateComparePages(ctx context.Context, cfg config.Config, args []string) error {
+	fs := newFlagSet("compare-pages")
+	output := fs.String("output", "", "каталог для страницы сравнения (по умолчанию compare в каталоге документации)")
+	versions := fs.Int("versions", 5, "сколько последних версий каждого сервиса предлагать для сравнения")
+	channels := fs.String("channels", "", "окружения для сравнения: имя=git-ссылка через запятую (по умолчанию из branch_environments)")
+	fs.Parse(args)
+
+	dir := "."
+	if fs.NArg() > 0 {
+		dir = fs.Arg(0)
+	}
+	out := *output
+	if out == "" {
+		out = filepath.Join(dir, "compare")
+	}
+	if *versions < 0 {
+		return fail(exitConfigInvalid, "--versions не может быть отрицательным")
+	}
+	refs := config.ParsePairs(*channels)
+	if *channels != "" && len(refs) == 0 {
+		return fail(exitConfigInvalid, "Некорректный --channels %q (ожидается имя=ссылка[,имя=ссылка])", *channels)
+	}
+
+	docs, err := readDocuments(dir)
+	if err != nil {
+		return fail(exitValidation, "Ошибка чтения спецификаций: %v", err)
+	}
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	page := &spec.ComparePage{Services: []spec.CompareService{}}
+	pairs := 0
+	var size int64
+	var sum report.Summary
+	for _, repo := range spec.SortedRepos(docs) {
+		svc, err := compareRepo(ctx, cfg, dir, out, repo, refs, *versions, &size)
+		if errors.Is(err, config.ErrLimit) {
+			return fail(exitValidation, "%v", err)
+		}
+		sum.Add(repo, err)
+		if err != nil {
+			printFail("%s: %v", repo, err)
+			continue
+		}
+		if len(svc.Sources) < 2 {
+			printInfo("%s: нечего сравнивать (доступна одна версия)", repo)
+			continue
+		}
+		pairs += len(svc.Pairs)
+		page.Services = append(page.Services, svc)
+	}
+
+	f, err := createFile(filepath.Join(out, "index.html"))
+	if err != nil {
+		return fail(exitError, "Ошибка создания страницы сравнения: %v", err)
+	}
+	err = page.WriteHTML(f)
+	f.Close()
+	if err != nil {
+		return fail(exitError, "Ошибка записи страницы сравнения: %v", err)
+	}
+	printWritten("Страница сравнения: %s (сервисов: %d, пар версий: %d)", out, len(page.Services), pairs)
+	if err := sum.Err(); err != nil {
+		printSummary(&sum)
+		return fail(exitCodeFor(err, exitError), "%v", err)
+	}
+	return nil
+}
+
+// compareRepo writes the diffs of every source against the current version
+// and of each pair of neighbouring sources, keeping the page linear in the
+// number of versions; size accumulates the bytes written for max_portal_size.
+func compareRepo(ctx context.Context, cfg config.Config, dir, out, repo string, refs map[string]string, versions int, size *int64) (spec.CompareService, error) {
+	svc := spec.CompareService{Repo: repo}
+	sources, err := compareSources(ctx, cfg, dir, repo, refs, versions)
+	if err != nil {
+		return svc, err
+	}
+	svc.Sources = sources
+	repoDir := filepath.Join(out, filepath.FromSlash(repo))
+	if err := removeAll(repoDir); err != nil {
+		return svc, fmt.Errorf("ошибка очистки %s: %w", repoDir, err)
+	}
+	if len(sources) < 2 {
+		return svc, nil
+	}
+	var pairs [][2]spec.CompareSource
+	for i := 1; i < len(sources); i++ {
+		pairs = append(pairs, [2]spec.CompareSource{sources[i], sources[i-1]})
+		if i > 1 {
+			pairs = append(pairs, [2]spec.CompareSource{sources[i], sources[0]})
+		}
+	}
+	for _, pair := range pairs {
+		c, err := spec.Compare(repo, pair[0], pair[1])
+		if err != nil {
+			return svc, err
+		}
+		data, err := json.Marshal(c)
+		if err != nil {
+			return svc, fmt.Errorf("ошибка сериализации сравнения: %w", err)
+		}
+		if err := cfg.Limits.CheckPortal(out, *size+int64(len(data))); err != nil {
+			return svc, err
+		}
+		path := filepath.Join(repoDir, c.File())
+		if err := writeFile(path, data, 0o644); err != nil {
+			return svc, fmt.Errorf("ошибка записи %s: %w", path, err)
+		}
+		*size += int64(len(data))
+		svc.Pairs = append(svc.Pairs, c.File())
+	}
+	return svc, nil
+}
+
+func compareSources(ctx context.Context, cfg config.Config, dir, repo string, refs map[string]string, versions int) ([]spec.CompareSource, error) {
+	var sources []spec.CompareSource
+	if data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(repo), "openapi.yaml")); err == nil {
+		sources = append(sources, spec.NewCompareSource("current", "tree", "текущая версия", data))
+	}
+
+	type channel struct{ name, ref, path string }
+	var list []channel
+	if len(refs) > 0 {
+		names := make([]string, 0, len(refs))
+		for name := range refs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			list = append(list, channel{name, refs[name], repo})
+		}
+	} else {
+		seen := map[string]bool{}
+		for _, e := range cfg.Environments() {
+			if seen[e.Environment] {
+				continue
+			}
+			seen[e.Environment] = true
+			ref, path := cfg.EnvironmentDocs(e.Environment, repo)
+			list = append(list, channel{e.Environment, ref, path})
+		}
+	}
+	for _, ch := range list {
+		data, err := spec.AtCommit(ctx, dir, ch.ref, ch.path)
+		if err != nil {
+			data, err = spec.AtCommit(ctx, dir, "origin/"+ch.ref, ch.path)
+		}
+		if err != nil {
+			printInfo("%s: нет спецификации в окружении %s (%s)", repo, ch.name, ch.ref)
+			continue
+		}
+		sources = append(sources, spec.NewCompareSource("env", ch.name, "окружение "+ch.name, data))
+	}
+
+	if versions == 0 {
+		return sources, nil
+	}
+	history, err := spec.History(ctx, dir, repo)
+	if err != nil {
+		printInfo("%s: история версий недоступна: %v", repo, err)
+		return sources, nil
+	}
+	for i, v := range history {
+		if i == versions {
+			break
+		}
+		data, err := spec.AtCommit(ctx, dir, v.Commit, repo)
+		if err != nil {
+			printInfo("%s: версия %s пропущена: %v", repo, v.Version, err)
+			continue
+		}
+		sources = append(sources, spec.NewCompareSource("v", v.Version, "v"+v.Version, data))
+	}
+	return sources, nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const bashCompletion = `_openapi_aggregator() {
+	local cur=${COMP_WORDS[COMP_CWORD]} cmd="" sub="" i
+	for ((i = 1; i < COMP_CWORD; i++)); do
+		case ${COMP_WORDS[i]} in
+		--config | -config | --env | -env | --timeout | -timeout | --fixtures | -fixtures | --log-format | -log-format)
+			[[ -z $cmd ]] && ((i++)) ;;
+		-*) ;;
+		*)
+			if [[ -z $cmd ]]; then
+				cmd=${COMP_WORDS[i]}
+			elif [[ -z $sub ]]; then
+				sub=${COMP_WORDS[i]}
+			fi
+			;;
+		esac
+	done
+	if [[ -z $cmd ]]; then
+		if [[ $cur == -* ]]; then
+			COMPREPLY=($(compgen -W "%s" -- "$cur"))
+		else
+			COMPREPLY=($(compgen -W "%s" -- "$cur"))
+		fi
+		return
+	fi
+	case $cmd in
+%s	esac
+	if [[ $cur == -* ]]; then
+		COMPREPLY=($(compgen -W "$("${COMP_WORDS[0]}" $cmd $sub -h 2>&1 | sed -n 's/^  -\([A-Za-z0-9-]*\).*/--\1/p')" -- "$cur"))
+		return
+	fi
+	COMPREPLY=($(compgen -f -- "$cur"))
+}
+complete -o filenames -F _openapi_aggregator openapi-aggregator
+`
+
+func writeCompletion(w io.Writer, args []string) error {
+	if len(args) != 1 {
+		return fail(exitConfigInvalid, "Использование: completion <bash|zsh|fish>")
+	}
+	switch args[0] {
+	case "bash":
+		_, err := io.WriteString(w, bashScript())
+		return err
+	case "zsh":
+		_, err := io.WriteString(w, "autoload -U +X bashcompinit && bashcompinit\n"+bashScript())
+		return err
+	case "fish":
+		_, err := io.WriteString(w, fishScript())
+		return err
+	}
+	return fail(exitConfigInvalid, "Неизвестная оболочка %q. Доступные оболочки: bash, zsh, fish", args[0])
+}
+
+func globalFlagNames() string {
+	var names []string
+	flag.VisitAll(func(f *flag.Flag) { names = append(names, "--"+f.Name) })
+	return strings.Join(names, " ")
+}
+
+func bashScript() string {
+	var subs strings.Builder
+	for _, c := range commandTable() {
+		if len(c.subcommands) > 0 {
+			fmt.Fprintf(&subs, "\t%s)\n\t\tif [[ -z $sub && $cur != -* ]]; then\n\t\t\tCOMPREPLY=($(compgen -W \"%s\" -- \"$cur\"))\n\t\t\treturn\n\t\tfi\n\t\t;;\n",
+				c.name, strings.Join(c.subcommands, " "))
+		}
+	}
+	return fmt.Sprintf(bashCompletion, globalFlagNames(), strings.Join(commandNames(), " "), subs.String())
+}
+
+func fishScript() string {
+	var b strings.Builder
+	b.WriteString("complete -c openapi-aggregator -f\n")
+	flag.VisitAll(func(f *flag.Flag) {
+		fmt.Fprintf(&b, "complete -c openapi-aggregator -n __fish_use_subcommand -l %s\n", f.Name)
+	})
+	for _, c := range commandTable() {
+		fmt.Fprintf(&b, "complete -c openapi-aggregator -n __fish_use_subcommand -a %s -d %q\n", c.name, c.summary)
+		for _, sub := range c.subcommands {
+			fmt.Fprintf(&b, "complete -c openapi-aggregator -n '__fish_seen_subcommand_from %s; and not __fish_seen_subcommand_from %s' -a %s\n",
+				c.name, strings.Join(c.subcommands, " "), sub)
+		}
+	}
+	b.WriteString(`complete -c openapi-aggregator -n 'not __fish_use_subcommand; and string match -q -- "-*" (commandline -ct)' -a "(openapi-aggregator (commandline -opc)[2..] -h 2>&1 | string replace -rf '^  -([A-Za-z0-9-]+).*' -- '--$1')"
+complete -c openapi-aggregator -n 'not __fish_use_subcommand' -F
+`)
+	return b.String()
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+
+	"github.com/RastBast/docs12121/pkg/config"
+	"github.com/RastBast/docs12121/pkg/generator"
+	"github.com/RastBast/docs12121/pkg/gitea"
+	"github.com/RastBast/docs12121/pkg/report"
+)
+
+func deployWorkflows(ctx context.Context, cfg config.Config, args []string) error {
+	fs := newFlagSet("deploy")
+	viaPR := fs.Bool("pr", false, "открывать pull request вместо прямого коммита в ветку по умолчанию")
+	platform := platformFlag(fs)
+	batchOpts := addBatchFlags(fs, "deploy")
+	fs.Parse(args)
+	if *platform != "" {
+		cfg.Platform = *platform
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return fail(exitConfigInvalid, "Некорректная конфигурация: %v", err)
+	}
+	token := os.Getenv("GITEA_TOKEN")
+	if token == "" {
+		return fail(exitConfigInvalid, "Не задан GITEA_TOKEN")
+	}
+	workflows, err := repoWorkflows(cfg)
+	if err != nil {
+		return err
+	}
+	path := generator.WorkflowPath(cfg.Platform)
+
+	client := gitea.NewClient(cfg.GiteaHost, token)
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var sum report.Summary
+	runner, err := batchOpts.runner()
+	if err != nil {
+		return err
+	}
+	err = runner.Run(ctx, cfg.Repositories, &sum, func(ctx context.Context, repo string) error {
+		status, err := deployRepo(ctx, client, cfg.Organization, repo, path, workflows[repo], *viaPR, cfg.Tracking)
+		if err == nil {
+			printOK("%s: %s", repo, status)
+		}
+		return err
+	})
+	printSummary(&sum)
+	if err != nil {
+		return fail(exitCodeFor(err, exitError), "Развёртывание прервано: %v", err)
+	}
+	if err := sum.Err(); err != nil {
+		return fail(exitCodeFor(err, exitAPI), "%v", err)
+	}
+	return nil
+}
+
+// installBranch carries the workflow, and the starter spec when onboarding
+// creates one, for --pr installs.
+const installBranch = "openapi-aggregator/install"
+
+func deployRepo(ctx context.Context, client *gitea.Client, org, repo, path, content string, viaPR bool, tracking config.Tracking) (string, error) {
+	info, err := client.GetRepo(ctx, org, repo)
+	if err != nil {
+		return "", err
+	}
+	base := info.DefaultBranch
+
+	current, _, err := client.GetFile(ctx, org, repo, path, base)
+	var apiErr *gitea.APIError
+	switch {
+	case err == nil && string(current) == content:
+		return "воркфлоу уже установлен", nil
+	case err == nil:
+		return "установлена другая версия воркфлоу, используйте sync", nil
+	case !errors.As(err, &apiErr) || apiErr.Status != http.StatusNotFound:
+		return "", err
+	}
+
+	if *dryRun {
+		if viaPR {
+			return "пробный запуск: будет открыт pull request в " + base + " с новым файлом " + path, nil
+		}
+		return "пробный запуск: " + path + " будет закоммичен в " + base, nil
+	}
+	if !viaPR {
+		if err := client.PutFile(ctx, org, repo, path, base, "Add OpenAPI aggregator workflow", []byte(content)); err != nil {
+			return "", err
+		}
+		return "воркфлоу закоммичен в " + base, nil
+	}
+
+	branch := installBranch
+	if err := client.CreateBranch(ctx, org, repo, branch, base); err != nil {
+		return "", err
+	}
+	if err := client.PutFile(ctx, org, repo, path, branch, "Add OpenAPI aggregator workflow", []byte(content)); err != nil {
+		return "", err
+	}
+	body := "Воркфлоу " + path + " публикует docs/openapi.yaml в репозиторий документации при каждом пуше."
+	pr, err := client.CreatePullRequest(ctx, org, repo, branch, base, "Add OpenAPI aggregator workflow", body)
+	if errors.As(err, &apiErr) && apiErr.Status == http.StatusConflict {
+		return "pull request уже открыт (" + branch + ")", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := tagPullRequest(ctx, client, tracking, org, repo, pr.Number); err != nil {
+		printFail("%s: не все метки и этап проставлены в %s: %v", repo, pr.HTMLURL, err)
+	}
+	return "открыт pull request " + pr.HTMLURL, nil
+}
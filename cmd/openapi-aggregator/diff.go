@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+
+	"github.com/RastBast/docs12121/pkg/spec"
+)
+
+func diffSpecs(args []string) error {
+	fs := newFlagSet("diff")
+	docsDir := fs.String("docs", ".", "локальная копия репозитория документации")
+	repo := fs.String("repo", "", "сервис, с опубликованной версией которого сравнивать")
+	format := fs.String("format", "text", "формат вывода: text или json")
+	fs.Parse(args)
+
+	before, after, err := readSpecPair(fs, *docsDir, *repo)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return nil
+	}
+	changes := spec.Diff(before, after)
+	breaking := spec.CountChanges(changes)[spec.ChangeBreaking]
+
+	switch *format {
+	case "text":
+		for _, c := range changes {
+			if c.Category == spec.ChangeBreaking {
+				printFail("[%s] %s", c.Category, c)
+			} else {
+				printInfo("[%s] %s", c.Category, c)
+			}
+		}
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if changes == nil {
+			changes = []spec.Change{}
+		}
+		if err := enc.Encode(changes); err != nil {
+			return fail(exitError, "Ошибка записи: %v", err)
+		}
+	default:
+		return fail(exitConfigInvalid, "Неизвестный формат %q. Доступные форматы: text, json", *format)
+	}
+
+	if breaking > 0 {
+		return fail(exitBreaking, "Ломающих изменений: %d", breaking)
+	}
+	if *format == "text" {
+		printOK("Ломающих изменений нет (изменений: %d)", len(changes))
+	}
+	return nil
+}
+
+func readSpecPair(fs *flag.FlagSet, docsDir, repo string) (before, after *spec.Document, err error) {
+	var oldPath string
+	switch {
+	case fs.NArg() == 2:
+		oldPath = fs.Arg(1)
+	case fs.NArg() == 1 && repo != "":
+		oldPath = filepath.Join(docsDir, filepath.FromSlash(repo), "openapi.yaml")
+	default:
+		return nil, nil, fail(exitConfigInvalid, "Использование: %s [--docs каталог] --repo сервис <новая спецификация> или %s <новая> <старая>", fs.Name(), fs.Name())
+	}
+
+	if after, err = readSpecFile(fs.Arg(0)); err != nil {
+		return nil, nil, err
+	}
+	if _, err := os.Stat(oldPath); os.IsNotExist(err) && fs.NArg() == 1 {
+		printOK("%s ещё не опубликован, сравнивать не с чем", repo)
+		return nil, after, nil
+	}
+	before, err = readSpecFile(oldPath)
+	return before, after, err
+}
+
+func readSpecFile(path string) (*spec.Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fail(exitError, "Ошибка чтения спецификации: %v", err)
+	}
+	doc, err := spec.ParseDocument(data)
+	if err != nil {
+		return nil, fail(exitValidation, "%s: %v", path, err)
+	}
+	return doc, nil
+}
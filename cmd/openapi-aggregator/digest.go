@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/RastBast/docs12121/pkg/config"
+	"github.com/RastBast/docs12121/pkg/git"
+	"github.com/RastBast/docs12121/pkg/notify"
+	"github.com/RastBast/docs12121/pkg/spec"
+)
+
+func weeklyDigest(ctx context.Context, cfg config.Config, args []string) error {
+	fs := newFlagSet("digest")
+	days := fs.Int("days", 7, "за сколько последних дней собирать изменения")
+	format := fs.String("format", "markdown", "формат дайджеста: markdown или html")
+	output := fs.String("output", "", "путь к файлу дайджеста (по умолчанию digests/<год>-W<неделя>.md в каталоге документации)")
+	commit := fs.Bool("commit", false, "закоммитить дайджест в репозиторий документации")
+	send := fs.Bool("notify", false, "отправить краткую сводку в NOTIFY_WEBHOOKS")
+	fs.Parse(args)
+
+	dir := "."
+	if fs.NArg() > 0 {
+		dir = fs.Arg(0)
+	}
+	if *days <= 0 {
+		return fail(exitConfigInvalid, "--days должен быть положительным")
+	}
+	ext := map[string]string{"markdown": ".md", "html": ".html"}[*format]
+	if ext == "" {
+		return fail(exitConfigInvalid, "Неизвестный формат %q (доступны: markdown, html)", *format)
+	}
+	if *send && len(cfg.NotifyWebhooks) == 0 {
+		return fail(exitConfigInvalid, "Каналы уведомлений не настроены: укажите NOTIFY_WEBHOOKS")
+	}
+
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	until := time.Now()
+	d, err := spec.BuildWeeklyDigest(ctx, dir, until.AddDate(0, 0, -*days), until)
+	if err != nil {
+		return fail(exitValidation, "Ошибка сбора истории спецификаций: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if *format == "html" {
+		err = d.WriteHTML(&buf)
+	} else {
+		err = d.WriteMarkdown(&buf)
+	}
+	if err != nil {
+		return fail(exitError, "Ошибка формирования дайджеста: %v", err)
+	}
+
+	path := *output
+	if path == "" {
+		year, week := until.ISOWeek()
+		path = filepath.Join(dir, "digests", fmt.Sprintf("%d-W%02d%s", year, week, ext))
+	}
+	if err := writeFile(path, buf.Bytes(), 0o644); err != nil {
+		return fail(exitError, "Ошибка записи дайджеста: %v", err)
+	}
+	printOK("Дайджест: %s (сервисов с изменениями: %d, ломающих изменений: %d)", path, len(d.Services), d.Counts[spec.ChangeBreaking])
+
+	if *commit && !skipInDryRun("дайджест не будет закоммичен в %s", dir) {
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return fail(exitConfigInvalid, "Дайджест должен лежать внутри %s: %v", dir, err)
+		}
+		author := git.Author{Name: "OpenAPI Aggregator Bot", Email: "openapi-bot@" + cfg.GiteaHost}
+		if err := git.Run(ctx, dir, "add", rel); err != nil {
+			return fail(exitError, "Ошибка добавления дайджеста: %v", err)
+		}
+		if err := git.Commit(ctx, dir, author, cfg.Tracking.CommitMessage(d.Title())); err != nil {
+			return fail(exitError, "Ошибка коммита дайджеста: %v", err)
+		}
+		printOK("Дайджест закоммичен в %s", dir)
+	}
+
+	if *send && !skipInDryRun("сводка не будет отправлена в %s", strings.Join(cfg.NotifyWebhooks, ", ")) {
+		failed := 0
+		text := d.Summary()
+		for _, url := range cfg.NotifyWebhooks {
+			if err := notify.Post(ctx, url, map[string]string{"text": text}); err != nil {
+				printFail("Уведомление не доставлено: %v", err)
+				failed++
+			}
+		}
+		switch {
+		case failed == len(cfg.NotifyWebhooks):
+			return fail(exitAPI, "Сводка не доставлена ни в один канал")
+		case failed > 0:
+			return fail(exitPartial, "Сводка доставлена в %d из %d каналов", len(cfg.NotifyWebhooks)-failed, len(cfg.NotifyWebhooks))
+		}
+		printOK("Сводка отправлена (каналов: %d)", len(cfg.NotifyWebhooks))
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/RastBast/docs12121/pkg/config"
+	"github.com/RastBast/docs12121/pkg/gitea"
+)
+
+var discoverPaths = []string{
+	"openapi.yaml", "openapi.yml", "openapi.json",
+	"docs/openapi.yml", "docs/openapi.json",
+	"api/openapi.yaml", "api/openapi.yml", "api/openapi.json",
+	"swagger.yaml", "swagger.json", "docs/swagger.yaml", "docs/swagger.json",
+}
+
+type discovered struct {
+	Repo     string
+	SpecPath string
+}
+
+func discoverRepos(ctx context.Context, cfg config.Config, args []string) error {
+	fs := newFlagSet("discover")
+	paths := fs.String("paths", "", "пути к спецификации через запятую (по умолчанию spec_path из конфигурации и типичные пути)")
+	write := fs.Bool("write", false, "добавить найденные репозитории в файл конфигурации")
+	archived := fs.Bool("include-archived", false, "проверять архивные репозитории")
+	forks := fs.Bool("include-forks", false, "проверять форки")
+	fs.Parse(args)
+
+	token := os.Getenv("GITEA_TOKEN")
+	if token == "" {
+		return fail(exitConfigInvalid, "Не задан GITEA_TOKEN")
+	}
+	candidates := config.SplitList(*paths)
+	if len(candidates) == 0 {
+		candidates = append([]string{cfg.Repo("").SpecPath}, discoverPaths...)
+	}
+	candidates = slices.Compact(candidates)
+
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	client := gitea.NewClient(cfg.GiteaHost, token)
+	repos, err := client.ListRepos(ctx, cfg.Organization)
+	if err != nil {
+		return fail(exitCodeFor(err, exitAPI), "Ошибка получения репозиториев %s: %v", cfg.Organization, err)
+	}
+
+	var found []discovered
+	for _, repo := range repos {
+		if slices.Contains(cfg.DocsRepos(), repo.Name) || (repo.Archived && !*archived) || (repo.Fork && !*forks) {
+			continue
+		}
+		for _, p := range candidates {
+			ok, err := client.FileExists(ctx, cfg.Organization, repo.Name, p, repo.DefaultBranch)
+			if err != nil {
+				return fail(exitCodeFor(err, exitAPI), "%s: %v", repo.Name, err)
+			}
+			if ok {
+				found = append(found, discovered{Repo: repo.Name, SpecPath: p})
+				break
+			}
+		}
+	}
+
+	var added []config.RepoConfig
+	for _, d := range found {
+		if slices.Contains(cfg.Repositories, d.Repo) {
+			printOK("%s: %s (уже в конфигурации)", d.Repo, d.SpecPath)
+			continue
+		}
+		printInfo("%s: %s (новый)", d.Repo, d.SpecPath)
+		rc := config.RepoConfig{Name: d.Repo}
+		if d.SpecPath != cfg.Repo("").SpecPath {
+			rc.SpecPath = d.SpecPath
+		}
+		added = append(added, rc)
+	}
+	for _, name := range cfg.Repositories {
+		if !slices.ContainsFunc(found, func(d discovered) bool { return d.Repo == name }) {
+			printFail("%s: в конфигурации, но спецификация не найдена", name)
+		}
+	}
+	printTotals("Найдено репозиториев со спецификацией: %d, новых: %d", len(found), len(added))
+	if len(added) == 0 {
+		return nil
+	}
+
+	if !*write {
+		names := make([]string, len(found))
+		for i, d := range found {
+			names[i] = d.Repo
+		}
+		printInfo("Для обновления конфигурации запустите с --write или задайте REPOSITORIES=%s", strings.Join(names, ","))
+		return nil
+	}
+	return registerRepos(added)
+}
+
+func registerRepos(added []config.RepoConfig) error {
+	files, err := configPaths()
+	if err != nil {
+		return err
+	}
+	path := config.DefaultFile
+	if len(files) > 0 {
+		path = files[0]
+	}
+	if strings.HasPrefix(path, config.RemoteScheme) {
+		return fail(exitConfigInvalid, "Конфигурация %s хранится в Gitea: добавьте репозитории вручную", path)
+	}
+	data, err := config.WithRepositories(path, added)
+	if err != nil {
+		return fail(exitError, "Ошибка обновления %s: %v", path, err)
+	}
+	if err := writeFile(path, data, 0o644); err != nil {
+		return fail(exitError, "Ошибка обновления %s: %v", path, err)
+	}
+	printOK("Добавлено в %s: %d", path, len(added))
+	return nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/RastBast/docs12121/pkg/audit"
+	"github.com/RastBast/docs12121/pkg/config"
+	"github.com/RastBast/docs12121/pkg/dispatch"
+	"github.com/RastBast/docs12121/pkg/git"
+)
+
+func newDispatcher(cfg config.Config, stateDir string, logf func(format string, args ...any)) *dispatch.Dispatcher {
+	if len(cfg.Dispatch) == 0 {
+		return nil
+	}
+	return &dispatch.Dispatcher{Targets: cfg.Dispatch, Audit: audit.Open(stateDir), Attempts: 5, Logf: logf}
+}
+
+func publishEvent(ctx context.Context, d *dispatch.Dispatcher, dir, repo, ref string, files []string) error {
+	if d == nil {
+		return nil
+	}
+	if *dryRun {
+		for _, t := range d.Targets {
+			if t.Matches(repo) {
+				printInfo("Пробный запуск: событие о публикации %s не будет отправлено в %s (%s)", repo, t.Name, t.URL)
+			}
+		}
+		return nil
+	}
+	ev := dispatch.NewEvent(repo, files)
+	ev.Ref = ref
+	if head, err := git.Output(ctx, dir, "rev-parse", "HEAD"); err == nil {
+		ev.Commit = strings.TrimSpace(head)
+	}
+	return d.Send(ctx, ev)
+}
+
+func runDispatchCommand(ctx context.Context, cfg config.Config, args []string) error {
+	if len(args) == 0 {
+		return fail(exitConfigInvalid, "Использование: dispatch send [--ref ветка] [--dir каталог] <репозиторий> [файл...] | dispatch log [--target имя] [--id событие] [--limit 50] [--format text|json]")
+	}
+	switch args[0] {
+	case "send":
+		return sendDispatch(ctx, cfg, args[1:])
+	case "log":
+		return showDispatchLog(args[1:])
+	default:
+		return fail(exitConfigInvalid, "Неизвестная подкоманда %q. Доступные подкоманды: send, log", args[0])
+	}
+}
+
+func sendDispatch(ctx context.Context, cfg config.Config, args []string) error {
+	fs := newFlagSet("dispatch send")
+	stateDir := stateDirFlag(fs)
+	dir := fs.String("dir", ".", "локальная копия репозитория документации (из неё берётся коммит публикации)")
+	ref := fs.String("ref", "", "ветка, из которой опубликована спецификация")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		return fail(exitConfigInvalid, "Использование: dispatch send [--ref ветка] [--dir каталог] <репозиторий> [файл...]")
+	}
+	if err := cfg.Validate(); err != nil {
+		return fail(exitConfigInvalid, "Некорректная конфигурация: %v", err)
+	}
+	if *stateDir == "" {
+		*stateDir = ".openapi-aggregator"
+	}
+	d := newDispatcher(cfg, *stateDir, printInfo)
+	if d == nil {
+		return fail(exitConfigInvalid, "Получатели событий не настроены (dispatch в конфигурации)")
+	}
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	if err := publishEvent(ctx, d, *dir, fs.Arg(0), *ref, fs.Args()[1:]); err != nil {
+		return fail(exitPartial, "Доставка не удалась: %v", err)
+	}
+	printOK("События о публикации %s доставлены", fs.Arg(0))
+	return nil
+}
+
+func showDispatchLog(args []string) error {
+	fs := newFlagSet("dispatch log")
+	stateDir := stateDirFlag(fs)
+	target := fs.String("target", "", "только доставки этому получателю")
+	id := fs.String("id", "", "только доставки события с этим идентификатором")
+	limit := fs.Int("limit", 50, "сколько последних записей показать (0 — все)")
+	format := fs.String("format", "text", "формат вывода: text или json")
+	fs.Parse(args)
+
+	if *stateDir == "" {
+		*stateDir = ".openapi-aggregator"
+	}
+	entries, err := audit.Open(*stateDir).Read(func(e audit.Entry) bool {
+		return e.Action == dispatch.AuditAction && (*target == "" || e.Target == *target) && (*id == "" || e.ID == *id)
+	})
+	if err != nil {
+		return fail(exitError, "Ошибка чтения журнала аудита: %v", err)
+	}
+	if *limit > 0 && len(entries) > *limit {
+		entries = entries[len(entries)-*limit:]
+	}
+
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(entries); err != nil {
+			return fail(exitError, "Ошибка вывода: %v", err)
+		}
+	case "text":
+		if len(entries) == 0 {
+			printInfo("Доставок нет")
+			return nil
+		}
+		for _, e := range entries {
+			line := fmt.Sprintf("%s %s → %s (%s, попытка %d)", e.Time.Local().Format("2006-01-02 15:04:05"), e.Repo, e.Target, e.ID, e.Attempt)
+			switch e.Status {
+			case audit.StatusOK:
+				printOK("%s", line)
+			case audit.StatusRetry:
+				printInfo("%s: %s", line, e.Detail)
+			default:
+				printFail("%s: %s", line, e.Detail)
+			}
+		}
+	default:
+		return fail(exitConfigInvalid, "Неизвестный формат %q (доступны: text, json)", *format)
+	}
+	return nil
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/RastBast/docs12121/pkg/config"
+	"github.com/RastBast/docs12121/pkg/git"
+	"github.com/RastBast/docs12121/pkg/gitea"
+)
+
+type BranchStatus struct {
+	Branch        string     `json:"branch"`
+	Exists        bool       `json:"exists"`
+	Commit        string     `json:"commit,omitempty"`
+	Updated       *time.Time `json:"updated,omitempty"`
+	SourceCommit  string     `json:"source_commit,omitempty"`
+	SourceUpdated *time.Time `json:"source_updated,omitempty"`
+	Stale         bool       `json:"stale"`
+}
+
+type SpecStatus struct {
+	Repo     string         `json:"repo"`
+	Name     string         `json:"name"`
+	SpecPath string         `json:"spec_path"`
+	Branches []BranchStatus `json:"branches"`
+}
+
+type commitInfo struct {
+	sha  string
+	date time.Time
+}
+
+type docsSource interface {
+	branches(ctx context.Context) ([]string, error)
+	lastCommit(ctx context.Context, branch, file string) (*commitInfo, error)
+}
+
+type apiDocs struct {
+	client      *gitea.Client
+	owner, repo string
+}
+
+func (d apiDocs) branches(ctx context.Context) ([]string, error) {
+	list, err := d.client.ListBranches(ctx, d.owner, d.repo)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(list))
+	for i, b := range list {
+		names[i] = b.Name
+	}
+	return names, nil
+}
+
+func (d apiDocs) lastCommit(ctx context.Context, branch, file string) (*commitInfo, error) {
+	c, err := d.client.LastCommit(ctx, d.owner, d.repo, branch, file)
+	var apiErr *gitea.APIError
+	if errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil || c == nil {
+		return nil, err
+	}
+	return &commitInfo{sha: c.SHA, date: c.Commit.Committer.Date}, nil
+}
+
+type localDocs struct {
+	dir string
+}
+
+func (d localDocs) branches(ctx context.Context) ([]string, error) {
+	out, err := git.Output(ctx, d.dir, "for-each-ref", "--format=%(refname:short)", "refs/heads", "refs/remotes/origin")
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, ref := range strings.Fields(out) {
+		ref = strings.TrimPrefix(ref, "origin/")
+		if ref != "HEAD" && ref != "origin" && !slices.Contains(names, ref) {
+			names = append(names, ref)
+		}
+	}
+	return names, nil
+}
+
+func (d localDocs) lastCommit(ctx context.Context, branch, file string) (*commitInfo, error) {
+	for _, ref := range []string{"refs/heads/" + branch, "refs/remotes/origin/" + branch} {
+		if git.Run(ctx, d.dir, "rev-parse", "-q", "--verify", ref) != nil {
+			continue
+		}
+		out, err := git.Output(ctx, d.dir, "log", "-1", "--format=%H %cI", ref, "--", file)
+		if err != nil {
+			return nil, err
+		}
+		sha, date, ok := strings.Cut(strings.TrimSpace(out), " ")
+		if !ok {
+			return nil, nil
+		}
+		t, err := time.Parse(time.RFC3339, date)
+		if err != nil {
+			return nil, err
+		}
+		return &commitInfo{sha: sha, date: t}, nil
+	}
+	return nil, nil
+}
+
+func docsStatus(ctx context.Context, cfg config.Config, args []string) error {
+	fs := newFlagSet("status")
+	source := fs.String("source", "api", "откуда читать репозиторий документации: api (Gitea API) или local (локальная копия)")
+	format := fs.String("format", "text", "формат вывода: text или json")
+	docsRepo := docsRepoFlag(fs, cfg)
+	fs.Parse(args)
+
+	if err := cfg.Validate(); err != nil {
+		return fail(exitConfigInvalid, "Некорректная конфигурация: %v", err)
+	}
+	token := os.Getenv("GITEA_TOKEN")
+	var client *gitea.Client
+	if token != "" {
+		client = gitea.NewClient(cfg.GiteaHost, token)
+	}
+
+	var docs docsSource
+	switch *source {
+	case "api":
+		if client == nil {
+			return fail(exitConfigInvalid, "Не задан GITEA_TOKEN")
+		}
+		docs = apiDocs{client: client, owner: cfg.Organization, repo: *docsRepo}
+	case "local":
+		docs = localDocs{dir: argOrDefault(fs.Args(), 0, ".")}
+	default:
+		return fail(exitConfigInvalid, "Неизвестный источник %q (доступны: api, local)", *source)
+	}
+	if client == nil && *format == "text" {
+		printInfo("GITEA_TOKEN не задан: актуальность относительно репозиториев сервисов не проверяется")
+	}
+
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	docsBranches, err := docs.branches(ctx)
+	if err != nil {
+		return fail(exitCodeFor(err, exitError), "Ошибка чтения веток репозитория документации: %v", err)
+	}
+
+	repos, err := routedRepos(cfg, *docsRepo)
+	if err != nil {
+		return err
+	}
+	var statuses []SpecStatus
+	for _, repo := range repos {
+		rc := cfg.Repo(repo)
+		branches, err := statusBranches(ctx, client, cfg.Organization, rc, docsBranches)
+		if err != nil {
+			return fail(exitCodeFor(err, exitAPI), "%s: %v", repo, err)
+		}
+		for _, api := range rc.Specs() {
+			st := SpecStatus{Repo: repo, Name: rc.DocsName(api), SpecPath: api.SpecPath}
+			for _, branch := range branches {
+				bs, err := branchStatus(ctx, docs, client, cfg, repo, branch, st.Name, api.SpecPath)
+				if err != nil {
+					return fail(exitCodeFor(err, exitAPI), "%s (%s): %v", st.Name, branch, err)
+				}
+				st.Branches = append(st.Branches, bs)
+			}
+			statuses = append(statuses, st)
+		}
+	}
+
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(statuses); err != nil {
+			return fail(exitError, "Ошибка записи: %v", err)
+		}
+	case "text":
+		printStatuses(statuses, client != nil)
+	default:
+		return fail(exitConfigInvalid, "Неизвестный формат %q. Доступные форматы: text, json", *format)
+	}
+	return nil
+}
+
+func statusBranches(ctx context.Context, client *gitea.Client, org string, rc config.RepoConfig, docsBranches []string) ([]string, error) {
+	candidates := docsBranches
+	if client != nil {
+		list, err := client.ListBranches(ctx, org, rc.Name)
+		if err != nil {
+			return nil, err
+		}
+		candidates = nil
+		for _, b := range list {
+			candidates = append(candidates, b.Name)
+		}
+	}
+	var branches []string
+	for _, pattern := range rc.Branches {
+		if !strings.ContainsAny(pattern, "*?[") {
+			if !slices.Contains(branches, pattern) {
+				branches = append(branches, pattern)
+			}
+			continue
+		}
+		for _, b := range candidates {
+			if ok, _ := path.Match(pattern, b); ok && !slices.Contains(branches, b) {
+				branches = append(branches, b)
+			}
+		}
+	}
+	return branches, nil
+}
+
+func branchStatus(ctx context.Context, docs docsSource, client *gitea.Client, cfg config.Config, repo, branch, name, specPath string) (BranchStatus, error) {
+	bs := BranchStatus{Branch: branch}
+	docsBranch, docsName := cfg.DocsTarget(branch, name)
+	published, err := docs.lastCommit(ctx, docsBranch, docsName+"/openapi.yaml")
+	if err != nil {
+		return bs, err
+	}
+	if published != nil {
+		bs.Exists, bs.Commit, bs.Updated = true, published.sha, &published.date
+	}
+	if client == nil {
+		return bs, nil
+	}
+	c, err := client.LastCommit(ctx, cfg.Organization, repo, branch, specPath)
+	var apiErr *gitea.APIError
+	if errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound {
+		return bs, nil
+	}
+	if err != nil || c == nil {
+		return bs, err
+	}
+	date := c.Commit.Committer.Date
+	bs.SourceCommit, bs.SourceUpdated = c.SHA, &date
+	bs.Stale = published == nil || date.After(published.date)
+	return bs, nil
+}
+
+func printStatuses(statuses []SpecStatus, sourceChecked bool) {
+	missing, stale := 0, 0
+	for _, st := range statuses {
+		printStart("%s (%s)", st.Name, st.SpecPath)
+		for _, b := range st.Branches {
+			switch {
+			case !b.Exists && sourceChecked && b.SourceCommit == "":
+				printInfo("%s: спецификации нет ни в сервисе, ни в документации", b.Branch)
+			case !b.Exists && !sourceChecked:
+				missing++
+				printFail("%s: копии нет", b.Branch)
+			case !b.Exists:
+				missing++
+				printFail("%s: копии нет, в сервисе есть %s от %s", b.Branch, shortSHA(b.SourceCommit), b.SourceUpdated.Format(time.DateOnly))
+			case b.Stale:
+				stale++
+				printFail("%s: устарела — %s от %s, в сервисе изменения от %s (%s)", b.Branch, shortSHA(b.Commit), b.Updated.Format(time.DateOnly), b.SourceUpdated.Format(time.DateOnly), shortSHA(b.SourceCommit))
+			default:
+				printOK("%s: %s от %s", b.Branch, shortSHA(b.Commit), b.Updated.Format(time.DateOnly))
+			}
+		}
+	}
+	if missing+stale > 0 {
+		printInfo("Нет копии: %d, устарело: %d", missing, stale)
+	}
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
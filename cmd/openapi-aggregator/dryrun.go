@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"unicode/utf8"
+
+	"github.com/RastBast/docs12121/pkg/gitea"
+	"github.com/RastBast/docs12121/pkg/textdiff"
+)
+
+var dryRun = flag.Bool("dry-run", false, "показать, какие файлы будут записаны и куда будут отправлены изменения, ничего не меняя")
+
+func configureDryRun() {
+	if !*dryRun {
+		return
+	}
+	gitea.Transport = &gitea.DryRun{Next: gitea.Transport, Log: func(method, path string) {
+		printInfo("Пробный запуск: запрос %s %s не отправлен", method, path)
+	}}
+	printInfo("Пробный запуск: файлы и удалённые репозитории не изменяются")
+}
+
+func writeFile(path string, data []byte, perm os.FileMode) error {
+	if *dryRun {
+		planWrite(path, data)
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, perm)
+}
+
+func createFile(path string) (io.WriteCloser, error) {
+	if *dryRun {
+		return &plannedFile{path: path}, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	return os.Create(path)
+}
+
+func removeAll(path string) error {
+	if *dryRun {
+		if _, err := os.Stat(path); err == nil {
+			printInfo("Пробный запуск: будет удалён %s", path)
+		}
+		return nil
+	}
+	return os.RemoveAll(path)
+}
+
+func skipInDryRun(format string, args ...any) bool {
+	if *dryRun {
+		printInfo("Пробный запуск: %s", fmt.Sprintf(format, args...))
+	}
+	return *dryRun
+}
+
+func printWritten(format string, args ...any) {
+	if !*dryRun {
+		printOK(format, args...)
+	}
+}
+
+type plannedFile struct {
+	path string
+	buf  bytes.Buffer
+}
+
+func (f *plannedFile) Write(p []byte) (int, error) { return f.buf.Write(p) }
+
+func (f *plannedFile) Close() error {
+	planWrite(f.path, f.buf.Bytes())
+	return nil
+}
+
+func planWrite(path string, data []byte) {
+	old, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		printInfo("Пробный запуск: будет создан %s (%d байт)", path, len(data))
+	case err != nil:
+		printInfo("Пробный запуск: будет перезаписан %s (не удалось прочитать: %v)", path, err)
+	case bytes.Equal(old, data):
+		printInfo("Пробный запуск: %s не изменится", path)
+	case !utf8.Valid(old) || !utf8.Valid(data):
+		printInfo("Пробный запуск: будет изменён %s (%d → %d байт)", path, len(old), len(data))
+	default:
+		printInfo("Пробный запуск: будет изменён %s", path)
+		fmt.Print(textdiff.Unified(path, path, string(old), string(data), 3))
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+
+	"github.com/RastBast/docs12121/pkg/e2e"
+)
+
+func runTestCommand(ctx context.Context, args []string) error {
+	if len(args) == 0 || args[0] != "e2e" {
+		return fail(exitConfigInvalid, "Использование: test e2e [--image образ] [--keep]")
+	}
+	return runE2E(ctx, args[1:])
+}
+
+func runE2E(ctx context.Context, args []string) error {
+	fs := newFlagSet("test e2e")
+	image := fs.String("image", "gitea/gitea:1.22", "образ Gitea")
+	keep := fs.Bool("keep", false, "не удалять контейнер после прогона")
+	fs.Parse(args)
+
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	printStart("Запуск Gitea в Docker")
+	gitea, err := e2e.StartGitea(ctx, *image)
+	if err != nil {
+		return fail(exitError, "Ошибка запуска Gitea: %v", err)
+	}
+	if *keep {
+		printInfo("Контейнер %s оставлен: %s", gitea.ID[:12], gitea.BaseURL)
+	}
+
+	scenario := &e2e.Scenario{
+		Gitea: gitea,
+		Repos: []string{"payments", "orders"},
+		Logf:  printOK,
+	}
+	err = scenario.Run(ctx)
+	if !*keep {
+		gitea.Stop()
+	}
+	printSummary(&scenario.Summary)
+	if err != nil {
+		return fail(exitCodeFor(err, exitValidation), "%s", markFail.format("E2E: %v", err))
+	}
+	printOK("E2E-прогон завершён успешно")
+	return nil
+}
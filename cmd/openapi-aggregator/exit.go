@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+
+	"github.com/RastBast/docs12121/pkg/gitea"
+	"github.com/RastBast/docs12121/pkg/report"
+)
+
+const (
+	exitError         = 1
+	exitConfigInvalid = 2
+	exitValidation    = 3
+	exitBreaking      = 4
+	exitAPI           = 5
+	exitPartial       = 6
+)
+
+const exitCodesHelp = `Коды выхода:
+  0  успешно
+  1  прочая ошибка (файловая система, git)
+  2  некорректная конфигурация или аргументы
+  3  проверка не пройдена: спецификации или воркфлоу (sync --check)
+  4  обнаружены ломающие изменения
+  5  ошибка сети или API Gitea
+  6  часть репозиториев обработана с ошибками`
+
+type exitErr struct {
+	code int
+	err  error
+}
+
+func (e *exitErr) Error() string { return e.err.Error() }
+
+func (e *exitErr) Unwrap() error { return e.err }
+
+func fail(code int, format string, args ...any) error {
+	return &exitErr{code: code, err: fmt.Errorf(format, args...)}
+}
+
+func exitCode(err error) int {
+	var e *exitErr
+	if errors.As(err, &e) {
+		return e.code
+	}
+	return exitCodeFor(err, exitError)
+}
+
+func exitCodeFor(err error, def int) int {
+	var partial *report.PartialError
+	if errors.As(err, &partial) && partial.Succeeded > 0 {
+		return exitPartial
+	}
+	var apiErr *gitea.APIError
+	if errors.As(err, &apiErr) {
+		return exitAPI
+	}
+	var urlErr *url.Error
+	var opErr *net.OpError
+	if errors.As(err, &urlErr) || errors.As(err, &opErr) {
+		return exitAPI
+	}
+	return def
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"github.com/RastBast/docs12121/pkg/config"
+	"github.com/RastBast/docs12121/pkg/spec"
+)
+
+var exportExtensions = map[string]string{
+	"zip":       "zip",
+	"tar.gz":    "tar.gz",
+	"terraform": "tf.json",
+	"hcl":       "tf",
+}
+
+func exportCatalog(cfg config.Config, args []string) error {
+	fs := newFlagSet("export")
+	format := fs.String("format", "zip", "формат: zip или tar.gz (архив спецификаций), terraform или hcl (описание API для IaC)")
+	output := fs.String("output", "", "путь к архиву (по умолчанию catalog.<format>)")
+	fs.Parse(args)
+
+	dir := "."
+	if fs.NArg() > 0 {
+		dir = fs.Arg(0)
+	}
+	ext, ok := exportExtensions[*format]
+	if !ok {
+		return fail(exitConfigInvalid, "Неизвестный формат %q. Доступные форматы: zip, tar.gz, terraform, hcl", *format)
+	}
+	if *output == "" {
+		*output = "catalog." + ext
+	}
+	if *format == "terraform" || *format == "hcl" {
+		return exportIaC(cfg, dir, *format, *output)
+	}
+
+	files, err := spec.CatalogFiles(dir)
+	if err != nil {
+		return fail(exitError, "Ошибка поиска спецификаций: %v", err)
+	}
+	if len(files) == 0 {
+		return fail(exitValidation, "В %s не найдено ни одной спецификации", dir)
+	}
+
+	out, err := createFile(*output)
+	if err != nil {
+		return fail(exitError, "Ошибка создания архива: %v", err)
+	}
+	defer out.Close()
+
+	switch *format {
+	case "zip":
+		err = spec.WriteZip(out, dir, files)
+	case "tar.gz":
+		err = spec.WriteTarGz(out, dir, files)
+	}
+	if err != nil {
+		return fail(exitError, "Ошибка записи архива: %v", err)
+	}
+
+	printOK("Каталог экспортирован: %s (файлов: %d)", *output, len(files))
+	return nil
+}
+
+func exportIaC(cfg config.Config, dir, format, output string) error {
+	docs, err := readDocuments(dir)
+	if err != nil {
+		return fail(exitValidation, "Ошибка чтения спецификаций: %v", err)
+	}
+	if len(docs) == 0 {
+		return fail(exitValidation, "В %s не найдено ни одной спецификации", dir)
+	}
+	apis := spec.BuildAPIResources(docs, cfg.PortalBase())
+
+	out, err := createFile(output)
+	if err != nil {
+		return fail(exitError, "Ошибка создания файла: %v", err)
+	}
+	defer out.Close()
+	if format == "hcl" {
+		err = spec.WriteHCL(out, apis)
+	} else {
+		err = spec.WriteTerraformJSON(out, apis)
+	}
+	if err != nil {
+		return fail(exitError, "Ошибка записи каталога: %v", err)
+	}
+	printOK("Каталог для IaC экспортирован: %s (API: %d)", output, len(apis))
+	return nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/RastBast/docs12121/pkg/config"
+	"github.com/RastBast/docs12121/pkg/gitea"
+	"github.com/RastBast/docs12121/pkg/report"
+	"github.com/RastBast/docs12121/pkg/server"
+)
+
+const failureIssueTitle = "OpenAPI docs aggregation is failing"
+
+func trackFailures(ctx context.Context, client *gitea.Client, cfg config.Config, stateDir string, results []report.Result, logf func(format string, args ...any)) error {
+	if !cfg.FailureIssues.Enabled() {
+		return nil
+	}
+	streaks, err := report.LoadStreaks(stateDir)
+	if err != nil {
+		return fmt.Errorf("чтение %s: %w", report.StreaksFile, err)
+	}
+	now := time.Now().UTC()
+	var errs []error
+	for _, r := range results {
+		st := streaks.Record(r.Repo, r.Err, now)
+		switch {
+		case r.Err == nil && st.Issue != 0:
+			if *dryRun {
+				logf("Пробный запуск: в %s будет закрыт issue #%d о сбоях агрегации", r.Repo, st.Issue)
+				continue
+			}
+			if err := closeFailureIssue(ctx, client, cfg, r.Repo, st.Issue); err != nil {
+				errs = append(errs, fmt.Errorf("%s: закрытие issue #%d: %w", r.Repo, st.Issue, err))
+				continue
+			}
+			logf("%s: агрегация снова проходит, issue #%d закрыт", r.Repo, st.Issue)
+			st.Issue = 0
+			streaks.Set(r.Repo, st)
+		case r.Err != nil && st.Issue == 0 && st.Failures >= cfg.FailureIssues.After:
+			if *dryRun {
+				logf("Пробный запуск: в %s будет открыт issue о сбоях агрегации (сбоев подряд: %d)", r.Repo, st.Failures)
+				continue
+			}
+			issue, err := openFailureIssue(ctx, client, cfg, cfg.Repo(r.Repo), st, logf)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: создание issue: %w", r.Repo, err))
+				continue
+			}
+			logf("%s: сбоев агрегации подряд: %d, открыт issue %s", r.Repo, st.Failures, issue.HTMLURL)
+			st.Issue = issue.Number
+			streaks.Set(r.Repo, st)
+		}
+	}
+	if !*dryRun {
+		if err := streaks.Save(stateDir); err != nil {
+			errs = append(errs, fmt.Errorf("запись %s: %w", report.StreaksFile, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func trackJobFailures(cfg config.Config, token, stateDir string, run server.JobFunc) server.JobFunc {
+	client := gitea.NewClient(cfg.GiteaHost, token)
+	return func(ctx context.Context, job *server.Job, logf func(format string, args ...any)) error {
+		err := run(ctx, job, logf)
+		if ctx.Err() != nil {
+			return err
+		}
+		tctx, cancel := withTimeout(ctx)
+		defer cancel()
+		if terr := trackFailures(tctx, client, cfg, stateDir, []report.Result{{Repo: job.Repo, Err: err}}, logf); terr != nil {
+			logf("Issue о сбоях агрегации не обновлён: %v", terr)
+		}
+		return err
+	}
+}
+
+func openFailureIssue(ctx context.Context, client *gitea.Client, cfg config.Config, rc config.RepoConfig, st report.Streak, logf func(format string, args ...any)) (*gitea.Issue, error) {
+	assignees := rc.Assignees
+	if len(assignees) == 0 && rc.Team != "" {
+		members, err := client.TeamMembers(ctx, cfg.Organization, rc.Team)
+		if err != nil {
+			logf("%s: участники команды %s не получены, issue будет без исполнителей: %v", rc.Name, rc.Team, err)
+		}
+		assignees = members
+	}
+	body := failureIssueBody(cfg, rc, st)
+	issue, err := client.CreateIssue(ctx, cfg.Organization, rc.Name, failureIssueTitle, body, assignees...)
+	var apiErr *gitea.APIError
+	if len(assignees) > 0 && errors.As(err, &apiErr) && apiErr.Status == http.StatusUnprocessableEntity {
+		logf("%s: не удалось назначить %s, issue будет без исполнителей: %v", rc.Name, strings.Join(assignees, ", "), err)
+		issue, err = client.CreateIssue(ctx, cfg.Organization, rc.Name, failureIssueTitle, body)
+	}
+	return issue, err
+}
+
+func closeFailureIssue(ctx context.Context, client *gitea.Client, cfg config.Config, repo string, number int) error {
+	comment := fmt.Sprintf("Агрегация спецификации снова проходит (%s). Issue закрыт автоматически.", time.Now().Local().Format("2006-01-02 15:04"))
+	if err := client.CommentIssue(ctx, cfg.Organization, repo, number, comment); err != nil {
+		return err
+	}
+	return client.CloseIssue(ctx, cfg.Organization, repo, number)
+}
+
+func failureIssueBody(cfg config.Config, rc config.RepoConfig, st report.Streak) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Агрегатор OpenAPI документации не может опубликовать спецификацию этого репозитория в %s/%s.\n\n",
+		cfg.Organization, rc.DocsRepo)
+	fmt.Fprintf(&b, "- Сбоев подряд: %d\n- Первый сбой: %s\n\n", st.Failures, st.Since.Local().Format("2006-01-02 15:04"))
+	fmt.Fprintf(&b, "## Последняя ошибка\n\n```\n%s\n```\n\n", st.LastError)
+	b.WriteString("## Как исправить\n\n")
+	var paths []string
+	for _, api := range rc.Specs() {
+		paths = append(paths, api.SpecPath)
+	}
+	fmt.Fprintf(&b, "1. Проверьте, что спецификация лежит в %s в ветках %s.\n", strings.Join(paths, ", "), strings.Join(rc.Branches, ", "))
+	fmt.Fprintf(&b, "2. Проверьте спецификацию локально: `openapi-aggregator validate %s` и `openapi-aggregator lint %s`.\n", paths[0], paths[0])
+	b.WriteString("3. Если спецификация переехала, обновите `spec_path` репозитория в конфигурации агрегатора.\n")
+	fmt.Fprintf(&b, "4. Проверьте, что у токена агрегатора есть доступ на чтение к %s/%s.\n\n", cfg.Organization, rc.Name)
+	b.WriteString("Issue закроется автоматически после первой успешной агрегации.\n")
+	return b.String()
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/RastBast/docs12121/pkg/config"
+	"github.com/RastBast/docs12121/pkg/server"
+)
+
+func collectGarbage(ctx context.Context, cfg config.Config, args []string) error {
+	fs := newFlagSet("gc")
+	keep := fs.Int("keep-versions", cfg.Retention.KeepVersions, "сколько последних версий хранить (0 — без ограничения)")
+	maxAge := fs.Int("max-age-months", cfg.Retention.MaxAgeMonths, "хранить версии не старше стольких месяцев (0 — без ограничения)")
+	stateDir := fs.String("state-dir", os.Getenv("SERVE_STATE_DIR"), "каталог состояния serve с историей задач (по умолчанию .openapi-aggregator)")
+	fs.Parse(args)
+
+	dir := "."
+	if fs.NArg() > 0 {
+		dir = fs.Arg(0)
+	}
+	if *stateDir == "" {
+		*stateDir = ".openapi-aggregator"
+	}
+	cfg.Retention = config.Retention{KeepVersions: *keep, MaxAgeMonths: *maxAge}
+	if err := cfg.Retention.Validate(); err != nil {
+		return fail(exitConfigInvalid, "%v", err)
+	}
+	if !cfg.Retention.Enabled() {
+		return fail(exitConfigInvalid, "Политика хранения не задана: укажите retention в конфигурации, --keep-versions или --max-age-months")
+	}
+
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	history := filepath.Join(dir, "history")
+	if _, err := os.Stat(history); err == nil {
+		_, versions, removed, err := writeHistoryPages(ctx, cfg, dir, history)
+		if err != nil {
+			return err
+		}
+		printOK("Страницы истории: осталось версий %d, удалено %d", versions, removed)
+	}
+
+	dirs, err := filepath.Glob(filepath.Join(*stateDir, "tenants", "*", "jobs"))
+	if err != nil {
+		return fail(exitError, "Ошибка поиска задач: %v", err)
+	}
+	now := time.Now()
+	for _, jobsDir := range append([]string{filepath.Join(*stateDir, "jobs")}, dirs...) {
+		if _, err := os.Stat(jobsDir); err != nil {
+			continue
+		}
+		expired := 0
+		removed, err := (&server.Jobs{Dir: jobsDir}).Prune(func(i int, created time.Time) bool {
+			keep := cfg.Retention.Keep(i, created, now)
+			if !keep && *dryRun {
+				expired++
+				return true
+			}
+			return keep
+		})
+		if err != nil {
+			return fail(exitError, "Ошибка очистки задач в %s: %v", jobsDir, err)
+		}
+		if *dryRun {
+			printInfo("Пробный запуск: в %s будет удалено задач: %d", jobsDir, expired)
+			continue
+		}
+		printOK("Задачи в %s: удалено %d", jobsDir, removed)
+	}
+	return nil
+}
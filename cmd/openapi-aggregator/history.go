@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/RastBast/docs12121/pkg/config"
+	"github.com/RastBast/docs12121/pkg/spec"
+)
+
+func generateHistoryPages(ctx context.Context, cfg config.Config, args []string) error {
+	fs := newFlagSet("history-pages")
+	output := fs.String("output", "", "каталог для страниц истории (по умолчанию history в каталоге документации)")
+	fs.Parse(args)
+
+	dir := "."
+	if fs.NArg() > 0 {
+		dir = fs.Arg(0)
+	}
+	out := *output
+	if out == "" {
+		out = filepath.Join(dir, "history")
+	}
+
+	if err := cfg.Retention.Validate(); err != nil {
+		return fail(exitConfigInvalid, "%v", err)
+	}
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	pages, versions, removed, err := writeHistoryPages(ctx, cfg, dir, out)
+	if err != nil {
+		return err
+	}
+	printOK("Страницы истории: %s (сервисов: %d, версий: %d)", out, pages, versions)
+	if removed > 0 {
+		printInfo("Удалено версий по политике хранения: %d", removed)
+	}
+	return nil
+}
+
+func writeHistoryPages(ctx context.Context, cfg config.Config, dir, out string) (pages, versions, removed int, err error) {
+	docs, err := readDocuments(dir)
+	if err != nil {
+		return pages, versions, removed, fail(exitValidation, "Ошибка чтения спецификаций: %v", err)
+	}
+
+	links := spec.ReleaseLinks{
+		Commit: func(commit string) string {
+			return "https://" + cfg.GiteaHost + "/" + cfg.Organization + "/" + cfg.DocsRepo + "/commit/" + commit
+		},
+		Download: func(repo, version string) string { return version + "/openapi.yaml" },
+	}
+	now := time.Now()
+	for _, repo := range spec.SortedRepos(docs) {
+		h, err := spec.BuildReleaseHistory(ctx, dir, repo, links)
+		if err != nil {
+			return pages, versions, removed, fail(exitError, "Ошибка чтения истории %s: %v", repo, err)
+		}
+		kept, idx := h.Releases[:0], 0
+		for _, r := range h.Releases {
+			if !spec.SafeVersion(r.Version) {
+				printFail("%s: версия %q не подходит для имени каталога, пропущена", repo, r.Version)
+				continue
+			}
+			if cfg.Retention.Keep(idx, r.Date, now) {
+				kept = append(kept, r)
+			}
+			idx++
+		}
+		h.Releases = kept
+		repoDir := filepath.Join(out, repo)
+		n, err := pruneVersionDirs(repoDir, h.Releases)
+		if err != nil {
+			return pages, versions, removed, fail(exitError, "Ошибка удаления старых версий %s: %v", repo, err)
+		}
+		removed += n
+		for _, r := range h.Releases {
+			data, err := spec.AtCommit(ctx, dir, r.Commit, repo)
+			if err != nil {
+				return pages, versions, removed, fail(exitError, "Ошибка чтения %s@%s: %v", repo, r.Version, err)
+			}
+			path := filepath.Join(repoDir, r.Version, "openapi.yaml")
+			if err := writeFile(path, data, 0o644); err != nil {
+				return pages, versions, removed, fail(exitError, "Ошибка записи %s: %v", path, err)
+			}
+			versions++
+		}
+		f, err := createFile(filepath.Join(repoDir, "index.html"))
+		if err != nil {
+			return pages, versions, removed, fail(exitError, "Ошибка создания страницы истории: %v", err)
+		}
+		err = h.WriteHTML(f)
+		f.Close()
+		if err != nil {
+			return pages, versions, removed, fail(exitError, "Ошибка записи страницы истории: %v", err)
+		}
+		pages++
+	}
+	return pages, versions, removed, nil
+}
+
+func pruneVersionDirs(repoDir string, kept []spec.Release) (int, error) {
+	entries, err := os.ReadDir(repoDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	keep := map[string]bool{}
+	for _, r := range kept {
+		keep[r.Version] = true
+	}
+	removed := 0
+	for _, e := range entries {
+		if !e.IsDir() || keep[e.Name()] {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(repoDir, e.Name(), "openapi.yaml")); err != nil {
+			continue
+		}
+		if err := removeAll(filepath.Join(repoDir, e.Name())); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/RastBast/docs12121/pkg/config"
+	"github.com/RastBast/docs12121/pkg/generator"
+	"github.com/RastBast/docs12121/pkg/git"
+)
+
+func installHooks(ctx context.Context, cfg config.Config, args []string) error {
+	fs := newFlagSet("install-hooks")
+	hooks := fs.String("hook", "pre-commit", "хуки через запятую: "+strings.Join(generator.HookKinds, ", "))
+	repo := fs.String("repo", "", "имя сервиса (по умолчанию имя каталога репозитория)")
+	binary := fs.String("binary", "openapi-aggregator", "команда, которой хук запускает агрегатор")
+	force := fs.Bool("force", false, "заменить существующий хук, установленный не агрегатором")
+	fs.Parse(args)
+
+	dir := argOrDefault(fs.Args(), 0, ".")
+	top, err := git.Output(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return fail(exitError, "%s не является git-репозиторием: %v", dir, err)
+	}
+	top = strings.TrimSpace(top)
+	hooksDir, err := git.Output(ctx, top, "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return fail(exitError, "Не удалось определить каталог хуков: %v", err)
+	}
+	hooksDir = strings.TrimSpace(hooksDir)
+	if !filepath.IsAbs(hooksDir) {
+		hooksDir = filepath.Join(top, hooksDir)
+	}
+	if *repo == "" {
+		*repo = filepath.Base(top)
+	}
+	rc := cfg.Repo(*repo)
+	var specs []string
+	for _, api := range rc.Specs() {
+		specs = append(specs, api.SpecPath)
+	}
+
+	for _, kind := range config.SplitList(*hooks) {
+		content, err := generator.Hook(kind, rc, *binary)
+		if err != nil {
+			return fail(exitConfigInvalid, "%v", err)
+		}
+		path := filepath.Join(hooksDir, kind)
+		if current, err := os.ReadFile(path); err == nil && !*force && !strings.Contains(string(current), generator.HookMarker) {
+			return fail(exitError, "%s уже существует и установлен не агрегатором; используйте --force, чтобы заменить его", path)
+		}
+		if err := writeFile(path, []byte(content), 0o755); err != nil {
+			return fail(exitError, "Ошибка записи хука: %v", err)
+		}
+		if err := os.Chmod(path, 0o755); err != nil {
+			return fail(exitError, "Ошибка записи хука: %v", err)
+		}
+		printOK("%s: хук установлен, проверяет %s", path, strings.Join(specs, ", "))
+	}
+	return nil
+}
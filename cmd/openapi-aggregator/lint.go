@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/RastBast/docs12121/pkg/lint"
+	"github.com/RastBast/docs12121/pkg/spec"
+)
+
+func lintSpecs(args []string) error {
+	fs := newFlagSet("lint")
+	rulesPath := fs.String("rules", ".lint.yaml", "файл правил: уровни (error, warning, info, off) и стили имён")
+	format := fs.String("format", "text", "формат вывода: text, json или github (аннотации для CI)")
+	failOn := fs.String("fail-on", lint.LevelError, "минимальный уровень, при котором проверка не проходит: error, warning или info")
+	list := fs.Bool("list", false, "показать правила и их уровни")
+	fs.Parse(args)
+
+	rules := lint.Default()
+	if _, err := os.Stat(*rulesPath); err == nil {
+		if rules, err = lint.Load(*rulesPath); err != nil {
+			return fail(exitConfigInvalid, "Ошибка чтения правил: %v", err)
+		}
+	}
+	threshold := map[string]int{lint.LevelError: 3, lint.LevelWarning: 2, lint.LevelInfo: 1}
+	if threshold[*failOn] == 0 {
+		return fail(exitConfigInvalid, "Неизвестный уровень %q (доступны: error, warning, info)", *failOn)
+	}
+	if *list {
+		for _, r := range lint.Rules {
+			cfg := rules[r.Name]
+			if cfg.Style != "" {
+				fmt.Printf("%-30s %-8s %s (%s)\n", r.Name, cfg.Level, r.Description, cfg.Style)
+			} else {
+				fmt.Printf("%-30s %-8s %s\n", r.Name, cfg.Level, r.Description)
+			}
+		}
+		return nil
+	}
+
+	paths := fs.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	var findings []lint.Finding
+	checked := 0
+	for _, p := range paths {
+		sources, err := specSources(p)
+		if err != nil {
+			return err
+		}
+		names := make([]string, 0, len(sources))
+		for name := range sources {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			file := sources[name]
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return fail(exitError, "Ошибка чтения %s: %v", file, err)
+			}
+			found, err := lint.Check(name, data, rules)
+			if err != nil {
+				return fail(exitValidation, "%s: %v", name, err)
+			}
+			findings = append(findings, found...)
+			checked++
+		}
+	}
+	if checked == 0 {
+		return fail(exitValidation, "Не найдено ни одной спецификации")
+	}
+
+	failed := 0
+	for _, f := range findings {
+		if threshold[f.Level] >= threshold[*failOn] {
+			failed++
+		}
+	}
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(findings)
+	case "github":
+		for _, f := range findings {
+			level := map[string]string{lint.LevelError: "error", lint.LevelWarning: "warning"}[f.Level]
+			if level == "" {
+				level = "notice"
+			}
+			fmt.Printf("::%s title=%s::%s: %s: %s\n", level, f.Rule, f.Repo, f.Location, f.Message)
+		}
+	case "text":
+		for _, f := range findings {
+			if f.Level == lint.LevelError {
+				printFail("%s: %s: %s [%s]", f.Repo, f.Location, f.Message, f.Rule)
+			} else {
+				printInfo("%s: %s: %s [%s]", f.Repo, f.Location, f.Message, f.Rule)
+			}
+		}
+	default:
+		return fail(exitConfigInvalid, "Неизвестный формат %q (доступны: text, json, github)", *format)
+	}
+	if failed > 0 {
+		return fail(exitValidation, "Замечаний уровня %s и выше: %d", *failOn, failed)
+	}
+	if *format == "text" {
+		printOK("Спецификаций проверено: %d, замечаний: %d", checked, len(findings))
+	}
+	return nil
+}
+
+func specSources(path string) (map[string]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fail(exitValidation, "Ошибка чтения %s: %v", path, err)
+	}
+	if !info.IsDir() {
+		return map[string]string{path: path}, nil
+	}
+	files, err := spec.SpecFiles(path)
+	if err != nil {
+		return nil, fail(exitError, "Ошибка поиска спецификаций: %v", err)
+	}
+	sources := map[string]string{}
+	for _, f := range files {
+		sources[spec.SpecName(path, f)] = f
+	}
+	return sources, nil
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/RastBast/docs12121/pkg/config"
+	"github.com/RastBast/docs12121/pkg/gitea"
+	"github.com/RastBast/docs12121/pkg/prose"
+	"github.com/RastBast/docs12121/pkg/server"
+)
+
+func listenWebhooks(ctx context.Context, cfg config.Config, args []string) error {
+	fs := newFlagSet("listen")
+	addr := fs.String("addr", ":8081", "адрес приёма вебхуков")
+	secret := fs.String("secret", os.Getenv("WEBHOOK_SECRET"), "секрет вебхука Gitea для проверки подписи")
+	stateDir := fs.String("state-dir", os.Getenv("SERVE_STATE_DIR"), "каталог состояния задач (по умолчанию .openapi-aggregator)")
+	jobInterval := fs.Duration("job-interval", 5*time.Second, "как часто проверять очередь задач агрегации")
+	push := fs.Bool("push", false, "отправлять коммиты агрегации в удалённый репозиторий документации")
+	glossaryPath := fs.String("glossary", os.Getenv("SERVE_GLOSSARY"), "глоссарий для задач агрегации: замечания пишутся в журнал, уровень error блокирует публикацию")
+	insecure := fs.Bool("insecure", false, "принимать вебхуки без проверки подписи, если WEBHOOK_SECRET не задан")
+	reloadInterval := fs.Duration("reload-interval", 30*time.Second, "как часто перечитывать конфигурацию: репозитории, ветки и цели уведомлений (0 — только по SIGHUP)")
+	fs.Parse(args)
+
+	dir := "."
+	if fs.NArg() > 0 {
+		dir = fs.Arg(0)
+	}
+	if *stateDir == "" {
+		*stateDir = ".openapi-aggregator"
+	}
+	if err := cfg.Validate(); err != nil {
+		return fail(exitConfigInvalid, "Некорректная конфигурация: %v", err)
+	}
+	token := os.Getenv("GITEA_TOKEN")
+	if token == "" {
+		return fail(exitConfigInvalid, "Не задан GITEA_TOKEN")
+	}
+	if *secret == "" {
+		if !*insecure {
+			return fail(exitConfigInvalid, "Не задан WEBHOOK_SECRET: без него любой может ставить задачи агрегации. Укажите --secret или явно --insecure")
+		}
+		printFail("WEBHOOK_SECRET не задан: подпись вебхуков не проверяется (--insecure)")
+	}
+	var glossary *prose.Glossary
+	if *glossaryPath != "" {
+		g, err := prose.LoadGlossary(*glossaryPath)
+		if err != nil {
+			return fail(exitConfigInvalid, "Ошибка чтения глоссария: %v", err)
+		}
+		glossary = g
+	}
+	jobs := &server.Jobs{
+		JobSettings: jobSettings(cfg, token, dir, *stateDir, *push, glossary, printInfo),
+		Dir:         filepath.Join(*stateDir, "jobs"),
+		Logf:        printInfo,
+	}
+	go jobs.Work(ctx, *jobInterval)
+
+	// Routing and the queue switch to a reloaded config together; queued and
+	// running jobs are kept.
+	var routing atomic.Pointer[pushRouting]
+	routing.Store(&pushRouting{cfg: cfg, client: gitea.NewClient(cfg.GiteaHost, token)})
+	reloader := &configReloader{
+		load: loadServeConfig,
+		apply: func(cfg config.Config) {
+			jobs.Reconfigure(jobSettings(cfg, token, dir, *stateDir, *push, glossary, printInfo))
+			routing.Store(&pushRouting{cfg: cfg, client: gitea.NewClient(cfg.GiteaHost, token)})
+		},
+		current: cfg,
+	}
+	go reloader.watch(ctx, *reloadInterval)
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hup:
+				reloader.reload(false)
+			}
+		}
+	}()
+
+	mux := http.NewServeMux()
+	mux.Handle("POST /webhook", &server.WebhookReceiver{
+		Secret: *secret,
+		Jobs:   jobs,
+		Route: func(ctx context.Context, ev *server.PushEvent) (string, string) {
+			r := routing.Load()
+			return routePush(ctx, r.cfg, r.client, ev)
+		},
+		Logf: printInfo,
+	})
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok\n"))
+	})
+	srv := &http.Server{Addr: *addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdown)
+	}()
+
+	printStart("Приём вебхуков Gitea на %s/webhook, документация в %s", *addr, dir)
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return fail(exitError, "Ошибка HTTP-сервера: %v", err)
+	}
+	return nil
+}
+
+type pushRouting struct {
+	cfg    config.Config
+	client *gitea.Client
+}
+
+func routePush(ctx context.Context, cfg config.Config, client *gitea.Client, ev *server.PushEvent) (repo, reason string) {
+	if ev.Repository.Fork {
+		return "", "push в форк " + ev.Repository.Owner.Login + "/" + ev.Repository.Name
+	}
+	if !strings.EqualFold(ev.Repository.Owner.Login, cfg.Organization) {
+		return "", "репозиторий вне организации " + cfg.Organization
+	}
+	name := ev.Repository.Name
+	if !slices.Contains(cfg.Repositories, name) {
+		return "", name + " не входит в конфигурацию"
+	}
+	branch, ok := ev.Branch()
+	if !ok {
+		return "", ev.Ref + " не является веткой"
+	}
+	if ev.Deleted() {
+		return "", "ветка " + branch + " удалена"
+	}
+	rc := cfg.Repo(name)
+	if rc.DocsRepo != cfg.DocsRepo {
+		return "", name + " публикуется в " + rc.DocsRepo
+	}
+	if !slices.ContainsFunc(rc.Branches, func(pattern string) bool {
+		ok, _ := path.Match(pattern, branch)
+		return ok
+	}) {
+		return "", "ветка " + branch + " не отслеживается"
+	}
+	if ev.Created() {
+		return name, ""
+	}
+	files, err := client.ChangedFiles(ctx, cfg.Organization, name, ev.Before, ev.After)
+	if err != nil {
+		printInfo("%s: изменения %.7s..%.7s не получены (%v), спецификации будут пересобраны", name, ev.Before, ev.After, err)
+		return name, ""
+	}
+	for _, api := range rc.Specs() {
+		if slices.Contains(files, api.SpecPath) {
+			return name, ""
+		}
+	}
+	return "", "спецификация не изменилась"
+}
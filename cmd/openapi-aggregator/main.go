@@ -0,0 +1,357 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/RastBast/docs12121/pkg/config"
+	"github.com/RastBast/docs12121/pkg/generator"
+	"github.com/RastBast/docs12121/pkg/gitea"
+	"github.com/RastBast/docs12121/pkg/spec"
+)
+
+var configFile = flag.String("config", os.Getenv("AGGREGATOR_CONFIG"), "файлы конфигурации YAML через запятую, следующие переопределяют предыдущие (по умолчанию "+config.DefaultFile+", если он есть)")
+
+var configEnv = flag.String("env", os.Getenv("AGGREGATOR_ENV"), "окружение: поверх основного файла конфигурации применяется <имя>.<env>.yaml")
+
+var fixturesDir = flag.String("fixtures", os.Getenv("AGGREGATOR_FIXTURES"), "каталог записанных ответов Gitea API: запросы воспроизводятся из него без обращения к серверу")
+
+var recordFixtures = flag.Bool("record", false, "вместе с --fixtures: выполнять запросы к Gitea и записывать ответы в каталог")
+
+var cacheTTL = flag.Duration("cache-ttl", 0, "кэшировать GET-ответы Gitea API на диске на указанное время, затем перепроверять по ETag (0 — без кэша)")
+
+var cacheDir = flag.String("cache-dir", os.Getenv("AGGREGATOR_CACHE_DIR"), "каталог кэша Gitea API (по умолчанию openapi-aggregator/gitea в пользовательском каталоге кэша)")
+
+var timeout = flag.Duration("timeout", 10*time.Minute, "максимальное время выполнения команды (в serve — одного запроса)")
+
+func main() {
+	flag.Usage = func() {
+		out := flag.CommandLine.Output()
+		fmt.Fprintln(out, "Использование: openapi-aggregator [флаги] <команда> [флаги команды]\n\nКоманды:")
+		printCommands(out)
+		fmt.Fprintln(out, "\nФлаги:")
+		flag.PrintDefaults()
+		fmt.Fprintf(out, "\nСправка по команде: openapi-aggregator help <команда>\n\n%s\n", exitCodesHelp)
+	}
+	flag.Parse()
+	if err := run(flag.Args()); err != nil {
+		code := exitCode(err)
+		logger.Error(err.Error(), "exit_code", code)
+		os.Exit(code)
+	}
+}
+
+func run(args []string) error {
+	if err := configureOutput(); err != nil {
+		return err
+	}
+	if *fixturesDir != "" {
+		gitea.Transport = &gitea.Fixtures{Dir: *fixturesDir, Record: *recordFixtures}
+	} else if *recordFixtures {
+		return fail(exitConfigInvalid, "--record требует --fixtures")
+	}
+	if *cacheTTL < 0 {
+		return fail(exitConfigInvalid, "--cache-ttl не может быть отрицательным")
+	}
+	if *cacheTTL > 0 {
+		cache, err := gitea.NewCache(*cacheDir, *cacheTTL)
+		if err != nil {
+			return fail(exitError, "Ошибка создания кэша Gitea API: %v", err)
+		}
+		gitea.DefaultCache = cache
+	}
+	configureTracing()
+	configureDryRun()
+	if len(args) < 1 {
+		return fail(exitConfigInvalid, "Использование: openapi-aggregator [флаги] <команда>\nКоманды: %s\nСправка: openapi-aggregator help\n%s", strings.Join(commandNames(), ", "), exitCodesHelp)
+	}
+
+	name, args := args[0], args[1:]
+	c, ok := findCommand(name)
+	if !ok {
+		return fail(exitConfigInvalid, "Неизвестная команда %q. Доступные команды: %s", name, strings.Join(commandNames(), ", "))
+	}
+	if helpRequested(args, len(c.subcommands) > 0) {
+		if len(c.subcommands) > 0 && strings.HasPrefix(args[0], "-") {
+			return showHelp([]string{name})
+		}
+		return c.run(context.Background(), config.Config{}, args)
+	}
+	if err := hoistSharedFlags(args); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var cfg config.Config
+	if c.config {
+		var err error
+		if cfg, err = loadConfig(); err != nil {
+			return err
+		}
+		spec.EnvironmentDirs = cfg.EnvironmentDirs()
+	}
+	return c.run(ctx, cfg, args)
+}
+
+func generateWorkflows(cfg config.Config, args []string) error {
+	fs := newFlagSet("generate")
+	platform := platformFlag(fs)
+	profile := fs.String("profile", "", "профиль воркфлоу: "+strings.Join(generator.Profiles, ", ")+" (по умолчанию из конфигурации)")
+	template := fs.String("template", "", "свой шаблон воркфлоу (text/template с разделителями [[ ]]) вместо встроенного профиля")
+	fs.Parse(args)
+	if *platform != "" {
+		cfg.Platform = *platform
+	}
+	if *profile != "" {
+		cfg.Profile = *profile
+	}
+	if *template != "" {
+		cfg.Template = *template
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return fail(exitConfigInvalid, "Некорректная конфигурация: %v", err)
+	}
+	path := filepath.FromSlash(generator.WorkflowPath(cfg.Platform))
+
+	content, err := generator.Generate(cfg)
+	if err != nil {
+		code := exitValidation
+		if errors.Is(err, generator.ErrUnknownProfile) || errors.Is(err, generator.ErrUnsupportedPlatform) {
+			code = exitConfigInvalid
+		}
+		return fail(code, "Ошибка генерации воркфлоу: %v", err)
+	}
+
+	if err := writeFile(path, []byte(content), 0o644); err != nil {
+		return fail(exitError, "Ошибка записи файла: %v", err)
+	}
+	printWritten("Воркфлоу создан: %s", path)
+
+	workflows, err := repoWorkflows(cfg)
+	if err != nil {
+		return err
+	}
+	for _, repo := range cfg.Repositories {
+		repoContent := workflows[repo]
+		if repoContent == content {
+			continue
+		}
+		repoPath := filepath.Join(filepath.Dir(path), repo, filepath.Base(path))
+		if err := writeFile(repoPath, []byte(repoContent), 0o644); err != nil {
+			return fail(exitError, "Ошибка записи файла: %v", err)
+		}
+		printWritten("Воркфлоу для %s создан: %s", repo, repoPath)
+	}
+	createReadme(cfg)
+	return nil
+}
+
+func platformFlag(fs *flag.FlagSet) *string {
+	return fs.String("platform", "", "платформа CI: gitea, github или gitlab (по умолчанию из конфигурации)")
+}
+
+func repoWorkflows(cfg config.Config) (map[string]string, error) {
+	warnUnpinnedTool()
+	workflows := make(map[string]string, len(cfg.Repositories))
+	for _, repo := range cfg.Repositories {
+		content, err := generator.GenerateRepo(cfg, repo)
+		if err != nil {
+			code := exitValidation
+			if errors.Is(err, generator.ErrUnknownProfile) || errors.Is(err, generator.ErrUnsupportedPlatform) {
+				code = exitConfigInvalid
+			}
+			return nil, fail(code, "Ошибка генерации воркфлоу для %s: %v", repo, err)
+		}
+		workflows[repo] = content
+	}
+	return workflows, nil
+}
+
+func warnUnpinnedTool() {
+	if generator.ToolVersion() == "" {
+		printFail("Версия агрегатора не определена (сборка не из тега): шаги воркфлоу будут запускать %s. Соберите тег через go install %s@<версия> или задайте -ldflags \"-X github.com/RastBast/docs12121/pkg/generator.Version=<версия>\"", generator.ToolRef(), generator.ToolModule)
+	}
+}
+
+func setupProject(ctx context.Context, args []string) error {
+	fs := newFlagSet("setup")
+	host := fs.String("host", "", "хост Gitea")
+	org := fs.String("org", "", "организация")
+	docsRepo := fs.String("docs-repo", "", "репозиторий документации")
+	repos := fs.String("repos", "", "репозитории через запятую")
+	profile := fs.String("profile", "", "профиль воркфлоу: "+strings.Join(generator.Profiles, ", "))
+	platform := fs.String("platform", "", "платформа CI: gitea, github или gitlab")
+	fromJSON := fs.String("from-json", "", "JSON-файл с конфигурацией (- для stdin)")
+	fs.Parse(args)
+
+	printStart("Настройка проекта агрегатора OpenAPI документации")
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	switch {
+	case *fromJSON != "":
+		if cfg, err = loadJSONConfig(*fromJSON); err != nil {
+			return err
+		}
+	case fs.NFlag() == 0:
+		var ok bool
+		if cfg, ok, err = runWizard(ctx, cfg); err != nil {
+			return err
+		}
+		if !ok {
+			printInfo("Настройка отменена, файлы не изменены")
+			return nil
+		}
+	}
+	for _, f := range []struct {
+		value  string
+		target *string
+	}{
+		{*host, &cfg.GiteaHost},
+		{*org, &cfg.Organization},
+		{*docsRepo, &cfg.DocsRepo},
+		{*profile, &cfg.Profile},
+		{*platform, &cfg.Platform},
+	} {
+		if f.value != "" {
+			*f.target = f.value
+		}
+	}
+	if *repos != "" {
+		cfg.Repositories = config.SplitList(*repos)
+	}
+	if err := cfg.Validate(); err != nil {
+		return fail(exitConfigInvalid, "Некорректная конфигурация: %v", err)
+	}
+
+	if err := writeFile(".env", []byte(cfg.EnvFile()), 0o644); err != nil {
+		return fail(exitError, "Ошибка создания .env: %v", err)
+	}
+	printWritten("Конфигурация сохранена в .env")
+
+	return generateWorkflows(cfg, nil)
+}
+
+func loadConfig() (config.Config, error) {
+	cfg, err := loadConfigFiles()
+	if err != nil {
+		return cfg, err
+	}
+	// Settings from the docs repo override the config files, while the
+	// environment is applied last and overrides both.
+	dir := cfg.SettingsDir
+	if env := os.Getenv("SETTINGS_DIR"); env != "" {
+		dir = env
+	}
+	if dir != "" {
+		if err := cfg.ApplySettings(dir); err != nil {
+			return cfg, fail(exitConfigInvalid, "Ошибка загрузки настроек команд: %v", err)
+		}
+	}
+	cfg.ApplyEnv()
+	return cfg, nil
+}
+
+func configPaths() ([]string, error) {
+	paths := config.SplitList(*configFile)
+	if len(paths) == 0 {
+		if _, err := os.Stat(config.DefaultFile); err == nil {
+			paths = []string{config.DefaultFile}
+		}
+	}
+	if *configEnv != "" {
+		if len(paths) == 0 {
+			return nil, fail(exitConfigInvalid, "Для --env нужен основной файл конфигурации (%s или --config)", config.DefaultFile)
+		}
+		paths = append(paths, config.OverlayPath(paths[0], *configEnv))
+	}
+	return paths, nil
+}
+
+func loadConfigFiles() (config.Config, error) {
+	paths, err := configPaths()
+	if err != nil {
+		return config.Config{}, err
+	}
+	if len(paths) == 0 {
+		return config.Defaults(), nil
+	}
+	cfg, err := config.ReadSources(readConfigSource, paths...)
+	if err != nil {
+		return cfg, fail(exitCodeFor(err, exitConfigInvalid), "Ошибка загрузки конфигурации: %v", err)
+	}
+	return cfg, nil
+}
+
+func readConfigSource(path string) ([]byte, error) {
+	remote, ok := config.ParseRemote(path)
+	if !ok {
+		if strings.HasPrefix(path, config.RemoteScheme) {
+			return nil, fmt.Errorf("%s: ожидается %sорганизация/репозиторий/путь", path, config.RemoteScheme)
+		}
+		return os.ReadFile(path)
+	}
+	host := os.Getenv("GITEA_HOST")
+	if host == "" {
+		return nil, fmt.Errorf("%s: для загрузки конфигурации из Gitea задайте GITEA_HOST", path)
+	}
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	data, _, err := gitea.NewClient(host, os.Getenv("GITEA_TOKEN")).GetFile(ctx, remote.Owner, remote.Repo, remote.Path, "")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return data, nil
+}
+
+func loadJSONConfig(path string) (config.Config, error) {
+	in := os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return config.Config{}, fail(exitError, "Ошибка чтения %s: %v", path, err)
+		}
+		defer f.Close()
+		in = f
+	}
+	cfg, err := config.FromJSON(in)
+	if err != nil {
+		return cfg, fail(exitConfigInvalid, "Некорректная конфигурация: %v", err)
+	}
+	return cfg, nil
+}
+
+func createReadme(cfg config.Config) {
+	if err := writeFile("README.md", []byte(generator.Readme(cfg)), 0o644); err != nil {
+		printFail("Не удалось создать README.md: %v", err)
+	} else {
+		printWritten("README.md создан")
+	}
+}
+
+func withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if *timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, *timeout)
+}
+
+func argOrDefault(args []string, i int, def string) string {
+	if len(args) > i {
+		return args[i]
+	}
+	return def
+}
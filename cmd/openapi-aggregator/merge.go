@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/RastBast/docs12121/pkg/config"
+	"github.com/RastBast/docs12121/pkg/spec"
+)
+
+func mergeSpecs(ctx context.Context, cfg config.Config, args []string) error {
+	fs := newFlagSet("merge")
+	dir := fs.String("dir", ".", "локальная копия репозитория документации")
+	output := fs.String("output", "-", "куда записать объединённую спецификацию (- для stdout, .json — в формате JSON)")
+	title := fs.String("title", "API", "заголовок объединённой спецификации")
+	version := fs.String("version", "1.0.0", "версия объединённой спецификации")
+	servers := fs.String("server", "", "адреса шлюза через запятую для секции servers")
+	noPrefix := fs.Bool("no-prefix", false, "не добавлять к путям префикс /<сервис>")
+	fs.Parse(args)
+
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	files, err := spec.SpecFiles(*dir)
+	if err != nil {
+		return fail(exitError, "Ошибка поиска спецификаций: %v", err)
+	}
+	var sources []spec.MergeSource
+	for _, f := range files {
+		name := spec.SpecName(*dir, f)
+		if name == cfg.SharedRepo || (fs.NArg() > 0 && !slices.Contains(fs.Args(), name)) {
+			continue
+		}
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return fail(exitError, "Ошибка чтения %s: %v", f, err)
+		}
+		if cfg.SharedRepo != "" {
+			data, _, err = spec.Bundle(data, cfg.SharedRepo, spec.SharedFromDocs(ctx, *dir, cfg.SharedRepo))
+			if err != nil {
+				return fail(exitValidation, "Ошибка сборки %s: %v", name, err)
+			}
+		}
+		sources = append(sources, spec.MergeSource{Name: name, Data: data})
+	}
+	if len(sources) == 0 {
+		return fail(exitError, "В %s нет спецификаций для объединения", *dir)
+	}
+
+	merged, result, err := spec.Merge(sources, spec.MergeOptions{
+		Title:    *title,
+		Version:  *version,
+		Servers:  config.SplitList(*servers),
+		NoPrefix: *noPrefix,
+	})
+	if err != nil {
+		return fail(exitValidation, "Ошибка объединения: %v", err)
+	}
+	if strings.EqualFold(filepath.Ext(*output), ".json") {
+		if merged, err = spec.ToJSON(merged); err != nil {
+			return fail(exitError, "Ошибка преобразования в JSON: %v", err)
+		}
+	}
+
+	if *output == "-" {
+		os.Stdout.Write(merged)
+		return nil
+	}
+	if err := writeFile(*output, merged, 0o644); err != nil {
+		return fail(exitError, "Ошибка записи %s: %v", *output, err)
+	}
+	printOK("Объединено сервисов: %d, путей: %d: %s", result.Services, result.Paths, *output)
+	if len(result.Shared) > 0 {
+		printInfo("Общие компоненты без префикса: %s", strings.Join(result.Shared, ", "))
+	}
+	if result.OperationIDs > 0 {
+		printInfo("Переименовано совпадающих operationId: %d", result.OperationIDs)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/RastBast/docs12121/pkg/config"
+	"github.com/RastBast/docs12121/pkg/metrics"
+)
+
+func pushMetricsCommand(ctx context.Context, cfg config.Config, args []string) error {
+	fs := newFlagSet("metrics")
+	repo := fs.String("repo", "", "репозиторий сервиса, например org/payments")
+	branch := fs.String("branch", "", "ветка, из которой опубликована спецификация")
+	name := fs.String("spec", "", "имя спецификации в репозитории документации (по умолчанию имя репозитория)")
+	timestamp := fs.String("timestamp", "", "время обновления в формате RFC 3339 (по умолчанию текущее)")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 || *repo == "" {
+		return fail(exitConfigInvalid, "Использование: metrics --repo <репозиторий> [--branch ветка] [--spec имя] [--timestamp время] <файл спецификации>")
+	}
+	if !cfg.Metrics.Enabled() {
+		printInfo("Бэкенд метрик не настроен (metrics.backend / METRICS_BACKEND), метрики не отправлены")
+		return nil
+	}
+	backend, err := metrics.New(cfg.Metrics)
+	if err != nil {
+		return fail(exitConfigInvalid, "Некорректные настройки метрик: %v", err)
+	}
+	info, err := os.Stat(fs.Arg(0))
+	if err != nil {
+		return fail(exitError, "Ошибка чтения %s: %v", fs.Arg(0), err)
+	}
+	s := metrics.Sample{Repository: *repo, Branch: *branch, Spec: *name, Timestamp: time.Now().UTC(), FileSize: info.Size()}
+	if s.Spec == "" {
+		s.Spec = s.Repository[strings.LastIndex(s.Repository, "/")+1:]
+	}
+	if *timestamp != "" {
+		if s.Timestamp, err = time.Parse(time.RFC3339, *timestamp); err != nil {
+			return fail(exitConfigInvalid, "Некорректное время %q: ожидается RFC 3339", *timestamp)
+		}
+	}
+	if skipInDryRun("метрики %s не будут отправлены в %s", s.Spec, cfg.Metrics.Backend) {
+		return nil
+	}
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	if err := backend.Push(ctx, s); err != nil {
+		return fail(exitAPI, "Ошибка отправки метрик: %v", err)
+	}
+	printOK("Метрики %s отправлены (%s)", s.Spec, cfg.Metrics.Backend)
+	return nil
+}
+
+func pushMetrics(ctx context.Context, cfg config.Config, dir, repo, ref string, changed []string) error {
+	if !cfg.Metrics.Enabled() || *dryRun {
+		return nil
+	}
+	backend, err := metrics.New(cfg.Metrics)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	for _, rel := range changed {
+		info, err := os.Stat(filepath.Join(dir, filepath.FromSlash(rel)))
+		if err != nil {
+			return err
+		}
+		s := metrics.Sample{
+			Repository: cfg.Organization + "/" + repo,
+			Branch:     ref,
+			Spec:       strings.TrimSuffix(rel, "/openapi.yaml"),
+			Timestamp:  now,
+			FileSize:   info.Size(),
+		}
+		if err := backend.Push(ctx, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
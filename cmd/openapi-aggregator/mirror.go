@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/RastBast/docs12121/pkg/config"
+	"github.com/RastBast/docs12121/pkg/generator"
+	"github.com/RastBast/docs12121/pkg/git"
+)
+
+func mirrorDocs(ctx context.Context, cfg config.Config, dir string) error {
+	if cfg.MirrorURL == "" {
+		return fail(exitConfigInvalid, "Зеркало не настроено: укажите MIRROR_URL")
+	}
+	remote := "https://" + cfg.MirrorURL + ".git"
+	if token := os.Getenv("MIRROR_TOKEN"); token != "" {
+		remote = "https://" + token + "@" + cfg.MirrorURL + ".git"
+	}
+
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	if skipInDryRun("репозиторий %s не будет отправлен в %s (режим %s)", dir, cfg.MirrorURL, cfg.MirrorMode) {
+		return nil
+	}
+	if cfg.MirrorMode != "portal" {
+		if err := git.MirrorRepo(ctx, dir, remote); err != nil {
+			return fail(exitAPI, "Ошибка зеркалирования: %v", err)
+		}
+		printOK("Репозиторий документации отзеркалирован в %s", cfg.MirrorURL)
+		return nil
+	}
+
+	author := git.Author{Name: "OpenAPI Aggregator Bot", Email: "openapi-bot@" + cfg.GiteaHost}
+	if err := git.MirrorPaths(ctx, dir, remote, generator.PortalPaths, author, "Portal snapshot"); err != nil {
+		return fail(exitAPI, "Ошибка зеркалирования портала: %v", err)
+	}
+	printOK("Портал отзеркалирован в %s", cfg.MirrorURL)
+	return nil
+}
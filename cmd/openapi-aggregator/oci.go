@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/RastBast/docs12121/pkg/config"
+	"github.com/RastBast/docs12121/pkg/oci"
+	"github.com/RastBast/docs12121/pkg/spec"
+)
+
+func pushSpecOCI(ctx context.Context, cfg config.Config, args []string) error {
+	fs := newFlagSet("oci-push")
+	repo := fs.String("repo", "", "имя сервиса (по умолчанию имя директории спецификации)")
+	fs.Parse(args)
+
+	if cfg.OCIRegistry == "" {
+		return fail(exitConfigInvalid, "Реестр не настроен: укажите OCI_REGISTRY (например registry.example.com/myorg/apis)")
+	}
+	if fs.NArg() == 0 {
+		return fail(exitConfigInvalid, "Использование: oci-push [--repo имя] <openapi.yaml>")
+	}
+	specPath := fs.Arg(0)
+	if *repo == "" {
+		*repo = filepath.Base(filepath.Dir(specPath))
+	}
+
+	data, err := os.ReadFile(specPath)
+	if err != nil {
+		return fail(exitError, "Ошибка чтения спецификации: %v", err)
+	}
+	info, err := spec.ParseInfo(data)
+	if err != nil {
+		return fail(exitValidation, "Ошибка чтения спецификации: %v", err)
+	}
+	if info.Version == "" {
+		return fail(exitValidation, "В %s не указан info.version", specPath)
+	}
+
+	host, prefix, _ := strings.Cut(cfg.OCIRegistry, "/")
+	name := strings.TrimPrefix(prefix+"/"+*repo, "/")
+	if skipInDryRun("%s %s не будет опубликован в %s/%s", specPath, info.Version, host, name) {
+		return nil
+	}
+	client := oci.NewClient(host, os.Getenv("OCI_USERNAME"), os.Getenv("OCI_PASSWORD"))
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	tags, err := client.PushSpec(ctx, name, filepath.Base(specPath), data, info.Title, info.Version)
+	if err != nil {
+		return fail(exitAPI, "Ошибка публикации артефакта: %v", err)
+	}
+	for _, tag := range tags {
+		printOK("%s/%s:%s", host, name, tag)
+	}
+	return nil
+}
@@ -0,0 +1,309 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/RastBast/docs12121/pkg/config"
+	"github.com/RastBast/docs12121/pkg/generator"
+	"github.com/RastBast/docs12121/pkg/git"
+	"github.com/RastBast/docs12121/pkg/gitea"
+	"github.com/RastBast/docs12121/pkg/spec"
+)
+
+const onboardIssueTitle = "OpenAPI aggregator onboarding"
+
+type onboardStep struct {
+	Title  string
+	Detail string
+	Todo   string
+}
+
+func onboardRepo(ctx context.Context, cfg config.Config, args []string) error {
+	fs := newFlagSet("onboard")
+	specPath := fs.String("spec-path", "", "путь к спецификации в репозитории (по умолчанию spec_path из конфигурации или найденный среди типичных путей)")
+	viaPR := fs.Bool("pr", false, "открыть pull request с воркфлоу вместо прямого коммита в ветку по умолчанию")
+	rotate := fs.Bool("rotate", false, "перезаписать уже заданные секреты новыми значениями")
+	tokenEnv := fs.String("token-env", "", "переменная окружения с отдельным токеном для секрета GITEA_TOKEN воркфлоу (обязательно; не сам GITEA_TOKEN)")
+	noIssue := fs.Bool("no-issue", false, "не создавать в репозитории issue с итогами подключения")
+	platform := platformFlag(fs)
+	fs.Parse(args)
+	if *platform != "" {
+		cfg.Platform = *platform
+	}
+
+	if fs.NArg() != 1 {
+		return fail(exitConfigInvalid, "Использование: onboard [--spec-path путь] [--pr] [--rotate] [--token-env переменная] [--no-issue] [--platform gitea|github|gitlab] <репозиторий>")
+	}
+	repo := fs.Arg(0)
+	if err := cfg.Validate(); err != nil {
+		return fail(exitConfigInvalid, "Некорректная конфигурация: %v", err)
+	}
+	if slices.Contains(cfg.DocsRepos(), repo) {
+		return fail(exitConfigInvalid, "%s — репозиторий документации, его не нужно подключать", repo)
+	}
+	token := os.Getenv("GITEA_TOKEN")
+	if token == "" {
+		return fail(exitConfigInvalid, "Не задан GITEA_TOKEN")
+	}
+	var secrets map[string]string
+	if cfg.Platform == "" || cfg.Platform == generator.PlatformGitea {
+		var err error
+		if secrets, err = workflowSecrets(*tokenEnv); err != nil {
+			return err
+		}
+	}
+
+	client := gitea.NewClient(cfg.GiteaHost, token)
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	info, err := client.GetRepo(ctx, cfg.Organization, repo)
+	if err != nil {
+		return fail(exitCodeFor(err, exitAPI), "Репозиторий %s/%s недоступен: %v", cfg.Organization, repo, err)
+	}
+	base := info.DefaultBranch
+	printStart("Подключение %s/%s", cfg.Organization, repo)
+
+	registered := slices.Contains(cfg.Repositories, repo)
+	rc := cfg.Repo(repo)
+	if *specPath != "" {
+		rc.SpecPath, rc.APIs = *specPath, nil
+	} else if !registered {
+		found, err := findSpec(ctx, client, cfg.Organization, repo, base, rc.SpecPath)
+		if err != nil {
+			return fail(exitCodeFor(err, exitAPI), "%s: %v", repo, err)
+		}
+		rc.SpecPath = found
+	}
+	cfg = withRepo(cfg, rc)
+	var steps []onboardStep
+
+	specBranch := base
+	if *viaPR {
+		specBranch = installBranch
+	}
+	apis := rc.Specs()
+	contents := make([][]byte, len(apis))
+	for i, api := range apis {
+		step, data, err := ensureSpec(ctx, client, cfg.Organization, rc, api, base, specBranch)
+		if err != nil {
+			return fail(exitCodeFor(err, exitValidation), "%s: %s: %v", repo, api.SpecPath, err)
+		}
+		printOK("%s: %s", step.Title, step.Detail)
+		contents[i] = data
+		steps = append(steps, step)
+	}
+
+	warnUnpinnedTool()
+	workflow, err := generator.GenerateRepo(cfg, repo)
+	if err != nil {
+		return fail(exitValidation, "Ошибка генерации воркфлоу для %s: %v", repo, err)
+	}
+	path := generator.WorkflowPath(cfg.Platform)
+	status, err := deployRepo(ctx, client, cfg.Organization, repo, path, workflow, *viaPR, cfg.Tracking)
+	if err != nil {
+		return fail(exitCodeFor(err, exitAPI), "%s: ошибка установки воркфлоу: %v", repo, err)
+	}
+	printOK("Воркфлоу %s: %s", path, status)
+	step := onboardStep{Title: "Воркфлоу " + path, Detail: status}
+	if *viaPR {
+		step.Todo = "Смёржить pull request с воркфлоу " + path
+	}
+	steps = append(steps, step)
+
+	if secrets == nil {
+		printInfo("Секреты настраиваются только через Gitea API: задайте DOCS_TOKEN с правом записи в %s/%s в настройках CI вручную", cfg.Organization, rc.DocsRepo)
+		steps = append(steps, onboardStep{Title: "Секреты воркфлоу", Todo: "Задать секрет DOCS_TOKEN с правом записи в " + cfg.Organization + "/" + rc.DocsRepo + " в настройках CI"})
+	} else {
+		if err := provisionRepoSecrets(ctx, client, cfg.Organization, repo, secrets, *rotate); err != nil {
+			return fail(exitCodeFor(err, exitAPI), "%s: ошибка настройки секретов: %v", repo, err)
+		}
+		names := make([]string, 0, len(secrets))
+		for name := range secrets {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		steps = append(steps, onboardStep{Title: "Секреты воркфлоу", Detail: strings.Join(names, ", ")})
+	}
+
+	if registered {
+		printOK("%s уже в конфигурации агрегатора", repo)
+		steps = append(steps, onboardStep{Title: "Конфигурация агрегатора", Detail: "уже подключён"})
+	} else {
+		entry := config.RepoConfig{Name: repo}
+		if rc.SpecPath != cfg.Repo("").SpecPath {
+			entry.SpecPath = rc.SpecPath
+		}
+		if err := registerRepos([]config.RepoConfig{entry}); err != nil {
+			return err
+		}
+		steps = append(steps, onboardStep{Title: "Конфигурация агрегатора", Detail: "репозиторий добавлен"})
+	}
+
+	step, err = publishOnboardedSpecs(ctx, cfg, token, rc, apis, base, contents)
+	if err != nil {
+		return fail(exitCodeFor(err, exitAPI), "%s: ошибка публикации в %s: %v", repo, rc.DocsRepo, err)
+	}
+	printOK("%s: %s", step.Title, step.Detail)
+	steps = append(steps, step)
+
+	if *noIssue {
+		return nil
+	}
+	if skipInDryRun("в %s будет создан issue с итогами подключения", repo) {
+		return nil
+	}
+	issue, err := client.FindOpenIssue(ctx, cfg.Organization, repo, onboardIssueTitle)
+	if err != nil {
+		return fail(exitCodeFor(err, exitAPI), "%s: ошибка поиска issue: %v", repo, err)
+	}
+	if issue != nil {
+		if err := client.CommentIssue(ctx, cfg.Organization, repo, issue.Number, onboardSummary(cfg, rc, steps)); err != nil {
+			return fail(exitCodeFor(err, exitAPI), "%s: ошибка обновления issue #%d: %v", repo, issue.Number, err)
+		}
+		printOK("Итоги подключения добавлены в %s", issue.HTMLURL)
+		return nil
+	}
+	issue, err = client.CreateIssue(ctx, cfg.Organization, repo, onboardIssueTitle, onboardSummary(cfg, rc, steps))
+	if err != nil {
+		return fail(exitCodeFor(err, exitAPI), "%s: ошибка создания issue: %v", repo, err)
+	}
+	printOK("Итоги подключения: %s", issue.HTMLURL)
+	return nil
+}
+
+func findSpec(ctx context.Context, client *gitea.Client, org, repo, ref, configured string) (string, error) {
+	for _, p := range slices.Compact(append([]string{configured}, discoverPaths...)) {
+		ok, err := client.FileExists(ctx, org, repo, p, ref)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			return p, nil
+		}
+	}
+	return configured, nil
+}
+
+func withRepo(cfg config.Config, rc config.RepoConfig) config.Config {
+	if !slices.Contains(cfg.Repositories, rc.Name) {
+		cfg.Repositories = append(slices.Clone(cfg.Repositories), rc.Name)
+	}
+	current := cfg.Repo(rc.Name)
+	if current.SpecPath == rc.SpecPath && len(current.APIs) == len(rc.APIs) {
+		return cfg
+	}
+	o := cfg.Overrides[rc.Name]
+	o.SpecPath, o.APIs = rc.SpecPath, rc.APIs
+	cfg.Overrides = maps.Clone(cfg.Overrides)
+	if cfg.Overrides == nil {
+		cfg.Overrides = map[string]config.RepoConfig{}
+	}
+	cfg.Overrides[rc.Name] = o
+	return cfg
+}
+
+func ensureSpec(ctx context.Context, client *gitea.Client, org string, rc config.RepoConfig, api config.APIConfig, ref, branch string) (onboardStep, []byte, error) {
+	step := onboardStep{Title: "Спецификация " + api.SpecPath}
+	data, _, err := client.GetFile(ctx, org, rc.Name, api.SpecPath, ref)
+	var apiErr *gitea.APIError
+	switch {
+	case err == nil:
+		if _, err := spec.ParseDocument(data); err != nil {
+			return step, nil, err
+		}
+		step.Detail = "найдена"
+		return step, data, nil
+	case !errors.As(err, &apiErr) || apiErr.Status != http.StatusNotFound:
+		return step, nil, err
+	}
+
+	data, err = spec.Scaffold(rc.DocsName(api))
+	if err != nil {
+		return step, nil, err
+	}
+	if branch != ref {
+		if err := client.CreateBranch(ctx, org, rc.Name, branch, ref); err != nil {
+			return step, nil, err
+		}
+	}
+	if err := client.PutFile(ctx, org, rc.Name, api.SpecPath, branch, "Add OpenAPI spec skeleton", data); err != nil {
+		return step, nil, err
+	}
+	step.Detail = "не найдена, закоммичена заготовка в " + branch
+	step.Todo = "Описать API в " + api.SpecPath
+	return step, data, nil
+}
+
+// publishOnboardedSpecs puts the specs into a clone of the docs repository
+// the same way aggregate does, so the manifest is updated and the commit is
+// made by the bot, then pushes it.
+func publishOnboardedSpecs(ctx context.Context, cfg config.Config, token string, rc config.RepoConfig, apis []config.APIConfig, ref string, contents [][]byte) (onboardStep, error) {
+	branch, prefix := cfg.DocsTarget(ref, "")
+	step := onboardStep{Title: "Каталог " + rc.DocsRepo}
+	if skipInDryRun("спецификации %s будут опубликованы в %s (%s)", rc.Name, rc.DocsRepo, branch) {
+		step.Detail = "пробный запуск"
+		return step, nil
+	}
+	tmp, err := os.MkdirTemp("", "openapi-onboard-")
+	if err != nil {
+		return step, err
+	}
+	defer os.RemoveAll(tmp)
+	dir := filepath.Join(tmp, "docs")
+	if err := cloneRepo(ctx, cfg, token, rc.DocsRepo, branch, dir); err != nil {
+		return step, err
+	}
+	changed, err := publishSpecs(dir, prefix, rc, apis, contents)
+	if err != nil {
+		return step, err
+	}
+	if len(changed) == 0 {
+		step.Detail = "уже опубликована"
+		return step, nil
+	}
+	author := git.Author{Name: "OpenAPI Aggregator Bot", Email: "openapi-bot@" + cfg.GiteaHost}
+	if err := git.Run(ctx, dir, "add", "."); err != nil {
+		return step, err
+	}
+	if err := git.Commit(ctx, dir, author, cfg.Tracking.CommitMessage("Add OpenAPI docs for "+rc.Name)); err != nil {
+		return step, err
+	}
+	if err := git.PushRebased(ctx, dir, author); err != nil {
+		return step, errors.New(strings.ReplaceAll(err.Error(), token, "***"))
+	}
+	step.Detail = "опубликовано в " + branch + ": " + strings.Join(changed, ", ")
+	return step, nil
+}
+
+func onboardSummary(cfg config.Config, rc config.RepoConfig, steps []onboardStep) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Репозиторий подключён к агрегатору OpenAPI документации: при каждом пуше в %s спецификация публикуется в %s/%s.\n\n",
+		strings.Join(rc.Branches, ", "), cfg.Organization, rc.DocsRepo)
+	b.WriteString("## Выполнено\n\n")
+	var todo []string
+	for _, s := range steps {
+		if s.Detail != "" {
+			fmt.Fprintf(&b, "- [x] %s: %s\n", s.Title, s.Detail)
+		}
+		if s.Todo != "" {
+			todo = append(todo, s.Todo)
+		}
+	}
+	if len(todo) > 0 {
+		b.WriteString("\n## Осталось сделать\n\n")
+		for _, t := range todo {
+			fmt.Fprintf(&b, "- [ ] %s\n", t)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+
+	"github.com/RastBast/docs12121/pkg/config"
+	"github.com/RastBast/docs12121/pkg/spec"
+)
+
+func generateOnboardingPages(cfg config.Config, args []string) error {
+	fs := newFlagSet("onboarding-pages")
+	output := fs.String("output", "", "каталог для страниц «Начало работы» (по умолчанию onboarding в каталоге документации)")
+	fs.Parse(args)
+
+	dir := "."
+	if fs.NArg() > 0 {
+		dir = fs.Arg(0)
+	}
+	out := *output
+	if out == "" {
+		out = filepath.Join(dir, "onboarding")
+	}
+
+	docs, err := loadDocuments(fs)
+	if err != nil {
+		return err
+	}
+	written := 0
+	for _, repo := range spec.SortedRepos(docs) {
+		frag := cfg.OnboardingFor(cfg.Repo(spec.SpecRepo(repo)))
+		pageDir := filepath.Join(out, filepath.FromSlash(repo))
+		f := spec.OnboardingFragments{
+			BaseURLs:   frag.BaseURLs,
+			RateLimits: frag.RateLimits,
+			Support:    frag.Support,
+			Auth:       frag.Auth,
+			SpecURL:    relLink(pageDir, filepath.Join(dir, filepath.FromSlash(repo), "openapi.yaml")),
+		}
+		if _, err := os.Stat(filepath.Join(dir, "static", filepath.FromSlash(repo), "index.html")); err == nil {
+			f.DocsURL = relLink(pageDir, filepath.Join(dir, "static", filepath.FromSlash(repo))) + "/"
+		}
+
+		var b bytes.Buffer
+		if err := spec.BuildOnboarding(repo, docs[repo], f).WriteHTML(&b); err != nil {
+			return fail(exitError, "Ошибка генерации страницы %s: %v", repo, err)
+		}
+		if err := writeFile(filepath.Join(pageDir, "index.html"), b.Bytes(), 0o644); err != nil {
+			return fail(exitError, "Ошибка записи страницы %s: %v", repo, err)
+		}
+		written++
+	}
+	printOK("Страницы «Начало работы»: %s (сервисов: %d)", out, written)
+	return nil
+}
+
+func relLink(from, to string) string {
+	rel, err := filepath.Rel(from, to)
+	if err != nil {
+		return ""
+	}
+	return filepath.ToSlash(rel)
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/RastBast/docs12121/pkg/gitea"
+	"github.com/RastBast/docs12121/pkg/report"
+)
+
+var (
+	noColor   = flag.Bool("no-color", false, "отключить цветной вывод (также NO_COLOR)")
+	noEmoji   = flag.Bool("no-emoji", false, "отключить эмодзи в выводе")
+	verbose   = flag.Bool("verbose", false, "подробный вывод: отладочные сообщения и запросы к Gitea API")
+	quiet     = flag.Bool("quiet", false, "выводить только ошибки и предупреждения")
+	logFormat = flag.String("log-format", os.Getenv("LOG_FORMAT"), "формат журнала: text или json (JSON Lines в stderr)")
+)
+
+type marker struct {
+	emoji string
+	plain string
+	color string
+}
+
+var (
+	markStart = marker{"🚀", "==>", "\033[36m"}
+	markOK    = marker{"✅", "OK", "\033[32m"}
+	markInfo  = marker{"ℹ️ ", "INFO", "\033[33m"}
+	markFail  = marker{"❌", "FAIL", "\033[31m"}
+	markDebug = marker{"🔍", "DEBUG", "\033[90m"}
+)
+
+const (
+	statusStart   = "start"
+	statusOK      = "ok"
+	statusInfo    = "info"
+	statusFail    = "fail"
+	statusDebug   = "debug"
+	statusSummary = "summary"
+)
+
+var logger = slog.New(&textHandler{out: os.Stdout, err: os.Stderr, level: slog.LevelInfo})
+
+var outputStyle = struct {
+	color bool
+	emoji bool
+}{}
+
+func configureOutput() error {
+	tty := isTerminal(os.Stdout)
+	outputStyle.color = tty && !*noColor && os.Getenv("NO_COLOR") == ""
+	outputStyle.emoji = tty && !*noEmoji
+
+	level := slog.LevelInfo
+	switch {
+	case *verbose && *quiet:
+		return fail(exitConfigInvalid, "--verbose и --quiet несовместимы")
+	case *verbose:
+		level = slog.LevelDebug
+	case *quiet:
+		level = slog.LevelWarn
+	}
+	switch *logFormat {
+	case "", "text":
+		logger = slog.New(&textHandler{out: os.Stdout, err: os.Stderr, level: level})
+	case "json":
+		logger = slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+	default:
+		return fail(exitConfigInvalid, "Неизвестный формат журнала %q. Доступные форматы: text, json", *logFormat)
+	}
+	return nil
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+func (m marker) format(format string, args ...any) string {
+	prefix := m.plain
+	if outputStyle.emoji {
+		prefix = m.emoji
+	}
+	if outputStyle.color {
+		prefix = m.color + prefix + "\033[0m"
+	}
+	return prefix + " " + fmt.Sprintf(format, args...)
+}
+
+func logf(level slog.Level, status, format string, args []any, attrs ...any) {
+	if !logger.Enabled(context.Background(), level) {
+		return
+	}
+	logger.Log(context.Background(), level, fmt.Sprintf(format, args...), append([]any{"status", status}, attrs...)...)
+}
+
+func printStart(format string, args ...any)  { logf(slog.LevelInfo, statusStart, format, args) }
+func printOK(format string, args ...any)     { logf(slog.LevelInfo, statusOK, format, args) }
+func printInfo(format string, args ...any)   { logf(slog.LevelInfo, statusInfo, format, args) }
+func printFail(format string, args ...any)   { logf(slog.LevelWarn, statusFail, format, args) }
+func printDebug(format string, args ...any)  { logf(slog.LevelDebug, statusDebug, format, args) }
+func printTotals(format string, args ...any) { logf(slog.LevelInfo, statusSummary, format, args) }
+
+func printSummary(sum *report.Summary) {
+	results := sum.Results()
+	if len(results) == 0 {
+		return
+	}
+	ok, failed := sum.Counts()
+	logf(slog.LevelInfo, statusSummary, "Итого: успешно %d, с ошибками %d", []any{ok, failed}, "succeeded", ok, "failed", failed)
+	for _, r := range results {
+		if r.Err == nil {
+			printOK("%s", r.Repo)
+		} else {
+			printFail("%s: %v", r.Repo, r.Err)
+		}
+	}
+}
+
+type textHandler struct {
+	mu    sync.Mutex
+	out   io.Writer
+	err   io.Writer
+	level slog.Level
+}
+
+func (h *textHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *textHandler) Handle(_ context.Context, r slog.Record) error {
+	status := ""
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key == "status" {
+			status = a.Value.String()
+			return false
+		}
+		return true
+	})
+	var w io.Writer = h.out
+	var line string
+	switch {
+	case r.Level >= slog.LevelError:
+		w, line = h.err, r.Time.Format("2006/01/02 15:04:05")+" "+r.Message
+	case r.Level < slog.LevelInfo:
+		line = markDebug.format("%s", r.Message)
+	case status == statusSummary:
+		line = "\n" + r.Message
+	case status == statusStart:
+		line = markStart.format("%s", r.Message)
+	case status == statusOK:
+		line = markOK.format("%s", r.Message)
+	case status == statusFail:
+		line = markFail.format("%s", r.Message)
+	default:
+		line = markInfo.format("%s", r.Message)
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := fmt.Fprintln(w, line)
+	return err
+}
+
+func (h *textHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h *textHandler) WithGroup(string) slog.Handler { return h }
+
+func configureTracing() {
+	if !logger.Enabled(context.Background(), slog.LevelDebug) {
+		return
+	}
+	gitea.Transport = &gitea.Trace{Next: gitea.Transport, Log: func(method, path string, status int, elapsed time.Duration, err error) {
+		if err != nil {
+			printDebug("Gitea API: %s %s: %v (%s)", method, path, err, elapsed.Round(time.Millisecond))
+			return
+		}
+		printDebug("Gitea API: %s %s → %d (%s)", method, path, status, elapsed.Round(time.Millisecond))
+	}}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/RastBast/docs12121/pkg/config"
+	"github.com/RastBast/docs12121/pkg/spec"
+)
+
+func generatePortal(cfg config.Config, args []string) error {
+	fs := newFlagSet("portal")
+	output := fs.String("output", "", "путь к странице портала (по умолчанию index.html в каталоге документации)")
+	title := fs.String("title", "", "заголовок портала (по умолчанию API Documentation)")
+	noSearch := fs.Bool("no-search", false, "не создавать поисковый индекс "+spec.SearchIndexFile+" и строку поиска на портале")
+	fs.Parse(args)
+
+	dir := "."
+	if fs.NArg() > 0 {
+		dir = fs.Arg(0)
+	}
+	if *output == "" {
+		*output = filepath.Join(dir, "index.html")
+	}
+
+	docs, err := readDocuments(dir)
+	if err != nil {
+		return fail(exitValidation, "Ошибка чтения спецификаций: %v", err)
+	}
+	portal := spec.BuildPortal(dir, docs, cfg.PortalBase(), cfg.StatusPages)
+	if *title != "" {
+		portal.Title = *title
+	}
+	planned := map[string]int64{}
+	indexPath := filepath.Join(dir, spec.SearchIndexFile)
+	var index []byte
+	if !*noSearch {
+		if index, err = searchIndex(cfg.PortalBase(), portal, docs); err != nil {
+			return fail(exitError, "Ошибка построения поискового индекса: %v", err)
+		}
+		planned[indexPath] = int64(len(index))
+	}
+
+	var b bytes.Buffer
+	if err := portal.WriteHTML(&b); err != nil {
+		return fail(exitError, "Ошибка генерации портала: %v", err)
+	}
+	planned[*output] = int64(b.Len())
+	if err := checkSiteSize(cfg, filepath.Dir(*output), planned); err != nil {
+		return err
+	}
+	if index != nil {
+		if err := writeChanged(indexPath, index); err != nil {
+			return fail(exitError, "Ошибка записи поискового индекса: %v", err)
+		}
+	}
+	if current, err := os.ReadFile(*output); err == nil && bytes.Equal(current, b.Bytes()) {
+		printOK("Портал не изменился: %s (API: %d)", *output, len(portal.Cards))
+		return nil
+	}
+	if err := writeFile(*output, b.Bytes(), 0o644); err != nil {
+		return fail(exitError, "Ошибка записи %s: %v", *output, err)
+	}
+	printOK("Портал обновлён: %s (API: %d)", *output, len(portal.Cards))
+	return nil
+}
+
+// searchIndex builds the search index and points the portal at it.
+func searchIndex(base string, portal *spec.Portal, docs map[string]*spec.Document) ([]byte, error) {
+	var b bytes.Buffer
+	if err := spec.BuildSearchIndex(portal, docs).WriteJSON(&b); err != nil {
+		return nil, err
+	}
+	portal.Search = base + spec.SearchIndexFile
+	return b.Bytes(), nil
+}
+
+func writeChanged(path string, data []byte) error {
+	if current, err := os.ReadFile(path); err == nil && bytes.Equal(current, data) {
+		return nil
+	}
+	return writeFile(path, data, 0o644)
+}
+
+// checkSiteSize checks max_portal_size against the site as it will be once
+// the planned files (path to size) are written, so an oversized site is
+// rejected before anything changes on disk.
+func checkSiteSize(cfg config.Config, dir string, planned map[string]int64) error {
+	if cfg.Limits.MaxPortalSize == 0 {
+		return nil
+	}
+	var size int64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fail(exitError, "Ошибка подсчёта размера %s: %v", dir, err)
+	}
+	for path, n := range planned {
+		if rel, err := filepath.Rel(dir, path); err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		if info, err := os.Stat(path); err == nil {
+			size -= info.Size()
+		}
+		size += n
+	}
+	if err := cfg.Limits.CheckPortal(dir, size); err != nil {
+		return fail(exitValidation, "%v", err)
+	}
+	return nil
+}
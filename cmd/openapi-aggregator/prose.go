@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/RastBast/docs12121/pkg/config"
+	"github.com/RastBast/docs12121/pkg/prose"
+	"github.com/RastBast/docs12121/pkg/spec"
+)
+
+func lintProse(args []string) error {
+	fs := newFlagSet("lint-prose")
+	rulesPath := fs.String("rules", ".prose.yaml", "файл правил (запрещённые термины, словарь исключений, языки)")
+	glossaryPath := fs.String("glossary", "glossary.yaml", "глоссарий предпочтительных терминов")
+	spell := fs.Bool("spell", true, "проверять орфографию через hunspell, если он установлен")
+	asJSON := fs.Bool("json", false, "вывести находки в JSON")
+	strict := fs.Bool("strict", false, "считать предупреждения ошибками")
+	fs.Parse(args)
+
+	rules, glossary, err := loadProseRules(*rulesPath, *glossaryPath)
+	if err != nil {
+		return err
+	}
+
+	docs, err := loadDocuments(fs)
+	if err != nil {
+		return err
+	}
+	var findings []prose.Finding
+	spellWarned := false
+	for _, repo := range spec.SortedRepos(docs) {
+		texts := prose.Texts(docs[repo])
+		findings = append(findings, prose.Check(repo, texts, rules)...)
+		if glossary != nil {
+			findings = append(findings, glossary.Check(repo, texts)...)
+		}
+		if !*spell {
+			continue
+		}
+		typos, err := prose.Spell(repo, texts, rules)
+		if err != nil && !spellWarned {
+			spellWarned = true
+			if errors.Is(err, exec.ErrNotFound) {
+				printInfo("hunspell не найден, орфография не проверяется")
+			} else {
+				printInfo("Орфография не проверяется: %v", err)
+			}
+		}
+		findings = append(findings, typos...)
+	}
+
+	failed := 0
+	for _, f := range findings {
+		if f.Level == prose.LevelError || *strict {
+			failed++
+		}
+	}
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(findings)
+	} else {
+		for _, f := range findings {
+			if f.Level == prose.LevelError {
+				printFail("%s: %s: %s [%s]", f.Repo, f.Location, f.Message, f.Rule)
+			} else {
+				printInfo("%s: %s: %s [%s]", f.Repo, f.Location, f.Message, f.Rule)
+			}
+		}
+	}
+	if failed > 0 {
+		return fail(exitValidation, "Замечаний к текстам: %d", failed)
+	}
+	if !*asJSON {
+		printOK("Тексты проверены, замечаний: %d", len(findings))
+	}
+	return nil
+}
+
+func loadProseRules(rulesPath, glossaryPath string) (prose.Rules, *prose.Glossary, error) {
+	rules := prose.DefaultRules
+	if _, err := os.Stat(rulesPath); err == nil {
+		if rules, err = prose.LoadRules(rulesPath); err != nil {
+			return rules, nil, fail(exitConfigInvalid, "Ошибка чтения правил: %v", err)
+		}
+	}
+
+	glossary, err := loadGlossary(glossaryPath)
+	return rules, glossary, err
+}
+
+func loadGlossary(path string) (*prose.Glossary, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, nil
+	}
+	glossary, err := prose.LoadGlossary(path)
+	if err != nil {
+		return nil, fail(exitConfigInvalid, "Ошибка чтения глоссария: %v", err)
+	}
+	return glossary, nil
+}
+
+// checkGlossary reports glossary findings in the specs about to be
+// published; findings at level error keep the repository from publishing.
+func checkGlossary(g *prose.Glossary, rc config.RepoConfig, apis []config.APIConfig, contents [][]byte, logf func(format string, args ...any)) error {
+	if g == nil {
+		return nil
+	}
+	failed := 0
+	for i, api := range apis {
+		doc, err := spec.ParseDocument(contents[i])
+		if err != nil {
+			return fmt.Errorf("%s: %w", api.SpecPath, err)
+		}
+		for _, f := range g.Check(rc.DocsName(api), prose.Texts(doc)) {
+			logf("%s: %s: %s [%s]", f.Repo, f.Location, f.Message, f.Level)
+			if f.Level == prose.LevelError {
+				failed++
+			}
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("замечаний глоссария уровня error: %d", failed)
+	}
+	return nil
+}
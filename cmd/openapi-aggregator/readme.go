@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/RastBast/docs12121/pkg/config"
+	"github.com/RastBast/docs12121/pkg/git"
+	"github.com/RastBast/docs12121/pkg/spec"
+)
+
+func generateDocsReadme(ctx context.Context, cfg config.Config, args []string) error {
+	fs := newFlagSet("readme")
+	output := fs.String("output", "", "путь к README (по умолчанию README.md в каталоге документации)")
+	title := fs.String("title", "", "заголовок README (по умолчанию API Documentation)")
+	noBadges := fs.Bool("no-badges", false, "не создавать значки версий в badges/")
+	fs.Parse(args)
+
+	dir := argOrDefault(fs.Args(), 0, ".")
+	if *output == "" {
+		*output = filepath.Join(dir, "README.md")
+	}
+	docs, err := readDocuments(dir)
+	if err != nil {
+		return fail(exitValidation, "Ошибка чтения спецификаций: %v", err)
+	}
+
+	base := cfg.PortalBase()
+	absolute := strings.Contains(base, "://")
+	linkBase := ""
+	if absolute {
+		linkBase = base
+	}
+	portal := spec.BuildPortal(dir, docs, linkBase, cfg.StatusPages)
+
+	updated := map[string]time.Time{}
+	badges := map[string]string{}
+	for _, card := range portal.Cards {
+		updated[card.Name] = lastUpdate(ctx, dir, card.Name+"/openapi.yaml")
+		if *noBadges || card.Version == "" {
+			continue
+		}
+		rel := "badges/" + card.Name + ".svg"
+		version := card.Version
+		if !strings.HasPrefix(version, "v") {
+			version = "v" + version
+		}
+		if err := writeFile(filepath.Join(dir, filepath.FromSlash(rel)), spec.Badge(card.Name, version), 0o644); err != nil {
+			return fail(exitError, "Ошибка записи значка %s: %v", rel, err)
+		}
+		if absolute {
+			badges[card.Name] = base + rel
+		} else {
+			badges[card.Name] = docsRawURL(cfg, rel)
+		}
+	}
+
+	readme := spec.BuildReadme(portal, updated, badges)
+	if *title != "" {
+		readme.Title = *title
+	}
+	if absolute {
+		readme.Portal = base
+	}
+	var b bytes.Buffer
+	if err := readme.Write(&b); err != nil {
+		return fail(exitError, "Ошибка генерации README: %v", err)
+	}
+	if current, err := os.ReadFile(*output); err == nil && bytes.Equal(current, b.Bytes()) {
+		printOK("README не изменился: %s (API: %d)", *output, len(readme.Rows))
+		return nil
+	}
+	if err := writeFile(*output, b.Bytes(), 0o644); err != nil {
+		return fail(exitError, "Ошибка записи %s: %v", *output, err)
+	}
+	printOK("README обновлён: %s (API: %d)", *output, len(readme.Rows))
+	return nil
+}
+
+func lastUpdate(ctx context.Context, dir, rel string) time.Time {
+	if out, err := git.Output(ctx, dir, "log", "-1", "--format=%cI", "--", rel); err == nil {
+		if t, err := time.Parse(time.RFC3339, strings.TrimSpace(out)); err == nil {
+			return t
+		}
+	}
+	if info, err := os.Stat(filepath.Join(dir, filepath.FromSlash(rel))); err == nil {
+		return info.ModTime()
+	}
+	return time.Time{}
+}
+
+func docsRawURL(cfg config.Config, rel string) string {
+	return docsRepoURL(cfg, "raw", rel)
+}
+
+func docsRepoURL(cfg config.Config, view, rel string) string {
+	host := cfg.GiteaHost
+	if !strings.Contains(host, "://") {
+		host = "https://" + host
+	}
+	return strings.TrimSuffix(host, "/") + "/" + cfg.Organization + "/" + cfg.DocsRepo + "/" + view + "/branch/" + cfg.DocsBranchName() + "/" + rel
+}
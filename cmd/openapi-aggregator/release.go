@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/RastBast/docs12121/pkg/config"
+	"github.com/RastBast/docs12121/pkg/gitea"
+	"github.com/RastBast/docs12121/pkg/render"
+	"github.com/RastBast/docs12121/pkg/spec"
+)
+
+type releaseAsset struct {
+	name string
+	data []byte
+}
+
+func attachReleaseAssets(ctx context.Context, cfg config.Config, args []string) error {
+	fs := newFlagSet("release-assets")
+	tag := fs.String("tag", os.Getenv("RELEASE_TAG"), "тег релиза, к которому прикладываются файлы")
+	source := fs.String("source", ".", "локальная копия репозитория сервиса на этом теге")
+	specs := fs.String("spec", "", "спецификации через запятую: путь или имя=путь (по умолчанию из конфигурации)")
+	docsDir := fs.String("docs-dir", "", "локальная копия репозитория документации для сборки общих компонентов (shared_repo)")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 || *tag == "" {
+		return fail(exitConfigInvalid, "Использование: release-assets --tag тег [--source каталог] [--spec путь[,имя=путь...]] [--docs-dir каталог] <репозиторий>")
+	}
+	if err := cfg.Validate(); err != nil {
+		return fail(exitConfigInvalid, "Некорректная конфигурация: %v", err)
+	}
+	token := os.Getenv("GITEA_TOKEN")
+	if token == "" {
+		return fail(exitConfigInvalid, "Не задан GITEA_TOKEN")
+	}
+	rc := cfg.Repo(fs.Arg(0))
+	if *specs != "" {
+		rc.APIs, rc.SpecPath = nil, ""
+		for _, item := range config.SplitList(*specs) {
+			if name, path, ok := strings.Cut(item, "="); ok {
+				rc.APIs = append(rc.APIs, config.APIConfig{Name: name, SpecPath: path})
+			} else {
+				rc.SpecPath = item
+			}
+		}
+	}
+
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	var assets []releaseAsset
+	for _, api := range rc.Specs() {
+		files, err := releaseFiles(ctx, cfg, rc.DocsName(api), filepath.Join(*source, filepath.FromSlash(api.SpecPath)), *docsDir)
+		if err != nil {
+			return fail(exitValidation, "%s: %v", api.SpecPath, err)
+		}
+		assets = append(assets, files...)
+	}
+
+	client := gitea.NewClient(cfg.GiteaHost, token)
+	release, err := client.GetReleaseByTag(ctx, cfg.Organization, rc.Name, *tag)
+	var apiErr *gitea.APIError
+	if errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound {
+		return fail(exitAPI, "Релиз %s не найден в %s/%s", *tag, cfg.Organization, rc.Name)
+	}
+	if err != nil {
+		return fail(exitAPI, "Ошибка получения релиза: %v", err)
+	}
+	for _, a := range assets {
+		// The new file is uploaded under a temporary name first, so a failed
+		// upload leaves the previous one in place.
+		tmp := a.name + ".uploading"
+		var old []gitea.Attachment
+		for _, existing := range release.Assets {
+			switch existing.Name {
+			case tmp:
+				if err := client.DeleteReleaseAttachment(ctx, cfg.Organization, rc.Name, release.ID, existing.ID); err != nil {
+					return fail(exitAPI, "Ошибка удаления незавершённой загрузки %s: %v", tmp, err)
+				}
+			case a.name:
+				old = append(old, existing)
+			}
+		}
+		uploaded, err := client.UploadReleaseAttachment(ctx, cfg.Organization, rc.Name, release.ID, tmp, a.data)
+		if err != nil {
+			return fail(exitAPI, "Ошибка загрузки %s: %v", a.name, err)
+		}
+		for _, existing := range old {
+			if err := client.DeleteReleaseAttachment(ctx, cfg.Organization, rc.Name, release.ID, existing.ID); err != nil {
+				return fail(exitAPI, "Ошибка удаления старого файла %s: %v", a.name, err)
+			}
+		}
+		if uploaded, err = client.RenameReleaseAttachment(ctx, cfg.Organization, rc.Name, release.ID, uploaded.ID, a.name); err != nil {
+			return fail(exitAPI, "Файл загружен как %s, но не переименован в %s: %v", tmp, a.name, err)
+		}
+		printOK("%s приложен к релизу %s: %s", a.name, *tag, uploaded.DownloadURL)
+	}
+	return nil
+}
+
+func releaseFiles(ctx context.Context, cfg config.Config, name, path, docsDir string) ([]releaseAsset, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if spec.IsSwagger2(data) {
+		if cfg.Swagger2 == "fail" {
+			return nil, fmt.Errorf("%w (swagger2: fail)", spec.ErrSwagger2)
+		}
+		if data, err = spec.ConvertSwagger2(data); err != nil {
+			return nil, fmt.Errorf("преобразование Swagger 2.0: %w", err)
+		}
+	}
+	if cfg.SharedRepo != "" && docsDir != "" {
+		if data, _, err = spec.Bundle(data, cfg.SharedRepo, spec.SharedFromDocs(ctx, docsDir, cfg.SharedRepo)); err != nil {
+			return nil, fmt.Errorf("сборка: %w", err)
+		}
+	}
+	if err := cfg.Limits.CheckSpec(path, len(data)); err != nil {
+		return nil, err
+	}
+	specJSON, err := spec.ToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	page, inlined, err := render.Offline(name, specJSON)
+	if err != nil {
+		return nil, err
+	}
+	if !inlined {
+		printInfo("redoc.standalone.js не встроен в сборку: страница %s загружает его с unpkg.com с проверкой целостности (см. go generate ./pkg/render)", name)
+	}
+	prefix := strings.ReplaceAll(name, "/", "-")
+	return []releaseAsset{
+		{name: prefix + "-openapi.yaml", data: data},
+		{name: prefix + "-docs.html", data: page},
+	}, nil
+}
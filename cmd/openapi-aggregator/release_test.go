@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/RastBast/docs12121/pkg/config"
+)
+
+// fakeReleases is a minimal Gitea release API: one release whose assets can
+// be uploaded, renamed and deleted.
+type fakeReleases struct {
+	mu     sync.Mutex
+	nextID int64
+	assets map[int64]string
+	bodies map[int64][]byte
+	calls  []string
+}
+
+func (f *fakeReleases) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, r.Method+" "+r.URL.Path)
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/releases/tags/v1.0.0"):
+		var list []map[string]any
+		for id, name := range f.assets {
+			list = append(list, map[string]any{"id": id, "name": name})
+		}
+		json.NewEncoder(w).Encode(map[string]any{"id": 1, "tag_name": "v1.0.0", "assets": list})
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/releases/1/assets"):
+		file, _, err := r.FormFile("attachment")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		data, _ := io.ReadAll(file)
+		f.nextID++
+		f.assets[f.nextID] = r.URL.Query().Get("name")
+		f.bodies[f.nextID] = data
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]any{"id": f.nextID, "name": f.assets[f.nextID]})
+	case r.Method == http.MethodPatch:
+		var id int64
+		fmt.Sscanf(r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:], "%d", &id)
+		var body struct{ Name string }
+		json.NewDecoder(r.Body).Decode(&body)
+		f.assets[id] = body.Name
+		json.NewEncoder(w).Encode(map[string]any{"id": id, "name": body.Name, "browser_download_url": "http://gitea/" + body.Name})
+	case r.Method == http.MethodDelete:
+		var id int64
+		fmt.Sscanf(r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:], "%d", &id)
+		delete(f.assets, id)
+		delete(f.bodies, id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestReleaseAssetsReplace(t *testing.T) {
+	gitea := &fakeReleases{
+		nextID: 10,
+		assets: map[int64]string{2: "pets-openapi.yaml", 3: "pets-docs.html.uploading"},
+		bodies: map[int64][]byte{2: []byte("old"), 3: []byte("partial")},
+	}
+	srv := httptest.NewServer(gitea)
+	defer srv.Close()
+
+	source := t.TempDir()
+	if err := os.WriteFile(filepath.Join(source, "openapi.yaml"), []byte("openapi: 3.0.0\ninfo:\n  title: Pets\n  version: 1.0.0\npaths: {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GITEA_TOKEN", "token")
+	cfg := config.Defaults()
+	cfg.GiteaHost, cfg.Organization, cfg.DocsRepo = srv.URL, "org", "docs"
+	cfg.Repositories = []string{"pets"}
+
+	err := attachReleaseAssets(context.Background(), cfg, []string{"--tag", "v1.0.0", "--source", source, "--spec", "openapi.yaml", "pets"})
+	if err != nil {
+		t.Fatalf("release-assets: %v", err)
+	}
+
+	names := map[string][]byte{}
+	for id, name := range gitea.assets {
+		names[name] = gitea.bodies[id]
+	}
+	if len(names) != 2 {
+		t.Fatalf("assets after upload: %v", gitea.assets)
+	}
+	if got := string(names["pets-openapi.yaml"]); !strings.Contains(got, "title: Pets") {
+		t.Errorf("pets-openapi.yaml was not replaced: %q", got)
+	}
+	if page := string(names["pets-docs.html"]); !strings.Contains(page, "Redoc.init") || !strings.Contains(page, `"title":"Pets"`) {
+		t.Errorf("pets-docs.html is not an offline page: %.200q", page)
+	}
+
+	// The old file goes away only after the new one is uploaded.
+	var upload, remove int
+	for i, call := range gitea.calls {
+		switch call {
+		case "POST /api/v1/repos/org/pets/releases/1/assets":
+			if upload == 0 {
+				upload = i
+			}
+		case "DELETE /api/v1/repos/org/pets/releases/1/assets/2":
+			remove = i
+		}
+	}
+	if upload == 0 || remove < upload {
+		t.Errorf("old asset deleted before the upload: %v", gitea.calls)
+	}
+}
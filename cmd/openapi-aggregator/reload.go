@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/RastBast/docs12121/pkg/config"
+	"github.com/RastBast/docs12121/pkg/gitea"
+	"github.com/RastBast/docs12121/pkg/prose"
+	"github.com/RastBast/docs12121/pkg/server"
+)
+
+// jobSettings builds the parts of the job queue that follow the config: the
+// repositories, their branch allow-lists and the job itself with its
+// dispatch and notification targets.
+func jobSettings(cfg config.Config, token, dir, stateDir string, push bool, glossary *prose.Glossary, logf func(format string, args ...any)) server.JobSettings {
+	repos := cfg.ReposFor(cfg.DocsRepo)
+	branches := make(map[string][]string, len(repos))
+	for _, repo := range repos {
+		branches[repo] = cfg.Repo(repo).Branches
+	}
+	client := gitea.NewClient(cfg.GiteaHost, token)
+	return server.JobSettings{
+		Repos:    repos,
+		Branches: branches,
+		DefaultBranch: func(ctx context.Context, repo string) (string, error) {
+			r, err := client.GetRepo(ctx, cfg.Organization, repo)
+			if err != nil {
+				return "", err
+			}
+			return r.DefaultBranch, nil
+		},
+		Run: trackJobFailures(cfg, token, stateDir, aggregateJob(cfg, token, dir, push, glossary, newDispatcher(cfg, stateDir, logf))),
+	}
+}
+
+// configReloader re-reads the config of a long-running command and hands a
+// changed, valid config to apply. An invalid config is logged and the one in
+// use stays.
+type configReloader struct {
+	load   func() (config.Config, error)
+	apply  func(cfg config.Config)
+	prefix string
+
+	mu      sync.Mutex
+	current config.Config
+	lastErr string
+}
+
+// reload reads the config once. Polling passes quiet, so an unchanged config
+// and a repeated error are not logged on every tick.
+func (r *configReloader) reload(quiet bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cfg, err := r.load()
+	if err != nil {
+		if !quiet || err.Error() != r.lastErr {
+			printFail("%sКонфигурация не перечитана, остаётся прежняя: %v", r.prefix, err)
+		}
+		r.lastErr = err.Error()
+		return
+	}
+	r.lastErr = ""
+	if reflect.DeepEqual(cfg, r.current) {
+		if !quiet {
+			printInfo("%sКонфигурация не изменилась", r.prefix)
+		}
+		return
+	}
+	// Environment directories are process-wide and read by running jobs.
+	if !slices.Equal(cfg.EnvironmentDirs(), r.current.EnvironmentDirs()) {
+		printFail("%sКонфигурация не перечитана: изменился список окружений, он вступит в силу после перезапуска", r.prefix)
+		return
+	}
+	r.apply(cfg)
+	r.current = cfg
+	printInfo("%sКонфигурация перечитана: репозитории %v", r.prefix, cfg.ReposFor(cfg.DocsRepo))
+}
+
+// watch polls the config every interval, which covers local files, the
+// settings directory and remote sources alike.
+func (r *configReloader) watch(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.reload(true)
+		}
+	}
+}
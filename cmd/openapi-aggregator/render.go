@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+
+	"github.com/RastBast/docs12121/pkg/config"
+	"github.com/RastBast/docs12121/pkg/render"
+	"github.com/RastBast/docs12121/pkg/spec"
+)
+
+func renderSite(cfg config.Config, args []string) error {
+	fs := newFlagSet("render")
+	uis := fs.String("ui", strings.Join(render.UIs, ","), "интерфейсы через запятую: swagger (interactive/<api>/) и redoc (static/<api>/)")
+	output := fs.String("output", "", "каталог сайта (по умолчанию каталог документации)")
+	noSearch := fs.Bool("no-search", false, "не создавать поисковый индекс "+spec.SearchIndexFile+" и строку поиска на портале")
+	fs.Parse(args)
+
+	dir := "."
+	if fs.NArg() > 0 {
+		dir = fs.Arg(0)
+	}
+	if *output == "" {
+		*output = dir
+	}
+
+	docs, err := readDocuments(dir)
+	if err != nil {
+		return fail(exitValidation, "Ошибка чтения спецификаций: %v", err)
+	}
+	if len(docs) == 0 {
+		return fail(exitValidation, "В %s не найдено ни одной спецификации", dir)
+	}
+	site := render.Site{Dir: dir, Output: *output, UIs: config.SplitList(*uis), OAuthClientID: cfg.OAuthClientID}
+	if *dryRun {
+		site.WriteFile = func(path string, data []byte) error { return writeFile(path, data, 0o644) }
+	}
+	// A first pass only records what the site would write, so the size
+	// limit is checked before any file changes.
+	planned := map[string]int64{}
+	plan := site
+	plan.WriteFile = func(path string, data []byte) error {
+		planned[path] = int64(len(data))
+		return nil
+	}
+	res, err := plan.Render(spec.SortedRepos(docs))
+	if errors.Is(err, render.ErrUnknownUI) {
+		return fail(exitConfigInvalid, "%v", err)
+	}
+	if err != nil {
+		return fail(exitError, "Ошибка генерации сайта: %v", err)
+	}
+
+	portal := spec.BuildPortal(*output, docs, "", cfg.StatusPages)
+	indexPath := filepath.Join(*output, spec.SearchIndexFile)
+	var index []byte
+	if !*noSearch {
+		if index, err = searchIndex("", portal, docs); err != nil {
+			return fail(exitError, "Ошибка построения поискового индекса: %v", err)
+		}
+		planned[indexPath] = int64(len(index))
+	}
+	var page bytes.Buffer
+	if err := portal.WriteHTML(&page); err != nil {
+		return fail(exitError, "Ошибка генерации портала: %v", err)
+	}
+	pagePath := filepath.Join(*output, "index.html")
+	planned[pagePath] = int64(page.Len())
+	if err := checkSiteSize(cfg, *output, planned); err != nil {
+		return err
+	}
+
+	if _, err := site.Render(spec.SortedRepos(docs)); err != nil {
+		return fail(exitError, "Ошибка генерации сайта: %v", err)
+	}
+	if len(res.CDN) > 0 {
+		printInfo("Ресурсы не встроены в сборку и загружаются с unpkg.com с проверкой целостности: %s (см. go generate ./pkg/render)", strings.Join(res.CDN, ", "))
+	}
+	if index != nil {
+		if err := writeChanged(indexPath, index); err != nil {
+			return fail(exitError, "Ошибка записи поискового индекса: %v", err)
+		}
+	}
+	if err := writeFile(pagePath, page.Bytes(), 0o644); err != nil {
+		return fail(exitError, "Ошибка записи портала: %v", err)
+	}
+	printOK("Сайт документации создан в %s (API: %d, страниц: %d)", *output, len(docs), res.Pages)
+	return nil
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+
+	"github.com/RastBast/docs12121/pkg/spec"
+)
+
+func runReportCommand(args []string) error {
+	if len(args) == 0 {
+		return fail(exitConfigInvalid, "Использование: report <errors|pagination|duplicates|webhooks|oauth|slo|sdk> [--output файл] [--format markdown|json] [--strict] [каталог]")
+	}
+	switch args[0] {
+	case "errors":
+		return reportErrors(args[1:])
+	case "pagination":
+		return reportPagination(args[1:])
+	case "duplicates":
+		return reportDuplicates(args[1:])
+	case "webhooks":
+		return reportWebhooks(args[1:])
+	case "oauth":
+		return reportOAuth(args[1:])
+	case "slo":
+		return reportSLO(args[1:])
+	case "sdk":
+		return reportSDK(args[1:])
+	default:
+		return fail(exitConfigInvalid, "Неизвестный отчёт %q. Доступные отчёты: errors, pagination, duplicates, webhooks, oauth, slo, sdk", args[0])
+	}
+}
+
+func reportErrors(args []string) error {
+	fs := newFlagSet("report errors")
+	output := fs.String("output", "errors.md", "путь к отчёту (- для stdout)")
+	format := fs.String("format", "markdown", "формат отчёта: markdown или json")
+	strict := fs.Bool("strict", false, "завершиться с ошибкой при расхождении форматов")
+	fs.Parse(args)
+
+	docs, err := loadDocuments(fs)
+	if err != nil {
+		return err
+	}
+	catalog := spec.BuildErrorCatalog(docs)
+	if err := writeReport(*output, *format, catalog, catalog.WriteMarkdown); err != nil {
+		return err
+	}
+
+	bad := catalog.Inconsistent()
+	printOK("Каталог ошибок: %s (ответов: %d, расхождений: %d)", *output, len(catalog.Responses), len(bad))
+	if *strict && len(bad) > 0 {
+		return fail(exitValidation, "Найдены ответы об ошибках в нестандартном формате: %d", len(bad))
+	}
+	return nil
+}
+
+func reportPagination(args []string) error {
+	fs := newFlagSet("report pagination")
+	output := fs.String("output", "pagination.md", "путь к отчёту (- для stdout)")
+	format := fs.String("format", "markdown", "формат отчёта: markdown или json")
+	strict := fs.Bool("strict", false, "завершиться с ошибкой при отклонениях от преобладающего стиля")
+	fs.Parse(args)
+
+	docs, err := loadDocuments(fs)
+	if err != nil {
+		return err
+	}
+	r := spec.BuildPaginationReport(docs)
+	if err := writeReport(*output, *format, r, r.WriteMarkdown); err != nil {
+		return err
+	}
+
+	bad := r.Divergent()
+	printOK("Отчёт о пагинации: %s (операций: %d, отклонений: %d)", *output, len(r.Operations), len(bad))
+	if *strict && len(bad) > 0 {
+		return fail(exitValidation, "Найдены операции с нестандартной пагинацией: %d", len(bad))
+	}
+	return nil
+}
+
+func reportDuplicates(args []string) error {
+	fs := newFlagSet("report duplicates")
+	output := fs.String("output", "duplicates.md", "путь к отчёту (- для stdout)")
+	format := fs.String("format", "markdown", "формат отчёта: markdown или json")
+	threshold := fs.Float64("threshold", 0.8, "минимальное сходство схем от 0 до 1")
+	fs.Parse(args)
+	if *threshold <= 0 || *threshold > 1 {
+		return fail(exitConfigInvalid, "--threshold должен быть в диапазоне (0, 1]")
+	}
+
+	docs, err := loadDocuments(fs)
+	if err != nil {
+		return err
+	}
+	r := spec.FindDuplicateSchemas(docs, *threshold)
+	if err := writeReport(*output, *format, r, r.WriteMarkdown); err != nil {
+		return err
+	}
+	printOK("Отчёт о дублирующихся схемах: %s (пар: %d, идентичных групп: %d)", *output, len(r.Pairs), len(r.Groups))
+	return nil
+}
+
+func reportWebhooks(args []string) error {
+	fs := newFlagSet("report webhooks")
+	output := fs.String("output", "webhooks.md", "путь к отчёту (- для stdout)")
+	format := fs.String("format", "markdown", "формат отчёта: markdown или json")
+	fs.Parse(args)
+
+	docs, err := loadDocuments(fs)
+	if err != nil {
+		return err
+	}
+	catalog := spec.CollectEvents(docs)
+	if err := writeReport(*output, *format, catalog, catalog.WriteMarkdown); err != nil {
+		return err
+	}
+	printOK("Каталог исходящих событий: %s (событий: %d)", *output, len(catalog.Events))
+	return nil
+}
+
+func reportOAuth(args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	fs := newFlagSet("report oauth")
+	output := fs.String("output", "oauth.md", "путь к отчёту (- для stdout)")
+	format := fs.String("format", "markdown", "формат отчёта: markdown или json")
+	issuer := fs.String("issuer", cfg.OAuthIssuer, "адрес корпоративного провайдера OAuth")
+	strict := fs.Bool("strict", false, "завершиться с ошибкой при найденных проблемах")
+	fs.Parse(args)
+
+	docs, err := loadDocuments(fs)
+	if err != nil {
+		return err
+	}
+	r := spec.BuildOAuthReport(docs, cfg.PortalBase(), cfg.OAuthClientID, *issuer)
+	if err := writeReport(*output, *format, r, r.WriteMarkdown); err != nil {
+		return err
+	}
+
+	bad := r.Problems()
+	printOK("Отчёт об OAuth: %s (схем: %d, с проблемами: %d)", *output, len(r.Schemes), len(bad))
+	if *strict && len(bad) > 0 {
+		return fail(exitValidation, "Найдены схемы OAuth, которые не заработают в интерактивной документации: %d", len(bad))
+	}
+	return nil
+}
+
+func reportSLO(args []string) error {
+	fs := newFlagSet("report slo")
+	output := fs.String("output", "slo.md", "путь к отчёту (- для stdout)")
+	format := fs.String("format", "markdown", "формат отчёта: markdown или json")
+	strict := fs.Bool("strict", false, "завершиться с ошибкой при некорректных x-slo")
+	fs.Parse(args)
+
+	docs, err := loadDocuments(fs)
+	if err != nil {
+		return err
+	}
+	r := spec.BuildSLOReport(docs)
+	if err := writeReport(*output, *format, r, r.WriteMarkdown); err != nil {
+		return err
+	}
+
+	bad := r.Invalid()
+	printOK("Отчёт о SLO: %s (операций с SLO: %d из %d, с ошибками: %d)", *output, len(r.Operations), r.Total, len(bad))
+	if *strict && len(bad) > 0 {
+		return fail(exitValidation, "Найдены некорректные x-slo: %d", len(bad))
+	}
+	return nil
+}
+
+func reportSDK(args []string) error {
+	fs := newFlagSet("report sdk")
+	output := fs.String("output", "sdk.md", "путь к отчёту (- для stdout)")
+	format := fs.String("format", "markdown", "формат отчёта: markdown или json")
+	strict := fs.Bool("strict", false, "завершиться с ошибкой, если какой-либо SDK отстаёт от контракта")
+	fs.Parse(args)
+
+	docs, err := loadDocuments(fs)
+	if err != nil {
+		return err
+	}
+	r, err := spec.BuildSDKReport(argOrDefault(fs.Args(), 0, "."), docs)
+	if err != nil {
+		return fail(exitValidation, "Ошибка чтения реестра SDK: %v", err)
+	}
+	if err := writeReport(*output, *format, r, r.WriteMarkdown); err != nil {
+		return err
+	}
+
+	lagging := r.Lagging()
+	printOK("Отчёт об SDK: %s (SDK: %d, отстают: %d, сервисов без SDK: %d)", *output, len(r.SDKs), len(lagging), len(r.Without))
+	if *strict && len(lagging) > 0 {
+		return fail(exitValidation, "SDK отстают от опубликованного контракта: %d", len(lagging))
+	}
+	return nil
+}
+
+func loadDocuments(fs *flag.FlagSet) (map[string]*spec.Document, error) {
+	dir := "."
+	if fs.NArg() > 0 {
+		dir = fs.Arg(0)
+	}
+	docs, err := readDocuments(dir)
+	if err != nil {
+		return nil, fail(exitValidation, "Ошибка чтения спецификаций: %v", err)
+	}
+	if len(docs) == 0 {
+		return nil, fail(exitValidation, "В %s не найдено ни одной спецификации", dir)
+	}
+	return docs, nil
+}
+
+func writeReport(path, format string, v any, markdown func(io.Writer) error) error {
+	out := io.Writer(os.Stdout)
+	if path != "-" {
+		f, err := createFile(path)
+		if err != nil {
+			return fail(exitError, "Ошибка создания отчёта: %v", err)
+		}
+		defer f.Close()
+		out = f
+	}
+	var err error
+	switch format {
+	case "markdown":
+		err = markdown(out)
+	case "json":
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(v)
+	default:
+		return fail(exitConfigInvalid, "Неизвестный формат %q. Доступные форматы: markdown, json", format)
+	}
+	if err != nil {
+		return fail(exitError, "Ошибка записи отчёта: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+	"strings"
+
+	"github.com/RastBast/docs12121/pkg/spec"
+)
+
+func generateCodeSamples(args []string) error {
+	fs := newFlagSet("code-samples")
+	languages := fs.String("languages", strings.Join(spec.SampleLanguages, ","), "языки примеров через запятую")
+	output := fs.String("output", "-", "куда записать спецификацию с примерами (- для stdout)")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		return fail(exitConfigInvalid, "Использование: code-samples [--languages curl,go,python,javascript] [--output файл] <openapi.yaml>")
+	}
+	langs, err := spec.ParseSampleLanguages(*languages)
+	if err != nil {
+		return fail(exitConfigInvalid, "Ошибка в --languages: %v", err)
+	}
+	if len(langs) == 0 {
+		return fail(exitConfigInvalid, "Не указан ни один язык примеров")
+	}
+
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return fail(exitError, "Ошибка чтения спецификации: %v", err)
+	}
+	out, added, err := spec.AddCodeSamples(data, langs)
+	if err != nil {
+		return fail(exitValidation, "Ошибка генерации примеров для %s: %v", fs.Arg(0), err)
+	}
+
+	if *output == "-" {
+		os.Stdout.Write(out)
+		return nil
+	}
+	if err := writeFile(*output, out, 0o644); err != nil {
+		return fail(exitError, "Ошибка записи %s: %v", *output, err)
+	}
+	printOK("Примеры кода записаны в %s (операций: %d)", *output, added)
+	return nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/RastBast/docs12121/pkg/spec"
+)
+
+func runSDKCommand(args []string) error {
+	if len(args) == 0 || args[0] != "record" {
+		return fail(exitConfigInvalid, "Использование: sdk record --repo сервис --language язык --package пакет --version версия [--spec-version версия] [каталог]")
+	}
+	return recordSDK(args[1:])
+}
+
+func recordSDK(args []string) error {
+	fs := newFlagSet("sdk record")
+	repo := fs.String("repo", "", "сервис, для которого опубликован SDK")
+	language := fs.String("language", "", "язык SDK")
+	pkg := fs.String("package", "", "имя пакета SDK")
+	version := fs.String("version", "", "опубликованная версия SDK")
+	specVersion := fs.String("spec-version", "", "версия контракта, из которой собран SDK (по умолчанию текущая)")
+	fs.Parse(args)
+
+	dir := "."
+	if fs.NArg() > 0 {
+		dir = fs.Arg(0)
+	}
+	if *repo == "" {
+		return fail(exitConfigInvalid, "Не указан --repo")
+	}
+	if *specVersion == "" {
+		data, err := os.ReadFile(filepath.Join(dir, *repo, "openapi.yaml"))
+		if err != nil {
+			return fail(exitValidation, "Ошибка чтения спецификации %s: %v", *repo, err)
+		}
+		info, err := spec.ParseInfo(data)
+		if err != nil {
+			return fail(exitValidation, "Ошибка разбора спецификации %s: %v", *repo, err)
+		}
+		*specVersion = info.Version
+	}
+
+	rel := spec.SDKRelease{Language: *language, Package: *pkg, Version: *version, SpecVersion: *specVersion, Published: time.Now().UTC().Truncate(time.Second)}
+	if skipInDryRun("SDK %s %s %s не будет записан в %s", rel.Language, rel.Package, rel.Version, filepath.Join(dir, *repo, spec.SDKFile)) {
+		return nil
+	}
+	if err := spec.RecordSDK(dir, *repo, rel); err != nil {
+		return fail(exitConfigInvalid, "Ошибка записи SDK: %v", err)
+	}
+	if err := spec.UpdateManifest(dir, []string{*repo + "/" + spec.SDKFile}); err != nil {
+		return fail(exitError, "Ошибка обновления манифеста: %v", err)
+	}
+	printOK("%s: SDK %s %s %s собран из контракта %s", *repo, rel.Language, rel.Package, rel.Version, rel.SpecVersion)
+	return nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"os"
+	"sort"
+
+	"github.com/RastBast/docs12121/pkg/config"
+	"github.com/RastBast/docs12121/pkg/generator"
+	"github.com/RastBast/docs12121/pkg/gitea"
+	"github.com/RastBast/docs12121/pkg/report"
+)
+
+func provisionSecrets(ctx context.Context, cfg config.Config, args []string) error {
+	fs := newFlagSet("secrets")
+	rotate := fs.Bool("rotate", false, "перезаписать уже заданные секреты новыми значениями")
+	tokenEnv := fs.String("token-env", "", "переменная окружения с отдельным токеном для секрета GITEA_TOKEN воркфлоу (обязательно; не сам GITEA_TOKEN)")
+	batchOpts := addBatchFlags(fs, "secrets")
+	fs.Parse(args)
+
+	if err := cfg.Validate(); err != nil {
+		return fail(exitConfigInvalid, "Некорректная конфигурация: %v", err)
+	}
+	if cfg.Platform != "" && cfg.Platform != generator.PlatformGitea {
+		return fail(exitConfigInvalid, "Секреты настраиваются только через Gitea API (платформа: %s)", cfg.Platform)
+	}
+	token := os.Getenv("GITEA_TOKEN")
+	if token == "" {
+		return fail(exitConfigInvalid, "Не задан GITEA_TOKEN")
+	}
+	values, err := workflowSecrets(*tokenEnv)
+	if err != nil {
+		return err
+	}
+
+	client := gitea.NewClient(cfg.GiteaHost, token)
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var sum report.Summary
+	runner, err := batchOpts.runner()
+	if err != nil {
+		return err
+	}
+	err = runner.Run(ctx, cfg.Repositories, &sum, func(ctx context.Context, repo string) error {
+		return provisionRepoSecrets(ctx, client, cfg.Organization, repo, values, *rotate)
+	})
+	printSummary(&sum)
+	if err != nil {
+		return fail(exitCodeFor(err, exitError), "Настройка секретов прервана: %v", err)
+	}
+	if err := sum.Err(); err != nil {
+		return fail(exitCodeFor(err, exitAPI), "%v", err)
+	}
+	return nil
+}
+
+func workflowSecrets(tokenEnv string) (map[string]string, error) {
+	if tokenEnv == "" {
+		return nil, fail(exitConfigInvalid, "Укажите --token-env: переменную окружения с отдельным токеном для воркфлоу с минимальными правами")
+	}
+	if tokenEnv == "GITEA_TOKEN" {
+		return nil, fail(exitConfigInvalid, "--token-env не может быть GITEA_TOKEN: токен администратора не должен попадать в секреты репозиториев")
+	}
+	values := map[string]string{"GITEA_TOKEN": os.Getenv(tokenEnv)}
+	if values["GITEA_TOKEN"] == "" {
+		return nil, fail(exitConfigInvalid, "Не задан %s", tokenEnv)
+	}
+	if values["GITEA_TOKEN"] == os.Getenv("GITEA_TOKEN") {
+		return nil, fail(exitConfigInvalid, "%s совпадает с GITEA_TOKEN: для воркфлоу нужен отдельный токен", tokenEnv)
+	}
+	if token := os.Getenv("SLACK_BOT_TOKEN"); token != "" {
+		values["SLACK_BOT_TOKEN"] = token
+	}
+	return values, nil
+}
+
+func provisionRepoSecrets(ctx context.Context, client *gitea.Client, org, repo string, values map[string]string, rotate bool) error {
+	existing, err := client.ListSecrets(ctx, org, repo)
+	if err != nil {
+		return err
+	}
+	set := map[string]bool{}
+	for _, s := range existing {
+		set[s.Name] = true
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		status := secretStatus(set[name], rotate)
+		if set[name] && !rotate {
+			printInfo("%s: %s %s", repo, name, status)
+			continue
+		}
+		if err := client.PutSecret(ctx, org, repo, name, values[name]); err != nil {
+			return err
+		}
+		printOK("%s: %s %s", repo, name, status)
+	}
+	return nil
+}
+
+func secretStatus(exists, rotate bool) string {
+	switch {
+	case exists && !rotate:
+		return "уже задан (используйте --rotate для замены)"
+	case *dryRun && exists:
+		return "пробный запуск: будет заменён"
+	case *dryRun:
+		return "пробный запуск: будет создан"
+	case exists:
+		return "заменён"
+	default:
+		return "создан"
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/RastBast/docs12121/pkg/spec"
+)
+
+func checkVersion(args []string) error {
+	fs := newFlagSet("check-version")
+	docsDir := fs.String("docs", ".", "локальная копия репозитория документации")
+	repo := fs.String("repo", "", "сервис, с опубликованной версией которого сравнивать")
+	format := fs.String("format", "text", "формат вывода: text или json")
+	fs.Parse(args)
+
+	before, after, err := readSpecPair(fs, *docsDir, *repo)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return nil
+	}
+	vc, err := spec.CheckVersion(before, after)
+	if err != nil {
+		return fail(exitValidation, "info.version: %v", err)
+	}
+
+	switch *format {
+	case "text":
+		for _, c := range vc.Changes {
+			printInfo("[%s] %s", c.Category, c)
+		}
+	case "json":
+		if vc.Changes == nil {
+			vc.Changes = []spec.Change{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(vc); err != nil {
+			return fail(exitError, "Ошибка записи: %v", err)
+		}
+	default:
+		return fail(exitConfigInvalid, "Неизвестный формат %q. Доступные форматы: text, json", *format)
+	}
+
+	if !vc.OK {
+		if vc.Actual == spec.BumpDowngrade {
+			return fail(exitValidation, "Версия уменьшилась: %s → %s", vc.From, vc.To)
+		}
+		return fail(exitValidation, "Версия %s → %s (%s), а изменения требуют как минимум %s", vc.From, vc.To, vc.Actual, vc.Required)
+	}
+	if *format == "text" {
+		printOK("Версия %s → %s соответствует изменениям (требуется: %s)", vc.From, vc.To, vc.Required)
+	}
+	return nil
+}
@@ -0,0 +1,466 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/RastBast/docs12121/pkg/config"
+	"github.com/RastBast/docs12121/pkg/dispatch"
+	"github.com/RastBast/docs12121/pkg/git"
+	"github.com/RastBast/docs12121/pkg/gitea"
+	"github.com/RastBast/docs12121/pkg/prose"
+	"github.com/RastBast/docs12121/pkg/server"
+	"github.com/RastBast/docs12121/pkg/spec"
+	"github.com/RastBast/docs12121/pkg/subscribe"
+)
+
+func serveDocs(ctx context.Context, args []string) error {
+	fs := newFlagSet("serve")
+	addr := fs.String("addr", ":8080", "адрес HTTP-сервера")
+	allow := fs.String("allow", os.Getenv("SERVE_ALLOW"), "разрешённые IP/подсети через запятую")
+	issuer := fs.String("oidc-issuer", os.Getenv("SERVE_OIDC_ISSUER"), "OIDC-издатель для проверки Bearer-токенов")
+	audience := fs.String("oidc-audience", os.Getenv("SERVE_OIDC_AUDIENCE"), "ожидаемый aud в OIDC-токене, обязателен с --oidc-issuer")
+	proxy := fs.String("proxy", os.Getenv("SERVE_PROXY"), "окружения для «Try it» через прокси: имя=URL через запятую")
+	proxyOrigins := fs.String("proxy-origins", os.Getenv("SERVE_PROXY_ORIGINS"), "источники внешнего портала, которым прокси разрешает CORS, через запятую (свой источник разрешён всегда)")
+	proxyCredentials := fs.String("proxy-credentials", os.Getenv("SERVE_PROXY_CREDENTIALS"), "окружения прокси через запятую, которым передаются учётные данные API: заголовок "+server.ProxyAuthorizationHeader+" уходит как Authorization (Authorization и Cookie портала не передаются никогда)")
+	clientID := fs.String("oauth-client-id", os.Getenv("OAUTH_CLIENT_ID"), "OAuth client_id для кнопки «Authorize» в интерактивной документации")
+	subscriptions := fs.String("subscriptions", os.Getenv("SERVE_SUBSCRIPTIONS"), "YAML-файл с подписками команд на изменения API")
+	digestInterval := fs.Duration("digest-interval", time.Minute, "как часто проверять новые версии спецификаций для подписок")
+	sandbox := fs.String("sandbox", os.Getenv("SERVE_SANDBOX"), "YAML-файл с тестовыми ключами окружений для интерактивной документации")
+	reloadInterval := fs.Duration("reload-interval", 30*time.Second, "как часто проверять изменения конфигурации задач агрегации (--jobs и config арендаторов), файлов тестовых ключей и подписок (0 — только по SIGHUP)")
+	stateDir := fs.String("state-dir", os.Getenv("SERVE_STATE_DIR"), "каталог состояния фоновых задач, для нескольких реплик — общий (по умолчанию .openapi-aggregator)")
+	leaseFile := fs.String("leader-lease", os.Getenv("SERVE_LEADER_LEASE"), "файл аренды на общем хранилище: фоновые задачи выполняет только ведущая реплика")
+	leaseTTL := fs.Duration("lease-ttl", 30*time.Second, "срок аренды ведущей реплики")
+	replicaID := fs.String("replica-id", defaultReplicaID(), "имя реплики в файле аренды")
+	jobs := fs.Bool("jobs", false, "включить API задач агрегации (POST /jobs, GET /jobs/{id}, журнал в GET /jobs/{id}/events) и страницу /admin по конфигурации --config и GITEA_TOKEN")
+	jobsToken := fs.String("jobs-token", os.Getenv("SERVE_JOBS_TOKEN"), "токен для запуска задач через POST /jobs (заголовок "+server.JobTokenHeader+"), обязателен с --jobs")
+	push := fs.Bool("push", false, "отправлять коммиты задач агрегации в удалённый репозиторий документации")
+	glossary := fs.String("glossary", os.Getenv("SERVE_GLOSSARY"), "глоссарий для задач агрегации: замечания пишутся в журнал задачи, уровень error блокирует публикацию")
+	jobInterval := fs.Duration("job-interval", 5*time.Second, "как часто проверять очередь задач агрегации")
+	tenantsFile := fs.String("tenants", os.Getenv("SERVE_TENANTS"), "YAML-файл с арендаторами: каждый получает свой каталог, доступ и подписки под префиксом /<имя>/")
+	fs.Parse(args)
+	if *stateDir == "" {
+		*stateDir = ".openapi-aggregator"
+	}
+
+	var sites []*site
+	var handler http.Handler
+	if *tenantsFile != "" {
+		if fs.NArg() > 0 {
+			return fail(exitConfigInvalid, "С --tenants каталог документации задаётся для каждого арендатора в %s", *tenantsFile)
+		}
+		tenants, err := server.LoadTenants(*tenantsFile)
+		if err != nil {
+			return fail(exitConfigInvalid, "Ошибка в списке арендаторов: %v", err)
+		}
+		names := make([]string, 0, len(tenants))
+		for name := range tenants {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		handlers := map[string]http.Handler{}
+		for _, name := range names {
+			t := tenants[name]
+			st, err := newSite(name, t, filepath.Join(*stateDir, "tenants", name))
+			if err != nil {
+				return fail(exitConfigInvalid, "Арендатор %s: %v", name, err)
+			}
+			if t.Config != "" {
+				cfg, err := loadTenantConfig(t)
+				if err != nil {
+					return fail(exitConfigInvalid, "Арендатор %s: %v", name, err)
+				}
+				if t.Token == "" {
+					return fail(exitConfigInvalid, "Арендатор %s: для задач агрегации нужен token", name)
+				}
+				if t.JobsToken == "" {
+					return fail(exitConfigInvalid, "Арендатор %s: для задач агрегации нужен jobs_token", name)
+				}
+				if err := st.enableJobs(cfg, t.Token, func() (config.Config, error) { return loadTenantConfig(t) }); err != nil {
+					return fail(exitConfigInvalid, "Арендатор %s: %v", name, err)
+				}
+			}
+			sites = append(sites, st)
+			handlers[name] = st.handler
+		}
+		handler = server.TenantsHandler(handlers)
+	} else {
+		if *issuer != "" && *audience == "" {
+			return fail(exitConfigInvalid, "С --oidc-issuer нужен --oidc-audience (SERVE_OIDC_AUDIENCE): иначе принимается токен издателя, выданный любому клиенту")
+		}
+		tenant := server.Tenant{
+			Dir:              ".",
+			BasicAuth:        os.Getenv("SERVE_BASIC_AUTH"),
+			Allow:            *allow,
+			OIDCIssuer:       *issuer,
+			OIDCAudience:     *audience,
+			OAuthClientID:    *clientID,
+			Proxy:            *proxy,
+			ProxyOrigins:     *proxyOrigins,
+			ProxyCredentials: *proxyCredentials,
+			Sandbox:          *sandbox,
+			Subscriptions:    *subscriptions,
+			JobsToken:        *jobsToken,
+			Push:             *push,
+			Glossary:         *glossary,
+		}
+		if fs.NArg() > 0 {
+			tenant.Dir = fs.Arg(0)
+		}
+		st, err := newSite("", tenant, *stateDir)
+		if err != nil {
+			return fail(exitConfigInvalid, "Некорректные настройки сервера: %v", err)
+		}
+		if *jobs {
+			cfg, err := loadServeConfig()
+			if err != nil {
+				return err
+			}
+			spec.EnvironmentDirs = cfg.EnvironmentDirs()
+			token := os.Getenv("GITEA_TOKEN")
+			if token == "" {
+				return fail(exitConfigInvalid, "Для --jobs нужен GITEA_TOKEN")
+			}
+			if *jobsToken == "" {
+				return fail(exitConfigInvalid, "Для --jobs нужен токен запуска задач: --jobs-token или SERVE_JOBS_TOKEN")
+			}
+			if err := st.enableJobs(cfg, token, loadServeConfig); err != nil {
+				return fail(exitConfigInvalid, "Ошибка чтения глоссария: %v", err)
+			}
+		}
+		sites = append(sites, st)
+		handler = st.handler
+	}
+
+	background := func(ctx context.Context) {
+		for _, st := range sites {
+			st.runBackground(ctx, *digestInterval, *jobInterval)
+		}
+	}
+	if *leaseFile != "" {
+		if *leaseTTL < time.Second {
+			return fail(exitConfigInvalid, "--lease-ttl должен быть не меньше 1s")
+		}
+		lease := &server.Lease{Path: *leaseFile, Holder: *replicaID, TTL: *leaseTTL}
+		go lease.Run(ctx, printInfo, background)
+	} else {
+		background(ctx)
+	}
+	for _, st := range sites {
+		st.watch(ctx, *reloadInterval)
+	}
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hup:
+				for _, st := range sites {
+					st.reload()
+					if st.config != nil {
+						st.config.reload(false)
+					}
+				}
+			}
+		}
+	}()
+
+	if *timeout > 0 {
+		handler = server.TimeoutHandler(handler, *timeout, "превышено время обработки запроса")
+	}
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdown)
+	}()
+
+	for _, st := range sites {
+		if st.name == "" {
+			printStart("Реестр спецификаций из %s доступен на %s", st.server.Dir, *addr)
+		} else {
+			printStart("Реестр спецификаций %s из %s доступен на %s%s/", st.name, st.server.Dir, *addr, st.server.BasePath)
+		}
+		for name, target := range st.server.Proxy {
+			if slices.Contains(st.server.ProxyCredentials, name) {
+				printInfo("Прокси для «Try it»: %s/proxy/%s/ → %s (с учётными данными)", st.server.BasePath, name, target)
+			} else {
+				printInfo("Прокси для «Try it»: %s/proxy/%s/ → %s", st.server.BasePath, name, target)
+			}
+		}
+	}
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return fail(exitError, "Ошибка HTTP-сервера: %v", err)
+	}
+	return nil
+}
+
+type site struct {
+	name     string
+	tenant   server.Tenant
+	stateDir string
+	server   *server.Server
+	handler  *server.Reloadable
+	config   *configReloader
+}
+
+func newSite(name string, t server.Tenant, stateDir string) (*site, error) {
+	access, err := t.Access()
+	if err != nil {
+		return nil, err
+	}
+	targets, err := server.ParseProxyTargets(t.Proxy)
+	if err != nil {
+		return nil, err
+	}
+	origins, err := server.ParseProxyOrigins(t.ProxyOrigins)
+	if err != nil {
+		return nil, err
+	}
+	credentials := config.SplitList(t.ProxyCredentials)
+	for _, name := range credentials {
+		if _, ok := targets[name]; !ok {
+			return nil, fmt.Errorf("окружение %q для передачи учётных данных не описано в proxy", name)
+		}
+	}
+	s := &server.Server{Dir: t.Dir, Access: access, Proxy: targets, ProxyOrigins: origins, ProxyCredentials: credentials, OAuthClientID: t.OAuthClientID}
+	if name != "" {
+		s.BasePath = "/" + name
+	}
+	if t.Sandbox != "" {
+		if s.Sandbox, err = loadSandbox(t.Sandbox, access); err != nil {
+			return nil, err
+		}
+	}
+	if t.Subscriptions != "" {
+		if s.Subscriptions, err = subscribe.Load(t.Subscriptions); err != nil {
+			return nil, err
+		}
+	}
+	st := &site{name: name, tenant: t, stateDir: stateDir, server: s}
+	s.Logf = func(format string, args ...any) {
+		printFail(st.logPrefix()+format, args...)
+	}
+	st.handler = server.NewReloadable(s.Handler())
+	return st, nil
+}
+
+func (st *site) logPrefix() string {
+	if st.name == "" {
+		return ""
+	}
+	return st.name + ": "
+}
+
+func (st *site) runBackground(ctx context.Context, digestInterval, jobInterval time.Duration) {
+	if st.server.Jobs != nil {
+		go st.server.Jobs.Work(ctx, jobInterval)
+	}
+	if st.server.Subscriptions != nil {
+		notifier := &subscribe.Notifier{
+			Dir:       st.server.Dir,
+			Store:     st.server.Subscriptions,
+			StatePath: filepath.Join(st.stateDir, "digests.json"),
+			Logf: func(format string, args ...any) {
+				printInfo(st.logPrefix()+format, args...)
+			},
+		}
+		go notifier.Run(ctx, digestInterval)
+	}
+}
+
+func (st *site) watch(ctx context.Context, reloadInterval time.Duration) {
+	var watched []string
+	for _, path := range []string{st.tenant.Sandbox, st.tenant.Subscriptions} {
+		if path != "" {
+			watched = append(watched, path)
+		}
+	}
+	if reloadInterval > 0 && len(watched) > 0 {
+		go server.WatchFiles(ctx, reloadInterval, watched, st.reload)
+	}
+	if st.config != nil {
+		go st.config.watch(ctx, reloadInterval)
+	}
+}
+
+// enableJobs turns on the job API with cfg; load re-reads the config on
+// reload, so repositories, branch allow-lists and dispatch targets change
+// without a restart.
+func (st *site) enableJobs(cfg config.Config, token string, load func() (config.Config, error)) error {
+	var glossary *prose.Glossary
+	if st.tenant.Glossary != "" {
+		var err error
+		if glossary, err = prose.LoadGlossary(st.tenant.Glossary); err != nil {
+			return err
+		}
+	}
+	logf := func(format string, args ...any) {
+		printInfo(st.logPrefix()+format, args...)
+	}
+	st.server.Jobs = &server.Jobs{
+		JobSettings: jobSettings(cfg, token, st.server.Dir, st.stateDir, st.tenant.Push, glossary, logf),
+		Dir:         filepath.Join(st.stateDir, "jobs"),
+		Token:       st.tenant.JobsToken,
+		Logf:        logf,
+	}
+	st.config = &configReloader{
+		load: load,
+		apply: func(cfg config.Config) {
+			st.server.Jobs.Reconfigure(jobSettings(cfg, token, st.server.Dir, st.stateDir, st.tenant.Push, glossary, logf))
+		},
+		prefix:  st.logPrefix(),
+		current: cfg,
+	}
+	st.handler.Swap(st.server.Handler())
+	return nil
+}
+
+// loadTenantConfig reads the tenant's config files; organization and
+// docs_repo from the tenants file override them, so several tenants can
+// share one config.
+func loadTenantConfig(t server.Tenant) (config.Config, error) {
+	cfg, err := config.ReadSources(readConfigSource, config.SplitList(t.Config)...)
+	if err != nil {
+		return config.Config{}, err
+	}
+	if cfg.SettingsDir != "" {
+		if err := cfg.ApplySettings(cfg.SettingsDir); err != nil {
+			return config.Config{}, err
+		}
+	}
+	if t.Organization != "" {
+		cfg.Organization = t.Organization
+	}
+	if t.DocsRepo != "" {
+		cfg.DocsRepo = t.DocsRepo
+	}
+	return cfg, cfg.Validate()
+}
+
+// loadServeConfig reads the config for serve --jobs the same way as at
+// startup, validation included.
+func loadServeConfig() (config.Config, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return cfg, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return cfg, fail(exitConfigInvalid, "Некорректная конфигурация: %v", err)
+	}
+	return cfg, nil
+}
+
+func aggregateJob(cfg config.Config, token, dir string, push bool, glossary *prose.Glossary, d *dispatch.Dispatcher) server.JobFunc {
+	client := gitea.NewClient(cfg.GiteaHost, token)
+	return func(ctx context.Context, job *server.Job, logf func(format string, args ...any)) error {
+		ctx, cancel := withTimeout(ctx)
+		defer cancel()
+		rc := cfg.Repo(job.Repo)
+		for _, api := range rc.Specs() {
+			logf("Загрузка %s из %s/%s", api.SpecPath, cfg.Organization, job.Repo)
+		}
+		branch := job.Branch
+		if branch == "" {
+			branch = job.Ref
+		}
+		prefix, err := docsPrefix(ctx, client, cfg, dir, job.Repo, branch)
+		if err != nil {
+			return err
+		}
+		changed, err := aggregateRepo(ctx, apiFetcher(client, cfg, job.Ref), dir, prefix, cfg, rc, glossary, logf)
+		if err != nil {
+			return err
+		}
+		if len(changed) == 0 {
+			logf("Спецификации не изменились")
+			return nil
+		}
+		job.Updated = changed
+		logf("Обновлено: %s", strings.Join(changed, ", "))
+		if *dryRun {
+			logf("Пробный запуск: изменения не закоммичены и не отправлены")
+			return nil
+		}
+		if err := git.Run(ctx, dir, append([]string{"add", "--", spec.ManifestFile}, changed...)...); err != nil {
+			return err
+		}
+		author := git.Author{Name: "OpenAPI Aggregator Bot", Email: "openapi-bot@" + cfg.GiteaHost}
+		if err := git.Commit(ctx, dir, author, cfg.Tracking.CommitMessage(fmt.Sprintf("Aggregate OpenAPI docs for %s", job.Repo))); err != nil {
+			return err
+		}
+		if push {
+			if err := git.PushRebased(ctx, dir, author); err != nil {
+				return err
+			}
+			logf("Изменения закоммичены и отправлены в удалённый репозиторий")
+			if err := publishEvent(ctx, d, dir, job.Repo, branch, changed); err != nil {
+				logf("Не все события о публикации доставлены: %v", err)
+			}
+		} else {
+			logf("Изменения закоммичены только локально в %s, в репозиторий документации не отправлены", dir)
+		}
+		if err := pushMetrics(ctx, cfg, dir, job.Repo, branch, changed); err != nil {
+			logf("Метрики не отправлены: %v", err)
+		}
+		return nil
+	}
+}
+
+func (st *site) reload() {
+	next := *st.server
+	if st.tenant.Sandbox != "" {
+		var err error
+		if next.Sandbox, err = loadSandbox(st.tenant.Sandbox, next.Access); err != nil {
+			printFail("%sНастройки не перечитаны, остаются прежние: ошибка в настройках тестовых ключей: %v", st.logPrefix(), err)
+			return
+		}
+	}
+	if next.Subscriptions != nil {
+		if err := next.Subscriptions.Reload(); err != nil {
+			printFail("%sНастройки не перечитаны, остаются прежние: ошибка в подписках: %v", st.logPrefix(), err)
+			return
+		}
+	}
+	st.handler.Swap(next.Handler())
+	printInfo("%sНастройки перечитаны", st.logPrefix())
+}
+
+func loadSandbox(path string, access server.AccessConfig) (server.Sandbox, error) {
+	sandbox, err := server.LoadSandbox(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(access.BasicAuth) == 0 && access.OIDCIssuer == "" {
+		for name, env := range sandbox {
+			if len(env.Audiences) > 0 {
+				printInfo("Окружение %s ограничено аудиториями, но вход на сервер не настроен: его ключи не будут выданы никому", name)
+			}
+		}
+	}
+	return sandbox, nil
+}
+
+func defaultReplicaID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "replica"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
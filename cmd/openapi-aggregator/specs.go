@@ -0,0 +1,281 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/RastBast/docs12121/pkg/config"
+	"github.com/RastBast/docs12121/pkg/git"
+	"github.com/RastBast/docs12121/pkg/gitea"
+	"github.com/RastBast/docs12121/pkg/prose"
+	"github.com/RastBast/docs12121/pkg/spec"
+)
+
+func runSpecCommand(ctx context.Context, cfg config.Config, args []string) error {
+	if len(args) == 0 {
+		return fail(exitConfigInvalid, "Использование: spec preview <файл> --repo сервис [--api имя] [--branch ветка] [--docs каталог] [--output каталог] | spec convert [--output файл] <файл> | spec scaffold [--from-routes файл] [--title название] [--output файл]")
+	}
+	switch args[0] {
+	case "preview":
+		return previewSpec(ctx, cfg, args[1:])
+	case "convert":
+		return convertSpec(args[1:])
+	case "scaffold":
+		return scaffoldSpec(args[1:])
+	default:
+		return fail(exitConfigInvalid, "Неизвестная подкоманда %q. Доступные подкоманды: preview, convert, scaffold", args[0])
+	}
+}
+
+func previewSpec(ctx context.Context, cfg config.Config, args []string) error {
+	fs := newFlagSet("spec preview")
+	repo := fs.String("repo", "", "сервис, под которым спецификация будет опубликована")
+	apiName := fs.String("api", "", "API сервиса с несколькими спецификациями (по умолчанию то, чей spec_path совпадает с файлом)")
+	branch := fs.String("branch", "", "ветка сервиса, от которой зависит окружение публикации (по умолчанию текущая ветка git)")
+	docsDir := fs.String("docs", ".", "локальная копия репозитория документации для сравнения с опубликованной версией")
+	output := fs.String("output", "preview", "каталог для предпросмотра")
+	rulesPath := fs.String("rules", ".prose.yaml", "файл правил проверки текстов")
+	glossaryPath := fs.String("glossary", "glossary.yaml", "глоссарий предпочтительных терминов")
+
+	var file string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		file, args = args[0], args[1:]
+	}
+	fs.Parse(args)
+	if file == "" && fs.NArg() > 0 {
+		file = fs.Arg(0)
+	}
+	if file == "" {
+		return fail(exitConfigInvalid, "Укажите файл спецификации")
+	}
+	if *repo == "" {
+		return fail(exitConfigInvalid, "Не указан --repo")
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fail(exitError, "Ошибка чтения спецификации: %v", err)
+	}
+	doc, err := spec.ParseDocument(data)
+	if err != nil {
+		return fail(exitValidation, "%v", err)
+	}
+
+	rc := cfg.Repo(*repo)
+	api, err := previewAPI(rc, *apiName, file)
+	if err != nil {
+		return err
+	}
+	if *branch == "" {
+		if current, err := git.Output(ctx, ".", "rev-parse", "--abbrev-ref", "HEAD"); err == nil {
+			*branch = strings.TrimSpace(current)
+		}
+	}
+	prefix, err := docsPrefix(ctx, gitea.NewClient(cfg.GiteaHost, os.Getenv("GITEA_TOKEN")), cfg, *docsDir, *repo, *branch)
+	if err != nil {
+		return fail(exitConfigInvalid, "Не удалось определить, куда публикуется %s: %v", *repo, err)
+	}
+	name := rc.DocsName(api)
+	var published *spec.Document
+	if current, err := os.ReadFile(filepath.Join(*docsDir, filepath.FromSlash(publishedPath(prefix, rc, api)))); err == nil {
+		if published, err = spec.ParseDocument(current); err != nil {
+			return fail(exitValidation, "Опубликованная спецификация %s: %v", name, err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fail(exitError, "Ошибка чтения опубликованной спецификации: %v", err)
+	}
+
+	p, err := spec.NewPreview(name, doc, published, data)
+	if err != nil {
+		return fail(exitValidation, "%v", err)
+	}
+	rules, glossary, err := loadProseRules(*rulesPath, *glossaryPath)
+	if err != nil {
+		return err
+	}
+	texts := prose.Texts(doc)
+	findings := prose.Check(name, texts, rules)
+	if glossary != nil {
+		findings = append(findings, glossary.Check(name, texts)...)
+	}
+	for _, f := range findings {
+		msg := f.Location + ": " + f.Message + " [" + f.Rule + "]"
+		if f.Level == prose.LevelError {
+			p.Problems = append(p.Problems, msg)
+		} else {
+			p.Warnings = append(p.Warnings, msg)
+		}
+	}
+
+	annotated, _, err := spec.AnnotateSLO(data)
+	if err != nil {
+		return fail(exitValidation, "%v", err)
+	}
+	if annotated, _, err = spec.AddCodeSamples(annotated, spec.SampleLanguages); err != nil {
+		return fail(exitValidation, "%v", err)
+	}
+	if err := writeFile(filepath.Join(*output, "openapi.yaml"), annotated, 0o644); err != nil {
+		return fail(exitError, "Ошибка записи спецификации: %v", err)
+	}
+	page, err := createFile(filepath.Join(*output, "index.html"))
+	if err != nil {
+		return fail(exitError, "Ошибка создания предпросмотра: %v", err)
+	}
+	err = p.WriteHTML(page)
+	page.Close()
+	if err != nil {
+		return fail(exitError, "Ошибка записи предпросмотра: %v", err)
+	}
+
+	for _, problem := range p.Problems {
+		printFail("%s", problem)
+	}
+	for _, w := range p.Warnings {
+		printInfo("%s", w)
+	}
+	for _, c := range p.Changes {
+		if c.Category == spec.ChangeBreaking {
+			printFail("[%s] %s", c.Category, c)
+		} else {
+			printInfo("[%s] %s", c.Category, c)
+		}
+	}
+	printInfo("Предпросмотр: %s (ошибок: %d, замечаний: %d, изменений: %d)",
+		filepath.Join(*output, "index.html"), len(p.Problems), len(p.Warnings), len(p.Changes))
+
+	switch {
+	case len(p.Problems) > 0:
+		return fail(exitValidation, "Спецификация не пройдёт публикацию: ошибок %d", len(p.Problems))
+	case p.Breaking() > 0:
+		return fail(exitBreaking, "Ломающих изменений относительно %s: %d", p.Published, p.Breaking())
+	}
+	printOK("%s %s готова к публикации", name, p.Version)
+	return nil
+}
+
+// previewAPI picks the API of rc the previewed file belongs to: the one named
+// by --api, otherwise the one whose spec_path is the file.
+func previewAPI(rc config.RepoConfig, name, file string) (config.APIConfig, error) {
+	apis := rc.Specs()
+	if len(rc.APIs) == 0 {
+		return apis[0], nil
+	}
+	names := make([]string, len(apis))
+	for i, api := range apis {
+		names[i] = api.Name
+		if name != "" && api.Name == name {
+			return api, nil
+		}
+		if name == "" && filepath.Clean(api.SpecPath) == filepath.Clean(file) {
+			return api, nil
+		}
+	}
+	if name != "" {
+		return config.APIConfig{}, fail(exitConfigInvalid, "У %s нет API %q (доступны: %s)", rc.Name, name, strings.Join(names, ", "))
+	}
+	return config.APIConfig{}, fail(exitConfigInvalid, "У %s несколько API, укажите --api (доступны: %s)", rc.Name, strings.Join(names, ", "))
+}
+
+func convertSpec(args []string) error {
+	fs := newFlagSet("spec convert")
+	output := fs.String("output", "-", "куда записать спецификацию OpenAPI 3 (- для stdout, .json — в формате JSON)")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		return fail(exitConfigInvalid, "Использование: spec convert [--output файл] <файл>")
+	}
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return fail(exitError, "Ошибка чтения спецификации: %v", err)
+	}
+	if !spec.IsSwagger2(data) {
+		return fail(exitValidation, "%s не является спецификацией Swagger 2.0", fs.Arg(0))
+	}
+	converted, err := spec.ConvertSwagger2(data)
+	if err != nil {
+		return fail(exitValidation, "Ошибка преобразования %s: %v", fs.Arg(0), err)
+	}
+	if strings.EqualFold(filepath.Ext(*output), ".json") {
+		if converted, err = spec.ToJSON(converted); err != nil {
+			return fail(exitError, "Ошибка преобразования в JSON: %v", err)
+		}
+	}
+	if *output == "-" {
+		os.Stdout.Write(converted)
+		return nil
+	}
+	if err := writeFile(*output, converted, 0o644); err != nil {
+		return fail(exitError, "Ошибка записи %s: %v", *output, err)
+	}
+	printOK("%s преобразован в OpenAPI 3.0: %s", fs.Arg(0), *output)
+	return nil
+}
+
+func scaffoldSpec(args []string) error {
+	fs := newFlagSet("spec scaffold")
+	fromRoutes := fs.String("from-routes", "", "список маршрутов: JSON/YAML (массив маршрутов, экспорт Kong) или текст со строками METHOD /path (вывод роутера, журнал доступа); - для stdin")
+	title := fs.String("title", "", "info.title заготовки (по умолчанию имя текущего каталога)")
+	output := fs.String("output", "-", "куда записать заготовку (- для stdout, .json — в формате JSON)")
+	fs.Parse(args)
+
+	if *title == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return fail(exitError, "Ошибка определения текущего каталога: %v", err)
+		}
+		*title = filepath.Base(wd)
+	}
+	var routes []spec.Route
+	if *fromRoutes != "" {
+		var data []byte
+		var err error
+		if *fromRoutes == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(*fromRoutes)
+		}
+		if err != nil {
+			return fail(exitError, "Ошибка чтения маршрутов: %v", err)
+		}
+		if routes, err = spec.ParseRoutes(data); err != nil {
+			return fail(exitValidation, "%s: %v", *fromRoutes, err)
+		}
+	}
+	data, err := spec.ScaffoldRoutes(*title, routes)
+	if err != nil {
+		return fail(exitError, "Ошибка генерации заготовки: %v", err)
+	}
+	if strings.EqualFold(filepath.Ext(*output), ".json") {
+		if data, err = spec.ToJSON(data); err != nil {
+			return fail(exitError, "Ошибка преобразования в JSON: %v", err)
+		}
+	}
+	if *output == "-" {
+		os.Stdout.Write(data)
+		return nil
+	}
+	if _, err := os.Stat(*output); err == nil {
+		return fail(exitConfigInvalid, "%s уже существует, заготовка не записана", *output)
+	}
+	if err := writeFile(*output, data, 0o644); err != nil {
+		return fail(exitError, "Ошибка записи %s: %v", *output, err)
+	}
+	printOK("Заготовка спецификации записана в %s (маршрутов: %d)", *output, len(routes))
+	return nil
+}
+
+// readDocuments reads the specifications in dir, warning about the ones
+// that fail to parse and continuing with the rest.
+func readDocuments(dir string) (map[string]*spec.Document, error) {
+	docs, err := spec.LoadDocuments(dir)
+	var bad spec.DocumentErrors
+	if errors.As(err, &bad) {
+		for _, err := range bad {
+			printFail("Спецификация пропущена: %v", err)
+		}
+		return docs, nil
+	}
+	return docs, err
+}
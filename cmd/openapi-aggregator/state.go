@@ -0,0 +1,389 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/RastBast/docs12121/pkg/config"
+	"github.com/RastBast/docs12121/pkg/spec"
+)
+
+const stateInfoFile = "state.json"
+
+type stateInfo struct {
+	Created time.Time `json:"created"`
+	Host    string    `json:"host,omitempty"`
+	Files   int       `json:"files"`
+}
+
+type stateEntry struct {
+	name string
+	path string
+}
+
+func runStateCommand(cfg config.Config, args []string) error {
+	if len(args) == 0 {
+		return fail(exitConfigInvalid, "Использование: state export [--output архив] [каталог] | state import [--force] <архив> [каталог]")
+	}
+	switch args[0] {
+	case "export":
+		return exportState(cfg, args[1:])
+	case "import":
+		return importState(cfg, args[1:])
+	default:
+		return fail(exitConfigInvalid, "Неизвестная подкоманда %q. Доступные подкоманды: export, import", args[0])
+	}
+}
+
+func stateDirFlag(fs *flag.FlagSet) *string {
+	return fs.String("state-dir", os.Getenv("SERVE_STATE_DIR"), "каталог состояния serve (по умолчанию .openapi-aggregator)")
+}
+
+func exportState(cfg config.Config, args []string) error {
+	fs := newFlagSet("state export")
+	stateDir := stateDirFlag(fs)
+	output := fs.String("output", "", "путь к архиву (по умолчанию openapi-aggregator-state-<дата>.tar.gz)")
+	fs.Parse(args)
+
+	dir := "."
+	if fs.NArg() > 0 {
+		dir = fs.Arg(0)
+	}
+	if *stateDir == "" {
+		*stateDir = ".openapi-aggregator"
+	}
+	if *output == "" {
+		*output = "openapi-aggregator-state-" + time.Now().Format("20060102-150405") + ".tar.gz"
+	}
+
+	entries, err := stateEntries(cfg, *stateDir, dir)
+	if err != nil {
+		return fail(exitError, "Ошибка сбора состояния: %v", err)
+	}
+	if len(entries) == 0 {
+		return fail(exitError, "Нечего экспортировать: нет ни состояния в %s, ни манифеста, ни файлов конфигурации", *stateDir)
+	}
+
+	out, err := createFile(*output)
+	if err != nil {
+		return fail(exitError, "Ошибка создания архива: %v", err)
+	}
+	defer out.Close()
+	host, _ := os.Hostname()
+	if err := writeStateArchive(out, stateInfo{Created: time.Now().UTC(), Host: host, Files: len(entries)}, entries); err != nil {
+		return fail(exitError, "Ошибка записи архива: %v", err)
+	}
+	printOK("Состояние экспортировано: %s (файлов: %d)", *output, len(entries))
+	return nil
+}
+
+func stateEntries(cfg config.Config, stateDir, dir string) ([]stateEntry, error) {
+	var entries []stateEntry
+	err := filepath.WalkDir(stateDir, func(p string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) && p == stateDir {
+			return fs.SkipDir
+		}
+		if err != nil || d.IsDir() || strings.HasSuffix(p, ".tmp") {
+			return err
+		}
+		rel, err := filepath.Rel(stateDir, p)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, stateEntry{name: "state/" + filepath.ToSlash(rel), path: p})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := filepath.Join(dir, spec.ManifestFile)
+	if _, err := os.Stat(manifest); err == nil {
+		entries = append(entries, stateEntry{name: "docs/" + spec.ManifestFile, path: manifest})
+	}
+
+	files, err := configPaths()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.SettingsDir != "" {
+		err := filepath.WalkDir(cfg.SettingsDir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			files = append(files, p)
+			return nil
+		})
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	for _, f := range files {
+		if strings.HasPrefix(f, config.RemoteScheme) {
+			continue
+		}
+		if _, err := os.Stat(f); err != nil {
+			continue
+		}
+		entries = append(entries, stateEntry{name: "config/" + configEntryName(f), path: f})
+	}
+	return entries, nil
+}
+
+// configEntryName is the name of a config file in the archive, below config/.
+func configEntryName(f string) string {
+	if r, err := filepath.Rel(".", f); err == nil && filepath.IsLocal(r) {
+		return filepath.ToSlash(r)
+	}
+	return filepath.Base(f)
+}
+
+// stateImport decides where archive entries are restored. Config entries
+// are written relative to the working directory, so only the files that
+// export would take are accepted: the --config files (aggregator.yaml by
+// default) and the files of a settings_dir inside the working directory,
+// whether it is set in the current config or in a config file restored from
+// the archive.
+type stateImport struct {
+	roots        map[string]string
+	configFiles  map[string]bool
+	settingsDirs []string
+}
+
+func newStateImport(cfg config.Config, stateDir, dir string) (*stateImport, error) {
+	imp := &stateImport{
+		roots:       map[string]string{"state": stateDir, "docs": dir, "config": "."},
+		configFiles: map[string]bool{config.DefaultFile: true},
+	}
+	files, err := configPaths()
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		if !strings.HasPrefix(f, config.RemoteScheme) {
+			imp.configFiles[configEntryName(f)] = true
+		}
+	}
+	imp.addSettingsDir(cfg.SettingsDir)
+	return imp, nil
+}
+
+func (imp *stateImport) addSettingsDir(dir string) {
+	if dir == "" {
+		return
+	}
+	if r, err := filepath.Rel(".", dir); err == nil && filepath.IsLocal(r) {
+		imp.settingsDirs = append(imp.settingsDirs, filepath.ToSlash(r))
+	}
+}
+
+// restoredConfig notes the settings_dir of a config file from the archive.
+// export writes config files before the settings files, so they are known
+// by the time the settings entries come.
+func (imp *stateImport) restoredConfig(name string, data []byte) error {
+	cfg, err := config.ReadSources(func(string) ([]byte, error) { return data, nil }, name)
+	if err != nil {
+		return err
+	}
+	imp.addSettingsDir(cfg.SettingsDir)
+	return nil
+}
+func (imp *stateImport) target(name string) (string, error) {
+	prefix, rel, ok := strings.Cut(name, "/")
+	root, known := imp.roots[prefix]
+	if !ok || !known || !filepath.IsLocal(filepath.FromSlash(rel)) || path.Clean(rel) != rel {
+		return "", fmt.Errorf("недопустимый путь %q", name)
+	}
+	for _, part := range strings.Split(rel, "/") {
+		if strings.EqualFold(part, ".git") {
+			return "", fmt.Errorf("недопустимый путь %q: запись в .git", name)
+		}
+	}
+	switch prefix {
+	case "docs":
+		if rel != spec.ManifestFile {
+			return "", fmt.Errorf("%q: из каталога документации восстанавливается только %s", name, spec.ManifestFile)
+		}
+	case "config":
+		inSettings := slices.ContainsFunc(imp.settingsDirs, func(dir string) bool {
+			return strings.HasPrefix(rel, dir+"/")
+		})
+		if !imp.configFiles[rel] && !inSettings {
+			return "", fmt.Errorf("%q не входит в файлы конфигурации (--config, settings_dir)", name)
+		}
+	}
+	return filepath.Join(root, filepath.FromSlash(rel)), nil
+}
+
+func writeStateArchive(w io.Writer, info stateInfo, entries []stateEntry) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: stateInfoFile, Mode: 0o644, Size: int64(len(data)), ModTime: info.Created}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(data); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		st, err := os.Stat(e.path)
+		if err != nil {
+			return err
+		}
+		hdr, err := tar.FileInfoHeader(st, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = e.name
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		f, err := os.Open(e.path)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(tw, f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+func importState(cfg config.Config, args []string) error {
+	fs := newFlagSet("state import")
+	stateDir := stateDirFlag(fs)
+	force := fs.Bool("force", false, "перезаписывать существующие файлы")
+
+	var archive string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		archive, args = args[0], args[1:]
+	}
+	fs.Parse(args)
+	rest := fs.Args()
+	if archive == "" && len(rest) > 0 {
+		archive, rest = rest[0], rest[1:]
+	}
+	if archive == "" {
+		return fail(exitConfigInvalid, "Использование: state import [--force] <архив> [каталог]")
+	}
+	dir := "."
+	if len(rest) > 0 {
+		dir = rest[0]
+	}
+	if *stateDir == "" {
+		*stateDir = ".openapi-aggregator"
+	}
+	imp, err := newStateImport(cfg, *stateDir, dir)
+	if err != nil {
+		return err
+	}
+
+	var info stateInfo
+	targets := map[string]string{}
+	err = readStateArchive(archive, func(name string, r io.Reader) error {
+		if name == stateInfoFile {
+			return json.NewDecoder(r).Decode(&info)
+		}
+		target, err := imp.target(name)
+		if err != nil {
+			return err
+		}
+		targets[name] = target
+		if rel, ok := strings.CutPrefix(name, "config/"); ok && imp.configFiles[rel] {
+			data, err := io.ReadAll(r)
+			if err != nil {
+				return err
+			}
+			return imp.restoredConfig(name, data)
+		}
+		return nil
+	})
+	if err != nil {
+		return fail(exitValidation, "Некорректный архив %s: %v", archive, err)
+	}
+	if !*force {
+		var existing []string
+		for _, target := range targets {
+			if _, err := os.Stat(target); err == nil {
+				existing = append(existing, target)
+			}
+		}
+		if len(existing) > 0 {
+			sort.Strings(existing)
+			return fail(exitError, "Файлы уже существуют (используйте --force для перезаписи): %s", strings.Join(existing, ", "))
+		}
+	}
+
+	err = readStateArchive(archive, func(name string, r io.Reader) error {
+		target, ok := targets[name]
+		if !ok {
+			return nil
+		}
+		f, err := createFile(target)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(f, r)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		return err
+	})
+	if err != nil {
+		return fail(exitError, "Ошибка восстановления: %v", err)
+	}
+	if !info.Created.IsZero() {
+		printInfo("Архив создан %s на %s", info.Created.Local().Format("2006-01-02 15:04"), info.Host)
+	}
+	printOK("Состояние восстановлено (файлов: %d)", len(targets))
+	return nil
+}
+
+func readStateArchive(archive string, visit func(name string, r io.Reader) error) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			return fmt.Errorf("недопустимая запись %q", hdr.Name)
+		}
+		if err := visit(hdr.Name, tr); err != nil {
+			return err
+		}
+	}
+}
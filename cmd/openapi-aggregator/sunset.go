@@ -0,0 +1,40 @@
+package main
+
+import (
+	"time"
+
+	"github.com/RastBast/docs12121/pkg/spec"
+)
+
+func exportSunsetCalendar(args []string) error {
+	fs := newFlagSet("sunset-calendar")
+	output := fs.String("output", "sunset.ics", "путь к файлу календаря")
+	all := fs.Bool("all", false, "включить уже прошедшие даты")
+	fs.Parse(args)
+
+	dir := "."
+	if fs.NArg() > 0 {
+		dir = fs.Arg(0)
+	}
+
+	sunsets, err := spec.Sunsets(dir)
+	if err != nil {
+		return fail(exitValidation, "Ошибка чтения спецификаций: %v", err)
+	}
+	now := time.Now()
+	if !*all {
+		sunsets = spec.Upcoming(sunsets, now)
+	}
+
+	out, err := createFile(*output)
+	if err != nil {
+		return fail(exitError, "Ошибка создания календаря: %v", err)
+	}
+	defer out.Close()
+	if err := spec.WriteICS(out, sunsets, now); err != nil {
+		return fail(exitError, "Ошибка записи календаря: %v", err)
+	}
+
+	printOK("Календарь вывода из эксплуатации: %s (событий: %d)", *output, len(sunsets))
+	return nil
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/RastBast/docs12121/pkg/config"
+	"github.com/RastBast/docs12121/pkg/generator"
+	"github.com/RastBast/docs12121/pkg/gitea"
+	"github.com/RastBast/docs12121/pkg/report"
+	"github.com/RastBast/docs12121/pkg/textdiff"
+)
+
+func syncWorkflows(ctx context.Context, cfg config.Config, args []string) error {
+	return syncCommand(ctx, cfg, "sync", args)
+}
+
+func syncCommand(ctx context.Context, cfg config.Config, name string, args []string) error {
+	fs := newFlagSet(name)
+	check := fs.Bool("check", false, "только найти расхождения с шаблоном, не открывая pull request (код выхода 3, если они есть)")
+	install := fs.Bool("install", false, "открыть pull request с воркфлоу и там, где он ещё не установлен")
+	platform := platformFlag(fs)
+	batchOpts := addBatchFlags(fs, name)
+	fs.Parse(args)
+	if *platform != "" {
+		cfg.Platform = *platform
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return fail(exitConfigInvalid, "Некорректная конфигурация: %v", err)
+	}
+	token := os.Getenv("GITEA_TOKEN")
+	if token == "" {
+		return fail(exitConfigInvalid, "Не задан GITEA_TOKEN")
+	}
+	workflows, err := repoWorkflows(cfg)
+	if err != nil {
+		return err
+	}
+	path := generator.WorkflowPath(cfg.Platform)
+
+	client := gitea.NewClient(cfg.GiteaHost, token)
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var sum report.Summary
+	var mu sync.Mutex
+	var drifted []string
+	runner, err := batchOpts.runner()
+	if err != nil {
+		return err
+	}
+	err = runner.Run(ctx, cfg.Repositories, &sum, func(ctx context.Context, repo string) error {
+		content := workflows[repo]
+		w, err := fetchWorkflow(ctx, client, cfg.Organization, repo, path)
+		if err != nil {
+			return err
+		}
+		if w.Content == content {
+			printOK("%s: воркфлоу актуален", repo)
+			return nil
+		}
+		mu.Lock()
+		drifted = append(drifted, repo)
+		mu.Unlock()
+
+		switch {
+		case w.Missing && *check:
+			printFail("%s: воркфлоу %s не установлен", repo, path)
+			return nil
+		case w.Missing && !*install:
+			printInfo("%s: воркфлоу не установлен, пропущено (--install, чтобы открыть pull request)", repo)
+			return nil
+		case w.Missing:
+			status, err := deployRepo(ctx, client, cfg.Organization, repo, path, content, true, cfg.Tracking)
+			if err == nil {
+				printOK("%s: %s", repo, status)
+			}
+			return err
+		}
+
+		added, removed := diffStat(textdiff.Unified("a/"+path, "b/"+path, w.Content, content, 0))
+		if *check {
+			printFail("%s: воркфлоу расходится с шаблоном (+%d −%d)", repo, added, removed)
+			return nil
+		}
+		status, err := openUpgradePR(ctx, client, cfg.Organization, repo, path, w, content, cfg.Tracking)
+		if err == nil {
+			printOK("%s: расходится с шаблоном (+%d −%d), %s", repo, added, removed, status)
+		}
+		return err
+	})
+	printSummary(&sum)
+	if err != nil {
+		return fail(exitCodeFor(err, exitError), "Синхронизация прервана: %v", err)
+	}
+	if err := sum.Err(); err != nil {
+		return fail(exitCodeFor(err, exitAPI), "%v", err)
+	}
+	if *check && len(drifted) > 0 {
+		sort.Strings(drifted)
+		return fail(exitValidation, "Воркфлоу расходятся с шаблоном: %s", strings.Join(drifted, ", "))
+	}
+	return nil
+}
+
+func diffStat(diff string) (added, removed int) {
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+		case strings.HasPrefix(line, "+"):
+			added++
+		case strings.HasPrefix(line, "-"):
+			removed++
+		}
+	}
+	return added, removed
+}
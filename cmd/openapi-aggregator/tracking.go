@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/RastBast/docs12121/pkg/config"
+	"github.com/RastBast/docs12121/pkg/gitea"
+)
+
+// tagPullRequest sets the tracking labels and milestone on a pull request in
+// a service repository, looking them up in that repository (labels also in
+// the organization). Docs-repo changes are commits, see config.Tracking.
+func tagPullRequest(ctx context.Context, client *gitea.Client, t config.Tracking, org, repo string, number int) error {
+	var errs []error
+	if len(t.Labels) > 0 {
+		ids, missing, err := client.LabelIDs(ctx, org, repo, t.Labels)
+		if err != nil {
+			return err
+		}
+		if len(missing) > 0 {
+			errs = append(errs, fmt.Errorf("нет меток в %s/%s и организации: %s", org, repo, strings.Join(missing, ", ")))
+		}
+		if len(ids) > 0 {
+			if err := client.AddLabels(ctx, org, repo, number, ids); err != nil {
+				return err
+			}
+		}
+	}
+	if t.Milestone != "" {
+		m, err := client.FindMilestone(ctx, org, repo, t.Milestone)
+		if err != nil {
+			return err
+		}
+		if m == nil {
+			errs = append(errs, fmt.Errorf("нет этапа %q в %s/%s", t.Milestone, org, repo))
+		} else if err := client.SetMilestone(ctx, org, repo, number, m.ID); err != nil {
+			return err
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/RastBast/docs12121/pkg/config"
+	"github.com/RastBast/docs12121/pkg/report"
+	"github.com/RastBast/docs12121/pkg/spec"
+)
+
+func translateSpecs(args []string) error {
+	fs := newFlagSet("translate")
+	langs := fs.String("lang", "", "языки через запятую (по умолчанию все файлы в <сервис>/translations)")
+	minCoverage := fs.Int("min-coverage", 0, "минимальная доля переведённых строк, %: ниже — проверка не проходит")
+	skeleton := fs.String("skeleton", "", "создать <сервис>/translations/<язык>.yaml с исходными текстами для перевода")
+	fs.Parse(args)
+
+	dir := "."
+	if fs.NArg() > 0 {
+		dir = fs.Arg(0)
+	}
+	if *skeleton != "" && !spec.ValidLanguage(*skeleton) {
+		return fail(exitConfigInvalid, "Некорректный код языка %q (например: en, de, pt-BR)", *skeleton)
+	}
+	only := config.SplitList(*langs)
+	for _, lang := range only {
+		if !spec.ValidLanguage(lang) {
+			return fail(exitConfigInvalid, "Некорректный код языка %q (например: en, de, pt-BR)", lang)
+		}
+	}
+
+	files, err := spec.SpecFiles(dir)
+	if err != nil {
+		return fail(exitError, "Ошибка поиска спецификаций: %v", err)
+	}
+	if len(files) == 0 {
+		return fail(exitValidation, "В %s не найдено ни одной спецификации", dir)
+	}
+	written, below := 0, 0
+	var sum report.Summary
+	for _, f := range files {
+		repo := spec.SpecName(dir, f)
+		w, b, err := translateRepo(dir, repo, f, *skeleton, only, *minCoverage)
+		sum.Add(repo, err)
+		if err != nil {
+			printFail("%s: %v", repo, err)
+			continue
+		}
+		written += w
+		below += b
+	}
+	if err := sum.Err(); err != nil {
+		printSummary(&sum)
+		return fail(exitCodeFor(err, exitValidation), "%v", err)
+	}
+	if *skeleton != "" {
+		return nil
+	}
+	if written == 0 {
+		printInfo("Переводов не найдено: добавьте <сервис>/%s/<язык>.yaml или запустите с --skeleton <язык>", spec.TranslationsDir)
+	}
+	if below > 0 {
+		return fail(exitValidation, "Переводов с покрытием ниже %d%%: %d", *minCoverage, below)
+	}
+	return nil
+}
+
+func translateRepo(dir, repo, file, skeleton string, only []string, minCoverage int) (written, below int, err error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return 0, 0, err
+	}
+	if skeleton != "" {
+		return 0, 0, writeTranslationSkeleton(dir, repo, skeleton, data)
+	}
+	translations, err := spec.LoadTranslations(dir, repo)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, t := range translations {
+		if len(only) > 0 && !slices.Contains(only, t.Lang) {
+			continue
+		}
+		localized, rep, err := spec.Translate(data, t)
+		if err != nil {
+			return written, below, fmt.Errorf("%s: %w", t.Lang, err)
+		}
+		path := filepath.Join(filepath.Dir(file), spec.LocalizedFile(t.Lang))
+		if err := writeFile(path, localized, 0o644); err != nil {
+			return written, below, fmt.Errorf("ошибка записи %s: %w", path, err)
+		}
+		written++
+		if rep.Coverage() < minCoverage {
+			below++
+			printFail("%s (%s): переведено %d из %d (%d%%)", repo, t.Lang, rep.Translated, rep.Total, rep.Coverage())
+		} else {
+			printOK("%s (%s): переведено %d из %d (%d%%)", repo, t.Lang, rep.Translated, rep.Total, rep.Coverage())
+		}
+		if len(rep.Stale) > 0 {
+			printInfo("%s (%s): в спецификации больше нет: %s", repo, t.Lang, strings.Join(rep.Stale, ", "))
+		}
+	}
+	return written, below, nil
+}
+
+func writeTranslationSkeleton(dir, repo, lang string, data []byte) error {
+	path := filepath.Join(dir, filepath.FromSlash(repo), spec.TranslationsDir, lang+".yaml")
+	if _, err := os.Stat(path); err == nil {
+		printInfo("%s: %s уже есть, пропущено", repo, path)
+		return nil
+	}
+	out, err := spec.TranslationSkeleton(data)
+	if err != nil {
+		return err
+	}
+	if err := writeFile(path, out, 0o644); err != nil {
+		return fmt.Errorf("ошибка записи %s: %w", path, err)
+	}
+	printOK("%s: заготовка перевода %s", repo, path)
+	return nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/RastBast/docs12121/pkg/config"
+	"github.com/RastBast/docs12121/pkg/gitea"
+	"github.com/RastBast/docs12121/pkg/textdiff"
+)
+
+func upgradeWorkflows(ctx context.Context, cfg config.Config, args []string) error {
+	return syncCommand(ctx, cfg, "upgrade", args)
+}
+
+const upgradeBranchPrefix = "openapi-aggregator/upgrade-"
+
+type installedWorkflow struct {
+	Base    string
+	Content string
+	Missing bool
+}
+
+func fetchWorkflow(ctx context.Context, client *gitea.Client, org, repo, path string) (installedWorkflow, error) {
+	info, err := client.GetRepo(ctx, org, repo)
+	if err != nil {
+		return installedWorkflow{}, err
+	}
+	w := installedWorkflow{Base: info.DefaultBranch}
+	current, _, err := client.GetFile(ctx, org, repo, path, w.Base)
+	var apiErr *gitea.APIError
+	switch {
+	case errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound:
+		w.Missing = true
+	case err != nil:
+		return w, err
+	}
+	w.Content = string(current)
+	return w, nil
+}
+
+func upgradeRepo(ctx context.Context, client *gitea.Client, org, repo, path, content string, tracking config.Tracking) (string, error) {
+	w, err := fetchWorkflow(ctx, client, org, repo, path)
+	switch {
+	case err != nil:
+		return "", err
+	case w.Missing:
+		return "воркфлоу не установлен, пропущено", nil
+	case w.Content == content:
+		return "воркфлоу актуален", nil
+	}
+	return openUpgradePR(ctx, client, org, repo, path, w, content, tracking)
+}
+
+func openUpgradePR(ctx context.Context, client *gitea.Client, org, repo, path string, w installedWorkflow, content string, tracking config.Tracking) (string, error) {
+	sum := sha256.Sum256([]byte(content))
+	branch := upgradeBranchPrefix + hex.EncodeToString(sum[:4])
+	diff := textdiff.Unified("a/"+path, "b/"+path, w.Content, content, 3)
+	if *dryRun {
+		return "пробный запуск: будет открыт pull request из " + branch + " в " + w.Base + "\n" + strings.TrimSuffix(diff, "\n"), nil
+	}
+	open, err := client.ListPullRequests(ctx, org, repo)
+	if err != nil {
+		return "", err
+	}
+	for _, pr := range open {
+		if pr.Head.Ref == branch {
+			return "pull request уже открыт: " + pr.HTMLURL, nil
+		}
+	}
+	if err := client.CreateBranch(ctx, org, repo, branch, w.Base); err != nil {
+		return "", err
+	}
+	if err := client.PutFile(ctx, org, repo, path, branch, "Upgrade OpenAPI aggregator workflow", []byte(content)); err != nil {
+		return "", err
+	}
+
+	body := fmt.Sprintf("Шаблон воркфлоу %s обновился в новой версии openapi-aggregator.\n\n```diff\n%s```\n",
+		path, diff)
+	pr, err := client.CreatePullRequest(ctx, org, repo, branch, w.Base, "Upgrade OpenAPI aggregator workflow", body)
+	var apiErr *gitea.APIError
+	if errors.As(err, &apiErr) && apiErr.Status == http.StatusConflict {
+		return "pull request уже открыт (" + branch + ")", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := tagPullRequest(ctx, client, tracking, org, repo, pr.Number); err != nil {
+		printFail("%s: не все метки и этап проставлены в %s: %v", repo, pr.HTMLURL, err)
+	}
+	for _, old := range open {
+		if !strings.HasPrefix(old.Head.Ref, upgradeBranchPrefix) {
+			continue
+		}
+		if err := closeSupersededPR(ctx, client, org, repo, old.Number, pr.Number); err != nil {
+			printFail("%s: устаревший pull request %s не закрыт: %v", repo, old.HTMLURL, err)
+		}
+	}
+	return "открыт pull request " + pr.HTMLURL, nil
+}
+
+func closeSupersededPR(ctx context.Context, client *gitea.Client, org, repo string, number, by int) error {
+	if err := client.CommentIssue(ctx, org, repo, number, fmt.Sprintf("Шаблон воркфлоу снова обновился, изменения перенесены в #%d.", by)); err != nil {
+		return err
+	}
+	return client.ClosePullRequest(ctx, org, repo, number)
+}
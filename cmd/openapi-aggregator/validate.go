@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/RastBast/docs12121/pkg/spec"
+)
+
+func validateSpecs(args []string) error {
+	fs := newFlagSet("validate")
+	format := fs.String("format", "text", "формат вывода: text, json или github (аннотации для Actions)")
+	fs.Parse(args)
+
+	files := fs.Args()
+	if len(files) == 0 {
+		found, err := spec.SpecFiles(".")
+		if err != nil {
+			return fail(exitError, "Ошибка поиска спецификаций: %v", err)
+		}
+		files = found
+	}
+	if len(files) == 0 {
+		return fail(exitConfigInvalid, "Укажите файлы спецификаций")
+	}
+
+	all := []spec.ValidationError{}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fail(exitError, "Ошибка чтения %s: %v", file, err)
+		}
+		errs := spec.Validate(file, data)
+		all = append(all, errs...)
+		if *format == "text" && len(errs) == 0 {
+			printOK("%s", file)
+		}
+	}
+
+	switch *format {
+	case "text":
+		for _, e := range all {
+			printFail("%s", e.Error())
+		}
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(all); err != nil {
+			return fail(exitError, "Ошибка записи: %v", err)
+		}
+	case "github":
+		for _, e := range all {
+			fmt.Printf("::error file=%s,line=%d,col=%d::%s\n", e.File, e.Line, e.Column, githubEscape(e.Message))
+		}
+	default:
+		return fail(exitConfigInvalid, "Неизвестный формат %q. Доступные форматы: text, json, github", *format)
+	}
+	if len(all) > 0 {
+		return fail(exitValidation, "Ошибок валидации: %d", len(all))
+	}
+	return nil
+}
+
+func githubEscape(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
@@ -0,0 +1,18 @@
+package main
+
+import "github.com/RastBast/docs12121/pkg/spec"
+
+func verifyDocs(dir string) error {
+	checked, issues, err := spec.Verify(dir)
+	if err != nil {
+		return fail(exitError, "Ошибка проверки: %v", err)
+	}
+	if len(issues) == 0 {
+		printOK("Проверено файлов: %d, расхождений нет", checked)
+		return nil
+	}
+	for _, is := range issues {
+		printFail("%s: %s", is.Path, is.Reason)
+	}
+	return fail(exitValidation, "Найдено расхождений: %d (правки в обход агрегатора)", len(issues))
+}
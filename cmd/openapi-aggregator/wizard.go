@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/RastBast/docs12121/pkg/config"
+	"github.com/RastBast/docs12121/pkg/generator"
+	"github.com/RastBast/docs12121/pkg/gitea"
+	"github.com/RastBast/docs12121/pkg/prompt"
+)
+
+func runWizard(ctx context.Context, cfg config.Config) (config.Config, bool, error) {
+	p := prompt.New(os.Stdin, os.Stdout)
+	ok, err := wizard(ctx, p, &cfg)
+	if errors.Is(err, prompt.ErrAborted) {
+		return cfg, false, fail(exitConfigInvalid, "Настройка прервана: ввод закончился до ответа на все вопросы")
+	}
+	return cfg, ok, err
+}
+
+// wizard asks the questions one by one. Each check against Gitea gets its own
+// timeout: the session itself may last as long as the user needs.
+func wizard(ctx context.Context, p *prompt.Prompter, cfg *config.Config) (bool, error) {
+	token := os.Getenv("GITEA_TOKEN")
+	if token == "" {
+		printInfo("GITEA_TOKEN не задан: список репозиториев будет только из публичных")
+	}
+
+	var err error
+	cfg.GiteaHost, err = p.Ask("Хост Gitea", cfg.GiteaHost, func(host string) error {
+		ctx, cancel := withTimeout(ctx)
+		defer cancel()
+		return checkGiteaHost(ctx, host, token)
+	})
+	if err != nil {
+		return false, err
+	}
+	client := gitea.NewClient(cfg.GiteaHost, token)
+
+	var repos []gitea.Repository
+	cfg.Organization, err = p.Ask("Организация", cfg.Organization, func(org string) error {
+		ctx, cancel := withTimeout(ctx)
+		defer cancel()
+		list, err := client.ListRepos(ctx, org)
+		var apiErr *gitea.APIError
+		if errors.As(err, &apiErr) && apiErr.Status == 404 {
+			return fmt.Errorf("организация %s не найдена", org)
+		}
+		if err != nil {
+			return fmt.Errorf("не удалось получить репозитории %s: %v", org, err)
+		}
+		repos = list
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	names := make([]string, 0, len(repos))
+	for _, r := range repos {
+		if !r.Archived && !r.Fork {
+			names = append(names, r.Name)
+		}
+	}
+	docsDefault := cfg.DocsRepo
+	if docsDefault == "" {
+		docsDefault = "docs"
+	}
+	cfg.DocsRepo, err = p.Ask("Репозиторий документации", docsDefault, validRepoName)
+	if err != nil {
+		return false, err
+	}
+	if !slices.Contains(names, cfg.DocsRepo) {
+		printInfo("Репозитория %s/%s пока нет: создайте его до первого запуска воркфлоу", cfg.Organization, cfg.DocsRepo)
+	}
+
+	services := slices.DeleteFunc(names, func(name string) bool { return name == cfg.DocsRepo })
+	if len(services) == 0 {
+		list, err := p.Ask("Репозитории сервисов через запятую", strings.Join(cfg.Repositories, ","), func(s string) error {
+			for _, name := range config.SplitList(s) {
+				if err := validRepoName(name); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return false, err
+		}
+		cfg.Repositories = config.SplitList(list)
+	} else {
+		fmt.Printf("Репозитории %s (без архивных и форков):\n", cfg.Organization)
+		// Without a token only public repositories are listed, so private
+		// ones may be typed by name; with a token the list is complete.
+		var other func(string) error
+		if token == "" {
+			other = validRepoName
+		}
+		if cfg.Repositories, err = p.Choose("Репозитории сервисов", services, cfg.Repositories, other); err != nil {
+			return false, err
+		}
+	}
+
+	platformDefault := cfg.Platform
+	if platformDefault == "" {
+		platformDefault = generator.PlatformGitea
+	}
+	cfg.Platform, err = p.Ask("Платформа CI ("+strings.Join(generator.Platforms, ", ")+")", platformDefault, oneOf(generator.Platforms...))
+	if err != nil {
+		return false, err
+	}
+	profileDefault := cfg.Profile
+	if profileDefault == "" {
+		profileDefault = generator.ProfileMinimal
+	}
+	cfg.Profile, err = p.Ask("Профиль воркфлоу ("+strings.Join(generator.Profiles, ", ")+")", profileDefault, oneOf(generator.Profiles...))
+	if err != nil {
+		return false, err
+	}
+
+	fmt.Printf("\nХост Gitea:               %s\nОрганизация:              %s\nРепозиторий документации: %s\nРепозитории сервисов:     %s\nПлатформа CI:             %s\nПрофиль воркфлоу:         %s\n\n",
+		cfg.GiteaHost, cfg.Organization, cfg.DocsRepo, strings.Join(cfg.Repositories, ", "), cfg.Platform, cfg.Profile)
+	return p.Confirm("Записать .env, воркфлоу и README.md", true)
+}
+
+func checkGiteaHost(ctx context.Context, host, token string) error {
+	raw := host
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || strings.ContainsAny(host, " \t") {
+		return fmt.Errorf("некорректный адрес %q, например: gitea.example.com", host)
+	}
+	if _, err := gitea.NewClient(host, token).Version(ctx); err != nil {
+		return fmt.Errorf("Gitea по адресу %s не отвечает: %v", host, err)
+	}
+	return nil
+}
+
+func validRepoName(name string) error {
+	if name == "" || strings.Trim(name, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789._-") != "" {
+		return fmt.Errorf("некорректное имя репозитория %q", name)
+	}
+	return nil
+}
+
+func oneOf(values ...string) func(string) error {
+	return func(s string) error {
+		if !slices.Contains(values, s) {
+			return fmt.Errorf("допустимые значения: %s", strings.Join(values, ", "))
+		}
+		return nil
+	}
+}
@@ -0,0 +1,5 @@
+module github.com/RastBast/docs12121
+
+go 1.22
+
+require gopkg.in/yaml.v3 v3.0.1
@@ -0,0 +1,89 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const File = "audit.log"
+
+const (
+	StatusOK      = "ok"
+	StatusRetry   = "retry"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+)
+
+type Entry struct {
+	Time    time.Time `json:"time"`
+	Action  string    `json:"action"`
+	ID      string    `json:"id,omitempty"`
+	Repo    string    `json:"repo,omitempty"`
+	Target  string    `json:"target,omitempty"`
+	Status  string    `json:"status"`
+	Attempt int       `json:"attempt,omitempty"`
+	Detail  string    `json:"detail,omitempty"`
+}
+
+type Log struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+func Open(stateDir string) *Log {
+	return &Log{Path: filepath.Join(stateDir, File)}
+}
+
+func (l *Log) Append(e Entry) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(l.Path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(data, '\n'))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func (l *Log) Read(match func(Entry) bool) ([]Entry, error) {
+	f, err := os.Open(l.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var entries []Entry
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64<<10), 1<<20)
+	for sc.Scan() {
+		var e Entry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			continue
+		}
+		if match == nil || match(e) {
+			entries = append(entries, e)
+		}
+	}
+	return entries, sc.Err()
+}
@@ -0,0 +1,131 @@
+package batch
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/RastBast/docs12121/pkg/report"
+)
+
+type Checkpoint struct {
+	path string
+	done map[string]bool
+}
+
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	c := &Checkpoint{path: path, done: map[string]bool{}}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if repo := strings.TrimSpace(scanner.Text()); repo != "" {
+			c.done[repo] = true
+		}
+	}
+	return c, scanner.Err()
+}
+
+func (c *Checkpoint) Done(repo string) bool {
+	return c.done[repo]
+}
+
+func (c *Checkpoint) Len() int {
+	return len(c.done)
+}
+
+func (c *Checkpoint) Mark(repo string) error {
+	if c.done[repo] {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(c.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(f, repo); err != nil {
+		f.Close()
+		return err
+	}
+	c.done[repo] = true
+	return f.Close()
+}
+
+func (c *Checkpoint) Remove() error {
+	err := os.Remove(c.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+type Runner struct {
+	BatchSize  int
+	Pause      time.Duration
+	Checkpoint *Checkpoint
+	Logf       func(format string, args ...any)
+}
+
+func (r *Runner) Run(ctx context.Context, repos []string, sum *report.Summary, fn func(ctx context.Context, repo string) error) error {
+	logf := r.Logf
+	if logf == nil {
+		logf = func(string, ...any) {}
+	}
+	var pending []string
+	for _, repo := range repos {
+		if r.Checkpoint != nil && r.Checkpoint.Done(repo) {
+			sum.Add(repo, nil)
+			continue
+		}
+		pending = append(pending, repo)
+	}
+	if skipped := len(repos) - len(pending); skipped > 0 {
+		logf("Пропущено уже обработанных репозиториев: %d", skipped)
+	}
+
+	size := r.BatchSize
+	if size <= 0 {
+		size = len(pending)
+	}
+	for start := 0; start < len(pending); start += size {
+		if start > 0 && r.Pause > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(r.Pause):
+			}
+		}
+		end := min(start+size, len(pending))
+		logf("Пакет %d–%d из %d", start+1, end, len(pending))
+		for _, repo := range pending[start:end] {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			err := fn(ctx, repo)
+			sum.Add(repo, err)
+			if err == nil && r.Checkpoint != nil {
+				if err := r.Checkpoint.Mark(repo); err != nil {
+					return fmt.Errorf("запись контрольной точки: %w", err)
+				}
+			}
+		}
+	}
+
+	if _, failed := sum.Counts(); failed == 0 && r.Checkpoint != nil {
+		return r.Checkpoint.Remove()
+	}
+	return nil
+}
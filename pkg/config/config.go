@@ -0,0 +1,347 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// slackChannel matches a channel ID or a channel name, with or without #.
+var slackChannel = regexp.MustCompile(`^#?[a-z0-9A-Z][a-zA-Z0-9._-]*$`)
+
+type Config struct {
+	GiteaHost    string   `json:"gitea_host" yaml:"gitea_host"`
+	Organization string   `json:"organization" yaml:"organization"`
+	Repositories []string `json:"repositories" yaml:"-"`
+	DocsRepo     string   `json:"docs_repo" yaml:"docs_repo"`
+	Profile      string   `json:"profile,omitempty" yaml:"profile"`
+	Platform     string   `json:"platform,omitempty" yaml:"platform"`
+	Template     string   `json:"workflow_template,omitempty" yaml:"workflow_template"`
+	MirrorURL    string   `json:"mirror_url,omitempty" yaml:"mirror_url"`
+	MirrorMode   string   `json:"mirror_mode,omitempty" yaml:"mirror_mode"`
+	OCIRegistry  string   `json:"oci_registry,omitempty" yaml:"oci_registry"`
+	SharedRepo   string   `json:"shared_repo,omitempty" yaml:"shared_repo"`
+
+	AnalyticsProvider string `json:"analytics_provider,omitempty" yaml:"analytics_provider"`
+	AnalyticsID       string `json:"analytics_id,omitempty" yaml:"analytics_id"`
+	AnalyticsURL      string `json:"analytics_url,omitempty" yaml:"analytics_url"`
+	AnalyticsSnippet  string `json:"analytics_snippet,omitempty" yaml:"analytics_snippet"`
+
+	PortalBaseURL string            `json:"portal_base_url,omitempty" yaml:"portal_base_url"`
+	StatusPages   map[string]string `json:"status_pages,omitempty" yaml:"status_pages"`
+
+	OAuthClientID string `json:"oauth_client_id,omitempty" yaml:"oauth_client_id"`
+	OAuthIssuer   string `json:"oauth_issuer,omitempty" yaml:"oauth_issuer"`
+
+	NotifyWebhooks []string `json:"notify_webhooks,omitempty" yaml:"notify_webhooks"`
+	SlackChannel   string   `json:"slack_channel,omitempty" yaml:"slack_channel"`
+
+	Retention Retention `json:"retention,omitempty" yaml:"retention"`
+	Limits    Limits    `json:"limits,omitempty" yaml:"limits"`
+
+	Branches  []string              `json:"branches,omitempty" yaml:"branches"`
+	SpecPath  string                `json:"spec_path,omitempty" yaml:"spec_path"`
+	Overrides map[string]RepoConfig `json:"overrides,omitempty" yaml:"overrides"`
+
+	BranchEnvironments map[string]string `json:"branch_environments,omitempty" yaml:"branch_environments"`
+	EnvironmentTarget  string            `json:"environment_target,omitempty" yaml:"environment_target"`
+	DocsBranch         string            `json:"docs_branch,omitempty" yaml:"docs_branch"`
+
+	SettingsDir string                `json:"settings_dir,omitempty" yaml:"settings_dir"`
+	Teams       map[string]RepoConfig `json:"teams,omitempty" yaml:"teams"`
+
+	DocsRoutes []DocsRoute `json:"docs_routes,omitempty" yaml:"docs_routes"`
+
+	AllowedOrgs []string `json:"allowed_orgs,omitempty" yaml:"allowed_orgs"`
+
+	Swagger2 string `json:"swagger2,omitempty" yaml:"swagger2"`
+
+	ReleaseAssets bool `json:"release_assets,omitempty" yaml:"release_assets"`
+	VersionCheck  bool `json:"version_check,omitempty" yaml:"version_check"`
+
+	Dispatch []DispatchTarget `json:"dispatch,omitempty" yaml:"dispatch"`
+
+	Onboarding Onboarding `json:"onboarding,omitempty" yaml:"onboarding"`
+
+	Metrics Metrics `json:"metrics,omitempty" yaml:"metrics"`
+
+	FailureIssues FailureIssues `json:"failure_issues,omitempty" yaml:"failure_issues"`
+
+	Tracking Tracking `json:"tracking,omitempty" yaml:"tracking"`
+}
+
+func Defaults() Config {
+	return Config{
+		GiteaHost:     "gitea.example.com",
+		Organization:  "myorg",
+		DocsRepo:      "docs",
+		Repositories:  []string{"repo1", "repo2", "repo3"},
+		Profile:       "minimal",
+		MirrorMode:    "repo",
+		PortalBaseURL: "/",
+		Limits:        DefaultLimits(),
+	}
+}
+
+func Load() Config {
+	cfg := Defaults()
+	cfg.ApplyEnv()
+	return cfg
+}
+
+// ApplyEnv overrides the config with the environment variables that are set;
+// it runs last so the environment takes precedence over files and settings.
+func (c *Config) ApplyEnv() {
+	for _, v := range []struct {
+		key    string
+		target *string
+	}{
+		{"GITEA_HOST", &c.GiteaHost},
+		{"ORGANIZATION", &c.Organization},
+		{"DOCS_REPO", &c.DocsRepo},
+		{"WORKFLOW_PROFILE", &c.Profile},
+		{"PLATFORM", &c.Platform},
+		{"WORKFLOW_TEMPLATE", &c.Template},
+		{"MIRROR_URL", &c.MirrorURL},
+		{"MIRROR_MODE", &c.MirrorMode},
+		{"OCI_REGISTRY", &c.OCIRegistry},
+		{"SHARED_REPO", &c.SharedRepo},
+		{"ANALYTICS_PROVIDER", &c.AnalyticsProvider},
+		{"ANALYTICS_ID", &c.AnalyticsID},
+		{"ANALYTICS_URL", &c.AnalyticsURL},
+		{"ANALYTICS_SNIPPET", &c.AnalyticsSnippet},
+		{"PORTAL_BASE_URL", &c.PortalBaseURL},
+		{"OAUTH_CLIENT_ID", &c.OAuthClientID},
+		{"OAUTH_ISSUER", &c.OAuthIssuer},
+		{"SLACK_CHANNEL", &c.SlackChannel},
+		{"SPEC_PATH", &c.SpecPath},
+		{"SETTINGS_DIR", &c.SettingsDir},
+		{"SWAGGER2", &c.Swagger2},
+		{"ENVIRONMENT_TARGET", &c.EnvironmentTarget},
+		{"DOCS_BRANCH", &c.DocsBranch},
+		{"METRICS_BACKEND", &c.Metrics.Backend},
+		{"METRICS_URL", &c.Metrics.URL},
+		{"METRICS_PREFIX", &c.Metrics.Prefix},
+		{"METRICS_PAYLOAD", &c.Metrics.Payload},
+		{"METRICS_AUTH", &c.Metrics.Auth},
+		{"METRICS_SECRET_ENV", &c.Metrics.SecretEnv},
+	} {
+		if value := os.Getenv(v.key); value != "" {
+			*v.target = value
+		}
+	}
+	for _, v := range []struct {
+		key    string
+		target *[]string
+	}{
+		{"REPOSITORIES", &c.Repositories},
+		{"NOTIFY_WEBHOOKS", &c.NotifyWebhooks},
+		{"BRANCHES", &c.Branches},
+		{"ALLOWED_ORGS", &c.AllowedOrgs},
+	} {
+		if value := os.Getenv(v.key); value != "" {
+			*v.target = SplitList(value)
+		}
+	}
+	if value := os.Getenv("STATUS_PAGES"); value != "" {
+		c.StatusPages = ParsePairs(value)
+	}
+	if value := os.Getenv("BRANCH_ENVIRONMENTS"); value != "" {
+		c.BranchEnvironments = ParsePairs(value)
+	}
+}
+
+func FromJSON(r io.Reader) (Config, error) {
+	cfg := Load()
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return Config{}, fmt.Errorf("разбор JSON-конфигурации: %w", err)
+	}
+	return cfg, nil
+}
+
+func SplitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func (c Config) Validate() error {
+	var missing []string
+	if c.GiteaHost == "" {
+		missing = append(missing, "хост Gitea")
+	}
+	if c.Organization == "" {
+		missing = append(missing, "организация")
+	}
+	if c.DocsRepo == "" {
+		missing = append(missing, "репозиторий документации")
+	}
+	if len(c.Repositories) == 0 {
+		missing = append(missing, "список репозиториев")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("не заполнено: %s", strings.Join(missing, ", "))
+	}
+	switch c.Platform {
+	case "", "gitea", "github", "gitlab":
+	default:
+		return fmt.Errorf("неизвестная платформа %q (доступны: gitea, github, gitlab)", c.Platform)
+	}
+	switch c.Swagger2 {
+	case "", "convert", "fail":
+	default:
+		return fmt.Errorf("неизвестный режим swagger2 %q (доступны: convert, fail)", c.Swagger2)
+	}
+	for _, name := range c.Repositories {
+		if err := checkRepoName(name); err != nil {
+			return err
+		}
+	}
+	if c.SlackChannel != "" && !slackChannel.MatchString(c.SlackChannel) {
+		return fmt.Errorf("некорректный канал Slack в slack_channel: %q (ожидается ID вида C0123456789 или имя канала)", c.SlackChannel)
+	}
+	for _, org := range c.AllowedOrgs {
+		if !repoName.MatchString(org) {
+			return fmt.Errorf("некорректное имя организации в allowed_orgs: %q", org)
+		}
+	}
+	if err := c.validateRoutes(); err != nil {
+		return err
+	}
+	if err := c.validateDispatch(); err != nil {
+		return err
+	}
+	if err := c.validateEnvironments(); err != nil {
+		return err
+	}
+	if err := c.Limits.Validate(); err != nil {
+		return err
+	}
+	if err := c.Metrics.Validate(); err != nil {
+		return err
+	}
+	if err := c.FailureIssues.Validate(); err != nil {
+		return err
+	}
+	if err := c.Tracking.Validate(); err != nil {
+		return err
+	}
+	for _, name := range append([]string{""}, c.Repositories...) {
+		rc := c.Repo(name)
+		if err := rc.validate(); err != nil {
+			return err
+		}
+		if name != "" {
+			if err := c.Limits.CheckFiles(name, len(rc.Specs())); err != nil {
+				return err
+			}
+		}
+	}
+	return c.Retention.Validate()
+}
+
+func ParsePairs(s string) map[string]string {
+	pairs := map[string]string{}
+	for _, item := range SplitList(s) {
+		if key, value, ok := strings.Cut(item, "="); ok {
+			pairs[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	if len(pairs) == 0 {
+		return nil
+	}
+	return pairs
+}
+
+func JoinPairs(pairs map[string]string) string {
+	keys := make([]string, 0, len(pairs))
+	for k := range pairs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for i, k := range keys {
+		keys[i] = k + "=" + pairs[k]
+	}
+	return strings.Join(keys, ",")
+}
+
+func (c Config) EnvFile() string {
+	env := fmt.Sprintf(`GITEA_HOST=%s
+ORGANIZATION=%s
+DOCS_REPO=%s
+REPOSITORIES=%s
+`,
+		c.GiteaHost,
+		c.Organization,
+		c.DocsRepo,
+		strings.Join(c.Repositories, ","),
+	)
+	optional := []struct{ key, value string }{
+		{"WORKFLOW_PROFILE", c.Profile},
+		{"PLATFORM", c.Platform},
+		{"WORKFLOW_TEMPLATE", c.Template},
+		{"MIRROR_URL", c.MirrorURL},
+		{"MIRROR_MODE", c.MirrorMode},
+		{"OCI_REGISTRY", c.OCIRegistry},
+		{"SHARED_REPO", c.SharedRepo},
+		{"ANALYTICS_PROVIDER", c.AnalyticsProvider},
+		{"ANALYTICS_ID", c.AnalyticsID},
+		{"ANALYTICS_URL", c.AnalyticsURL},
+		{"PORTAL_BASE_URL", c.PortalBaseURL},
+		{"STATUS_PAGES", JoinPairs(c.StatusPages)},
+		{"OAUTH_CLIENT_ID", c.OAuthClientID},
+		{"OAUTH_ISSUER", c.OAuthIssuer},
+		{"NOTIFY_WEBHOOKS", strings.Join(c.NotifyWebhooks, ",")},
+		{"SLACK_CHANNEL", c.SlackChannel},
+		{"BRANCHES", strings.Join(c.Branches, ",")},
+		{"BRANCH_ENVIRONMENTS", JoinPairs(c.BranchEnvironments)},
+		{"ENVIRONMENT_TARGET", c.EnvironmentTarget},
+		{"DOCS_BRANCH", c.DocsBranch},
+		{"ALLOWED_ORGS", strings.Join(c.AllowedOrgs, ",")},
+		{"SPEC_PATH", c.SpecPath},
+		{"SETTINGS_DIR", c.SettingsDir},
+		{"SWAGGER2", c.Swagger2},
+		{"METRICS_BACKEND", c.Metrics.Backend},
+		{"METRICS_URL", c.Metrics.URL},
+		{"METRICS_PREFIX", c.Metrics.Prefix},
+		{"METRICS_AUTH", c.Metrics.Auth},
+		{"METRICS_SECRET_ENV", c.Metrics.SecretEnv},
+	}
+	for _, o := range optional {
+		if o.value != "" {
+			env += o.key + "=" + o.value + "\n"
+		}
+	}
+	return env
+}
+
+func (c Config) PortalBase() string {
+	base := strings.TrimSpace(c.PortalBaseURL)
+	if base == "" {
+		return "/"
+	}
+	if !strings.Contains(base, "://") && !strings.HasPrefix(base, "/") {
+		base = "/" + base
+	}
+	if !strings.HasSuffix(base, "/") {
+		base += "/"
+	}
+	return base
+}
+
+func (c Config) PublishOrgs() []string {
+	if len(c.AllowedOrgs) > 0 {
+		return c.AllowedOrgs
+	}
+	return []string{c.Organization}
+}
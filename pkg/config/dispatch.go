@@ -0,0 +1,56 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"slices"
+)
+
+type DispatchTarget struct {
+	Name      string   `json:"name" yaml:"name"`
+	URL       string   `json:"url" yaml:"url"`
+	SecretEnv string   `json:"secret_env,omitempty" yaml:"secret_env"`
+	Repos     []string `json:"repos,omitempty" yaml:"repos"`
+}
+
+func (t DispatchTarget) Secret() string {
+	if t.SecretEnv == "" {
+		return ""
+	}
+	return os.Getenv(t.SecretEnv)
+}
+
+func (t DispatchTarget) Matches(repo string) bool {
+	return len(t.Repos) == 0 || slices.ContainsFunc(t.Repos, func(pattern string) bool {
+		ok, _ := path.Match(pattern, repo)
+		return ok
+	})
+}
+
+func (c Config) validateDispatch() error {
+	seen := map[string]bool{}
+	for i, t := range c.Dispatch {
+		if t.Name == "" {
+			return fmt.Errorf("у получателя событий %d не указано имя", i+1)
+		}
+		if seen[t.Name] {
+			return fmt.Errorf("получатель событий %q указан дважды", t.Name)
+		}
+		seen[t.Name] = true
+		u, err := url.Parse(t.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("некорректный адрес получателя событий %s: %q", t.Name, t.URL)
+		}
+		if t.SecretEnv == "" {
+			return fmt.Errorf("у получателя событий %s не указан secret_env: события подписываются обязательно", t.Name)
+		}
+		for _, pattern := range t.Repos {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("некорректный шаблон репозитория у получателя событий %s: %q", t.Name, pattern)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const (
+	EnvironmentTargetBranch    = "branch"
+	EnvironmentTargetDirectory = "directory"
+)
+
+type BranchEnvironment struct {
+	Branch      string
+	Environment string
+}
+
+func (c Config) Environments() []BranchEnvironment {
+	envs := make([]BranchEnvironment, 0, len(c.BranchEnvironments))
+	for branch, env := range c.BranchEnvironments {
+		envs = append(envs, BranchEnvironment{Branch: branch, Environment: env})
+	}
+	sort.Slice(envs, func(i, j int) bool {
+		pi, pj := isBranchPattern(envs[i].Branch), isBranchPattern(envs[j].Branch)
+		if pi != pj {
+			return !pi
+		}
+		return envs[i].Branch < envs[j].Branch
+	})
+	return envs
+}
+
+func (c Config) EnvironmentFor(branch string) string {
+	for _, e := range c.Environments() {
+		if ok, _ := path.Match(e.Branch, branch); ok {
+			return e.Environment
+		}
+	}
+	return branch
+}
+
+func (c Config) DocsTarget(branch, name string) (string, string) {
+	if len(c.BranchEnvironments) == 0 {
+		return branch, name
+	}
+	return c.EnvironmentDocs(c.EnvironmentFor(branch), name)
+}
+
+func (c Config) EnvironmentDirs() []string {
+	if c.EnvironmentTarget != EnvironmentTargetDirectory || len(c.BranchEnvironments) == 0 {
+		return nil
+	}
+	var dirs []string
+	add := func(env string) {
+		if !slices.Contains(dirs, env) {
+			dirs = append(dirs, env)
+		}
+	}
+	for _, e := range c.Environments() {
+		add(e.Environment)
+	}
+	branches := slices.Clone(c.Branches)
+	for _, o := range c.Overrides {
+		branches = append(branches, o.Branches...)
+	}
+	for _, b := range branches {
+		if !isBranchPattern(b) {
+			add(c.EnvironmentFor(b))
+		}
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+func (c Config) EnvironmentDocs(env, name string) (string, string) {
+	if c.EnvironmentTarget == EnvironmentTargetDirectory {
+		return c.DocsBranchName(), env + "/" + name
+	}
+	return env, name
+}
+
+func (c Config) DocsBranchName() string {
+	if c.DocsBranch == "" {
+		return "main"
+	}
+	return c.DocsBranch
+}
+
+func (c Config) environmentBranches() []string {
+	branches := make([]string, 0, len(c.BranchEnvironments))
+	for _, e := range c.Environments() {
+		branches = append(branches, e.Branch)
+	}
+	return branches
+}
+
+func (c Config) validateEnvironments() error {
+	switch c.EnvironmentTarget {
+	case "", EnvironmentTargetBranch, EnvironmentTargetDirectory:
+	default:
+		return fmt.Errorf("неизвестный режим environment_target %q (доступны: branch, directory)", c.EnvironmentTarget)
+	}
+	if c.DocsBranch != "" && !validBranch(c.DocsBranch) {
+		return fmt.Errorf("некорректная ветка репозитория документации: %q", c.DocsBranch)
+	}
+	for _, e := range c.Environments() {
+		if !validBranch(e.Branch) {
+			return fmt.Errorf("некорректное имя ветки в branch_environments: %q", e.Branch)
+		}
+		if !repoName.MatchString(e.Environment) || strings.HasPrefix(e.Environment, ".") {
+			return fmt.Errorf("некорректное окружение для ветки %s: %q", e.Branch, e.Environment)
+		}
+	}
+	return nil
+}
+
+func validBranch(b string) bool {
+	return b != "" && !strings.ContainsRune("-*!%@{", rune(b[0])) && !strings.ContainsAny(b, "'\"`$\\ \t\n:#~^?[;()|&<>")
+}
+
+func isBranchPattern(b string) bool {
+	return strings.Contains(b, "*")
+}
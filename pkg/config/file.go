@@ -0,0 +1,328 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	DefaultFile  = "aggregator.yaml"
+	RemoteScheme = "gitea://"
+)
+
+var apiName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+var (
+	DefaultBranches = []string{"main", "staging", "dev"}
+	DefaultSpecPath = "docs/openapi.yaml"
+)
+
+type RepoConfig struct {
+	Name     string      `json:"-" yaml:"-"`
+	Team     string      `json:"team,omitempty" yaml:"team"`
+	Branches []string    `json:"branches,omitempty" yaml:"branches"`
+	SpecPath string      `json:"spec_path,omitempty" yaml:"spec_path"`
+	APIs     []APIConfig `json:"apis,omitempty" yaml:"apis"`
+	DocsRepo string      `json:"docs_repo,omitempty" yaml:"docs_repo"`
+	Audience string      `json:"audience,omitempty" yaml:"audience"`
+
+	Assignees []string `json:"assignees,omitempty" yaml:"assignees"`
+
+	Support    string `json:"support,omitempty" yaml:"support"`
+	RateLimits string `json:"rate_limits,omitempty" yaml:"rate_limits"`
+}
+
+type APIConfig struct {
+	Name     string `json:"name" yaml:"name"`
+	SpecPath string `json:"spec_path" yaml:"spec_path"`
+}
+
+func (rc RepoConfig) Specs() []APIConfig {
+	if len(rc.APIs) > 0 {
+		return rc.APIs
+	}
+	return []APIConfig{{SpecPath: rc.SpecPath}}
+}
+
+func (rc RepoConfig) DocsName(api APIConfig) string {
+	if api.Name == "" {
+		return rc.Name
+	}
+	return rc.Name + "/" + api.Name
+}
+
+func (c Config) Repo(name string) RepoConfig {
+	rc := RepoConfig{Name: name, Branches: c.Branches, SpecPath: c.SpecPath}
+	o, ok := c.Overrides[name]
+	if team, found := c.Teams[o.Team]; ok && o.Team != "" && found {
+		rc.Team = o.Team
+		rc.merge(team)
+	}
+	if ok {
+		rc.merge(o)
+	}
+	if len(rc.Branches) == 0 {
+		rc.Branches = c.environmentBranches()
+	}
+	if len(rc.Branches) == 0 {
+		rc.Branches = DefaultBranches
+	}
+	if rc.SpecPath == "" {
+		rc.SpecPath = DefaultSpecPath
+	}
+	if rc.DocsRepo == "" {
+		rc.DocsRepo = c.routeDocsRepo(rc)
+	}
+	return rc
+}
+
+func (rc *RepoConfig) merge(o RepoConfig) {
+	if len(o.Branches) > 0 {
+		rc.Branches = o.Branches
+	}
+	if o.SpecPath != "" {
+		rc.SpecPath = o.SpecPath
+	}
+	if len(o.APIs) > 0 {
+		rc.APIs = o.APIs
+	}
+	if o.DocsRepo != "" {
+		rc.DocsRepo = o.DocsRepo
+	}
+	if o.Audience != "" {
+		rc.Audience = o.Audience
+	}
+	if len(o.Assignees) > 0 {
+		rc.Assignees = o.Assignees
+	}
+	if o.Support != "" {
+		rc.Support = o.Support
+	}
+	if o.RateLimits != "" {
+		rc.RateLimits = o.RateLimits
+	}
+}
+
+func (rc RepoConfig) validate() error {
+	where := "по умолчанию"
+	if rc.Name != "" {
+		where = "для " + rc.Name
+	}
+	seen := map[string]bool{}
+	for _, api := range rc.Specs() {
+		p := api.SpecPath
+		if path.IsAbs(p) || path.Clean(p) != p || p == ".." || strings.HasPrefix(p, "../") || strings.ContainsAny(p, "'\"`$\\ \t\n") {
+			return fmt.Errorf("некорректный путь к спецификации %s: %q (нужен относительный путь внутри репозитория)", where, p)
+		}
+		if len(rc.APIs) == 0 {
+			continue
+		}
+		if !apiName.MatchString(api.Name) {
+			return fmt.Errorf("некорректное имя API %s: %q (допустимы строчные латинские буквы, цифры, - и _)", where, api.Name)
+		}
+		if seen[api.Name] {
+			return fmt.Errorf("API %q %s указан дважды", api.Name, where)
+		}
+		seen[api.Name] = true
+	}
+	if rc.DocsRepo != "" && !repoName.MatchString(rc.DocsRepo) {
+		return fmt.Errorf("некорректный репозиторий документации %s: %q", where, rc.DocsRepo)
+	}
+	for _, b := range rc.Branches {
+		if !validBranch(b) {
+			return fmt.Errorf("некорректное имя ветки %s: %q", where, b)
+		}
+	}
+	return nil
+}
+
+type repoEntry struct {
+	RepoConfig `yaml:",inline"`
+	Name       string `yaml:"name"`
+}
+
+func (e *repoEntry) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		return n.Decode(&e.Name)
+	}
+	type plain repoEntry
+	return n.Decode((*plain)(e))
+}
+
+type fileConfig struct {
+	Config       `yaml:",inline"`
+	Repositories []repoEntry `yaml:"repositories"`
+}
+
+func LoadFile(path string) (Config, error) {
+	return LoadFiles(path)
+}
+
+func LoadFiles(paths ...string) (Config, error) {
+	return LoadSources(os.ReadFile, paths...)
+}
+
+func LoadSources(read func(path string) ([]byte, error), paths ...string) (Config, error) {
+	cfg, err := ReadSources(read, paths...)
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.ApplyEnv()
+	return cfg, nil
+}
+
+func ReadSources(read func(path string) ([]byte, error), paths ...string) (Config, error) {
+	cfg := Defaults()
+	for _, path := range paths {
+		if err := cfg.applyFile(path, read); err != nil {
+			return Config{}, err
+		}
+	}
+	return cfg, nil
+}
+
+type RemoteFile struct {
+	Owner, Repo, Path string
+}
+
+func ParseRemote(s string) (RemoteFile, bool) {
+	rest, ok := strings.CutPrefix(s, RemoteScheme)
+	if !ok {
+		return RemoteFile{}, false
+	}
+	parts := strings.SplitN(rest, "/", 3)
+	if len(parts) < 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return RemoteFile{}, false
+	}
+	return RemoteFile{Owner: parts[0], Repo: parts[1], Path: parts[2]}, true
+}
+
+func OverlayPath(base, env string) string {
+	ext := filepath.Ext(base)
+	return strings.TrimSuffix(base, ext) + "." + env + ext
+}
+
+func (c *Config) applyFile(path string, read func(string) ([]byte, error)) error {
+	if ext := filepath.Ext(path); ext == ".toml" {
+		return fmt.Errorf("%s: формат TOML не поддерживается, используйте YAML", path)
+	}
+	data, err := read(path)
+	if err != nil {
+		return err
+	}
+	file := fileConfig{Config: *c}
+	file.Overrides = nil
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("разбор %s: %w", path, err)
+	}
+
+	cfg := file.Config
+	if file.Repositories == nil {
+		for name, o := range c.Overrides {
+			if _, ok := cfg.Overrides[name]; ok {
+				continue
+			}
+			if cfg.Overrides == nil {
+				cfg.Overrides = map[string]RepoConfig{}
+			}
+			cfg.Overrides[name] = o
+		}
+	} else {
+		cfg.Repositories = nil
+		for i, r := range file.Repositories {
+			name := strings.TrimSpace(r.Name)
+			if name == "" {
+				return fmt.Errorf("%s: у репозитория %d не указано имя", path, i+1)
+			}
+			if err := checkRepoName(name); err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			cfg.Repositories = append(cfg.Repositories, name)
+			if r.Team == "" && len(r.Branches) == 0 && r.SpecPath == "" && len(r.APIs) == 0 && r.DocsRepo == "" && r.Audience == "" &&
+				r.Support == "" && r.RateLimits == "" && len(r.Assignees) == 0 {
+				continue
+			}
+			if cfg.Overrides == nil {
+				cfg.Overrides = map[string]RepoConfig{}
+			}
+			cfg.Overrides[name] = r.RepoConfig
+		}
+	}
+	if err := cfg.checkOverrides(path); err != nil {
+		return err
+	}
+	*c = cfg
+	return nil
+}
+
+func (c Config) checkOverrides(source string) error {
+	for name, o := range c.Overrides {
+		if !slices.Contains(c.Repositories, name) {
+			return fmt.Errorf("%s: настройки заданы для неизвестного репозитория %q", source, name)
+		}
+		if _, ok := c.Teams[o.Team]; o.Team != "" && !ok {
+			return fmt.Errorf("%s: репозиторий %s относится к неизвестной команде %q", source, name, o.Team)
+		}
+	}
+	return nil
+}
+
+func WithRepositories(path string, repos []RepoConfig) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("разбор %s: %w", path, err)
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s: ожидается словарь настроек", path)
+	}
+	var list *yaml.Node
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "repositories" {
+			list = root.Content[i+1]
+		}
+	}
+	if list == nil {
+		list = &yaml.Node{Kind: yaml.SequenceNode}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "repositories"}, list)
+	}
+	if list.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("%s: repositories должен быть списком", path)
+	}
+	for _, rc := range repos {
+		entry := &yaml.Node{Kind: yaml.ScalarNode, Value: rc.Name}
+		if rc.SpecPath != "" {
+			entry = &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{
+				{Kind: yaml.ScalarNode, Value: "name"}, {Kind: yaml.ScalarNode, Value: rc.Name},
+				{Kind: yaml.ScalarNode, Value: "spec_path"}, {Kind: yaml.ScalarNode, Value: rc.SpecPath},
+			}}
+		}
+		list.Content = append(list.Content, entry)
+	}
+	var b bytes.Buffer
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,18 @@
+package config
+
+import "fmt"
+
+type FailureIssues struct {
+	After int `json:"after,omitempty" yaml:"after"`
+}
+
+func (f FailureIssues) Enabled() bool {
+	return f.After > 0
+}
+
+func (f FailureIssues) Validate() error {
+	if f.After < 0 {
+		return fmt.Errorf("некорректный порог failure_issues.after: %d (нужно число сбоев подряд, 0 — не открывать issue)", f.After)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var ErrLimit = errors.New("превышен лимит")
+
+type Size int64
+
+const (
+	KB Size = 1 << (10 * (iota + 1))
+	MB
+	GB
+)
+
+func ParseSize(s string) (Size, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	unit := Size(1)
+	for _, u := range []struct {
+		suffix string
+		size   Size
+	}{{"GB", GB}, {"MB", MB}, {"KB", KB}, {"G", GB}, {"M", MB}, {"K", KB}, {"B", 1}} {
+		if rest, ok := strings.CutSuffix(s, u.suffix); ok {
+			s, unit = strings.TrimSpace(rest), u.size
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(n) || n < 0 || n*float64(unit) >= math.MaxInt64 {
+		return 0, fmt.Errorf("некорректный размер %q (пример: 512KB, 10MB, 1GB)", s)
+	}
+	return Size(n * float64(unit)), nil
+}
+
+func (s Size) String() string {
+	for _, u := range []struct {
+		suffix string
+		size   Size
+	}{{"GB", GB}, {"MB", MB}, {"KB", KB}} {
+		if s >= u.size {
+			return strings.TrimSuffix(strconv.FormatFloat(float64(s)/float64(u.size), 'f', 1, 64), ".0") + " " + u.suffix
+		}
+	}
+	return strconv.FormatInt(int64(s), 10) + " B"
+}
+
+func (s Size) MarshalText() ([]byte, error) {
+	for _, u := range []struct {
+		suffix string
+		size   Size
+	}{{"GB", GB}, {"MB", MB}, {"KB", KB}} {
+		if s > 0 && s%u.size == 0 {
+			return []byte(strconv.FormatInt(int64(s/u.size), 10) + u.suffix), nil
+		}
+	}
+	return []byte(strconv.FormatInt(int64(s), 10)), nil
+}
+
+func (s *Size) UnmarshalText(text []byte) error {
+	size, err := ParseSize(string(text))
+	if err != nil {
+		return err
+	}
+	*s = size
+	return nil
+}
+
+type Limits struct {
+	MaxSpecSize     Size `json:"max_spec_size,omitempty" yaml:"max_spec_size"`
+	MaxFilesPerRepo int  `json:"max_files_per_repo,omitempty" yaml:"max_files_per_repo"`
+	MaxPortalSize   Size `json:"max_portal_size,omitempty" yaml:"max_portal_size"`
+}
+
+func DefaultLimits() Limits {
+	return Limits{MaxSpecSize: 20 * MB, MaxFilesPerRepo: 20, MaxPortalSize: 500 * MB}
+}
+
+func (l Limits) Validate() error {
+	if l.MaxSpecSize < 0 || l.MaxFilesPerRepo < 0 || l.MaxPortalSize < 0 {
+		return fmt.Errorf("некорректные лимиты: max_spec_size, max_files_per_repo и max_portal_size не могут быть отрицательными")
+	}
+	return nil
+}
+
+func (l Limits) CheckSpec(path string, size int) error {
+	if l.MaxSpecSize > 0 && Size(size) > l.MaxSpecSize {
+		return fmt.Errorf("%s: размер %s: %w max_spec_size (%s)", path, Size(size), ErrLimit, l.MaxSpecSize)
+	}
+	return nil
+}
+
+func (l Limits) CheckFiles(repo string, n int) error {
+	if l.MaxFilesPerRepo > 0 && n > l.MaxFilesPerRepo {
+		return fmt.Errorf("%s: спецификаций %d: %w max_files_per_repo (%d)", repo, n, ErrLimit, l.MaxFilesPerRepo)
+	}
+	return nil
+}
+
+func (l Limits) CheckPortal(dir string, size int64) error {
+	if l.MaxPortalSize > 0 && Size(size) > l.MaxPortalSize {
+		return fmt.Errorf("%s: размер сайта %s: %w max_portal_size (%s)", dir, Size(size), ErrLimit, l.MaxPortalSize)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+)
+
+const (
+	MetricsHTTP        = "http"
+	MetricsStatsD      = "statsd"
+	MetricsPushgateway = "pushgateway"
+)
+
+var envName = regexp.MustCompile(`^[A-Z_][A-Z0-9_]*$`)
+
+type Metrics struct {
+	Backend   string `json:"backend,omitempty" yaml:"backend"`
+	URL       string `json:"url,omitempty" yaml:"url"`
+	Prefix    string `json:"prefix,omitempty" yaml:"prefix"`
+	Payload   string `json:"payload,omitempty" yaml:"payload"`
+	Auth      string `json:"auth,omitempty" yaml:"auth"`
+	SecretEnv string `json:"secret_env,omitempty" yaml:"secret_env"`
+}
+
+func (m Metrics) Enabled() bool {
+	return m.Backend != ""
+}
+
+func (m Metrics) PrefixOrDefault() string {
+	if m.Prefix == "" {
+		return "openapi_docs"
+	}
+	return m.Prefix
+}
+
+func (m Metrics) Secret() string {
+	if m.SecretEnv == "" {
+		return ""
+	}
+	return os.Getenv(m.SecretEnv)
+}
+
+func (m Metrics) Validate() error {
+	switch m.Backend {
+	case "":
+		return nil
+	case MetricsHTTP, MetricsPushgateway:
+		u, err := url.Parse(m.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("некорректный адрес metrics.url для %s: %q", m.Backend, m.URL)
+		}
+	case MetricsStatsD:
+		if _, _, err := net.SplitHostPort(m.URL); err != nil {
+			return fmt.Errorf("metrics.url для statsd должен быть в виде хост:порт: %q", m.URL)
+		}
+		if m.Auth != "" {
+			return fmt.Errorf("statsd не поддерживает авторизацию (metrics.auth)")
+		}
+	default:
+		return fmt.Errorf("неизвестный бэкенд метрик %q (доступны: http, statsd, pushgateway)", m.Backend)
+	}
+	if m.Payload != "" && m.Backend != MetricsHTTP {
+		return fmt.Errorf("metrics.payload задаётся только для бэкенда http")
+	}
+	switch m.Auth {
+	case "", "bearer", "basic":
+	default:
+		return fmt.Errorf("неизвестный способ авторизации метрик %q (доступны: bearer, basic)", m.Auth)
+	}
+	if m.Auth != "" && m.SecretEnv == "" {
+		return fmt.Errorf("для metrics.auth нужен metrics.secret_env с именем переменной окружения")
+	}
+	if m.SecretEnv != "" && !envName.MatchString(m.SecretEnv) {
+		return fmt.Errorf("некорректное имя переменной окружения metrics.secret_env: %q", m.SecretEnv)
+	}
+	return nil
+}
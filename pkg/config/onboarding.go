@@ -0,0 +1,19 @@
+package config
+
+type Onboarding struct {
+	BaseURLs   map[string]string `json:"base_urls,omitempty" yaml:"base_urls"`
+	RateLimits string            `json:"rate_limits,omitempty" yaml:"rate_limits"`
+	Support    string            `json:"support,omitempty" yaml:"support"`
+	Auth       string            `json:"auth,omitempty" yaml:"auth"`
+}
+
+func (c Config) OnboardingFor(rc RepoConfig) Onboarding {
+	o := c.Onboarding
+	if rc.RateLimits != "" {
+		o.RateLimits = rc.RateLimits
+	}
+	if rc.Support != "" {
+		o.Support = rc.Support
+	}
+	return o
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+type Retention struct {
+	KeepVersions int `json:"keep_versions,omitempty" yaml:"keep_versions"`
+	MaxAgeMonths int `json:"max_age_months,omitempty" yaml:"max_age_months"`
+}
+
+func (r Retention) Enabled() bool {
+	return r.KeepVersions > 0 || r.MaxAgeMonths > 0
+}
+
+func (r Retention) Validate() error {
+	if r.KeepVersions < 0 || r.MaxAgeMonths < 0 {
+		return fmt.Errorf("некорректная политика хранения: keep_versions и max_age_months не могут быть отрицательными")
+	}
+	return nil
+}
+
+func (r Retention) Keep(index int, date, now time.Time) bool {
+	if index == 0 || !r.Enabled() {
+		return true
+	}
+	if r.KeepVersions > 0 && index < r.KeepVersions {
+		return true
+	}
+	return r.MaxAgeMonths > 0 && date.After(now.AddDate(0, -r.MaxAgeMonths, 0))
+}
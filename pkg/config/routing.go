@@ -0,0 +1,95 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+)
+
+var repoName = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// checkRepoName rejects names that can't be a Gitea repository. Repository
+// names become paths in the docs checkout, so "." and ".." are refused too.
+func checkRepoName(name string) error {
+	if !repoName.MatchString(name) || name == "." || name == ".." {
+		return fmt.Errorf("некорректное имя репозитория %q", name)
+	}
+	return nil
+}
+
+type DocsRoute struct {
+	DocsRepo  string   `json:"docs_repo" yaml:"docs_repo"`
+	Repos     []string `json:"repos,omitempty" yaml:"repos"`
+	Teams     []string `json:"teams,omitempty" yaml:"teams"`
+	Audiences []string `json:"audiences,omitempty" yaml:"audiences"`
+}
+
+func (r DocsRoute) Matches(rc RepoConfig) bool {
+	if len(r.Repos) > 0 && !slices.ContainsFunc(r.Repos, func(pattern string) bool {
+		ok, _ := path.Match(pattern, rc.Name)
+		return ok
+	}) {
+		return false
+	}
+	if len(r.Teams) > 0 && !slices.Contains(r.Teams, rc.Team) {
+		return false
+	}
+	if len(r.Audiences) > 0 && !slices.Contains(r.Audiences, rc.Audience) {
+		return false
+	}
+	return len(r.Repos)+len(r.Teams)+len(r.Audiences) > 0
+}
+
+func (c Config) routeDocsRepo(rc RepoConfig) string {
+	if rc.Name != "" {
+		for _, r := range c.DocsRoutes {
+			if r.Matches(rc) {
+				return r.DocsRepo
+			}
+		}
+	}
+	return c.DocsRepo
+}
+
+func (c Config) DocsRepos() []string {
+	repos := []string{c.DocsRepo}
+	for _, name := range c.Repositories {
+		if docs := c.Repo(name).DocsRepo; !slices.Contains(repos, docs) {
+			repos = append(repos, docs)
+		}
+	}
+	return repos
+}
+
+func (c Config) ReposFor(docsRepo string) []string {
+	var repos []string
+	for _, name := range c.Repositories {
+		if c.Repo(name).DocsRepo == docsRepo {
+			repos = append(repos, name)
+		}
+	}
+	return repos
+}
+
+func (c Config) validateRoutes() error {
+	for i, r := range c.DocsRoutes {
+		if !repoName.MatchString(r.DocsRepo) {
+			return fmt.Errorf("некорректный репозиторий документации в правиле маршрутизации %d: %q", i+1, r.DocsRepo)
+		}
+		if len(r.Repos)+len(r.Teams)+len(r.Audiences) == 0 {
+			return fmt.Errorf("в правиле маршрутизации %d (%s) не указаны repos, teams или audiences", i+1, r.DocsRepo)
+		}
+		for _, pattern := range r.Repos {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("некорректный шаблон репозитория в правиле маршрутизации %d: %q", i+1, pattern)
+			}
+		}
+		for _, team := range r.Teams {
+			if _, ok := c.Teams[team]; !ok {
+				return fmt.Errorf("правило маршрутизации %d ссылается на неизвестную команду %q", i+1, team)
+			}
+		}
+	}
+	return nil
+}
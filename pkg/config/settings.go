@@ -0,0 +1,105 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	SettingsOrgFile  = "org.yaml"
+	SettingsTeamsDir = "teams"
+)
+
+type teamFile struct {
+	Branches     []string    `yaml:"branches"`
+	SpecPath     string      `yaml:"spec_path"`
+	DocsRepo     string      `yaml:"docs_repo"`
+	Audience     string      `yaml:"audience"`
+	Support      string      `yaml:"support"`
+	RateLimits   string      `yaml:"rate_limits"`
+	Assignees    []string    `yaml:"assignees"`
+	Repositories []repoEntry `yaml:"repositories"`
+}
+
+func (c *Config) ApplySettings(dir string) error {
+	var org RepoConfig
+	if err := decodeSettings(filepath.Join(dir, SettingsOrgFile), &org); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if org.Team != "" || len(org.APIs) > 0 || org.DocsRepo != "" || org.Audience != "" || len(org.Assignees) > 0 {
+		return fmt.Errorf("%s: на уровне организации задаются только branches и spec_path", filepath.Join(dir, SettingsOrgFile))
+	}
+	if len(org.Branches) > 0 {
+		c.Branches = org.Branches
+	}
+	if org.SpecPath != "" {
+		c.SpecPath = org.SpecPath
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, SettingsTeamsDir, "*.yaml"))
+	if err != nil {
+		return err
+	}
+	owner := map[string]string{}
+	for _, file := range files {
+		team := strings.TrimSuffix(filepath.Base(file), ".yaml")
+		var tf teamFile
+		if err := decodeSettings(file, &tf); err != nil {
+			return err
+		}
+		if c.Teams == nil {
+			c.Teams = map[string]RepoConfig{}
+		}
+		c.Teams[team] = RepoConfig{Name: team, Branches: tf.Branches, SpecPath: tf.SpecPath, DocsRepo: tf.DocsRepo, Audience: tf.Audience,
+			Support: tf.Support, RateLimits: tf.RateLimits, Assignees: tf.Assignees}
+
+		for i, r := range tf.Repositories {
+			name := strings.TrimSpace(r.Name)
+			if name == "" {
+				return fmt.Errorf("%s: у репозитория %d не указано имя", file, i+1)
+			}
+			if err := checkRepoName(name); err != nil {
+				return fmt.Errorf("%s: %w", file, err)
+			}
+			if prev, ok := owner[name]; ok {
+				return fmt.Errorf("%s: репозиторий %s уже относится к команде %s", file, name, prev)
+			}
+			owner[name] = team
+			if r.Team != "" && r.Team != team {
+				return fmt.Errorf("%s: у репозитория %s указана другая команда %q", file, name, r.Team)
+			}
+			if !slices.Contains(c.Repositories, name) {
+				c.Repositories = append(c.Repositories, name)
+			}
+			o := c.Overrides[name]
+			o.Team = team
+			o.merge(r.RepoConfig)
+			if c.Overrides == nil {
+				c.Overrides = map[string]RepoConfig{}
+			}
+			c.Overrides[name] = o
+		}
+	}
+	return c.checkOverrides(dir)
+}
+
+func decodeSettings(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("разбор %s: %w", path, err)
+	}
+	return nil
+}
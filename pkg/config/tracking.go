@@ -0,0 +1,50 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Tracking ties aggregator changes to release planning. Labels and the
+// milestone are set only on the pull requests the aggregator opens in
+// service repositories (deploy --pr, upgrade, onboard --pr); the aggregator
+// pushes to the docs repository directly, so its commits there carry the
+// same values as Labels:/Milestone: trailers instead.
+type Tracking struct {
+	Labels    []string `json:"labels,omitempty" yaml:"labels"`
+	Milestone string   `json:"milestone,omitempty" yaml:"milestone"`
+}
+
+func (t Tracking) Enabled() bool {
+	return len(t.Labels) > 0 || t.Milestone != ""
+}
+
+func (t Tracking) Validate() error {
+	for _, label := range t.Labels {
+		if strings.TrimSpace(label) == "" || strings.ContainsAny(label, ",'\"`$\\\n") {
+			return fmt.Errorf("некорректная метка в tracking.labels: %q", label)
+		}
+	}
+	if strings.ContainsAny(t.Milestone, "'\"`$\\\n") {
+		return fmt.Errorf("некорректный этап в tracking.milestone: %q", t.Milestone)
+	}
+	return nil
+}
+
+func (t Tracking) Trailers() []string {
+	var trailers []string
+	if len(t.Labels) > 0 {
+		trailers = append(trailers, "Labels: "+strings.Join(t.Labels, ", "))
+	}
+	if t.Milestone != "" {
+		trailers = append(trailers, "Milestone: "+t.Milestone)
+	}
+	return trailers
+}
+
+func (t Tracking) CommitMessage(subject string) string {
+	if !t.Enabled() {
+		return subject
+	}
+	return subject + "\n\n" + strings.Join(t.Trailers(), "\n")
+}
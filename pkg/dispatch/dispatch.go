@@ -0,0 +1,156 @@
+package dispatch
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/RastBast/docs12121/pkg/audit"
+	"github.com/RastBast/docs12121/pkg/config"
+)
+
+const (
+	EventPublished = "spec.published"
+	AuditAction    = "dispatch"
+)
+
+type Event struct {
+	ID     string    `json:"id"`
+	Type   string    `json:"type"`
+	Time   time.Time `json:"time"`
+	Repo   string    `json:"repo"`
+	Files  []string  `json:"files,omitempty"`
+	Commit string    `json:"commit,omitempty"`
+	Ref    string    `json:"ref,omitempty"`
+}
+
+func NewEvent(repo string, files []string) Event {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return Event{ID: hex.EncodeToString(b), Type: EventPublished, Time: time.Now().UTC(), Repo: repo, Files: files}
+}
+
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+type Dispatcher struct {
+	Targets  []config.DispatchTarget
+	Audit    *audit.Log
+	Client   *http.Client
+	Attempts int
+	Backoff  time.Duration
+	Logf     func(format string, args ...any)
+}
+
+func (d *Dispatcher) Send(ctx context.Context, ev Event) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, t := range d.Targets {
+		if !t.Matches(ev.Repo) {
+			continue
+		}
+		if err := d.deliver(ctx, t, ev, body); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", t.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, t config.DispatchTarget, ev Event, body []byte) error {
+	attempts := max(d.Attempts, 1)
+	backoff := d.Backoff
+	if backoff == 0 {
+		backoff = 2 * time.Second
+	}
+	for attempt := 1; ; attempt++ {
+		retry, err := d.post(ctx, t, ev, body)
+		entry := audit.Entry{Action: AuditAction, ID: ev.ID, Repo: ev.Repo, Target: t.Name, Attempt: attempt, Status: audit.StatusOK}
+		switch {
+		case err == nil:
+		case retry && attempt < attempts:
+			entry.Status, entry.Detail = audit.StatusRetry, err.Error()
+		default:
+			entry.Status, entry.Detail = audit.StatusFailed, err.Error()
+		}
+		d.record(entry)
+		if entry.Status != audit.StatusRetry {
+			return err
+		}
+		d.logf("%s: попытка %d не удалась, повтор через %s: %v", t.Name, attempt, backoff, err)
+		select {
+		case <-ctx.Done():
+			d.record(audit.Entry{Action: AuditAction, ID: ev.ID, Repo: ev.Repo, Target: t.Name, Attempt: attempt, Status: audit.StatusFailed, Detail: ctx.Err().Error()})
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (d *Dispatcher) post(ctx context.Context, t config.DispatchTarget, ev Event, body []byte) (retry bool, err error) {
+	secret := t.Secret()
+	if secret == "" {
+		return false, fmt.Errorf("не задан секрет подписи %s", t.SecretEnv)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "openapi-aggregator")
+	req.Header.Set("X-Aggregator-Event", ev.Type)
+	req.Header.Set("X-Aggregator-Delivery", ev.ID)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("X-Aggregator-Timestamp", timestamp)
+	req.Header.Set("X-Aggregator-Signature", Sign(secret, timestamp, body))
+	client := d.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("POST %s: %s", t.URL, resp.Status)
+	default:
+		return false, fmt.Errorf("POST %s: %s", t.URL, resp.Status)
+	}
+}
+
+func (d *Dispatcher) record(e audit.Entry) {
+	if d.Audit == nil {
+		return
+	}
+	if err := d.Audit.Append(e); err != nil {
+		d.logf("Ошибка записи в журнал аудита: %v", err)
+	}
+}
+
+func (d *Dispatcher) logf(format string, args ...any) {
+	if d.Logf != nil {
+		d.Logf(format, args...)
+	}
+}
@@ -0,0 +1,87 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+type Container struct {
+	ID      string
+	BaseURL string
+}
+
+func StartGitea(ctx context.Context, image string) (*Container, error) {
+	out, err := exec.CommandContext(ctx, "docker", "run", "-d", "-P",
+		"-e", "GITEA__security__INSTALL_LOCK=true",
+		"-e", "GITEA__database__DB_TYPE=sqlite3",
+		"-e", "GITEA__server__ROOT_URL=http://localhost:3000/",
+		image).Output()
+	if err != nil {
+		return nil, fmt.Errorf("docker run: %w", err)
+	}
+	c := &Container{ID: strings.TrimSpace(string(out))}
+
+	out, err = exec.CommandContext(ctx, "docker", "port", c.ID, "3000/tcp").Output()
+	if err != nil {
+		c.Stop()
+		return nil, fmt.Errorf("docker port: %w", err)
+	}
+	hostPort := strings.Fields(string(out))[0]
+	hostPort = strings.Replace(hostPort, "0.0.0.0", "127.0.0.1", 1)
+	c.BaseURL = "http://" + hostPort
+
+	if err := c.waitReady(ctx, 2*time.Minute); err != nil {
+		c.Stop()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Container) waitReady(ctx context.Context, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+"/api/v1/version", nil)
+		if err != nil {
+			return err
+		}
+		if resp, err := http.DefaultClient.Do(req); err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("Gitea не запустилась за %s: %w", timeout, ctx.Err())
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+func (c *Container) CreateAdmin(ctx context.Context, user, password string) error {
+	out, err := exec.CommandContext(ctx, "docker", "exec", "-u", "git", c.ID,
+		"gitea", "admin", "user", "create", "--admin",
+		"--username", user, "--password", password,
+		"--email", user+"@example.com", "--must-change-password=false").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("создание администратора: %v: %s", err, out)
+	}
+	return nil
+}
+
+func (c *Container) Host() string {
+	return strings.TrimPrefix(c.BaseURL, "http://")
+}
+
+func (c *Container) CloneURL(user, password, owner, repo string) string {
+	return fmt.Sprintf("http://%s:%s@%s/%s/%s.git", user, password, c.Host(), owner, repo)
+}
+
+func (c *Container) Stop() {
+	exec.Command("docker", "rm", "-f", c.ID).Run()
+}
@@ -0,0 +1,182 @@
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/RastBast/docs12121/pkg/config"
+	"github.com/RastBast/docs12121/pkg/generator"
+	"github.com/RastBast/docs12121/pkg/git"
+	"github.com/RastBast/docs12121/pkg/gitea"
+	"github.com/RastBast/docs12121/pkg/report"
+	"github.com/RastBast/docs12121/pkg/spec"
+)
+
+const (
+	User     = "e2e-admin"
+	Password = "e2e-password"
+	Org      = "e2e"
+)
+
+const specTemplate = `openapi: 3.0.3
+info:
+  title: %s API
+  version: 1.0.0
+paths:
+  /health:
+    get:
+      responses:
+        "200":
+          description: OK
+`
+
+var bot = git.Author{Name: "OpenAPI Aggregator Bot", Email: "openapi-bot@example.com"}
+
+type Scenario struct {
+	Gitea   *Container
+	Repos   []string
+	Logf    func(format string, args ...any)
+	Summary report.Summary
+	client  *gitea.Client
+}
+
+func (s *Scenario) Run(ctx context.Context) error {
+	if s.Logf == nil {
+		s.Logf = func(string, ...any) {}
+	}
+	if err := s.Gitea.CreateAdmin(ctx, User, Password); err != nil {
+		return err
+	}
+	s.client = gitea.NewClient(s.Gitea.BaseURL, "")
+	s.client.SetBasicAuth(User, Password)
+
+	if err := s.client.CreateOrg(ctx, Org); err != nil {
+		return err
+	}
+	if err := s.client.CreateRepo(ctx, Org, "docs"); err != nil {
+		return err
+	}
+	s.each(func(repo string) error {
+		if err := s.client.CreateRepo(ctx, Org, repo); err != nil {
+			return err
+		}
+		content := fmt.Sprintf(specTemplate, repo)
+		return s.client.PutFile(ctx, Org, repo, "docs/openapi.yaml", "main", "Add OpenAPI spec", []byte(content))
+	})
+	s.Logf("Организация, репозитории и спецификации созданы")
+
+	cfg := config.Config{
+		GiteaHost:    s.Gitea.Host(),
+		Organization: Org,
+		DocsRepo:     "docs",
+		Repositories: s.Repos,
+	}
+	workflow, err := generator.Generate(cfg)
+	if err != nil {
+		return err
+	}
+	s.each(func(repo string) error {
+		path := generator.WorkflowDir + "/" + generator.WorkflowFile
+		return s.client.PutFile(ctx, Org, repo, path, "main", "Add OpenAPI aggregator workflow", []byte(workflow))
+	})
+	s.Logf("Воркфлоу доставлен в репозитории")
+
+	work, err := os.MkdirTemp("", "e2e-docs-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(work)
+	if err := s.aggregate(ctx, work); err != nil {
+		return err
+	}
+	s.Logf("Спецификации агрегированы в репозиторий документации")
+
+	if err := s.assertDocs(ctx, work); err != nil {
+		return err
+	}
+	s.each(func(string) error { return nil })
+	return s.Summary.Err()
+}
+
+func (s *Scenario) each(fn func(repo string) error) {
+	for _, repo := range s.Repos {
+		if s.Summary.Failed(repo) {
+			continue
+		}
+		if err := fn(repo); err != nil {
+			s.Summary.Add(repo, err)
+		}
+	}
+}
+
+func (s *Scenario) aggregate(ctx context.Context, work string) error {
+	dir := filepath.Join(work, "docs")
+	if err := git.Run(ctx, work, "clone", s.Gitea.CloneURL(User, Password, Org, "docs"), "docs"); err != nil {
+		return err
+	}
+	var paths []string
+	s.each(func(repo string) error {
+		data, _, err := s.client.GetFile(ctx, Org, repo, "docs/openapi.yaml", "main")
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Join(dir, repo), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, repo, "openapi.yaml"), data, 0o644); err != nil {
+			return err
+		}
+		paths = append(paths, repo+"/openapi.yaml")
+		return nil
+	})
+	if len(paths) == 0 {
+		return s.Summary.Err()
+	}
+	if err := spec.UpdateManifest(dir, paths); err != nil {
+		return err
+	}
+	if err := git.Run(ctx, dir, "add", "."); err != nil {
+		return err
+	}
+	if err := git.Commit(ctx, dir, bot, "Aggregate OpenAPI docs"); err != nil {
+		return err
+	}
+	return git.Run(ctx, dir, "push", "origin", "main")
+}
+
+func (s *Scenario) assertDocs(ctx context.Context, work string) error {
+	s.each(func(repo string) error {
+		want, _, err := s.client.GetFile(ctx, Org, repo, "docs/openapi.yaml", "main")
+		if err != nil {
+			return err
+		}
+		got, _, err := s.client.GetFile(ctx, Org, "docs", repo+"/openapi.yaml", "main")
+		if err != nil {
+			return fmt.Errorf("openapi.yaml отсутствует в репозитории документации: %w", err)
+		}
+		if !bytes.Equal(got, want) {
+			return errors.New("openapi.yaml отличается от исходной спецификации")
+		}
+		path := generator.WorkflowDir + "/" + generator.WorkflowFile
+		if _, _, err := s.client.GetFile(ctx, Org, repo, path, "main"); err != nil {
+			return fmt.Errorf("воркфлоу не найден: %w", err)
+		}
+		return nil
+	})
+
+	if err := git.Run(ctx, work, "clone", s.Gitea.CloneURL(User, Password, Org, "docs"), "verify"); err != nil {
+		return err
+	}
+	_, issues, err := spec.Verify(filepath.Join(work, "verify"))
+	if err != nil {
+		return err
+	}
+	if len(issues) > 0 {
+		return fmt.Errorf("verify нашёл расхождения: %v", issues)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/RastBast/docs12121/pkg/config"
+)
+
+const analyticsMarker = "<!-- openapi-aggregator:analytics -->"
+
+const analyticsGuard = `<script>
+(function () {
+  if (navigator.doNotTrack === "1" || localStorage.getItem("analytics-opt-out") === "1") return;
+  %s
+})();
+</script>`
+
+const analyticsOptOut = `<a class="analytics-opt-out" href="#" onclick="localStorage.setItem('analytics-opt-out', '1'); this.remove(); return false">Opt out of analytics</a>`
+
+const analyticsStepTemplate = `      - name: Inject analytics snippet
+        run: |
+          REPO=${{ steps.repo_info.outputs.repo_name }}
+          cat > /tmp/analytics.html << 'SNIPPET'
+%s
+          SNIPPET
+          for f in $(find docs-repo/static/$REPO docs-repo/interactive/$REPO docs-repo/index.html -name '*.html' 2>/dev/null); do
+            grep -q 'openapi-aggregator:analytics' "$f" && continue
+            if grep -q '</head>' "$f"; then
+              sed -i '/<\/head>/r /tmp/analytics.html' "$f"
+            else
+              cat /tmp/analytics.html >> "$f"
+            fi
+          done
+          if ! grep -q 'class="analytics-opt-out"' docs-repo/index.html; then
+            cat >> docs-repo/index.html << 'OPTOUT'
+          %s
+          OPTOUT
+          fi
+`
+
+func AnalyticsSnippet(cfg config.Config) (string, error) {
+	var js string
+	switch cfg.AnalyticsProvider {
+	case "":
+		return "", nil
+	case "custom":
+		return analyticsMarker + "\n" + cfg.AnalyticsSnippet, nil
+	case "plausible":
+		base := strings.TrimSuffix(orDefault(cfg.AnalyticsURL, "https://plausible.io"), "/")
+		js = fmt.Sprintf(`var s = document.createElement("script"); s.defer = true; s.dataset.domain = %q; s.src = %q; document.head.appendChild(s);`,
+			cfg.AnalyticsID, base+"/js/script.js")
+	case "matomo":
+		base := strings.TrimSuffix(cfg.AnalyticsURL, "/")
+		js = fmt.Sprintf(`var _paq = window._paq = window._paq || []; _paq.push(["trackPageView"]); _paq.push(["enableLinkTracking"]); _paq.push(["setTrackerUrl", %q]); _paq.push(["setSiteId", %q]); var s = document.createElement("script"); s.async = true; s.src = %q; document.head.appendChild(s);`,
+			base+"/matomo.php", cfg.AnalyticsID, base+"/matomo.js")
+	case "ga":
+		js = fmt.Sprintf(`window.dataLayer = window.dataLayer || []; window.gtag = function () { dataLayer.push(arguments); }; gtag("js", new Date()); gtag("config", %q); var s = document.createElement("script"); s.async = true; s.src = %q; document.head.appendChild(s);`,
+			cfg.AnalyticsID, "https://www.googletagmanager.com/gtag/js?id="+cfg.AnalyticsID)
+	default:
+		return "", fmt.Errorf("неизвестный провайдер аналитики %q (доступны: plausible, matomo, ga, custom)", cfg.AnalyticsProvider)
+	}
+	return analyticsMarker + "\n" + fmt.Sprintf(analyticsGuard, js), nil
+}
+
+func AnalyticsStep(cfg config.Config) (string, error) {
+	snippet, err := AnalyticsSnippet(cfg)
+	if err != nil || snippet == "" {
+		return "", err
+	}
+	lines := strings.Split(snippet, "\n")
+	for i, l := range lines {
+		lines[i] = "          " + l
+	}
+	return fmt.Sprintf(analyticsStepTemplate, strings.Join(lines, "\n"), analyticsOptOut), nil
+}
+
+func orDefault(v, def string) string {
+	if v != "" {
+		return v
+	}
+	return def
+}
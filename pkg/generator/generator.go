@@ -0,0 +1,207 @@
+package generator
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/RastBast/docs12121/pkg/config"
+)
+
+const (
+	WorkflowDir  = ".gitea/workflows"
+	WorkflowFile = "openapi-aggregator.yml"
+)
+
+var ErrUnknownProfile = errors.New("неизвестный профиль")
+
+//go:embed templates
+var templateFS embed.FS
+
+const (
+	ProfileMinimal    = "minimal"
+	ProfileValidation = "validation"
+	ProfileFull       = "full"
+)
+
+var Profiles = []string{ProfileMinimal, ProfileValidation, ProfileFull}
+
+var profileAliases = map[string]string{"": ProfileMinimal, "basic": ProfileMinimal, "portal": ProfileFull}
+
+func ProfileName(profile string) string {
+	if name, ok := profileAliases[profile]; ok {
+		return name
+	}
+	return profile
+}
+
+func Render(cfg config.Config) (string, error) {
+	return RenderRepo(cfg, "")
+}
+
+type TemplateData struct {
+	config.Config
+	Repo          config.RepoConfig
+	OAuthStep     string
+	StatusStep    string
+	AnalyticsStep string
+	MetricsStep   string
+	MirrorStep    string
+	Tool          string
+}
+
+var templateFuncs = template.FuncMap{
+	"branchPattern": branchPattern,
+	"joinPairs":     config.JoinPairs,
+	"join":          strings.Join,
+}
+
+func RenderRepo(cfg config.Config, repo string) (string, error) {
+	tmpl, err := workflowTemplate(cfg)
+	if err != nil {
+		return "", err
+	}
+	data := TemplateData{Config: cfg, Repo: cfg.Repo(repo), MirrorStep: MirrorStep(cfg), Tool: ToolRef()}
+	if ProfileName(cfg.Profile) == ProfileFull || cfg.Template != "" {
+		if data.AnalyticsStep, err = AnalyticsStep(cfg); err != nil {
+			return "", err
+		}
+		if data.MetricsStep, err = MetricsStep(cfg); err != nil {
+			return "", err
+		}
+		if data.StatusStep, err = StatusLookup(cfg); err != nil {
+			return "", err
+		}
+		if data.OAuthStep, err = OAuthSetup(cfg); err != nil {
+			return "", err
+		}
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("шаблон воркфлоу: %w", err)
+	}
+	return adaptPlatform(cfg.Platform, b.String()), nil
+}
+
+func workflowTemplate(cfg config.Config) (*template.Template, error) {
+	base, err := template.New("workflow").Delims("[[", "]]").Funcs(templateFuncs).Option("missingkey=error").
+		ParseFS(templateFS, "templates/common.tmpl")
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Template != "" {
+		text, err := os.ReadFile(cfg.Template)
+		if err != nil {
+			return nil, fmt.Errorf("шаблон воркфлоу: %w", err)
+		}
+		tmpl, err := base.New(filepath.Base(cfg.Template)).Parse(string(text))
+		if err != nil {
+			return nil, fmt.Errorf("шаблон воркфлоу: %w", err)
+		}
+		return tmpl, nil
+	}
+
+	profile := ProfileName(cfg.Profile)
+	file := profile + ".yml"
+	if cfg.Platform == PlatformGitLab {
+		if profile != ProfileMinimal {
+			return nil, fmt.Errorf("профиль %q %w gitlab (доступен: minimal)", cfg.Profile, ErrUnsupportedPlatform)
+		}
+		if cfg.MirrorURL != "" {
+			return nil, fmt.Errorf("зеркалирование %w gitlab", ErrUnsupportedPlatform)
+		}
+		if cfg.ReleaseAssets {
+			return nil, fmt.Errorf("публикация файлов в релизы %w gitlab", ErrUnsupportedPlatform)
+		}
+		file = "gitlab.yml"
+	} else if !slices.Contains(Profiles, profile) {
+		return nil, fmt.Errorf("%w %q (доступны: %s)", ErrUnknownProfile, cfg.Profile, strings.Join(Profiles, ", "))
+	}
+	if _, err := base.ParseFS(templateFS, "templates/"+file); err != nil {
+		return nil, err
+	}
+	return base.Lookup(file), nil
+}
+
+func Generate(cfg config.Config) (string, error) {
+	return GenerateRepo(cfg, "")
+}
+
+func GenerateRepo(cfg config.Config, repo string) (string, error) {
+	content, err := RenderRepo(cfg, repo)
+	if err != nil {
+		return "", err
+	}
+	if cfg.Platform == PlatformGitLab {
+		return content, checkGitLab(content)
+	}
+	if err := Check(content); err != nil {
+		return "", err
+	}
+	return content, nil
+}
+
+func Readme(cfg config.Config) string {
+	return fmt.Sprintf(`# OpenAPI Documentation Aggregator
+
+Этот проект автоматически собирает OpenAPI документацию из разных репозиториев.
+
+## Конфигурация
+- Gitea Host: %s
+- Организация: %s
+- Репозиторий документации: %s
+- Отслеживаемые репозитории: %s
+
+## Как это работает
+1. Пуш в ветки %s.
+2. Проверка %s (путь можно переопределить для репозитория в %s).
+3. Копирование файла в репозиторий документации.
+4. Коммит и пуш.
+
+## Ручной запуск
+Воркфлоу можно запустить вручную из интерфейса Gitea (Actions → Run workflow):
+- target_branch — ветка репозитория документации (по умолчанию текущая);
+- skip_validation — пропустить валидацию и проверку breaking changes;
+- dry_run — подготовить коммит, но не пушить его.
+
+## Структура результата
+`+"```"+`
+%s`+"```"+`
+
+Таблицу API с версиями, датами обновления и ссылками на документацию собирает в README.md репозитория документации команда `+"`openapi-aggregator readme`"+`.
+`,
+		cfg.GiteaHost,
+		cfg.Organization,
+		cfg.DocsRepo,
+		strings.Join(cfg.Repositories, ", "),
+		strings.Join(cfg.Repo("").Branches, ", "),
+		cfg.Repo("").SpecPath,
+		config.DefaultFile,
+		docsTree(cfg),
+	)
+}
+
+func docsTree(cfg config.Config) string {
+	var names []string
+	for _, repo := range cfg.Repositories {
+		rc := cfg.Repo(repo)
+		for _, api := range rc.Specs() {
+			names = append(names, rc.DocsName(api))
+		}
+	}
+	var b strings.Builder
+	b.WriteString(cfg.DocsRepo + "/\n")
+	for i, name := range names {
+		branch, indent := "├── ", "│   "
+		if i == len(names)-1 {
+			branch, indent = "└── ", "    "
+		}
+		b.WriteString(branch + name + "/\n" + indent + "└── openapi.yaml\n")
+	}
+	return b.String()
+}
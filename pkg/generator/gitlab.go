@@ -0,0 +1,30 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const GitLabCIFile = ".gitlab-ci.yml"
+
+var ErrUnsupportedPlatform = errors.New("не поддерживается для платформы")
+
+func branchPattern(branches []string) string {
+	patterns := make([]string, len(branches))
+	for i, b := range branches {
+		patterns[i] = strings.ReplaceAll(strings.ReplaceAll(regexp.QuoteMeta(b), `\*`, ".*"), "/", `\/`)
+	}
+	return strings.Join(patterns, "|")
+}
+
+func checkGitLab(content string) error {
+	var check map[string]any
+	if err := yaml.Unmarshal([]byte(content), &check); err != nil {
+		return fmt.Errorf("пайплайн GitLab не прошёл проверку: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/RastBast/docs12121/pkg/config"
+)
+
+const HookMarker = "# Installed by openapi-aggregator install-hooks"
+
+var HookKinds = []string{"pre-commit", "pre-push"}
+
+const hookTemplate = `#!/bin/sh
+%s
+AGGREGATOR=%s
+
+if ! command -v "$AGGREGATOR" >/dev/null 2>&1; then
+  echo "openapi-aggregator: $AGGREGATOR не найден, проверка спецификаций пропущена" >&2
+  exit 0
+fi
+
+STATUS=0
+check() {
+  NAME=$1
+  SPEC=$2
+  %s
+  WORK=$(mktemp -d)
+  %s
+  "$AGGREGATOR" spec preview "$WORK/openapi.yaml" --repo "$NAME" --docs "$WORK/docs" --output "$WORK/preview" || {
+    echo "openapi-aggregator: $SPEC не прошёл проверку, исправьте ошибки или используйте --no-verify" >&2
+    STATUS=1
+  }
+  rm -rf "$WORK"
+}
+
+%s
+exit $STATUS
+`
+
+const preCommitCheck = `git diff --cached --name-only --diff-filter=ACMR | grep -qxF "$SPEC" || return 0`
+
+const preCommitSource = `git show ":$SPEC" > "$WORK/openapi.yaml" || { rm -rf "$WORK"; STATUS=1; return; }`
+
+const prePushCheck = `git cat-file -e "HEAD:$SPEC" 2>/dev/null || return 0`
+
+const prePushSource = `git show "HEAD:$SPEC" > "$WORK/openapi.yaml" || { rm -rf "$WORK"; STATUS=1; return; }`
+
+func Hook(kind string, rc config.RepoConfig, binary string) (string, error) {
+	var check, source string
+	switch kind {
+	case "pre-commit":
+		check, source = preCommitCheck, preCommitSource
+	case "pre-push":
+		check, source = prePushCheck, prePushSource
+	default:
+		return "", fmt.Errorf("неизвестный хук %q (доступны: %s)", kind, strings.Join(HookKinds, ", "))
+	}
+	var calls strings.Builder
+	for _, api := range rc.Specs() {
+		fmt.Fprintf(&calls, "check %s %s\n", shellQuote(rc.DocsName(api)), shellQuote(api.SpecPath))
+	}
+	return fmt.Sprintf(hookTemplate, HookMarker, shellQuote(binary), check, source, calls.String()), nil
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
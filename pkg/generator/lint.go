@@ -0,0 +1,180 @@
+package generator
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	workflowKeys = keySet("name", "run-name", "on", "permissions", "env", "defaults", "concurrency", "jobs")
+	jobKeys      = keySet("name", "needs", "runs-on", "if", "permissions", "environment", "concurrency", "outputs",
+		"env", "defaults", "steps", "timeout-minutes", "strategy", "continue-on-error", "container", "services", "uses", "with", "secrets")
+	stepKeys = keySet("id", "if", "name", "uses", "run", "shell", "with", "env", "working-directory", "continue-on-error", "timeout-minutes")
+
+	stepRefPattern = regexp.MustCompile(`steps\.([A-Za-z0-9_-]+)\.`)
+)
+
+func keySet(keys ...string) map[string]bool {
+	m := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		m[k] = true
+	}
+	return m
+}
+
+func Check(content string) error {
+	problems := Lint(content)
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("воркфлоу не прошёл проверку:\n  %s", strings.Join(problems, "\n  "))
+}
+
+func Lint(content string) []string {
+	if path, err := exec.LookPath("actionlint"); err == nil {
+		return runActionlint(path, content)
+	}
+	return Validate(content)
+}
+
+func runActionlint(path, content string) []string {
+	cmd := exec.Command(path, "-no-color", "-oneline", "-ignore", `undefined variable "gitea"`, "-")
+	cmd.Stdin = strings.NewReader(content)
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		return nil
+	}
+	var problems []string
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if line != "" {
+			problems = append(problems, strings.TrimPrefix(line, "<stdin>:"))
+		}
+	}
+	return problems
+}
+
+func Validate(content string) []string {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+		return []string{err.Error()}
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return []string{"воркфлоу должен быть YAML-объектом"}
+	}
+
+	var problems []string
+	report := func(n *yaml.Node, format string, args ...any) {
+		problems = append(problems, fmt.Sprintf("строка %d: %s", n.Line, fmt.Sprintf(format, args...)))
+	}
+
+	root := doc.Content[0]
+	checkKeys(root, workflowKeys, "воркфлоу", report)
+	if mappingValue(root, "on") == nil {
+		report(root, "отсутствует секция on")
+	}
+	jobs := mappingValue(root, "jobs")
+	if jobs == nil || len(jobs.Content) == 0 {
+		report(root, "отсутствует секция jobs")
+		return problems
+	}
+
+	for i := 0; i+1 < len(jobs.Content); i += 2 {
+		name, job := jobs.Content[i].Value, jobs.Content[i+1]
+		checkKeys(job, jobKeys, "задаче "+name, report)
+		if mappingValue(job, "uses") != nil {
+			continue
+		}
+		if mappingValue(job, "runs-on") == nil {
+			report(job, "задача %s: отсутствует runs-on", name)
+		}
+		steps := mappingValue(job, "steps")
+		if steps == nil || steps.Kind != yaml.SequenceNode {
+			report(job, "задача %s: отсутствует список steps", name)
+			continue
+		}
+		ids := make(map[string]bool)
+		for _, step := range steps.Content {
+			checkKeys(step, stepKeys, "шаге", report)
+			uses, run := mappingValue(step, "uses"), mappingValue(step, "run")
+			if (uses == nil) == (run == nil) {
+				report(step, "шаг должен содержать ровно одно из uses или run")
+			}
+			checkStepRefs(step, ids, report)
+			if id := mappingValue(step, "id"); id != nil {
+				if ids[id.Value] {
+					report(id, "повторяющийся id шага %q", id.Value)
+				}
+				ids[id.Value] = true
+			}
+		}
+	}
+
+	checkExpressions(root, report)
+	return problems
+}
+
+func checkKeys(n *yaml.Node, allowed map[string]bool, where string, report func(*yaml.Node, string, ...any)) {
+	if n.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i < len(n.Content); i += 2 {
+		key := n.Content[i]
+		if allowed[key.Value] {
+			continue
+		}
+		if key.Value == "run" && allowed["run-name"] {
+			report(key, "неизвестный ключ %q в %s (возможно, имелся в виду run-name)", key.Value, where)
+			continue
+		}
+		report(key, "неизвестный ключ %q в %s", key.Value, where)
+	}
+}
+
+func checkStepRefs(n *yaml.Node, ids map[string]bool, report func(*yaml.Node, string, ...any)) {
+	if n.Kind == yaml.ScalarNode {
+		for _, m := range stepRefPattern.FindAllStringSubmatch(n.Value, -1) {
+			if !ids[m[1]] {
+				report(n, "ссылка на неизвестный или более поздний шаг %q", m[1])
+			}
+		}
+		return
+	}
+	for _, c := range n.Content {
+		checkStepRefs(c, ids, report)
+	}
+}
+
+func checkExpressions(n *yaml.Node, report func(*yaml.Node, string, ...any)) {
+	if n.Kind == yaml.ScalarNode {
+		for rest := n.Value; ; {
+			_, expr, ok := strings.Cut(rest, "${{")
+			if !ok {
+				break
+			}
+			if _, rest, ok = strings.Cut(expr, "}}"); !ok {
+				report(n, "незакрытое выражение ${{ }}")
+				break
+			}
+		}
+		return
+	}
+	for _, c := range n.Content {
+		checkExpressions(c, report)
+	}
+}
+
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	if n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/RastBast/docs12121/pkg/config"
+)
+
+const metricsStepTemplate = `      - name: Push metrics
+        if: ${{ !inputs.dry_run && steps.origin.outputs.publish == 'true' }}
+        continue-on-error: true
+        env:
+%s        run: |
+          go run %s metrics \
+            --repo "${{ github.repository }}" \
+            --branch "$BRANCH_NAME" \
+            --spec "${{ steps.repo_info.outputs.repo_name }}" \
+            --timestamp "${{ github.event.head_commit.timestamp }}" \
+            "$SPEC_PATH"
+`
+
+func MetricsStep(cfg config.Config) (string, error) {
+	m := cfg.Metrics
+	if !m.Enabled() {
+		return "", nil
+	}
+	if err := m.Validate(); err != nil {
+		return "", err
+	}
+	var env strings.Builder
+	for _, v := range []struct{ key, value string }{
+		{"METRICS_BACKEND", m.Backend},
+		{"METRICS_URL", m.URL},
+		{"METRICS_PREFIX", m.Prefix},
+		{"METRICS_AUTH", m.Auth},
+		{"METRICS_SECRET_ENV", m.SecretEnv},
+	} {
+		if v.value != "" {
+			fmt.Fprintf(&env, "          %s: '%s'\n", v.key, strings.ReplaceAll(v.value, "'", "''"))
+		}
+	}
+	if m.SecretEnv != "" {
+		fmt.Fprintf(&env, "          %s: ${{ secrets.%s }}\n", m.SecretEnv, m.SecretEnv)
+	}
+	if m.Payload != "" {
+		env.WriteString("          METRICS_PAYLOAD: |\n")
+		for _, line := range strings.Split(strings.TrimRight(m.Payload, "\n"), "\n") {
+			env.WriteString("            " + line + "\n")
+		}
+	}
+	return fmt.Sprintf(metricsStepTemplate, env.String(), ToolRef()), nil
+}
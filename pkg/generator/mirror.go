@@ -0,0 +1,42 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/RastBast/docs12121/pkg/config"
+)
+
+var PortalPaths = []string{"index.html", "search-index.json", "assets", "static", "interactive", "history"}
+
+const mirrorRepoStep = `      - name: Mirror docs repository
+        if: ${{ !inputs.dry_run && steps.origin.outputs.publish == 'true' }}
+        run: |
+          cd docs-repo
+          git push --mirror https://${{ secrets.MIRROR_TOKEN }}@%s.git
+`
+
+const mirrorPortalStep = `      - name: Mirror docs portal
+        if: ${{ !inputs.dry_run && steps.origin.outputs.publish == 'true' }}
+        run: |
+          rm -rf portal-mirror && mkdir portal-mirror
+          for p in %s; do
+            if [ -e "docs-repo/$p" ]; then cp -r "docs-repo/$p" portal-mirror/; fi
+          done
+          cd portal-mirror
+          git init -q -b main
+          git add .
+          git -c user.name="OpenAPI Aggregator Bot" -c user.email="openapi-bot@%s" commit -q -m "Portal snapshot from ${{ gitea.repository }}"
+          git push --force https://${{ secrets.MIRROR_TOKEN }}@%s.git main
+`
+
+func MirrorStep(cfg config.Config) string {
+	switch {
+	case cfg.MirrorURL == "":
+		return ""
+	case cfg.MirrorMode == "portal":
+		return fmt.Sprintf(mirrorPortalStep, strings.Join(PortalPaths, " "), cfg.GiteaHost, cfg.MirrorURL)
+	default:
+		return fmt.Sprintf(mirrorRepoStep, cfg.MirrorURL)
+	}
+}
@@ -0,0 +1,25 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/RastBast/docs12121/pkg/config"
+)
+
+const oauthSetupTemplate = `          INIT=docs-repo/interactive/${{ steps.repo_info.outputs.repo_name }}/swagger-initializer.js
+          if [ -f "$INIT" ] && ! grep -q 'oauth2RedirectUrl' "$INIT"; then
+            sed -i 's|dom_id: .#swagger-ui.,|&\n    oauth2RedirectUrl: new URL("oauth2-redirect.html", window.location.href).href,|' "$INIT"
+            sed -i 's|//</editor-fold>|window.ui.initOAuth({ clientId: "%s", usePkceWithAuthorizationCodeGrant: true });\n  &|' "$INIT"
+          fi
+`
+
+func OAuthSetup(cfg config.Config) (string, error) {
+	if cfg.OAuthClientID == "" {
+		return "", nil
+	}
+	if strings.ContainsAny(cfg.OAuthClientID, "\"'|\\&<> \n") {
+		return "", fmt.Errorf("некорректный OAuth client_id %q", cfg.OAuthClientID)
+	}
+	return fmt.Sprintf(oauthSetupTemplate, cfg.OAuthClientID), nil
+}
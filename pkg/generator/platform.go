@@ -0,0 +1,37 @@
+package generator
+
+import "strings"
+
+const (
+	PlatformGitea  = "gitea"
+	PlatformGitHub = "github"
+	PlatformGitLab = "gitlab"
+)
+
+var Platforms = []string{PlatformGitea, PlatformGitHub, PlatformGitLab}
+
+const GitHubWorkflowDir = ".github/workflows"
+
+func WorkflowPath(platform string) string {
+	switch platform {
+	case PlatformGitHub:
+		return GitHubWorkflowDir + "/" + WorkflowFile
+	case PlatformGitLab:
+		return GitLabCIFile
+	}
+	return WorkflowDir + "/" + WorkflowFile
+}
+
+var githubContexts = strings.NewReplacer(
+	"gitea.repository", "github.repository",
+	"gitea.ref", "github.ref",
+	"gitea.event", "github.event",
+	"token: ${{ secrets.GITEA_TOKEN }}", "token: ${{ secrets.GITHUB_TOKEN }}",
+)
+
+func adaptPlatform(platform, content string) string {
+	if platform == PlatformGitHub {
+		return githubContexts.Replace(content)
+	}
+	return content
+}
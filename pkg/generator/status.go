@@ -0,0 +1,61 @@
+package generator
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/RastBast/docs12121/pkg/config"
+)
+
+const statusScript = `<script id="api-status-script">
+document.addEventListener("DOMContentLoaded", function () {
+  document.querySelectorAll(".api-status[data-health]").forEach(function (el) {
+    fetch(el.dataset.health, { mode: "no-cors", cache: "no-store" })
+      .then(function () { el.classList.add("up"); el.textContent = "● up"; })
+      .catch(function () { el.classList.add("down"); el.textContent = "● down"; });
+  });
+});
+</script>`
+
+const statusLookupTemplate = `          STATUS_URL=""
+          case "${{ steps.repo_info.outputs.repo_name }}" in
+%s          esac
+          STATUS_HTML=""
+          if [ -n "$STATUS_URL" ]; then
+            STATUS_HTML="<a class=\"api-status\" data-health=\"$STATUS_URL\" href=\"$STATUS_URL\">Status</a>"
+            if ! grep -q 'id="api-status-script"' docs-repo/index.html 2>/dev/null; then
+              cat >> docs-repo/index.html << 'STATUS'
+%s
+          STATUS
+            fi
+          fi
+`
+
+func StatusLookup(cfg config.Config) (string, error) {
+	if len(cfg.StatusPages) == 0 {
+		return "", nil
+	}
+	repos := make([]string, 0, len(cfg.StatusPages))
+	for repo := range cfg.StatusPages {
+		repos = append(repos, repo)
+	}
+	sort.Strings(repos)
+
+	var cases strings.Builder
+	for _, repo := range repos {
+		link := cfg.StatusPages[repo]
+		u, err := url.Parse(link)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || strings.ContainsAny(link, `"'<> `) {
+			return "", fmt.Errorf("некорректная ссылка на статус %s: %q", repo, link)
+		}
+		fmt.Fprintf(&cases, "            %s|%s/*) STATUS_URL='%s' ;;\n", repo, repo, link)
+	}
+
+	lines := strings.Split(statusScript, "\n")
+	for i, l := range lines {
+		lines[i] = "          " + l
+	}
+	return fmt.Sprintf(statusLookupTemplate, cases.String(), strings.Join(lines, "\n")), nil
+}
@@ -0,0 +1,30 @@
+package generator
+
+import (
+	"runtime/debug"
+	"strings"
+)
+
+const ToolModule = "github.com/RastBast/docs12121/cmd/openapi-aggregator"
+
+var Version = ""
+
+func ToolVersion() string {
+	v := Version
+	if v == "" {
+		if bi, ok := debug.ReadBuildInfo(); ok {
+			v = bi.Main.Version
+		}
+	}
+	if !strings.HasPrefix(v, "v") || strings.Contains(v, "+") {
+		return ""
+	}
+	return v
+}
+
+func ToolRef() string {
+	if v := ToolVersion(); v != "" {
+		return ToolModule + "@" + v
+	}
+	return ToolModule + "@latest"
+}
@@ -0,0 +1,100 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+type Author struct {
+	Name  string
+	Email string
+}
+
+func Run(ctx context.Context, dir string, args ...string) error {
+	_, err := Output(ctx, dir, args...)
+	return err
+}
+
+func Output(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %v: %s", args[0], err, strings.TrimSpace(string(out)))
+	}
+	return string(out), nil
+}
+
+func Commit(ctx context.Context, dir string, author Author, message string) error {
+	return Run(ctx, dir, "-c", "user.name="+author.Name, "-c", "user.email="+author.Email, "commit", "-q", "-m", message)
+}
+
+func PushRebased(ctx context.Context, dir string, author Author) error {
+	var err error
+	for attempt := 0; attempt < 3; attempt++ {
+		if err = Run(ctx, dir, "-c", "user.name="+author.Name, "-c", "user.email="+author.Email, "pull", "-q", "--rebase"); err != nil {
+			Run(ctx, dir, "rebase", "--abort")
+			return err
+		}
+		if err = Run(ctx, dir, "push", "-q"); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+func MirrorRepo(ctx context.Context, dir, remote string) error {
+	return Run(ctx, dir, "push", "--mirror", remote)
+}
+
+func MirrorPaths(ctx context.Context, dir, remote string, paths []string, author Author, message string) error {
+	tmp, err := os.MkdirTemp("", "portal-mirror-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	for _, p := range paths {
+		src := filepath.Join(dir, p)
+		if _, err := os.Stat(src); os.IsNotExist(err) {
+			continue
+		}
+		if err := CopyTree(src, filepath.Join(tmp, p)); err != nil {
+			return fmt.Errorf("копирование %s: %w", p, err)
+		}
+	}
+
+	if err := Run(ctx, tmp, "init", "-q", "-b", "main"); err != nil {
+		return err
+	}
+	if err := Run(ctx, tmp, "add", "."); err != nil {
+		return err
+	}
+	if err := Commit(ctx, tmp, author, message); err != nil {
+		return err
+	}
+	return Run(ctx, tmp, "push", "--force", remote, "main")
+}
+
+func CopyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(src, path)
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0o755)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, 0o644)
+	})
+}
@@ -0,0 +1,91 @@
+package gitea
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type Cache struct {
+	Dir string
+	TTL time.Duration
+}
+
+type cacheEntry struct {
+	Status       int       `json:"status"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	Stored       time.Time `json:"stored"`
+	Body         []byte    `json:"body"`
+}
+
+func NewCache(dir string, ttl time.Duration) (*Cache, error) {
+	if dir == "" {
+		base, err := os.UserCacheDir()
+		if err != nil {
+			return nil, err
+		}
+		dir = filepath.Join(base, "openapi-aggregator", "gitea")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &Cache{Dir: dir, TTL: ttl}, nil
+}
+
+// DefaultCache, when set, is used by every client created by NewClient.
+var DefaultCache *Cache
+
+func (c *Cache) key(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	return filepath.Join(c.Dir, hex.EncodeToString(h.Sum(nil))+".json")
+}
+
+func (c *Cache) load(key string) (*cacheEntry, bool) {
+	data, err := os.ReadFile(key)
+	if err != nil {
+		return nil, false
+	}
+	var e cacheEntry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, false
+	}
+	return &e, true
+}
+
+func (c *Cache) store(key string, e *cacheEntry) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	tmp := key + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return
+	}
+	os.Rename(tmp, key)
+}
+
+func (e *cacheEntry) fresh(ttl time.Duration) bool {
+	return time.Since(e.Stored) < ttl
+}
+
+func (e *cacheEntry) revalidate(req *http.Request) {
+	if e.ETag != "" {
+		req.Header.Set("If-None-Match", e.ETag)
+	}
+	if e.LastModified != "" {
+		req.Header.Set("If-Modified-Since", e.LastModified)
+	}
+}
+
+func cacheable(status int) bool {
+	return status == http.StatusOK || status == http.StatusNotFound
+}
@@ -0,0 +1,400 @@
+package gitea
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+type Client struct {
+	baseURL  string
+	token    string
+	username string
+	password string
+	http     *http.Client
+	cache    *Cache
+}
+
+type APIError struct {
+	Method string
+	Path   string
+	Status int
+	Body   string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.Path, e.Status, e.Body)
+}
+
+func (c *Client) SetBasicAuth(username, password string) {
+	c.username, c.password = username, password
+}
+
+func NewClient(baseURL, token string) *Client {
+	if !strings.Contains(baseURL, "://") {
+		baseURL = "https://" + baseURL
+	}
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+		http:    &http.Client{Timeout: 30 * time.Second, Transport: Transport},
+		cache:   DefaultCache,
+	}
+}
+
+func (c *Client) Do(ctx context.Context, method, path string, in, out any) error {
+	return c.do(ctx, method, path, in, out, true)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, in, out any, useCache bool) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+"/api/v1"+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	c.authorize(req)
+
+	var key string
+	var cached *cacheEntry
+	if c.cache != nil && method == http.MethodGet {
+		key = c.cacheKey(path)
+		if e, ok := c.cache.load(key); ok {
+			if useCache && e.fresh(c.cache.TTL) {
+				return decode(method, path, e.Status, e.Body, out)
+			}
+			cached = e
+			e.revalidate(req)
+		}
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if cached != nil && resp.StatusCode == http.StatusNotModified {
+		cached.Stored = time.Now()
+		c.cache.store(key, cached)
+		return decode(method, path, cached.Status, cached.Body, out)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if key != "" && cacheable(resp.StatusCode) {
+		c.cache.store(key, &cacheEntry{
+			Status:       resp.StatusCode,
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+			Stored:       time.Now(),
+			Body:         data,
+		})
+	}
+	return decode(method, path, resp.StatusCode, data, out)
+}
+
+func (c *Client) authorize(req *http.Request) {
+	switch {
+	case c.token != "":
+		req.Header.Set("Authorization", "token "+c.token)
+	case c.username != "":
+		req.SetBasicAuth(c.username, c.password)
+	}
+}
+
+func (c *Client) cacheKey(path string) string {
+	return c.cache.key(c.baseURL, path, c.token, c.username)
+}
+
+func (c *Client) invalidate(paths ...string) {
+	if c.cache == nil {
+		return
+	}
+	for _, p := range paths {
+		os.Remove(c.cacheKey(p))
+	}
+}
+
+func decode(method, path string, status int, data []byte, out any) error {
+	if status >= 300 {
+		if len(data) > 1024 {
+			data = data[:1024]
+		}
+		return &APIError{Method: method, Path: path, Status: status, Body: strings.TrimSpace(string(data))}
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+func (c *Client) Version(ctx context.Context) (string, error) {
+	var v struct {
+		Version string `json:"version"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/version", nil, &v, false); err != nil {
+		return "", err
+	}
+	return v.Version, nil
+}
+
+func (c *Client) CreateOrg(ctx context.Context, name string) error {
+	return c.Do(ctx, http.MethodPost, "/orgs", map[string]any{"username": name, "visibility": "public"}, nil)
+}
+
+func (c *Client) CreateRepo(ctx context.Context, org, name string) error {
+	return c.Do(ctx, http.MethodPost, "/orgs/"+url.PathEscape(org)+"/repos", map[string]any{
+		"name":           name,
+		"auto_init":      true,
+		"default_branch": "main",
+	}, nil)
+}
+
+type fileContent struct {
+	SHA     string `json:"sha"`
+	Content string `json:"content"`
+}
+
+type Repository struct {
+	Name          string `json:"name"`
+	FullName      string `json:"full_name"`
+	DefaultBranch string `json:"default_branch"`
+	Archived      bool   `json:"archived"`
+	Fork          bool   `json:"fork"`
+}
+
+func (c *Client) ListRepos(ctx context.Context, org string) ([]Repository, error) {
+	const limit = 50
+	var all []Repository
+	for page := 1; ; page++ {
+		var repos []Repository
+		p := fmt.Sprintf("/orgs/%s/repos?page=%d&limit=%d", url.PathEscape(org), page, limit)
+		if err := c.Do(ctx, http.MethodGet, p, nil, &repos); err != nil {
+			return nil, err
+		}
+		all = append(all, repos...)
+		if len(repos) < limit {
+			return all, nil
+		}
+	}
+}
+
+func contentsPath(owner, repo, path, ref string) string {
+	p := fmt.Sprintf("/repos/%s/%s/contents/%s", url.PathEscape(owner), url.PathEscape(repo), path)
+	if ref != "" {
+		p += "?ref=" + url.QueryEscape(ref)
+	}
+	return p
+}
+
+func (c *Client) GetFile(ctx context.Context, owner, repo, path, ref string) ([]byte, string, error) {
+	return c.getFile(ctx, owner, repo, path, ref, true)
+}
+
+func (c *Client) getFile(ctx context.Context, owner, repo, path, ref string, useCache bool) ([]byte, string, error) {
+	var f fileContent
+	if err := c.do(ctx, http.MethodGet, contentsPath(owner, repo, path, ref), nil, &f, useCache); err != nil {
+		return nil, "", err
+	}
+	data, err := base64.StdEncoding.DecodeString(f.Content)
+	return data, f.SHA, err
+}
+
+func (c *Client) FileExists(ctx context.Context, owner, repo, path, ref string) (bool, error) {
+	_, _, err := c.GetFile(ctx, owner, repo, path, ref)
+	var apiErr *APIError
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound:
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+func (c *Client) PutFile(ctx context.Context, owner, repo, path, branch, message string, content []byte) error {
+	p := contentsPath(owner, repo, path, "")
+	defer c.invalidate(p, contentsPath(owner, repo, path, branch))
+	req := map[string]any{
+		"content": base64.StdEncoding.EncodeToString(content),
+		"message": message,
+	}
+	if branch != "" {
+		req["branch"] = branch
+	}
+
+	_, sha, err := c.getFile(ctx, owner, repo, path, branch, false)
+	var apiErr *APIError
+	switch {
+	case err == nil:
+		req["sha"] = sha
+		return c.Do(ctx, http.MethodPut, p, req, nil)
+	case errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound:
+		return c.Do(ctx, http.MethodPost, p, req, nil)
+	default:
+		return err
+	}
+}
+
+func (c *Client) GetRepo(ctx context.Context, owner, repo string) (*Repository, error) {
+	var r Repository
+	if err := c.Do(ctx, http.MethodGet, fmt.Sprintf("/repos/%s/%s", url.PathEscape(owner), url.PathEscape(repo)), nil, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func (c *Client) CreateBranch(ctx context.Context, owner, repo, name, from string) error {
+	err := c.Do(ctx, http.MethodPost, fmt.Sprintf("/repos/%s/%s/branches", url.PathEscape(owner), url.PathEscape(repo)), map[string]any{
+		"new_branch_name": name,
+		"old_branch_name": from,
+	}, nil)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Status == http.StatusConflict {
+		return nil
+	}
+	return err
+}
+
+type Branch struct {
+	Name   string `json:"name"`
+	Commit struct {
+		ID        string    `json:"id"`
+		Timestamp time.Time `json:"timestamp"`
+	} `json:"commit"`
+}
+
+func (c *Client) ListBranches(ctx context.Context, owner, repo string) ([]Branch, error) {
+	const limit = 50
+	var all []Branch
+	for page := 1; ; page++ {
+		var branches []Branch
+		p := fmt.Sprintf("/repos/%s/%s/branches?page=%d&limit=%d", url.PathEscape(owner), url.PathEscape(repo), page, limit)
+		if err := c.Do(ctx, http.MethodGet, p, nil, &branches); err != nil {
+			return nil, err
+		}
+		all = append(all, branches...)
+		if len(branches) < limit {
+			return all, nil
+		}
+	}
+}
+
+type Commit struct {
+	SHA    string `json:"sha"`
+	Commit struct {
+		Committer struct {
+			Date time.Time `json:"date"`
+		} `json:"committer"`
+	} `json:"commit"`
+	Files []struct {
+		Filename string `json:"filename"`
+	} `json:"files"`
+}
+
+func (c *Client) LastCommit(ctx context.Context, owner, repo, ref, path string) (*Commit, error) {
+	q := url.Values{"limit": {"1"}, "stat": {"false"}, "verification": {"false"}, "files": {"false"}}
+	if ref != "" {
+		q.Set("sha", ref)
+	}
+	if path != "" {
+		q.Set("path", path)
+	}
+	var commits []Commit
+	if err := c.Do(ctx, http.MethodGet, fmt.Sprintf("/repos/%s/%s/commits?%s", url.PathEscape(owner), url.PathEscape(repo), q.Encode()), nil, &commits); err != nil {
+		return nil, err
+	}
+	if len(commits) == 0 {
+		return nil, nil
+	}
+	return &commits[0], nil
+}
+
+func (c *Client) ChangedFiles(ctx context.Context, owner, repo, base, head string) ([]string, error) {
+	var compare struct {
+		Commits []Commit `json:"commits"`
+	}
+	path := fmt.Sprintf("/repos/%s/%s/compare/%s...%s", url.PathEscape(owner), url.PathEscape(repo), url.PathEscape(base), url.PathEscape(head))
+	if err := c.Do(ctx, http.MethodGet, path, nil, &compare); err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, commit := range compare.Commits {
+		for _, f := range commit.Files {
+			if !slices.Contains(files, f.Filename) {
+				files = append(files, f.Filename)
+			}
+		}
+	}
+	return files, nil
+}
+
+type PullRequest struct {
+	Number  int      `json:"number"`
+	HTMLURL string   `json:"html_url"`
+	Head    PRBranch `json:"head"`
+}
+
+type PRBranch struct {
+	Ref string `json:"ref"`
+}
+
+func pullsPath(owner, repo string) string {
+	return fmt.Sprintf("/repos/%s/%s/pulls", url.PathEscape(owner), url.PathEscape(repo))
+}
+
+func (c *Client) ListPullRequests(ctx context.Context, owner, repo string) ([]PullRequest, error) {
+	const limit = 50
+	var all []PullRequest
+	for page := 1; ; page++ {
+		var prs []PullRequest
+		p := fmt.Sprintf("%s?state=open&page=%d&limit=%d", pullsPath(owner, repo), page, limit)
+		if err := c.do(ctx, http.MethodGet, p, nil, &prs, false); err != nil {
+			return nil, err
+		}
+		all = append(all, prs...)
+		if len(prs) < limit {
+			return all, nil
+		}
+	}
+}
+
+func (c *Client) ClosePullRequest(ctx context.Context, owner, repo string, number int) error {
+	return c.do(ctx, http.MethodPatch, fmt.Sprintf("%s/%d", pullsPath(owner, repo), number), map[string]string{"state": "closed"}, nil, false)
+}
+
+func (c *Client) CreatePullRequest(ctx context.Context, owner, repo, head, base, title, body string) (*PullRequest, error) {
+	var pr PullRequest
+	err := c.Do(ctx, http.MethodPost, pullsPath(owner, repo), map[string]any{
+		"head":  head,
+		"base":  base,
+		"title": title,
+		"body":  body,
+	}, &pr)
+	if err != nil {
+		return nil, err
+	}
+	return &pr, nil
+}
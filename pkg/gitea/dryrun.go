@@ -0,0 +1,38 @@
+package gitea
+
+import (
+	"io"
+	"net/http"
+	"strings"
+)
+
+type DryRun struct {
+	Next http.RoundTripper
+	Log  func(method, path string)
+}
+
+func (d *DryRun) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method == http.MethodGet || req.Method == http.MethodHead {
+		next := d.Next
+		if next == nil {
+			next = http.DefaultTransport
+		}
+		return next.RoundTrip(req)
+	}
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	if d.Log != nil {
+		d.Log(req.Method, req.URL.RequestURI())
+	}
+	return &http.Response{
+		Status:     "204 No Content",
+		StatusCode: http.StatusNoContent,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader("")),
+		Request:    req,
+	}, nil
+}
@@ -0,0 +1,244 @@
+package gitea
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+var Transport http.RoundTripper
+
+type Fixtures struct {
+	Dir    string
+	Record bool
+	Next   http.RoundTripper
+
+	mu   sync.Mutex
+	seen map[string]int
+}
+
+type fixture struct {
+	Method      string `json:"method"`
+	Path        string `json:"path"`
+	Request     string `json:"request,omitempty"`
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        string `json:"body"`
+}
+
+func (f *Fixtures) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		data, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		reqBody = data
+		req.Body = io.NopCloser(bytes.NewReader(data))
+	}
+	path := redactQuery(req.URL)
+	recorded := redactBody(path, reqBody)
+	name := f.name(req.Method, path, recorded)
+
+	if f.Record {
+		return f.record(req, name, path, recorded)
+	}
+	fx, err := f.load(name)
+	if err != nil {
+		return nil, fmt.Errorf("нет записи для %s %s в %s: %w", req.Method, path, f.Dir, err)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", fx.Status, http.StatusText(fx.Status)),
+		StatusCode:    fx.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {fx.ContentType}},
+		Body:          io.NopCloser(strings.NewReader(fx.Body)),
+		ContentLength: int64(len(fx.Body)),
+		Request:       req,
+	}, nil
+}
+
+// secretFields are request body fields whose values never reach the fixtures
+// directory. The data field carries the value only for Actions secrets.
+var secretFields = []string{"secret", "password", "token", "authorization_header"}
+
+const redacted = "REDACTED"
+
+// redactBody replaces secret values in a JSON request body. Fixture names are
+// derived from the redacted body too, so replay matches whatever secret the
+// run uses.
+func redactBody(path string, body []byte) []byte {
+	var v any
+	if len(body) == 0 || json.Unmarshal(body, &v) != nil {
+		return body
+	}
+	fields := secretFields
+	if strings.Contains(path, "/actions/secrets/") {
+		fields = append(slices.Clone(fields), "data")
+	}
+	if !redactFields(v, fields) {
+		return body
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return body
+	}
+	return data
+}
+
+func redactFields(v any, fields []string) bool {
+	changed := false
+	switch v := v.(type) {
+	case map[string]any:
+		for k, item := range v {
+			if _, ok := item.(string); ok && slices.Contains(fields, strings.ToLower(k)) {
+				v[k] = redacted
+				changed = true
+			} else if redactFields(item, fields) {
+				changed = true
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if redactFields(item, fields) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// redactQuery returns the request URI with token parameters hidden; the
+// Authorization header is never recorded.
+func redactQuery(u *url.URL) string {
+	q := u.Query()
+	changed := false
+	for _, key := range []string{"token", "access_token"} {
+		if q.Has(key) {
+			q.Set(key, redacted)
+			changed = true
+		}
+	}
+	if !changed {
+		return u.RequestURI()
+	}
+	c := *u
+	c.RawQuery = q.Encode()
+	return c.RequestURI()
+}
+
+func (f *Fixtures) name(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(path))
+	h.Write([]byte{0})
+	h.Write(body)
+	sum := hex.EncodeToString(h.Sum(nil))[:12]
+
+	slug := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '.' {
+			return r
+		}
+		return '_'
+	}, strings.TrimPrefix(strings.SplitN(path, "?", 2)[0], "/api/v1/"))
+	if len(slug) > 80 {
+		slug = slug[:80]
+	}
+	base := strings.ToLower(method) + "_" + slug + "_" + sum
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.seen == nil {
+		f.seen = map[string]int{}
+	}
+	f.seen[base]++
+	if n := f.seen[base]; n > 1 {
+		return fmt.Sprintf("%s~%d", base, n)
+	}
+	return base
+}
+
+func (f *Fixtures) load(name string) (*fixture, error) {
+	data, err := os.ReadFile(filepath.Join(f.Dir, name+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		if base, _, ok := strings.Cut(name, "~"); ok {
+			data, err = f.latest(base)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	var fx fixture
+	if err := json.Unmarshal(data, &fx); err != nil {
+		return nil, err
+	}
+	return &fx, nil
+}
+
+func (f *Fixtures) latest(base string) ([]byte, error) {
+	var data []byte
+	err := os.ErrNotExist
+	for n := 1; ; n++ {
+		name := base
+		if n > 1 {
+			name = fmt.Sprintf("%s~%d", base, n)
+		}
+		d, e := os.ReadFile(filepath.Join(f.Dir, name+".json"))
+		if e != nil {
+			return data, err
+		}
+		data, err = d, nil
+	}
+}
+
+func (f *Fixtures) record(req *http.Request, name, path string, reqBody []byte) (*http.Response, error) {
+	next := f.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	fx := fixture{
+		Method:      req.Method,
+		Path:        path,
+		Request:     string(reqBody),
+		Status:      resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+		Body:        string(body),
+	}
+	data, err := json.MarshalIndent(fx, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(f.Dir, 0o755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(f.Dir, name+".json"), append(data, '\n'), 0o644); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
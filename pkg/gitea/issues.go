@@ -0,0 +1,59 @@
+package gitea
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+type Issue struct {
+	Number  int    `json:"number"`
+	Title   string `json:"title"`
+	State   string `json:"state"`
+	HTMLURL string `json:"html_url"`
+}
+
+func issuesPath(owner, repo string) string {
+	return fmt.Sprintf("/repos/%s/%s/issues", url.PathEscape(owner), url.PathEscape(repo))
+}
+
+func (c *Client) CreateIssue(ctx context.Context, owner, repo, title, body string, assignees ...string) (*Issue, error) {
+	in := map[string]any{
+		"title": title,
+		"body":  body,
+	}
+	if len(assignees) > 0 {
+		in["assignees"] = assignees
+	}
+	var issue Issue
+	if err := c.do(ctx, http.MethodPost, issuesPath(owner, repo), in, &issue, false); err != nil {
+		return nil, err
+	}
+	return &issue, nil
+}
+
+// FindOpenIssue returns the open issue with exactly this title, or nil.
+func (c *Client) FindOpenIssue(ctx context.Context, owner, repo, title string) (*Issue, error) {
+	q := url.Values{"state": {"open"}, "type": {"issues"}, "q": {title}, "limit": {"50"}}
+	var issues []Issue
+	if err := c.do(ctx, http.MethodGet, issuesPath(owner, repo)+"?"+q.Encode(), nil, &issues, false); err != nil {
+		return nil, err
+	}
+	for i := range issues {
+		if issues[i].Title == title {
+			return &issues[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (c *Client) CommentIssue(ctx context.Context, owner, repo string, number int, body string) error {
+	path := fmt.Sprintf("%s/%d/comments", issuesPath(owner, repo), number)
+	return c.do(ctx, http.MethodPost, path, map[string]string{"body": body}, nil, false)
+}
+
+func (c *Client) CloseIssue(ctx context.Context, owner, repo string, number int) error {
+	path := fmt.Sprintf("%s/%d", issuesPath(owner, repo), number)
+	return c.do(ctx, http.MethodPatch, path, map[string]string{"state": "closed"}, nil, false)
+}
@@ -0,0 +1,86 @@
+package gitea
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+type Label struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type Milestone struct {
+	ID    int64  `json:"id"`
+	Title string `json:"title"`
+	State string `json:"state"`
+}
+
+func (c *Client) listLabels(ctx context.Context, path string) ([]Label, error) {
+	const limit = 50
+	var all []Label
+	for page := 1; ; page++ {
+		var labels []Label
+		if err := c.Do(ctx, http.MethodGet, fmt.Sprintf("%s?page=%d&limit=%d", path, page, limit), nil, &labels); err != nil {
+			return nil, err
+		}
+		all = append(all, labels...)
+		if len(labels) < limit {
+			return all, nil
+		}
+	}
+}
+
+func (c *Client) LabelIDs(ctx context.Context, owner, repo string, names []string) (ids []int64, missing []string, err error) {
+	labels, err := c.listLabels(ctx, fmt.Sprintf("/repos/%s/%s/labels", url.PathEscape(owner), url.PathEscape(repo)))
+	if err != nil {
+		return nil, nil, err
+	}
+	orgLabels, err := c.listLabels(ctx, fmt.Sprintf("/orgs/%s/labels", url.PathEscape(owner)))
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound {
+		err = nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	byName := map[string]int64{}
+	for _, l := range append(orgLabels, labels...) {
+		byName[l.Name] = l.ID
+	}
+	for _, name := range names {
+		if id, ok := byName[name]; ok {
+			ids = append(ids, id)
+		} else {
+			missing = append(missing, name)
+		}
+	}
+	return ids, missing, nil
+}
+
+func (c *Client) FindMilestone(ctx context.Context, owner, repo, title string) (*Milestone, error) {
+	var milestones []Milestone
+	path := fmt.Sprintf("/repos/%s/%s/milestones?state=all&name=%s", url.PathEscape(owner), url.PathEscape(repo), url.QueryEscape(title))
+	if err := c.Do(ctx, http.MethodGet, path, nil, &milestones); err != nil {
+		return nil, err
+	}
+	for _, m := range milestones {
+		if m.Title == title {
+			return &m, nil
+		}
+	}
+	return nil, nil
+}
+
+func (c *Client) AddLabels(ctx context.Context, owner, repo string, number int, ids []int64) error {
+	path := fmt.Sprintf("%s/%d/labels", issuesPath(owner, repo), number)
+	return c.do(ctx, http.MethodPost, path, map[string]any{"labels": ids}, nil, false)
+}
+
+func (c *Client) SetMilestone(ctx context.Context, owner, repo string, number int, id int64) error {
+	path := fmt.Sprintf("%s/%d", issuesPath(owner, repo), number)
+	return c.do(ctx, http.MethodPatch, path, map[string]any{"milestone": id}, nil, false)
+}
@@ -0,0 +1,86 @@
+package gitea
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+)
+
+type Release struct {
+	ID      int64        `json:"id"`
+	TagName string       `json:"tag_name"`
+	Name    string       `json:"name"`
+	Assets  []Attachment `json:"assets"`
+}
+
+type Attachment struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Size        int64  `json:"size"`
+	DownloadURL string `json:"browser_download_url"`
+}
+
+func (c *Client) GetReleaseByTag(ctx context.Context, owner, repo, tag string) (*Release, error) {
+	var r Release
+	p := fmt.Sprintf("/repos/%s/%s/releases/tags/%s", url.PathEscape(owner), url.PathEscape(repo), url.PathEscape(tag))
+	if err := c.do(ctx, http.MethodGet, p, nil, &r, false); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func (c *Client) DeleteReleaseAttachment(ctx context.Context, owner, repo string, release, id int64) error {
+	p := fmt.Sprintf("/repos/%s/%s/releases/%d/assets/%d", url.PathEscape(owner), url.PathEscape(repo), release, id)
+	return c.do(ctx, http.MethodDelete, p, nil, nil, false)
+}
+
+func (c *Client) RenameReleaseAttachment(ctx context.Context, owner, repo string, release, id int64, name string) (*Attachment, error) {
+	var a Attachment
+	p := fmt.Sprintf("/repos/%s/%s/releases/%d/assets/%d", url.PathEscape(owner), url.PathEscape(repo), release, id)
+	if err := c.do(ctx, http.MethodPatch, p, map[string]string{"name": name}, &a, false); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (c *Client) UploadReleaseAttachment(ctx context.Context, owner, repo string, release int64, name string, content []byte) (*Attachment, error) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("attachment", name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write(content); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	p := fmt.Sprintf("/repos/%s/%s/releases/%d/assets?name=%s", url.PathEscape(owner), url.PathEscape(repo), release, url.QueryEscape(name))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/v1"+p, &body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	c.authorize(req)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var a Attachment
+	if err := decode(http.MethodPost, p, resp.StatusCode, data, &a); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
@@ -0,0 +1,38 @@
+package gitea
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type Secret struct {
+	Name    string    `json:"name"`
+	Created time.Time `json:"created_at"`
+}
+
+func secretsPath(owner, repo string) string {
+	return fmt.Sprintf("/repos/%s/%s/actions/secrets", url.PathEscape(owner), url.PathEscape(repo))
+}
+
+func (c *Client) ListSecrets(ctx context.Context, owner, repo string) ([]Secret, error) {
+	const limit = 50
+	var all []Secret
+	for page := 1; ; page++ {
+		var secrets []Secret
+		p := fmt.Sprintf("%s?page=%d&limit=%d", secretsPath(owner, repo), page, limit)
+		if err := c.do(ctx, http.MethodGet, p, nil, &secrets, false); err != nil {
+			return nil, err
+		}
+		all = append(all, secrets...)
+		if len(secrets) < limit {
+			return all, nil
+		}
+	}
+}
+
+func (c *Client) PutSecret(ctx context.Context, owner, repo, name, value string) error {
+	return c.do(ctx, http.MethodPut, secretsPath(owner, repo)+"/"+url.PathEscape(name), map[string]string{"data": value}, nil, false)
+}
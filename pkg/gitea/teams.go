@@ -0,0 +1,42 @@
+package gitea
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+type Team struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type User struct {
+	Login string `json:"login"`
+}
+
+func (c *Client) TeamMembers(ctx context.Context, org, team string) ([]string, error) {
+	var found struct {
+		Data []Team `json:"data"`
+	}
+	path := fmt.Sprintf("/orgs/%s/teams/search?q=%s", url.PathEscape(org), url.QueryEscape(team))
+	if err := c.do(ctx, http.MethodGet, path, nil, &found, true); err != nil {
+		return nil, err
+	}
+	for _, t := range found.Data {
+		if t.Name != team {
+			continue
+		}
+		var members []User
+		if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/teams/%d/members", t.ID), nil, &members, true); err != nil {
+			return nil, err
+		}
+		logins := make([]string, len(members))
+		for i, m := range members {
+			logins[i] = m.Login
+		}
+		return logins, nil
+	}
+	return nil, &APIError{Method: http.MethodGet, Path: path, Status: http.StatusNotFound, Body: "team " + team + " not found"}
+}
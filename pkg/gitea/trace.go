@@ -0,0 +1,28 @@
+package gitea
+
+import (
+	"net/http"
+	"time"
+)
+
+type Trace struct {
+	Next http.RoundTripper
+	Log  func(method, path string, status int, elapsed time.Duration, err error)
+}
+
+func (t *Trace) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	start := time.Now()
+	resp, err := next.RoundTrip(req)
+	if t.Log != nil {
+		status := 0
+		if resp != nil {
+			status = resp.StatusCode
+		}
+		t.Log(req.Method, req.URL.RequestURI(), status, time.Since(start), err)
+	}
+	return resp, err
+}
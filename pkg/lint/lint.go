@@ -0,0 +1,267 @@
+package lint
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/RastBast/docs12121/pkg/spec"
+)
+
+const (
+	LevelError   = "error"
+	LevelWarning = "warning"
+	LevelInfo    = "info"
+	LevelOff     = "off"
+)
+
+var Levels = []string{LevelError, LevelWarning, LevelInfo, LevelOff}
+
+var styles = map[string]*regexp.Regexp{
+	"camelCase":  regexp.MustCompile(`^[a-z][a-zA-Z0-9]*$`),
+	"PascalCase": regexp.MustCompile(`^[A-Z][a-zA-Z0-9]*$`),
+	"snake_case": regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`),
+	"kebab-case": regexp.MustCompile(`^[a-z][a-z0-9]*(-[a-z0-9]+)*$`),
+}
+
+type Finding struct {
+	Repo     string `json:"repo"`
+	Location string `json:"location"`
+	Level    string `json:"level"`
+	Rule     string `json:"rule"`
+	Message  string `json:"message"`
+}
+
+type RuleConfig struct {
+	Level string `yaml:"level"`
+	Style string `yaml:"style"`
+}
+
+func (r *RuleConfig) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		r.Level = n.Value
+		return nil
+	}
+	type plain RuleConfig
+	return n.Decode((*plain)(r))
+}
+
+type Ruleset map[string]RuleConfig
+
+type Rule struct {
+	Name        string
+	Description string
+	Default     RuleConfig
+	check       func(c *checker, cfg RuleConfig)
+}
+
+var Rules = []Rule{
+	{"operation-operationid", "у каждой операции есть operationId", RuleConfig{Level: LevelError}, checkOperationID},
+	{"operation-operationid-unique", "operationId не повторяются", RuleConfig{Level: LevelError}, checkOperationIDUnique},
+	{"operation-description", "у каждой операции есть описание (description)", RuleConfig{Level: LevelWarning}, checkOperationDescription},
+	{"operation-tags", "у каждой операции есть хотя бы один тег", RuleConfig{Level: LevelInfo}, checkOperationTags},
+	{"no-unused-schemas", "все схемы из components.schemas используются", RuleConfig{Level: LevelWarning}, checkUnusedSchemas},
+	{"operationid-case", "стиль имён operationId", RuleConfig{Level: LevelWarning, Style: "camelCase"}, checkOperationIDCase},
+	{"schema-name-case", "стиль имён схем", RuleConfig{Level: LevelWarning, Style: "PascalCase"}, checkSchemaNameCase},
+	{"path-case", "стиль сегментов путей", RuleConfig{Level: LevelWarning, Style: "kebab-case"}, checkPathCase},
+	{"property-case", "стиль имён свойств схем", RuleConfig{Level: LevelOff, Style: "camelCase"}, checkPropertyCase},
+}
+
+func Default() Ruleset {
+	rs := Ruleset{}
+	for _, r := range Rules {
+		rs[r.Name] = r.Default
+	}
+	return rs
+}
+
+func Load(path string) (Ruleset, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Rules map[string]RuleConfig `yaml:"rules"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("разбор %s: %w", path, err)
+	}
+	rs := Default()
+	for name, cfg := range file.Rules {
+		def, ok := rs[name]
+		if !ok {
+			return nil, fmt.Errorf("%s: неизвестное правило %q (доступны: %s)", path, name, strings.Join(ruleNames(), ", "))
+		}
+		if cfg.Level == "" {
+			cfg.Level = def.Level
+		}
+		if cfg.Style == "" {
+			cfg.Style = def.Style
+		}
+		if !slices.Contains(Levels, cfg.Level) {
+			return nil, fmt.Errorf("%s: %s: неизвестный уровень %q (доступны: %s)", path, name, cfg.Level, strings.Join(Levels, ", "))
+		}
+		if def.Style != "" && styles[cfg.Style] == nil {
+			return nil, fmt.Errorf("%s: %s: неизвестный стиль %q (доступны: %s)", path, name, cfg.Style, strings.Join(styleNames(), ", "))
+		}
+		rs[name] = cfg
+	}
+	return rs, nil
+}
+
+func Check(repo string, data []byte, rs Ruleset) ([]Finding, error) {
+	doc, err := spec.ParseDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	c := &checker{repo: repo, data: data, doc: doc}
+	for _, r := range Rules {
+		cfg, ok := rs[r.Name]
+		if !ok {
+			cfg = r.Default
+		}
+		if cfg.Level == LevelOff {
+			continue
+		}
+		c.rule, c.level = r.Name, cfg.Level
+		r.check(c, cfg)
+		if c.err != nil {
+			return nil, c.err
+		}
+	}
+	return c.findings, nil
+}
+
+type checker struct {
+	repo     string
+	data     []byte
+	doc      *spec.Document
+	rule     string
+	level    string
+	findings []Finding
+	err      error
+}
+
+func (c *checker) report(location, format string, args ...any) {
+	c.findings = append(c.findings, Finding{Repo: c.repo, Location: location, Level: c.level, Rule: c.rule, Message: fmt.Sprintf(format, args...)})
+}
+
+func checkOperationID(c *checker, _ RuleConfig) {
+	for _, op := range c.doc.Operations() {
+		if op.Operation.OperationID == "" {
+			c.report(op.String(), "нет operationId")
+		}
+	}
+}
+
+func checkOperationIDUnique(c *checker, _ RuleConfig) {
+	seen := map[string]string{}
+	for _, op := range c.doc.Operations() {
+		id := op.Operation.OperationID
+		if id == "" {
+			continue
+		}
+		if first, ok := seen[id]; ok {
+			c.report(op.String(), "operationId %q уже используется в %s", id, first)
+			continue
+		}
+		seen[id] = op.String()
+	}
+}
+
+func checkOperationDescription(c *checker, _ RuleConfig) {
+	for _, op := range c.doc.Operations() {
+		if strings.TrimSpace(op.Operation.Description) == "" {
+			c.report(op.String(), "нет описания (description)")
+		}
+	}
+}
+
+func checkOperationTags(c *checker, _ RuleConfig) {
+	for _, op := range c.doc.Operations() {
+		if len(op.Operation.Tags) == 0 {
+			c.report(op.String(), "нет тегов")
+		}
+	}
+}
+
+func checkUnusedSchemas(c *checker, _ RuleConfig) {
+	unused, err := spec.UnusedSchemas(c.data)
+	if err != nil {
+		c.err = err
+		return
+	}
+	for _, name := range unused {
+		c.report("схема "+name, "схема нигде не используется")
+	}
+}
+
+func checkOperationIDCase(c *checker, cfg RuleConfig) {
+	for _, op := range c.doc.Operations() {
+		if id := op.Operation.OperationID; id != "" && !styles[cfg.Style].MatchString(id) {
+			c.report(op.String(), "operationId %q не в стиле %s", id, cfg.Style)
+		}
+	}
+}
+
+func checkSchemaNameCase(c *checker, cfg RuleConfig) {
+	for _, name := range sortedKeys(c.doc.Components.Schemas) {
+		if !styles[cfg.Style].MatchString(name) {
+			c.report("схема "+name, "имя схемы не в стиле %s", cfg.Style)
+		}
+	}
+}
+
+func checkPathCase(c *checker, cfg RuleConfig) {
+	for _, p := range sortedKeys(c.doc.Paths) {
+		for _, segment := range strings.Split(strings.Trim(p, "/"), "/") {
+			if segment == "" || strings.HasPrefix(segment, "{") {
+				continue
+			}
+			if !styles[cfg.Style].MatchString(segment) {
+				c.report(p, "сегмент %q не в стиле %s", segment, cfg.Style)
+				break
+			}
+		}
+	}
+}
+
+func checkPropertyCase(c *checker, cfg RuleConfig) {
+	for _, name := range sortedKeys(c.doc.Components.Schemas) {
+		s := c.doc.Components.Schemas[name]
+		if s == nil {
+			continue
+		}
+		for _, prop := range sortedKeys(s.Properties) {
+			if !styles[cfg.Style].MatchString(prop) {
+				c.report("схема "+name+"."+prop, "имя свойства не в стиле %s", cfg.Style)
+			}
+		}
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func ruleNames() []string {
+	names := make([]string, len(Rules))
+	for i, r := range Rules {
+		names[i] = r.Name
+	}
+	return names
+}
+
+func styleNames() []string {
+	return sortedKeys(styles)
+}
@@ -0,0 +1,155 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/RastBast/docs12121/pkg/config"
+)
+
+const DefaultPayload = `{"repository": {{ json .Repository }}, "branch": {{ json .Branch }}, "spec": {{ json .Spec }}, "timestamp": {{ json .Timestamp }}, "file_size": {{ .FileSize }}}`
+
+var client = &http.Client{Timeout: 30 * time.Second}
+
+var unsafeName = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+type Sample struct {
+	Repository string
+	Branch     string
+	Spec       string
+	Timestamp  time.Time
+	FileSize   int64
+}
+
+type Backend interface {
+	Push(ctx context.Context, s Sample) error
+}
+
+func New(cfg config.Metrics) (Backend, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	switch cfg.Backend {
+	case config.MetricsHTTP:
+		text := cfg.Payload
+		if text == "" {
+			text = DefaultPayload
+		}
+		tmpl, err := template.New("payload").Funcs(template.FuncMap{"json": toJSON}).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("шаблон metrics.payload: %w", err)
+		}
+		return &httpBackend{cfg: cfg, payload: tmpl}, nil
+	case config.MetricsStatsD:
+		return &statsdBackend{addr: cfg.URL, prefix: cfg.PrefixOrDefault()}, nil
+	case config.MetricsPushgateway:
+		return &pushgateway{cfg: cfg}, nil
+	}
+	return nil, nil
+}
+
+func toJSON(v any) (string, error) {
+	data, err := json.Marshal(v)
+	return string(data), err
+}
+
+type httpBackend struct {
+	cfg     config.Metrics
+	payload *template.Template
+}
+
+func (b *httpBackend) Push(ctx context.Context, s Sample) error {
+	var body bytes.Buffer
+	if err := b.payload.Execute(&body, s); err != nil {
+		return fmt.Errorf("шаблон metrics.payload: %w", err)
+	}
+	contentType := "text/plain"
+	if json.Valid(body.Bytes()) {
+		contentType = "application/json"
+	}
+	return post(ctx, b.cfg, http.MethodPost, b.cfg.URL, contentType, body.Bytes())
+}
+
+type pushgateway struct {
+	cfg config.Metrics
+}
+
+func (p *pushgateway) Push(ctx context.Context, s Sample) error {
+	prefix := unsafeName.ReplaceAllString(p.cfg.PrefixOrDefault(), "_")
+	var body bytes.Buffer
+	for _, m := range []struct {
+		name  string
+		help  string
+		value any
+	}{
+		{"last_update_timestamp_seconds", "Time of the last published spec update.", s.Timestamp.Unix()},
+		{"spec_size_bytes", "Size of the published spec.", s.FileSize},
+	} {
+		name := prefix + "_" + m.name
+		fmt.Fprintf(&body, "# HELP %s %s\n# TYPE %s gauge\n%s %v\n", name, m.help, name, name, m.value)
+	}
+	target := strings.TrimSuffix(p.cfg.URL, "/") + "/metrics/job/openapi-aggregator" +
+		groupingLabel("repository", s.Repository) + groupingLabel("branch", s.Branch) + groupingLabel("spec", s.Spec)
+	return post(ctx, p.cfg, http.MethodPut, target, "text/plain; version=0.0.4", body.Bytes())
+}
+
+func groupingLabel(name, value string) string {
+	if value == "" {
+		return ""
+	}
+	return "/" + name + "@base64/" + base64.RawURLEncoding.EncodeToString([]byte(value))
+}
+
+func post(ctx context.Context, cfg config.Metrics, method, url, contentType string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	switch cfg.Auth {
+	case "bearer":
+		req.Header.Set("Authorization", "Bearer "+cfg.Secret())
+	case "basic":
+		user, password, _ := strings.Cut(cfg.Secret(), ":")
+		req.SetBasicAuth(user, password)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s: %s", method, url, resp.Status)
+	}
+	return nil
+}
+
+type statsdBackend struct {
+	addr   string
+	prefix string
+}
+
+func (b *statsdBackend) Push(ctx context.Context, s Sample) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "udp", b.addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	name := b.prefix + "." + unsafeName.ReplaceAllString(s.Spec, "_")
+	if s.Spec == "" {
+		name = b.prefix + "." + unsafeName.ReplaceAllString(s.Repository, "_")
+	}
+	lines := fmt.Sprintf("%s.updates:1|c\n%s.spec_size_bytes:%d|g\n", name, name, s.FileSize)
+	_, err = conn.Write([]byte(lines))
+	return err
+}
@@ -0,0 +1,241 @@
+package oci
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	ManifestType = "application/vnd.oci.image.manifest.v1+json"
+	EmptyType    = "application/vnd.oci.empty.v1+json"
+	ArtifactType = "application/vnd.oai.openapi"
+	SpecType     = "application/vnd.oai.openapi+yaml"
+)
+
+type Descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int               `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type Manifest struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType"`
+	ArtifactType  string            `json:"artifactType"`
+	Config        Descriptor        `json:"config"`
+	Layers        []Descriptor      `json:"layers"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+type Client struct {
+	host     string
+	username string
+	password string
+	token    string
+	http     *http.Client
+}
+
+func NewClient(host, username, password string) *Client {
+	return &Client{host: host, username: username, password: password, http: http.DefaultClient}
+}
+
+func (c *Client) PushSpec(ctx context.Context, name, filename string, data []byte, title, version string) ([]string, error) {
+	config := []byte("{}")
+	manifest := Manifest{
+		SchemaVersion: 2,
+		MediaType:     ManifestType,
+		ArtifactType:  ArtifactType,
+		Config:        Descriptor{MediaType: EmptyType, Digest: Digest(config), Size: len(config)},
+		Layers: []Descriptor{{
+			MediaType:   SpecType,
+			Digest:      Digest(data),
+			Size:        len(data),
+			Annotations: map[string]string{"org.opencontainers.image.title": filename},
+		}},
+		Annotations: map[string]string{
+			"org.opencontainers.image.title":   title,
+			"org.opencontainers.image.version": version,
+		},
+	}
+
+	if err := c.PushBlob(ctx, name, config); err != nil {
+		return nil, fmt.Errorf("загрузка конфигурации артефакта: %w", err)
+	}
+	if err := c.PushBlob(ctx, name, data); err != nil {
+		return nil, fmt.Errorf("загрузка спецификации: %w", err)
+	}
+	body, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, err
+	}
+	tags := SemverTags(version)
+	for _, tag := range tags {
+		if err := c.PushManifest(ctx, name, tag, body); err != nil {
+			return nil, fmt.Errorf("публикация тега %s: %w", tag, err)
+		}
+	}
+	return tags, nil
+}
+
+func SemverTags(version string) []string {
+	v := strings.TrimPrefix(version, "v")
+	tags := []string{v}
+	if strings.ContainsAny(v, "-+") {
+		return tags
+	}
+	parts := strings.Split(v, ".")
+	if len(parts) == 3 {
+		tags = append(tags, parts[0]+"."+parts[1], parts[0])
+	}
+	return tags
+}
+
+func Digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func (c *Client) PushBlob(ctx context.Context, name string, data []byte) error {
+	digest := Digest(data)
+	resp, err := c.do(ctx, http.MethodHead, c.url("/v2/%s/blobs/%s", name, digest), "", nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	resp, err = c.do(ctx, http.MethodPost, c.url("/v2/%s/blobs/uploads/", name), "", nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("начало загрузки: %s", resp.Status)
+	}
+	loc, err := resp.Request.URL.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return err
+	}
+	q := loc.Query()
+	q.Set("digest", digest)
+	loc.RawQuery = q.Encode()
+
+	resp, err = c.do(ctx, http.MethodPut, loc.String(), "application/octet-stream", data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("загрузка %s: %s", digest, resp.Status)
+	}
+	return nil
+}
+
+func (c *Client) PushManifest(ctx context.Context, name, tag string, body []byte) error {
+	resp, err := c.do(ctx, http.MethodPut, c.url("/v2/%s/manifests/%s", name, tag), ManifestType, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+func (c *Client) url(format string, args ...any) string {
+	return "https://" + c.host + fmt.Sprintf(format, args...)
+}
+
+func (c *Client) do(ctx context.Context, method, target, contentType string, body []byte) (*http.Response, error) {
+	resp, err := c.send(ctx, method, target, contentType, body)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	resp.Body.Close()
+	if err := c.authorize(ctx, resp.Header.Get("WWW-Authenticate")); err != nil {
+		return nil, err
+	}
+	return c.send(ctx, method, target, contentType, body)
+}
+
+func (c *Client) send(ctx context.Context, method, target, contentType string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	switch {
+	case c.token != "":
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	case c.username != "":
+		req.SetBasicAuth(c.username, c.password)
+	}
+	return c.http.Do(req)
+}
+
+func (c *Client) authorize(ctx context.Context, challenge string) error {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return fmt.Errorf("реестр требует авторизацию: %s", challenge)
+	}
+	fields := map[string]string{}
+	for _, p := range strings.Split(params, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(p), "=")
+		fields[k] = strings.Trim(v, `"`)
+	}
+
+	u, err := url.Parse(fields["realm"])
+	if err != nil {
+		return err
+	}
+	q := u.Query()
+	for _, k := range []string{"service", "scope"} {
+		if fields[k] != "" {
+			q.Set(k, fields[k])
+		}
+	}
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("получение токена: %s", resp.Status)
+	}
+	var tok struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil {
+		return err
+	}
+	c.token = tok.Token
+	if c.token == "" {
+		c.token = tok.AccessToken
+	}
+	return nil
+}
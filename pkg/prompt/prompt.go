@@ -0,0 +1,171 @@
+package prompt
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var ErrAborted = errors.New("ввод прерван")
+
+type Prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func New(in io.Reader, out io.Writer) *Prompter {
+	return &Prompter{in: bufio.NewReader(in), out: out}
+}
+
+func (p *Prompter) readLine() (string, error) {
+	line, err := p.in.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	if err == io.EOF {
+		fmt.Fprintln(p.out)
+		return "", ErrAborted
+	}
+	return strings.TrimSpace(line), err
+}
+
+func (p *Prompter) Ask(label, def string, validate func(string) error) (string, error) {
+	for {
+		if def != "" {
+			fmt.Fprintf(p.out, "%s [%s]: ", label, def)
+		} else {
+			fmt.Fprintf(p.out, "%s: ", label)
+		}
+		value, err := p.readLine()
+		if err != nil {
+			return "", err
+		}
+		if value == "" {
+			value = def
+		}
+		if value == "" {
+			fmt.Fprintln(p.out, "  Значение обязательно")
+			continue
+		}
+		if validate != nil {
+			if err := validate(value); err != nil {
+				fmt.Fprintf(p.out, "  %v\n", err)
+				continue
+			}
+		}
+		return value, nil
+	}
+}
+
+func (p *Prompter) Confirm(label string, def bool) (bool, error) {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	for {
+		fmt.Fprintf(p.out, "%s [%s]: ", label, hint)
+		value, err := p.readLine()
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(value) {
+		case "":
+			return def, nil
+		case "y", "yes", "д", "да":
+			return true, nil
+		case "n", "no", "н", "нет":
+			return false, nil
+		}
+		fmt.Fprintln(p.out, "  Ответьте y или n")
+	}
+}
+
+// Choose asks for a subset of options. Names outside the options are accepted
+// only when other is set and approves them.
+func (p *Prompter) Choose(label string, options, selected []string, other func(string) error) ([]string, error) {
+	for i, o := range options {
+		mark := " "
+		if slices.Contains(selected, o) {
+			mark = "x"
+		}
+		fmt.Fprintf(p.out, "  [%s] %2d. %s\n", mark, i+1, o)
+	}
+	for {
+		if len(selected) > 0 {
+			fmt.Fprintf(p.out, "%s (номера, диапазоны 1-3, имена или all; Enter — отмеченные): ", label)
+		} else {
+			fmt.Fprintf(p.out, "%s (номера, диапазоны 1-3, имена или all): ", label)
+		}
+		value, err := p.readLine()
+		if err != nil {
+			return nil, err
+		}
+		if value == "" && len(selected) > 0 {
+			return selected, nil
+		}
+		chosen, err := parseChoice(value, options, other)
+		if err != nil {
+			fmt.Fprintf(p.out, "  %v\n", err)
+			continue
+		}
+		return chosen, nil
+	}
+}
+
+func parseChoice(value string, options []string, other func(string) error) ([]string, error) {
+	if value == "all" || value == "*" {
+		return slices.Clone(options), nil
+	}
+	picked := make([]bool, len(options))
+	var extra []string
+	for _, item := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' }) {
+		if from, to, ok := strings.Cut(item, "-"); ok {
+			a, errA := strconv.Atoi(from)
+			b, errB := strconv.Atoi(to)
+			if errA == nil && errB == nil {
+				if a < 1 || b > len(options) || a > b {
+					return nil, fmt.Errorf("диапазон %s вне списка 1-%d", item, len(options))
+				}
+				for i := a; i <= b; i++ {
+					picked[i-1] = true
+				}
+				continue
+			}
+		}
+		if n, err := strconv.Atoi(item); err == nil {
+			if n < 1 || n > len(options) {
+				return nil, fmt.Errorf("номер %d вне списка 1-%d", n, len(options))
+			}
+			picked[n-1] = true
+			continue
+		}
+		if i := slices.Index(options, item); i >= 0 {
+			picked[i] = true
+			continue
+		}
+		if other == nil {
+			return nil, fmt.Errorf("%s нет в списке", item)
+		}
+		if err := other(item); err != nil {
+			return nil, err
+		}
+		if !slices.Contains(extra, item) {
+			extra = append(extra, item)
+		}
+	}
+	var chosen []string
+	for i, ok := range picked {
+		if ok {
+			chosen = append(chosen, options[i])
+		}
+	}
+	chosen = append(chosen, extra...)
+	if len(chosen) == 0 {
+		return nil, errors.New("ничего не выбрано")
+	}
+	return chosen, nil
+}
@@ -0,0 +1,70 @@
+package prose
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Glossary struct {
+	Level string              `yaml:"level"`
+	Terms map[string][]string `yaml:"terms"`
+}
+
+func LoadGlossary(path string) (*Glossary, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var g Glossary
+	if err := yaml.Unmarshal(data, &g); err != nil {
+		return nil, fmt.Errorf("разбор %s: %w", path, err)
+	}
+	switch g.Level {
+	case "":
+		g.Level = LevelWarning
+	case LevelWarning, LevelError:
+	default:
+		return nil, fmt.Errorf("%s: неизвестный уровень %q (доступны: warning, error)", path, g.Level)
+	}
+	return &g, nil
+}
+
+func (g *Glossary) Check(repo string, texts []Text) []Finding {
+	preferred := make([]string, 0, len(g.Terms))
+	for term := range g.Terms {
+		preferred = append(preferred, term)
+	}
+	sort.Strings(preferred)
+
+	var findings []Finding
+	for _, t := range texts {
+		ws := words(t.Value)
+		lower := words(strings.ToLower(t.Value))
+		for _, term := range preferred {
+			for _, variant := range g.Terms[term] {
+				if strings.EqualFold(variant, term) {
+					continue
+				}
+				if containsTerm(lower, strings.Fields(strings.ToLower(variant))) {
+					findings = append(findings, Finding{Repo: repo, Location: t.Location, Level: g.Level, Rule: "glossary",
+						Message: fmt.Sprintf("«%s» → «%s»", strings.ReplaceAll(variant, "*", ""), term)})
+				}
+			}
+			if strings.ToLower(term) == term || strings.ContainsRune(term, ' ') {
+				continue
+			}
+			for _, w := range ws {
+				if w != term && strings.EqualFold(w, term) {
+					findings = append(findings, Finding{Repo: repo, Location: t.Location, Level: g.Level, Rule: "glossary",
+						Message: fmt.Sprintf("«%s» → «%s»", w, term)})
+					break
+				}
+			}
+		}
+	}
+	return findings
+}
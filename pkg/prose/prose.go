@@ -0,0 +1,279 @@
+package prose
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/RastBast/docs12121/pkg/spec"
+)
+
+const (
+	LevelError   = "error"
+	LevelWarning = "warning"
+)
+
+type Term struct {
+	Term    string `yaml:"term"`
+	Replace string `yaml:"replace"`
+	Level   string `yaml:"level"`
+}
+
+type Rules struct {
+	Languages []string `yaml:"languages"`
+	Banned    []Term   `yaml:"banned"`
+	Accept    []string `yaml:"accept"`
+}
+
+var DefaultRules = Rules{
+	Languages: []string{"en", "ru"},
+	Banned: []Term{
+		{Term: "whitelist", Replace: "allowlist", Level: LevelError},
+		{Term: "blacklist", Replace: "denylist", Level: LevelError},
+		{Term: "master/slave", Replace: "primary/replica", Level: LevelError},
+		{Term: "simply", Level: LevelWarning},
+		{Term: "obviously", Level: LevelWarning},
+		{Term: "юзер*", Replace: "пользователь", Level: LevelError},
+		{Term: "залогин*", Replace: "войти", Level: LevelError},
+		{Term: "апишк*", Replace: "API", Level: LevelError},
+	},
+}
+
+var dictionaries = map[string]string{"en": "en_US", "ru": "ru_RU"}
+
+type Text struct {
+	Location string
+	Value    string
+}
+
+type Finding struct {
+	Repo     string `json:"repo"`
+	Location string `json:"location"`
+	Level    string `json:"level"`
+	Rule     string `json:"rule"`
+	Message  string `json:"message"`
+}
+
+func LoadRules(path string) (Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Rules{}, err
+	}
+	rules := DefaultRules
+	var custom Rules
+	if err := yaml.Unmarshal(data, &custom); err != nil {
+		return Rules{}, fmt.Errorf("разбор %s: %w", path, err)
+	}
+	if len(custom.Languages) > 0 {
+		rules.Languages = custom.Languages
+	}
+	rules.Banned = append(append([]Term(nil), rules.Banned...), custom.Banned...)
+	rules.Accept = custom.Accept
+	return rules, nil
+}
+
+func Texts(doc *spec.Document) []Text {
+	var out []Text
+	add := func(loc, v string) {
+		if strings.TrimSpace(v) != "" {
+			out = append(out, Text{loc, v})
+		}
+	}
+	add("info.title", doc.Info.Title)
+	add("info.description", doc.Info.Description)
+	for _, op := range doc.Operations() {
+		loc := op.String()
+		add(loc+" summary", op.Operation.Summary)
+		add(loc+" description", op.Operation.Description)
+		for _, p := range op.Operation.Parameters {
+			if p != nil {
+				add(loc+" параметр "+p.Name, p.Description)
+			}
+		}
+		codes := make([]string, 0, len(op.Operation.Responses))
+		for code := range op.Operation.Responses {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+		for _, code := range codes {
+			if r := op.Operation.Responses[code]; r != nil {
+				add(loc+" ответ "+code, r.Description)
+			}
+		}
+	}
+	names := make([]string, 0, len(doc.Components.Schemas))
+	for name := range doc.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		s := doc.Components.Schemas[name]
+		if s == nil {
+			continue
+		}
+		add("схема "+name, s.Description)
+		props := make([]string, 0, len(s.Properties))
+		for p := range s.Properties {
+			props = append(props, p)
+		}
+		sort.Strings(props)
+		for _, p := range props {
+			if s.Properties[p] != nil {
+				add("схема "+name+"."+p, s.Properties[p].Description)
+			}
+		}
+	}
+	return out
+}
+
+func words(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\'' && r != '/' && r != '-'
+	})
+}
+
+func Check(repo string, texts []Text, rules Rules) []Finding {
+	var findings []Finding
+	report := func(loc, level, rule, format string, args ...any) {
+		findings = append(findings, Finding{Repo: repo, Location: loc, Level: level, Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, t := range texts {
+		lower := words(strings.ToLower(t.Value))
+		for _, term := range rules.Banned {
+			if !containsTerm(lower, strings.Fields(strings.ToLower(term.Term))) {
+				continue
+			}
+			level := term.Level
+			if level == "" {
+				level = LevelError
+			}
+			name := strings.ReplaceAll(term.Term, "*", "")
+			if term.Replace != "" {
+				report(t.Location, level, "terminology", "«%s» — используйте «%s»", name, term.Replace)
+			} else {
+				report(t.Location, level, "terminology", "избегайте «%s»", name)
+			}
+		}
+
+		ws := words(t.Value)
+		for i := 1; i < len(ws); i++ {
+			if strings.EqualFold(ws[i], ws[i-1]) && unicode.IsLetter([]rune(ws[i])[0]) {
+				report(t.Location, LevelWarning, "repetition", "повтор слова «%s»", ws[i])
+			}
+		}
+		for _, w := range ws {
+			if mixedScript(w) {
+				report(t.Location, LevelError, "mixed-script", "в слове «%s» смешаны латиница и кириллица", w)
+			}
+		}
+	}
+	return findings
+}
+
+func containsTerm(ws, term []string) bool {
+	if len(term) == 0 {
+		return false
+	}
+outer:
+	for i := 0; i+len(term) <= len(ws); i++ {
+		for j, t := range term {
+			w := ws[i+j]
+			if prefix, ok := strings.CutSuffix(t, "*"); ok {
+				if !strings.HasPrefix(w, prefix) {
+					continue outer
+				}
+			} else if w != t {
+				continue outer
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func mixedScript(w string) bool {
+	var latin, cyrillic bool
+	for _, r := range w {
+		switch {
+		case unicode.Is(unicode.Latin, r):
+			latin = true
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic = true
+		}
+	}
+	return latin && cyrillic
+}
+
+func spellable(w string) bool {
+	runes := []rune(w)
+	if len(runes) < 3 {
+		return false
+	}
+	for i, r := range runes {
+		if !unicode.IsLetter(r) {
+			return false
+		}
+		if i > 0 && unicode.IsUpper(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func Spell(repo string, texts []Text, rules Rules) ([]Finding, error) {
+	path, err := exec.LookPath("hunspell")
+	if err != nil {
+		return nil, err
+	}
+	var dicts []string
+	for _, lang := range rules.Languages {
+		if d, ok := dictionaries[lang]; ok {
+			dicts = append(dicts, d)
+		} else {
+			dicts = append(dicts, lang)
+		}
+	}
+	accept := map[string]bool{}
+	for _, w := range rules.Accept {
+		accept[strings.ToLower(w)] = true
+	}
+
+	var input strings.Builder
+	for _, t := range texts {
+		for _, w := range words(t.Value) {
+			if spellable(w) && !accept[strings.ToLower(w)] {
+				input.WriteString(w + "\n")
+			}
+		}
+	}
+	cmd := exec.Command(path, "-d", strings.Join(dicts, ","), "-l")
+	cmd.Stdin = strings.NewReader(input.String())
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("hunspell: %w", err)
+	}
+	misspelled := map[string]bool{}
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	for scanner.Scan() {
+		misspelled[scanner.Text()] = true
+	}
+
+	var findings []Finding
+	for _, t := range texts {
+		reported := map[string]bool{}
+		for _, w := range words(t.Value) {
+			if misspelled[w] && !reported[w] {
+				reported[w] = true
+				findings = append(findings, Finding{Repo: repo, Location: t.Location, Level: LevelWarning, Rule: "spelling", Message: fmt.Sprintf("возможная опечатка «%s»", w)})
+			}
+		}
+	}
+	return findings, nil
+}
@@ -0,0 +1,67 @@
+//go:build ignore
+
+package main
+
+import (
+	"bufio"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	list := filepath.Join("assets", "vendor.txt")
+	f, err := os.Open(list)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 && len(fields) != 3 {
+			continue
+		}
+		url := "https://unpkg.com/" + fields[0] + "/" + fields[1]
+		integrity, err := download(url, filepath.Join("assets", path.Base(fields[1])))
+		if err != nil {
+			log.Fatalf("%s: %v", url, err)
+		}
+		if len(fields) == 3 && fields[2] != integrity {
+			log.Fatalf("%s: хэш %s не совпадает с записанным %s", url, integrity, fields[2])
+		}
+		lines = append(lines, fields[0]+" "+fields[1]+" "+integrity)
+		fmt.Println(url, integrity)
+	}
+	f.Close()
+	if err := scanner.Err(); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(list, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func download(url, dest string) (string, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("HTTP %s", resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	sum := sha512.Sum384(data)
+	return "sha384-" + base64.StdEncoding.EncodeToString(sum[:]), os.WriteFile(dest, data, 0o644)
+}
@@ -0,0 +1,40 @@
+package render
+
+const OAuthRedirectPage = `<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<title>Авторизация</title>
+</head>
+<body>
+<script>
+(function () {
+  var oauth2 = window.opener && window.opener.swaggerUIRedirectOauth2;
+  if (!oauth2) {
+    document.body.textContent = "Откройте эту страницу из интерактивной документации.";
+    return;
+  }
+  var raw = /code|token|error/.test(window.location.hash) ? window.location.hash.substring(1) : window.location.search.substring(1);
+  var params = Object.fromEntries(new URLSearchParams(raw));
+  var isValid = params.state === oauth2.state;
+  var flow = oauth2.auth.schema.get("flow");
+  if ((flow === "accessCode" || flow === "authorizationCode" || flow === "authorization_code") && !oauth2.auth.code) {
+    if (!isValid) {
+      oauth2.errCb({ authId: oauth2.auth.name, source: "auth", level: "warning", message: "Параметр state не совпадает с отправленным" });
+    }
+    if (params.code) {
+      delete oauth2.state;
+      oauth2.auth.code = params.code;
+      oauth2.callback({ auth: oauth2.auth, redirectUrl: oauth2.redirectUrl });
+    } else {
+      oauth2.errCb({ authId: oauth2.auth.name, source: "auth", level: "error", message: params.error ? params.error + ": " + (params.error_description || "") : "Провайдер не вернул код авторизации" });
+    }
+  } else {
+    oauth2.callback({ auth: oauth2.auth, token: params, isValid: isValid, redirectUrl: oauth2.redirectUrl });
+  }
+  window.close();
+})();
+</script>
+</body>
+</html>
+`
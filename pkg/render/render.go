@@ -0,0 +1,345 @@
+package render
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	UISwagger = "swagger"
+	UIRedoc   = "redoc"
+)
+
+var UIs = []string{UISwagger, UIRedoc}
+
+var ErrUnknownUI = errors.New("неизвестный интерфейс")
+
+var ErrUnpinnedAsset = errors.New("ресурс не встроен в сборку и для него не записан хэш целостности (выполните go generate ./pkg/render)")
+
+var uiDirs = map[string]string{UISwagger: "interactive", UIRedoc: "static"}
+
+//go:generate go run fetch.go
+
+//go:embed assets
+var assetsFS embed.FS
+
+type Asset struct {
+	Package   string
+	File      string
+	Integrity string
+}
+
+func (a Asset) Name() string { return path.Base(a.File) }
+
+func (a Asset) CDN() string { return "https://unpkg.com/" + a.Package + "/" + a.File }
+
+func Vendored() ([]Asset, error) {
+	data, err := assetsFS.ReadFile("assets/vendor.txt")
+	if err != nil {
+		return nil, err
+	}
+	var assets []Asset
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		switch len(fields) {
+		case 2:
+			assets = append(assets, Asset{Package: fields[0], File: fields[1]})
+		case 3:
+			assets = append(assets, Asset{Package: fields[0], File: fields[1], Integrity: fields[2]})
+		}
+	}
+	return assets, scanner.Err()
+}
+
+// Lookup returns the asset called name with its content when it is vendored;
+// an asset that is only pinned comes back with its Integrity and no content.
+func Lookup(name string) (Asset, []byte, error) {
+	assets, err := Vendored()
+	if err != nil {
+		return Asset{}, nil, err
+	}
+	for _, a := range assets {
+		if a.Name() != name {
+			continue
+		}
+		if data, err := assetsFS.ReadFile("assets/" + a.Name()); err == nil {
+			return a, data, nil
+		}
+		if a.Integrity == "" {
+			return Asset{}, nil, fmt.Errorf("%s: %w", a.Name(), ErrUnpinnedAsset)
+		}
+		return a, nil, nil
+	}
+	return Asset{}, nil, fmt.Errorf("%s: %w", name, fs.ErrNotExist)
+}
+
+type Site struct {
+	Dir           string
+	Output        string
+	UIs           []string
+	OAuthClientID string
+	WriteFile     func(path string, data []byte) error
+}
+
+type Language struct {
+	Code    string
+	SpecURL string
+}
+
+type Result struct {
+	Pages int
+	CDN   []string
+}
+
+func (s Site) Render(names []string) (*Result, error) {
+	for _, ui := range s.UIs {
+		if _, ok := uiDirs[ui]; !ok {
+			return nil, fmt.Errorf("%w %q (доступны: %s)", ErrUnknownUI, ui, strings.Join(UIs, ", "))
+		}
+	}
+	res := &Result{}
+	urls, err := s.writeAssets(res)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		if s.Output != s.Dir {
+			if err := s.copySpec(name); err != nil {
+				return nil, err
+			}
+		}
+		for _, ui := range s.UIs {
+			if err := s.writePage(ui, name, urls); err != nil {
+				return nil, err
+			}
+			res.Pages++
+		}
+	}
+	return res, nil
+}
+
+func (s Site) writeAssets(res *Result) (map[string]Asset, error) {
+	assets, err := Vendored()
+	if err != nil {
+		return nil, err
+	}
+	urls := map[string]Asset{}
+	for _, a := range assets {
+		data, err := assetsFS.ReadFile("assets/" + a.Name())
+		if err != nil {
+			if a.Integrity == "" {
+				return nil, fmt.Errorf("%s: %w", a.Name(), ErrUnpinnedAsset)
+			}
+			urls[a.Name()] = a
+			res.CDN = append(res.CDN, a.Name())
+			continue
+		}
+		if err := s.write(filepath.Join(s.Output, "assets", a.Name()), data); err != nil {
+			return nil, err
+		}
+		urls[a.Name()] = Asset{File: "assets/" + a.Name()}
+	}
+	return urls, nil
+}
+
+func (s Site) languages(name string) []string {
+	files, _ := filepath.Glob(filepath.Join(s.Dir, filepath.FromSlash(name), "openapi.*.yaml"))
+	var langs []string
+	for _, f := range files {
+		langs = append(langs, strings.TrimSuffix(strings.TrimPrefix(filepath.Base(f), "openapi."), ".yaml"))
+	}
+	return langs
+}
+
+func (s Site) writePage(ui, name string, urls map[string]Asset) error {
+	dir := uiDirs[ui]
+	root := strings.Repeat("../", strings.Count(dir+"/"+name, "/")+1)
+	asset := func(file string) string {
+		a := urls[file]
+		if a.Integrity != "" {
+			return a.CDN()
+		}
+		return root + a.File
+	}
+	integrity := func(file string) string { return urls[file].Integrity }
+	var langs []Language
+	for _, code := range s.languages(name) {
+		langs = append(langs, Language{Code: code, SpecURL: root + name + "/openapi." + code + ".yaml"})
+	}
+	var b bytes.Buffer
+	err := pages.ExecuteTemplate(&b, ui, struct {
+		Name          string
+		SpecURL       string
+		Languages     []Language
+		OAuthClientID string
+		Asset         func(string) string
+		Integrity     func(string) string
+	}{name, root + name + "/openapi.yaml", langs, s.OAuthClientID, asset, integrity})
+	if err != nil {
+		return err
+	}
+	out := filepath.Join(s.Output, dir, filepath.FromSlash(name))
+	if err := s.write(filepath.Join(out, "index.html"), b.Bytes()); err != nil {
+		return err
+	}
+	if ui == UISwagger && s.OAuthClientID != "" {
+		return s.write(filepath.Join(out, "oauth2-redirect.html"), []byte(OAuthRedirectPage))
+	}
+	return nil
+}
+
+func Offline(name string, specJSON []byte) ([]byte, bool, error) {
+	assets, err := Vendored()
+	if err != nil {
+		return nil, false, err
+	}
+	var script string
+	inlined := false
+	for _, a := range assets {
+		if a.Name() != "redoc.standalone.js" {
+			continue
+		}
+		if data, err := assetsFS.ReadFile("assets/" + a.Name()); err == nil {
+			script = "<script>" + strings.ReplaceAll(string(data), "</script", "<\\/script") + "</script>"
+			inlined = true
+			continue
+		}
+		if a.Integrity == "" {
+			return nil, false, fmt.Errorf("%s: %w", a.Name(), ErrUnpinnedAsset)
+		}
+		script = `<script src="` + template.HTMLEscapeString(a.CDN()) + `" integrity="` + template.HTMLEscapeString(a.Integrity) + `" crossorigin="anonymous"></script>`
+	}
+	var b bytes.Buffer
+	err = pages.ExecuteTemplate(&b, "offline", struct {
+		Name   string
+		Spec   json.RawMessage
+		Script template.HTML
+	}{name, json.RawMessage(specJSON), template.HTML(script)})
+	return b.Bytes(), inlined, err
+}
+
+func (s Site) copySpec(name string) error {
+	files := []string{"openapi.yaml"}
+	for _, code := range s.languages(name) {
+		files = append(files, "openapi."+code+".yaml")
+	}
+	for _, file := range files {
+		rel := filepath.Join(filepath.FromSlash(name), file)
+		data, err := os.ReadFile(filepath.Join(s.Dir, rel))
+		if err != nil {
+			return err
+		}
+		if err := s.write(filepath.Join(s.Output, rel), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s Site) write(path string, data []byte) error {
+	if s.WriteFile != nil {
+		return s.WriteFile(path, data)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if current, err := os.ReadFile(path); err == nil && bytes.Equal(current, data) {
+		return nil
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+var pages = template.Must(template.New("pages").Parse(`
+{{- define "swagger" -}}
+<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{ .Name }}</title>
+<link rel="stylesheet" href="{{ call .Asset "swagger-ui.css" }}"{{ with call .Integrity "swagger-ui.css" }} integrity="{{ . }}" crossorigin="anonymous"{{ end }}>
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="{{ call .Asset "swagger-ui-bundle.js" }}"{{ with call .Integrity "swagger-ui-bundle.js" }} integrity="{{ . }}" crossorigin="anonymous"{{ end }}></script>
+<script>
+window.ui = SwaggerUIBundle({
+{{- if .Languages }}
+  urls: [
+    { name: "original", url: new URL({{ .SpecURL }}, window.location.href).href },
+{{- range .Languages }}
+    { name: {{ .Code }}, url: new URL({{ .SpecURL }}, window.location.href).href },
+{{- end }}
+  ],
+{{- else }}
+  url: new URL({{ .SpecURL }}, window.location.href).href,
+{{- end }}
+  dom_id: "#swagger-ui",
+  deepLinking: true,
+  oauth2RedirectUrl: new URL("oauth2-redirect.html", window.location.href).href,
+});
+{{- with .OAuthClientID }}
+window.ui.initOAuth({ clientId: {{ . }}, usePkceWithAuthorizationCodeGrant: true });
+{{- end }}
+</script>
+</body>
+</html>
+{{ end -}}
+
+{{- define "offline" -}}
+<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{ .Name }}</title>
+</head>
+<body>
+<div id="redoc"></div>
+{{ .Script }}
+<script>
+Redoc.init({{ .Spec }}, {}, document.getElementById("redoc"));
+</script>
+</body>
+</html>
+{{ end -}}
+
+{{- define "redoc" -}}
+<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{ .Name }}</title>
+</head>
+<body>
+{{- if .Languages }}
+<nav class="languages">
+  <a href="?">original</a>
+{{- range .Languages }}
+  <a href="?lang={{ .Code }}">{{ .Code }}</a>
+{{- end }}
+</nav>
+<div id="redoc"></div>
+<script src="{{ call .Asset "redoc.standalone.js" }}"{{ with call .Integrity "redoc.standalone.js" }} integrity="{{ . }}" crossorigin="anonymous"{{ end }}></script>
+<script>
+var specs = { "": {{ .SpecURL }}{{ range .Languages }}, {{ .Code }}: {{ .SpecURL }}{{ end }} };
+var lang = new URLSearchParams(window.location.search).get("lang") || "";
+Redoc.init(specs[lang] || specs[""], {}, document.getElementById("redoc"));
+</script>
+{{- else }}
+<redoc spec-url="{{ .SpecURL }}"></redoc>
+<script src="{{ call .Asset "redoc.standalone.js" }}"{{ with call .Integrity "redoc.standalone.js" }} integrity="{{ . }}" crossorigin="anonymous"{{ end }}></script>
+{{- end }}
+</body>
+</html>
+{{ end -}}
+`))
@@ -0,0 +1,77 @@
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testSpec = `openapi: 3.0.0
+info:
+  title: Pets
+  version: 1.0.0
+paths: {}
+`
+
+// TestRenderSite renders a whole site from the vendored asset set. It fails
+// when the assets were never fetched: run go generate ./pkg/render and commit
+// the result.
+func TestRenderSite(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "pets"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "pets", "openapi.yaml"), []byte(testSpec), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out := t.TempDir()
+	site := Site{Dir: dir, Output: out, UIs: UIs}
+	res, err := site.Render([]string{"pets"})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if res.Pages != len(UIs) {
+		t.Errorf("pages = %d, want %d", res.Pages, len(UIs))
+	}
+
+	assets, err := Vendored()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(assets) == 0 {
+		t.Fatal("vendor.txt lists no assets")
+	}
+	for _, ui := range UIs {
+		page, err := os.ReadFile(filepath.Join(out, uiDirs[ui], "pets", "index.html"))
+		if err != nil {
+			t.Fatalf("%s: %v", ui, err)
+		}
+		if !strings.Contains(string(page), "pets/openapi.yaml") {
+			t.Errorf("%s: page does not reference the spec", ui)
+		}
+	}
+	for _, a := range assets {
+		if _, err := os.Stat(filepath.Join(out, "assets", a.Name())); err == nil {
+			continue
+		}
+		if a.Integrity == "" {
+			t.Errorf("%s: neither vendored nor pinned", a.Name())
+		}
+	}
+	if _, err := os.Stat(filepath.Join(out, "pets", "openapi.yaml")); err != nil {
+		t.Errorf("spec not copied: %v", err)
+	}
+}
+
+func TestOffline(t *testing.T) {
+	page, _, err := Offline("pets", []byte(`{"openapi":"3.0.0","info":{"title":"Pets","version":"1.0.0"},"paths":{}}`))
+	if err != nil {
+		t.Fatalf("Offline: %v", err)
+	}
+	for _, want := range []string{`"title":"Pets"`, "<script", "Redoc.init"} {
+		if !strings.Contains(string(page), want) {
+			t.Errorf("offline page lacks %q", want)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+)
+
+type Result struct {
+	Repo string
+	Err  error
+}
+
+type Summary struct {
+	results map[string]error
+	order   []string
+}
+
+func (s *Summary) Add(repo string, err error) {
+	if s.results == nil {
+		s.results = map[string]error{}
+	}
+	if prev, ok := s.results[repo]; ok {
+		if prev == nil {
+			s.results[repo] = err
+		}
+		return
+	}
+	s.results[repo] = err
+	s.order = append(s.order, repo)
+}
+
+func (s *Summary) Failed(repo string) bool {
+	return s.results[repo] != nil
+}
+
+func (s *Summary) Results() []Result {
+	out := make([]Result, 0, len(s.order))
+	for _, repo := range s.order {
+		out = append(out, Result{Repo: repo, Err: s.results[repo]})
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Err == nil && out[j].Err != nil
+	})
+	return out
+}
+
+func (s *Summary) Counts() (ok, failed int) {
+	for _, err := range s.results {
+		if err == nil {
+			ok++
+		} else {
+			failed++
+		}
+	}
+	return ok, failed
+}
+
+func (s *Summary) Err() error {
+	ok, failed := s.Counts()
+	if failed == 0 {
+		return nil
+	}
+	return &PartialError{Succeeded: ok, Failed: failed}
+}
+
+type PartialError struct {
+	Succeeded int
+	Failed    int
+}
+
+func (e *PartialError) Error() string {
+	return fmt.Sprintf("не удалось обработать репозиториев: %d из %d", e.Failed, e.Succeeded+e.Failed)
+}
@@ -0,0 +1,73 @@
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const StreaksFile = "failures.json"
+
+type Streak struct {
+	Failures  int       `json:"failures"`
+	Since     time.Time `json:"since"`
+	LastError string    `json:"last_error,omitempty"`
+	Issue     int       `json:"issue,omitempty"`
+}
+
+type Streaks map[string]Streak
+
+func LoadStreaks(dir string) (Streaks, error) {
+	s := Streaks{}
+	data, err := os.ReadFile(filepath.Join(dir, StreaksFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s Streaks) Save(dir string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp := filepath.Join(dir, StreaksFile+".tmp")
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(dir, StreaksFile))
+}
+
+func (s Streaks) Record(repo string, err error, now time.Time) Streak {
+	st := s[repo]
+	if err == nil {
+		st.Failures, st.Since, st.LastError = 0, time.Time{}, ""
+	} else {
+		if st.Failures == 0 {
+			st.Since = now
+		}
+		st.Failures++
+		st.LastError = err.Error()
+	}
+	s.Set(repo, st)
+	return st
+}
+
+func (s Streaks) Set(repo string, st Streak) {
+	if st == (Streak{}) {
+		delete(s, repo)
+		return
+	}
+	s[repo] = st
+}
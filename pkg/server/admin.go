@@ -0,0 +1,141 @@
+package server
+
+import (
+	"html/template"
+	"net/http"
+)
+
+var adminPage = template.Must(template.New("admin").Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<title>OpenAPI Aggregator: администрирование</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+.queued, .running { color: #b58900; }
+.succeeded { color: #2e7d32; }
+.failed { color: #c62828; }
+tr.job { cursor: pointer; }
+pre { background: #f5f5f5; padding: 1em; min-height: 4em; max-height: 30em; overflow: auto; }
+</style>
+</head>
+<body>
+<h1>OpenAPI Aggregator</h1>
+<p><label>Токен запуска задач: <input type="password" id="token" autocomplete="off"></label></p>
+<h2>Репозитории</h2>
+<table>
+<thead><tr><th>Репозиторий</th><th>Последний запуск</th><th>Статус</th><th></th></tr></thead>
+<tbody id="repos"></tbody>
+</table>
+<h2>Запуски</h2>
+<table>
+<thead><tr><th>ID</th><th>Репозиторий</th><th>Ref</th><th>Статус</th><th>Создана</th><th>Запустил</th><th>Ошибка</th></tr></thead>
+<tbody id="jobs"></tbody>
+</table>
+<h2>Журнал <span id="log-title"></span></h2>
+<pre id="log">Выберите запуск в таблице.</pre>
+<script>
+const repos = {{ .Repos }};
+let stream = null;
+const tokenInput = document.getElementById("token");
+tokenInput.value = sessionStorage.getItem("jobs-token") || "";
+tokenInput.onchange = () => sessionStorage.setItem("jobs-token", tokenInput.value);
+
+function cell(row, text, cls) {
+  const td = row.insertCell();
+  td.textContent = text || "";
+  if (cls) td.className = cls;
+  return td;
+}
+
+async function submit(repo, ref) {
+  const resp = await fetch("jobs", {
+    method: "POST",
+    headers: { "Content-Type": "application/json", "{{ .TokenHeader }}": tokenInput.value },
+    body: JSON.stringify({ repo, ref }),
+  });
+  if (!resp.ok) {
+    alert(await resp.text());
+    return;
+  }
+  follow((await resp.json()).id);
+  refresh();
+}
+
+async function refresh() {
+  const jobs = await (await fetch("jobs", { cache: "no-store" })).json();
+  const last = {};
+  for (const job of jobs) {
+    if (!last[job.repo]) last[job.repo] = job;
+  }
+  const reposBody = document.getElementById("repos");
+  reposBody.replaceChildren();
+  for (const repo of repos) {
+    const job = last[repo];
+    const row = reposBody.insertRow();
+    cell(row, repo);
+    cell(row, job && new Date(job.created).toLocaleString());
+    cell(row, job && job.status, job && job.status);
+    const button = document.createElement("button");
+    button.textContent = job && job.status === "failed" ? "Повторить" : "Запустить";
+    button.onclick = () => submit(repo, job ? job.branch || job.ref : "");
+    row.insertCell().append(button);
+  }
+  const jobsBody = document.getElementById("jobs");
+  jobsBody.replaceChildren();
+  for (const job of jobs) {
+    const row = jobsBody.insertRow();
+    row.className = "job";
+    row.onclick = () => follow(job.id);
+    cell(row, job.id);
+    cell(row, job.repo);
+    cell(row, job.branch || job.ref);
+    cell(row, job.status, job.status);
+    cell(row, new Date(job.created).toLocaleString());
+    cell(row, job.requested_by);
+    cell(row, job.error);
+  }
+}
+
+function follow(id) {
+  if (stream) stream.close();
+  const log = document.getElementById("log");
+  document.getElementById("log-title").textContent = id;
+  log.textContent = "";
+  const es = new EventSource("jobs/" + id + "/events");
+  stream = es;
+  es.addEventListener("log", (e) => {
+    log.textContent += e.data + "\n";
+    log.scrollTop = log.scrollHeight;
+  });
+  es.addEventListener("done", () => {
+    es.close();
+    refresh();
+  });
+  es.onerror = () => {
+    es.close();
+    log.textContent += "Поток журнала прерван.\n";
+  };
+}
+
+refresh();
+setInterval(refresh, 3000);
+</script>
+</body>
+</html>
+`))
+
+func (s *Server) handleAdmin(w http.ResponseWriter, r *http.Request) {
+	repos := s.Jobs.Settings().Repos
+	if repos == nil {
+		repos = []string{}
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	adminPage.Execute(w, struct {
+		Repos       []string
+		TokenHeader string
+	}{repos, JobTokenHeader})
+}
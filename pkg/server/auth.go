@@ -0,0 +1,315 @@
+package server
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+type AccessConfig struct {
+	BasicAuth    map[string]string
+	AllowedNets  []*net.IPNet
+	OIDCIssuer   string
+	OIDCAudience string
+}
+
+func (a AccessConfig) Enabled() bool {
+	return len(a.BasicAuth) > 0 || len(a.AllowedNets) > 0 || a.OIDCIssuer != ""
+}
+
+type Principal struct {
+	Name   string
+	Groups []string
+}
+
+type principalKey struct{}
+
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+func withPrincipal(r *http.Request, p Principal) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), principalKey{}, p))
+}
+
+func ParseBasicAuth(s string) (map[string]string, error) {
+	users := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		user, pass, ok := strings.Cut(pair, ":")
+		if !ok || user == "" {
+			return nil, fmt.Errorf("ожидается user:password, получено %q", pair)
+		}
+		users[user] = pass
+	}
+	return users, nil
+}
+
+func ParseAllowList(s string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		if !strings.Contains(item, "/") {
+			if strings.Contains(item, ":") {
+				item += "/128"
+			} else {
+				item += "/32"
+			}
+		}
+		_, n, err := net.ParseCIDR(item)
+		if err != nil {
+			return nil, err
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+func robotsHandler(a AccessConfig) http.HandlerFunc {
+	body := "User-agent: *\nAllow: /\n"
+	if a.Enabled() {
+		body = "User-agent: *\nDisallow: /\n"
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte(body))
+	}
+}
+
+func WithAccessControl(a AccessConfig, next http.Handler) http.Handler {
+	var oidc *oidcVerifier
+	if a.OIDCIssuer != "" {
+		oidc = &oidcVerifier{issuer: strings.TrimSuffix(a.OIDCIssuer, "/"), audience: a.OIDCAudience}
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(a.AllowedNets) > 0 && !ipAllowed(a.AllowedNets, r.RemoteAddr) {
+			http.Error(w, "доступ запрещён", http.StatusForbidden)
+			return
+		}
+
+		if len(a.BasicAuth) == 0 && oidc == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if user, pass, ok := r.BasicAuth(); ok && len(a.BasicAuth) > 0 {
+			if want, found := a.BasicAuth[user]; found && subtle.ConstantTimeCompare([]byte(pass), []byte(want)) == 1 {
+				next.ServeHTTP(w, withPrincipal(r, Principal{Name: user}))
+				return
+			}
+		}
+		if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && oidc != nil {
+			if p, err := oidc.verify(r.Context(), token); err == nil {
+				next.ServeHTTP(w, withPrincipal(r, p))
+				return
+			}
+		}
+
+		if len(a.BasicAuth) > 0 {
+			w.Header().Set("WWW-Authenticate", `Basic realm="openapi-aggregator"`)
+		} else {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+		}
+		http.Error(w, "требуется авторизация", http.StatusUnauthorized)
+	})
+}
+
+func ipAllowed(nets []*net.IPNet, remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	for _, n := range nets {
+		if ip != nil && n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+type oidcVerifier struct {
+	issuer   string
+	audience string
+
+	mu        sync.Mutex
+	keys      map[string]*rsa.PublicKey
+	fetched   time.Time
+	attempted time.Time
+}
+
+// jwksRefreshInterval limits how often tokens with an unknown kid can make
+// the server refetch the key set.
+const jwksRefreshInterval = time.Minute
+
+func (v *oidcVerifier) verify(ctx context.Context, token string) (Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Principal{}, errors.New("некорректный токен")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return Principal{}, err
+	}
+	if header.Alg != "RS256" {
+		return Principal{}, fmt.Errorf("алгоритм %s не поддерживается", header.Alg)
+	}
+	key, err := v.key(ctx, header.Kid)
+	if err != nil {
+		return Principal{}, err
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Principal{}, err
+	}
+	sum := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, sum[:], sig); err != nil {
+		return Principal{}, err
+	}
+
+	var claims struct {
+		Iss    string          `json:"iss"`
+		Sub    string          `json:"sub"`
+		Aud    json.RawMessage `json:"aud"`
+		Exp    int64           `json:"exp"`
+		Nbf    int64           `json:"nbf"`
+		Groups []string        `json:"groups"`
+	}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return Principal{}, err
+	}
+	if strings.TrimSuffix(claims.Iss, "/") != v.issuer {
+		return Principal{}, errors.New("чужой издатель токена")
+	}
+	now := time.Now().Unix()
+	if now >= claims.Exp {
+		return Principal{}, errors.New("токен истёк")
+	}
+	if claims.Nbf != 0 && now < claims.Nbf {
+		return Principal{}, errors.New("токен ещё не действует")
+	}
+	if !audienceContains(claims.Aud, v.audience) {
+		return Principal{}, errors.New("токен выдан для другого клиента")
+	}
+	return Principal{Name: claims.Sub, Groups: claims.Groups}, nil
+}
+
+func audienceContains(raw json.RawMessage, want string) bool {
+	var one string
+	if json.Unmarshal(raw, &one) == nil {
+		return one == want
+	}
+	var many []string
+	json.Unmarshal(raw, &many)
+	for _, a := range many {
+		if a == want {
+			return true
+		}
+	}
+	return false
+}
+
+func decodeSegment(seg string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func (v *oidcVerifier) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if k, ok := v.keys[kid]; ok && time.Since(v.fetched) < time.Hour {
+		return k, nil
+	}
+	if time.Since(v.attempted) >= jwksRefreshInterval {
+		v.attempted = time.Now()
+		if err := v.fetchKeys(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if k, ok := v.keys[kid]; ok {
+		return k, nil
+	}
+	return nil, fmt.Errorf("ключ %q не найден", kid)
+}
+
+func (v *oidcVerifier) fetchKeys(ctx context.Context) error {
+	var discovery struct {
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := getJSON(ctx, v.issuer+"/.well-known/openid-configuration", &discovery); err != nil {
+		return err
+	}
+	var jwks struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := getJSON(ctx, discovery.JWKSURI, &jwks); err != nil {
+		return err
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, k := range jwks.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			continue
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+	v.keys, v.fetched = keys, time.Now()
+	return nil
+}
+
+func getJSON(ctx context.Context, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+const (
+	formatYAML = "yaml"
+	formatJSON = "json"
+)
+
+func negotiateFormat(r *http.Request) string {
+	switch r.URL.Query().Get("format") {
+	case formatJSON:
+		return formatJSON
+	case formatYAML:
+		return formatYAML
+	}
+	accept := r.Header.Get("Accept")
+	if strings.Contains(accept, "json") && !strings.Contains(accept, "yaml") {
+		return formatJSON
+	}
+	return formatYAML
+}
+
+func acceptsGzip(r *http.Request) bool {
+	for _, enc := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(enc), ";")
+		if strings.EqualFold(strings.TrimSpace(name), "gzip") && strings.ReplaceAll(params, " ", "") != "q=0" {
+			return true
+		}
+	}
+	return false
+}
+
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func writeContract(w http.ResponseWriter, r *http.Request, contentType string, body []byte) {
+	h := w.Header()
+	setCORSHeaders(h, r)
+	h.Set("Content-Type", contentType)
+	h.Set("Cache-Control", "no-cache")
+	h.Add("Vary", "Accept")
+	h.Add("Vary", "Accept-Encoding")
+
+	sum := sha256.Sum256(body)
+	etag := hex.EncodeToString(sum[:12])
+	gz := acceptsGzip(r)
+	if gz {
+		etag += "-gzip"
+	}
+	etag = `"` + etag + `"`
+	h.Set("ETag", etag)
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	if gz {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write(body)
+		zw.Close()
+		body = buf.Bytes()
+		h.Set("Content-Encoding", "gzip")
+	}
+	w.Write(body)
+}
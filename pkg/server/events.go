@@ -0,0 +1,131 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const followInterval = 500 * time.Millisecond
+
+func (j *Job) Done() bool {
+	return j.Status == JobSucceeded || j.Status == JobFailed
+}
+
+func (q *Jobs) Follow(ctx context.Context, id string, interval time.Duration, line func(string) error) (*Job, error) {
+	var offset int64
+	var partial []byte
+	for {
+		job, err := q.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		data, err := readFrom(q.logPath(id), offset)
+		if err != nil {
+			return nil, err
+		}
+		offset += int64(len(data))
+		partial = append(partial, data...)
+		for {
+			i := bytes.IndexByte(partial, '\n')
+			if i < 0 {
+				break
+			}
+			if err := line(string(partial[:i])); err != nil {
+				return nil, err
+			}
+			partial = partial[i+1:]
+		}
+		if job.Done() {
+			if len(partial) > 0 {
+				if err := line(string(partial)); err != nil {
+					return nil, err
+				}
+			}
+			return job, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+func readFrom(path string, offset int64) ([]byte, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return io.ReadAll(f)
+}
+
+func (s *Server) handleJobEvents(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, err := s.Jobs.Get(id); errors.Is(err, os.ErrNotExist) {
+		http.NotFound(w, r)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "потоковая передача не поддерживается", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	job, err := s.Jobs.Follow(r.Context(), id, followInterval, func(line string) error {
+		if _, err := fmt.Fprintf(w, "event: log\ndata: %s\n\n", line); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	})
+	if err != nil {
+		if r.Context().Err() == nil {
+			fmt.Fprintf(w, "event: error\ndata: %s\n\n", strings.ReplaceAll(err.Error(), "\n", " "))
+			flusher.Flush()
+		}
+		return
+	}
+	data, _ := json.Marshal(job)
+	fmt.Fprintf(w, "event: done\ndata: %s\n\n", data)
+	flusher.Flush()
+}
+
+// eventsRoute matches the job log stream, optionally under a tenant prefix.
+var eventsRoute = regexp.MustCompile(`^(/[a-z0-9][a-z0-9_-]*)?/jobs/[^/]+/events$`)
+
+// TimeoutHandler limits request time for everything except the job log
+// stream, which stays open until the job finishes.
+func TimeoutHandler(h http.Handler, dt time.Duration, msg string) http.Handler {
+	limited := http.TimeoutHandler(h, dt, msg)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && eventsRoute.MatchString(r.URL.Path) {
+			h.ServeHTTP(w, r)
+			return
+		}
+		limited.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,413 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+)
+
+type Job struct {
+	ID          string     `json:"id"`
+	Repo        string     `json:"repo"`
+	Branch      string     `json:"branch,omitempty"`
+	Ref         string     `json:"ref,omitempty"`
+	Status      string     `json:"status"`
+	RequestedBy string     `json:"requested_by,omitempty"`
+	Created     time.Time  `json:"created"`
+	Started     *time.Time `json:"started,omitempty"`
+	Finished    *time.Time `json:"finished,omitempty"`
+	Updated     []string   `json:"updated,omitempty"`
+	Error       string     `json:"error,omitempty"`
+}
+
+const JobTokenHeader = "X-Aggregator-Token"
+
+var (
+	ErrUnknownRepo   = errors.New("репозиторий не входит в конфигурацию")
+	ErrRefNotAllowed = errors.New("ветка не входит в список веток репозитория")
+)
+
+type JobFunc func(ctx context.Context, job *Job, logf func(format string, args ...any)) error
+
+// JobSettings are the parts of the queue that follow the aggregator config;
+// Reconfigure swaps them while jobs keep running.
+type JobSettings struct {
+	Repos    []string
+	Branches map[string][]string
+	// DefaultBranch resolves the branch built when a job names no ref.
+	DefaultBranch func(ctx context.Context, repo string) (string, error)
+	Run           JobFunc
+}
+
+type Jobs struct {
+	JobSettings
+	Dir   string
+	Token string
+	Logf  func(format string, args ...any)
+
+	mu         sync.Mutex
+	settingsMu sync.RWMutex
+}
+
+// Reconfigure replaces the settings for jobs submitted or started from now
+// on; a job that is already running finishes with the settings it started
+// with.
+func (q *Jobs) Reconfigure(s JobSettings) {
+	q.settingsMu.Lock()
+	defer q.settingsMu.Unlock()
+	q.JobSettings = s
+}
+
+func (q *Jobs) Settings() JobSettings {
+	q.settingsMu.RLock()
+	defer q.settingsMu.RUnlock()
+	return q.JobSettings
+}
+
+func (q *Jobs) path(id string) string {
+	return filepath.Join(q.Dir, id+".json")
+}
+
+func (q *Jobs) logPath(id string) string {
+	return filepath.Join(q.Dir, id+".log")
+}
+
+func (q *Jobs) appendLog(id, line string) {
+	f, err := os.OpenFile(q.logPath(id), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		q.logf("Ошибка записи журнала задачи %s: %v", id, err)
+		return
+	}
+	defer f.Close()
+	fmt.Fprintf(f, "%s %s\n", time.Now().UTC().Format(time.TimeOnly), line)
+}
+
+func (q *Jobs) Log(id string) ([]byte, error) {
+	if _, err := q.Get(id); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(q.logPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
+
+func (q *Jobs) Get(id string) (*Job, error) {
+	if id == "" || strings.ContainsAny(id, `/\.`) {
+		return nil, os.ErrNotExist
+	}
+	data, err := os.ReadFile(q.path(id))
+	if err != nil {
+		return nil, err
+	}
+	var job Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, fmt.Errorf("задача %s: %w", id, err)
+	}
+	return &job, nil
+}
+
+func (q *Jobs) save(job *Job) error {
+	data, err := json.MarshalIndent(job, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(q.Dir, 0o755); err != nil {
+		return err
+	}
+	tmp := q.path(job.ID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, q.path(job.ID))
+}
+
+func (q *Jobs) Submit(repo, branch, ref, requestedBy string) (*Job, error) {
+	if !slices.Contains(q.Settings().Repos, repo) {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownRepo, repo)
+	}
+	b := make([]byte, 8)
+	rand.Read(b)
+	job := &Job{
+		ID:          hex.EncodeToString(b),
+		Repo:        repo,
+		Branch:      branch,
+		Ref:         ref,
+		Status:      JobQueued,
+		RequestedBy: requestedBy,
+		Created:     time.Now().UTC(),
+	}
+	return job, q.save(job)
+}
+
+// CheckRef returns the branch a job for repo builds: ref itself, or the
+// repository's default branch when ref is empty. Either way the branch has to
+// match the repository's allow-list.
+func (q *Jobs) CheckRef(ctx context.Context, repo, ref string) (string, error) {
+	s := q.Settings()
+	if !slices.Contains(s.Repos, repo) {
+		return "", fmt.Errorf("%w: %q", ErrUnknownRepo, repo)
+	}
+	branch := strings.TrimPrefix(ref, "refs/heads/")
+	if branch == "" {
+		if s.DefaultBranch == nil {
+			return "", fmt.Errorf("%w: не указана ветка (ref)", ErrRefNotAllowed)
+		}
+		var err error
+		if branch, err = s.DefaultBranch(ctx, repo); err != nil {
+			return "", fmt.Errorf("ветка по умолчанию %s: %w", repo, err)
+		}
+	}
+	for _, pattern := range s.Branches[repo] {
+		if ok, _ := path.Match(pattern, branch); ok {
+			return branch, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %q (допустимы %s)", ErrRefNotAllowed, branch, strings.Join(s.Branches[repo], ", "))
+}
+
+func (q *Jobs) List() ([]*Job, error) {
+	entries, err := os.ReadDir(q.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var jobs []*Job
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), ".json")
+		if !ok {
+			continue
+		}
+		job, err := q.Get(id)
+		if err != nil {
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].Created.After(jobs[j].Created) })
+	return jobs, nil
+}
+
+func (q *Jobs) Prune(keep func(index int, created time.Time) bool) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	jobs, err := q.List()
+	if err != nil {
+		return 0, err
+	}
+	removed, finished := 0, 0
+	for _, job := range jobs {
+		if !job.Done() {
+			continue
+		}
+		finished++
+		if keep(finished-1, job.Created) {
+			continue
+		}
+		if err := os.Remove(q.path(job.ID)); err != nil {
+			return removed, err
+		}
+		if err := os.Remove(q.logPath(job.ID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+func (q *Jobs) logf(format string, args ...any) {
+	if q.Logf != nil {
+		q.Logf(format, args...)
+	}
+}
+
+func (q *Jobs) Work(ctx context.Context, interval time.Duration) {
+	q.requeueRunning()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := q.runQueued(ctx); err != nil && ctx.Err() == nil {
+			q.logf("Ошибка очереди задач: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (q *Jobs) requeueRunning() {
+	jobs, err := q.List()
+	if err != nil {
+		return
+	}
+	for _, job := range jobs {
+		if job.Status == JobRunning {
+			job.Status, job.Started = JobQueued, nil
+			q.save(job)
+		}
+	}
+}
+
+func (q *Jobs) runQueued(ctx context.Context) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	jobs, err := q.List()
+	if err != nil {
+		return err
+	}
+	slices.Reverse(jobs)
+	for _, job := range jobs {
+		if job.Status != JobQueued {
+			continue
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		now := time.Now().UTC()
+		job.Status, job.Started = JobRunning, &now
+		if err := q.save(job); err != nil {
+			return err
+		}
+		id := job.ID
+		logf := func(format string, args ...any) {
+			q.appendLog(id, fmt.Sprintf(format, args...))
+		}
+		switch {
+		case job.Branch != "" && job.Branch != job.Ref:
+			logf("Задача запущена: %s (%s, %.7s)", job.Repo, job.Branch, job.Ref)
+		case job.Ref != "":
+			logf("Задача запущена: %s (%s)", job.Repo, job.Ref)
+		default:
+			logf("Задача запущена: %s", job.Repo)
+		}
+		err := q.Settings().Run(ctx, job, logf)
+		if ctx.Err() != nil {
+			logf("Задача прервана и возвращена в очередь")
+			job.Status, job.Started = JobQueued, nil
+			return q.save(job)
+		}
+		finished := time.Now().UTC()
+		job.Finished = &finished
+		if err != nil {
+			job.Status, job.Error = JobFailed, err.Error()
+			logf("Ошибка: %v", err)
+			q.logf("Задача %s (%s) завершилась ошибкой: %v", job.ID, job.Repo, err)
+		} else {
+			job.Status = JobSucceeded
+			logf("Задача выполнена")
+		}
+		if err := q.save(job); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) handleSubmitJob(w http.ResponseWriter, r *http.Request) {
+	token := r.Header.Get(JobTokenHeader)
+	if s.Jobs.Token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.Jobs.Token)) != 1 {
+		http.Error(w, "для запуска задач нужен токен в заголовке "+JobTokenHeader, http.StatusForbidden)
+		return
+	}
+	if mt, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mt != "application/json" {
+		http.Error(w, "ожидается Content-Type: application/json", http.StatusUnsupportedMediaType)
+		return
+	}
+	var req struct {
+		Repo string `json:"repo"`
+		Ref  string `json:"ref"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16)).Decode(&req); err != nil {
+		http.Error(w, "некорректный JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	var requestedBy string
+	if p, ok := PrincipalFrom(r.Context()); ok {
+		requestedBy = p.Name
+	}
+	var job *Job
+	branch, err := s.Jobs.CheckRef(r.Context(), req.Repo, req.Ref)
+	if err == nil {
+		job, err = s.Jobs.Submit(req.Repo, branch, branch, requestedBy)
+	}
+	if errors.Is(err, ErrUnknownRepo) || errors.Is(err, ErrRefNotAllowed) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", s.BasePath+"/jobs/"+job.ID)
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job)
+}
+
+func (s *Server) handleGetJob(w http.ResponseWriter, r *http.Request) {
+	job, err := s.Jobs.Get(r.PathValue("id"))
+	if errors.Is(err, os.ErrNotExist) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
+
+func (s *Server) handleListJobs(w http.ResponseWriter, r *http.Request) {
+	jobs, err := s.Jobs.List()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if jobs == nil {
+		jobs = []*Job{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(jobs)
+}
+
+func (s *Server) handleJobLog(w http.ResponseWriter, r *http.Request) {
+	data, err := s.Jobs.Log(r.PathValue("id"))
+	if errors.Is(err, os.ErrNotExist) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(data)
+}
@@ -0,0 +1,33 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCheckRefEmpty(t *testing.T) {
+	ctx := context.Background()
+	q := &Jobs{JobSettings: JobSettings{Repos: []string{"pets"}, Branches: map[string][]string{"pets": {"main", "release/*"}}}}
+
+	if _, err := q.CheckRef(ctx, "pets", ""); !errors.Is(err, ErrRefNotAllowed) {
+		t.Errorf("empty ref without a default branch: err = %v, want ErrRefNotAllowed", err)
+	}
+
+	q.DefaultBranch = func(context.Context, string) (string, error) { return "main", nil }
+	if branch, err := q.CheckRef(ctx, "pets", ""); err != nil || branch != "main" {
+		t.Errorf("empty ref resolved to %q, %v; want main", branch, err)
+	}
+
+	q.DefaultBranch = func(context.Context, string) (string, error) { return "develop", nil }
+	if _, err := q.CheckRef(ctx, "pets", ""); !errors.Is(err, ErrRefNotAllowed) {
+		t.Errorf("default branch outside the allow-list: err = %v, want ErrRefNotAllowed", err)
+	}
+
+	if branch, err := q.CheckRef(ctx, "pets", "refs/heads/release/1.2"); err != nil || branch != "release/1.2" {
+		t.Errorf("allowed ref: %q, %v", branch, err)
+	}
+	if _, err := q.CheckRef(ctx, "other", "main"); !errors.Is(err, ErrUnknownRepo) {
+		t.Errorf("unknown repo: err = %v", err)
+	}
+}
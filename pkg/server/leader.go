@@ -0,0 +1,188 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type Lease struct {
+	Path   string
+	Holder string
+	TTL    time.Duration
+}
+
+type leaseRecord struct {
+	Holder  string    `json:"holder"`
+	Expires time.Time `json:"expires"`
+}
+
+func (l *Lease) read() (leaseRecord, error) {
+	var rec leaseRecord
+	data, err := os.ReadFile(l.Path)
+	if err != nil {
+		return rec, err
+	}
+	err = json.Unmarshal(data, &rec)
+	return rec, err
+}
+
+// Acquire takes or renews the lease. The read-check-write runs under a lock
+// file created with O_EXCL, so two replicas can't both take an expired lease.
+func (l *Lease) Acquire() (bool, error) {
+	if err := os.MkdirAll(filepath.Dir(l.Path), 0o755); err != nil {
+		return false, err
+	}
+	unlock, err := l.lock()
+	if errors.Is(err, errLeaseBusy) {
+		rec, err := l.read()
+		if err != nil {
+			return false, nil
+		}
+		return rec.Holder == l.Holder && time.Now().Before(rec.Expires), nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	rec, err := l.read()
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+	if err == nil && rec.Holder != l.Holder && time.Now().Before(rec.Expires) {
+		return false, nil
+	}
+	data, err := json.Marshal(leaseRecord{Holder: l.Holder, Expires: time.Now().Add(l.TTL)})
+	if err != nil {
+		return false, err
+	}
+	tmp := l.Path + "." + l.Holder + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return false, err
+	}
+	if err := os.Rename(tmp, l.Path); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+var errLeaseBusy = errors.New("аренда занята другой репликой")
+
+// lock creates the lock file next to the lease. A lock left by a crashed
+// replica is broken once it is older than the TTL, see breakLock.
+func (l *Lease) lock() (func(), error) {
+	path := l.Path + ".lock"
+	for attempt := 0; ; attempt++ {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err == nil {
+			f.WriteString(l.Holder)
+			own, err := f.Stat()
+			f.Close()
+			if err != nil {
+				os.Remove(path)
+				return nil, err
+			}
+			return func() {
+				if info, err := os.Stat(path); err == nil && os.SameFile(info, own) {
+					os.Remove(path)
+				}
+			}, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		info, err := os.Stat(path)
+		if attempt > 0 || err != nil || time.Since(info.ModTime()) < l.TTL {
+			return nil, errLeaseBusy
+		}
+		if err := l.breakLock(path); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// breakLock moves a stale lock out of the way. Replicas that found the same
+// stale lock take turns through a second O_EXCL file, and the lock is checked
+// again under it, so a fresh lock taken in the meantime is never broken. The
+// stale lock is renamed to a name unique to this replica and the create is
+// retried only when that rename succeeded.
+func (l *Lease) breakLock(path string) error {
+	guard := path + ".break"
+	g, err := os.OpenFile(guard, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if errors.Is(err, os.ErrExist) {
+		// The guard lives for a few syscalls; an old one was left by a
+		// replica that crashed while breaking.
+		if info, err := os.Stat(guard); err == nil && time.Since(info.ModTime()) >= l.TTL {
+			os.Remove(guard)
+		}
+		return errLeaseBusy
+	}
+	if err != nil {
+		return err
+	}
+	g.Close()
+	defer os.Remove(guard)
+
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if time.Since(info.ModTime()) < l.TTL {
+		return errLeaseBusy
+	}
+	broken := path + "." + l.Holder + ".stale"
+	if err := os.Rename(path, broken); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return errLeaseBusy
+		}
+		return err
+	}
+	return os.Remove(broken)
+}
+
+func (l *Lease) Release() error {
+	rec, err := l.read()
+	if err != nil || rec.Holder != l.Holder {
+		return nil
+	}
+	return os.Remove(l.Path)
+}
+
+func (l *Lease) Run(ctx context.Context, logf func(format string, args ...any), lead func(ctx context.Context)) {
+	ticker := time.NewTicker(l.TTL / 3)
+	defer ticker.Stop()
+	var cancel context.CancelFunc
+	for {
+		ok, err := l.Acquire()
+		if err != nil {
+			logf("Ошибка продления аренды %s: %v", l.Path, err)
+		}
+		switch {
+		case ok && cancel == nil:
+			logf("Реплика %s стала ведущей", l.Holder)
+			var leadCtx context.Context
+			leadCtx, cancel = context.WithCancel(ctx)
+			go lead(leadCtx)
+		case !ok && cancel != nil:
+			logf("Реплика %s больше не ведущая", l.Holder)
+			cancel()
+			cancel = nil
+		}
+		select {
+		case <-ctx.Done():
+			if cancel != nil {
+				cancel()
+				l.Release()
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
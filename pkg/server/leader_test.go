@@ -0,0 +1,87 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestLeaseStaleLockRace starts replicas at once on a lock left by a crashed
+// one. Each keeps what it got until all have tried: exactly one may hold the
+// lock, and only that one may take the lease.
+func TestLeaseStaleLockRace(t *testing.T) {
+	// Replicas are separate processes: let the goroutines interleave even on
+	// a single CPU.
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(8))
+	path := filepath.Join(t.TempDir(), "lease.json")
+	var replicas []*Lease
+	for i := 0; i < 8; i++ {
+		replicas = append(replicas, &Lease{Path: path, Holder: fmt.Sprintf("replica-%d", i), TTL: time.Minute})
+	}
+	for round := 0; round < 300; round++ {
+		os.Remove(path)
+		if err := os.WriteFile(path+".lock", []byte("crashed"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		old := time.Now().Add(-time.Hour)
+		if err := os.Chtimes(path+".lock", old, old); err != nil {
+			t.Fatal(err)
+		}
+
+		var tried, done sync.WaitGroup
+		start := make(chan struct{})
+		holders := make(chan string, len(replicas))
+		for _, l := range replicas {
+			tried.Add(1)
+			done.Add(1)
+			go func() {
+				defer done.Done()
+				<-start
+				unlock, err := l.lock()
+				tried.Done()
+				if errors.Is(err, errLeaseBusy) {
+					return
+				}
+				if err != nil {
+					t.Errorf("round %d: %s: %v", round, l.Holder, err)
+					return
+				}
+				holders <- l.Holder
+				tried.Wait()
+				unlock()
+			}()
+		}
+		close(start)
+		done.Wait()
+		close(holders)
+
+		var got []string
+		for h := range holders {
+			got = append(got, h)
+		}
+		if len(got) != 1 {
+			t.Fatalf("round %d: lock held by %v, want exactly one replica", round, got)
+		}
+		if _, err := os.Stat(path + ".lock"); !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("round %d: lock left behind: %v", round, err)
+		}
+	}
+
+	// A stale lock does not keep the lease from being taken.
+	if err := os.WriteFile(path+".lock", nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(path+".lock", old, old)
+	if ok, err := replicas[0].Acquire(); !ok || err != nil {
+		t.Fatalf("Acquire over a stale lock: %v, %v", ok, err)
+	}
+	if ok, _ := replicas[1].Acquire(); ok {
+		t.Fatal("second replica took a lease that is held")
+	}
+}
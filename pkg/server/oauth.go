@@ -0,0 +1,13 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/RastBast/docs12121/pkg/render"
+)
+
+func handleOAuthRedirect(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write([]byte(render.OAuthRedirectPage))
+}
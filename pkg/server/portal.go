@@ -0,0 +1,80 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/RastBast/docs12121/pkg/spec"
+)
+
+type APIEntry struct {
+	Name        string `json:"name"`
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+	SpecURL     string `json:"spec_url"`
+	DocsURL     string `json:"docs_url"`
+	VersionsURL string `json:"versions_url"`
+	HistoryURL  string `json:"history_url"`
+}
+
+func (s *Server) apis() ([]APIEntry, error) {
+	docs, err := s.loadDocuments()
+	if err != nil {
+		return nil, err
+	}
+	entries := []APIEntry{}
+	for _, name := range spec.SortedRepos(docs) {
+		doc := docs[name]
+		prefix := s.BasePath + "/apis/" + url.PathEscape(name)
+		entries = append(entries, APIEntry{
+			Name:        name,
+			Title:       doc.Info.Title,
+			Version:     doc.Info.Version,
+			Description: doc.Info.Description,
+			SpecURL:     prefix + "/openapi.yaml",
+			DocsURL:     prefix + "/docs",
+			VersionsURL: prefix + "/versions",
+			HistoryURL:  prefix + "/history",
+		})
+	}
+	return entries, nil
+}
+
+func (s *Server) handleAPIs(w http.ResponseWriter, r *http.Request) {
+	entries, err := s.apis()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
+
+func (s *Server) handlePortal(w http.ResponseWriter, r *http.Request) {
+	entries, err := s.apis()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	p := &spec.Portal{Title: "API Documentation", Cards: []spec.PortalCard{}}
+	for _, e := range entries {
+		title := e.Title
+		if title == "" {
+			title = e.Name
+		}
+		p.Cards = append(p.Cards, spec.PortalCard{
+			Name:        e.Name,
+			Title:       title,
+			Version:     e.Version,
+			Description: e.Description,
+			SpecURL:     e.SpecURL,
+			Interactive: e.DocsURL,
+			History:     e.HistoryURL,
+		})
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	p.WriteHTML(w)
+}
@@ -0,0 +1,161 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+const proxyPrefix = "/proxy/"
+
+// ProxyAuthorizationHeader carries the API credentials of a "Try it" request.
+// The browser's Authorization and Cookie belong to the portal itself and are
+// never forwarded; this header becomes Authorization for targets allowed to
+// receive credentials.
+const ProxyAuthorizationHeader = "X-Try-Authorization"
+
+var (
+	proxyDropHeaders       = []string{"Origin", "Referer"}
+	proxyCredentialHeaders = []string{"Authorization", "Cookie", "Proxy-Authorization"}
+)
+
+func ParseProxyTargets(s string) (map[string]*url.URL, error) {
+	targets := make(map[string]*url.URL)
+	for _, pair := range strings.Split(s, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		name, raw, ok := strings.Cut(pair, "=")
+		if !ok || name == "" || strings.Contains(name, "/") {
+			return nil, fmt.Errorf("ожидается имя=URL, получено %q", pair)
+		}
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("некорректный адрес окружения %s: %q", name, raw)
+		}
+		targets[name] = u
+	}
+	return targets, nil
+}
+
+func setCORSHeaders(h http.Header, r *http.Request) {
+	h.Set("Access-Control-Allow-Origin", "*")
+	h.Set("Access-Control-Expose-Headers", "*")
+	if r.Method == http.MethodOptions {
+		h.Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, HEAD, OPTIONS")
+		if req := r.Header.Get("Access-Control-Request-Headers"); req != "" {
+			h.Set("Access-Control-Allow-Headers", req)
+		}
+		h.Set("Access-Control-Max-Age", "600")
+	}
+}
+
+func (s *Server) setProxyCORSHeaders(h http.Header, r *http.Request) {
+	origin := r.Header.Get("Origin")
+	h.Add("Vary", "Origin")
+	if origin == "" || !s.proxyOriginAllowed(origin, r.Host) {
+		return
+	}
+	h.Set("Access-Control-Allow-Origin", origin)
+	if r.Method == http.MethodOptions {
+		h.Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, HEAD, OPTIONS")
+		if req := r.Header.Get("Access-Control-Request-Headers"); req != "" {
+			h.Set("Access-Control-Allow-Headers", req)
+		}
+		h.Set("Access-Control-Max-Age", "600")
+	}
+}
+
+func (s *Server) proxyOriginAllowed(origin, host string) bool {
+	if u, err := url.Parse(origin); err == nil && u.Host == host {
+		return true
+	}
+	for _, allowed := range s.ProxyOrigins {
+		if strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
+
+func ParseProxyOrigins(s string) ([]string, error) {
+	var origins []string
+	for _, raw := range strings.Split(s, ",") {
+		if raw = strings.TrimSpace(raw); raw == "" {
+			continue
+		}
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || strings.Trim(u.Path, "/") != "" {
+			return nil, fmt.Errorf("ожидается источник вида https://host[:порт], получено %q", raw)
+		}
+		origins = append(origins, u.Scheme+"://"+u.Host)
+	}
+	return origins, nil
+}
+
+func isPreflight(r *http.Request) bool {
+	return r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" &&
+		(strings.HasPrefix(r.URL.Path, proxyPrefix) || strings.HasPrefix(r.URL.Path, "/apis/"))
+}
+
+func (s *Server) withPreflight(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isPreflight(r) {
+			if strings.HasPrefix(r.URL.Path, proxyPrefix) {
+				s.setProxyCORSHeaders(w.Header(), r)
+			} else {
+				setCORSHeaders(w.Header(), r)
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) tryItProxy() http.HandlerFunc {
+	proxies := make(map[string]*httputil.ReverseProxy, len(s.Proxy))
+	for name, target := range s.Proxy {
+		credentials := slices.Contains(s.ProxyCredentials, name)
+		proxies[name] = &httputil.ReverseProxy{
+			Rewrite: func(pr *httputil.ProxyRequest) {
+				pr.Out.URL.Path, pr.Out.URL.RawPath = "/"+pr.In.PathValue("path"), ""
+				pr.SetURL(target)
+				for _, h := range proxyDropHeaders {
+					pr.Out.Header.Del(h)
+				}
+				for _, h := range proxyCredentialHeaders {
+					pr.Out.Header.Del(h)
+				}
+				pr.Out.Header.Del(ProxyAuthorizationHeader)
+				if auth := pr.In.Header.Get(ProxyAuthorizationHeader); credentials && auth != "" {
+					pr.Out.Header.Set("Authorization", auth)
+				}
+			},
+			ModifyResponse: func(resp *http.Response) error {
+				for key := range resp.Header {
+					if strings.HasPrefix(key, "Access-Control-") {
+						resp.Header.Del(key)
+					}
+				}
+				resp.Header.Del("Set-Cookie")
+				return nil
+			},
+			ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+				http.Error(w, fmt.Sprintf("окружение %s недоступно: %v", name, err), http.StatusBadGateway)
+			},
+		}
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.setProxyCORSHeaders(w.Header(), r)
+		p, ok := proxies[r.PathValue("target")]
+		if !ok {
+			http.Error(w, "неизвестное окружение", http.StatusNotFound)
+			return
+		}
+		p.ServeHTTP(w, r)
+	}
+}
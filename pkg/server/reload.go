@@ -0,0 +1,61 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+type Reloadable struct {
+	current atomic.Pointer[http.Handler]
+}
+
+func NewReloadable(h http.Handler) *Reloadable {
+	r := &Reloadable{}
+	r.Swap(h)
+	return r
+}
+
+func (r *Reloadable) Swap(h http.Handler) {
+	r.current.Store(&h)
+}
+
+func (r *Reloadable) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	(*r.current.Load()).ServeHTTP(w, req)
+}
+
+func WatchFiles(ctx context.Context, interval time.Duration, paths []string, onChange func()) {
+	last := fingerprint(paths)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if current := fingerprint(paths); current != last {
+			last = current
+			onChange()
+		}
+	}
+}
+
+func fingerprint(paths []string) [sha256.Size]byte {
+	h := sha256.New()
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			data = []byte(err.Error())
+		}
+		h.Write([]byte(p + "\x00"))
+		h.Write(data)
+		h.Write([]byte{0})
+	}
+	var sum [sha256.Size]byte
+	copy(sum[:], h.Sum(nil))
+	return sum
+}
@@ -0,0 +1,211 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"slices"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/RastBast/docs12121/pkg/render"
+)
+
+type SandboxOAuth struct {
+	ClientID string   `yaml:"client_id" json:"clientId"`
+	Scopes   []string `yaml:"scopes" json:"scopes,omitempty"`
+	UsePKCE  bool     `yaml:"-" json:"usePkceWithAuthorizationCodeGrant"`
+}
+
+type SandboxEnvironment struct {
+	APIKeys   map[string]string `yaml:"api_keys" json:"api_keys,omitempty"`
+	OAuth     *SandboxOAuth     `yaml:"oauth" json:"oauth,omitempty"`
+	Audiences []string          `yaml:"audiences" json:"-"`
+}
+
+type Sandbox map[string]*SandboxEnvironment
+
+func LoadSandbox(path string) (Sandbox, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Environments Sandbox `yaml:"environments"`
+	}
+	if err := yaml.Unmarshal([]byte(expandEnv(string(data))), &file); err != nil {
+		return nil, fmt.Errorf("разбор %s: %w", path, err)
+	}
+	for name, env := range file.Environments {
+		if env == nil {
+			return nil, fmt.Errorf("окружение %s пустое", name)
+		}
+		if env.OAuth != nil {
+			env.OAuth.UsePKCE = true
+		}
+	}
+	return file.Environments, nil
+}
+
+func (e *SandboxEnvironment) allows(p Principal, authenticated bool) bool {
+	if len(e.Audiences) == 0 {
+		return true
+	}
+	if !authenticated {
+		return false
+	}
+	for _, a := range e.Audiences {
+		if a == p.Name || slices.Contains(p.Groups, a) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s Sandbox) For(r *http.Request) Sandbox {
+	p, ok := PrincipalFrom(r.Context())
+	out := Sandbox{}
+	for name, env := range s {
+		if env.allows(p, ok) {
+			out[name] = env
+		}
+	}
+	return out
+}
+
+func (s Sandbox) pick(name string) *SandboxEnvironment {
+	if name != "" {
+		return s[name]
+	}
+	names := make([]string, 0, len(s))
+	for n := range s {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		return nil
+	}
+	return s[names[0]]
+}
+
+var docsPage = template.Must(template.New("docs").Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<title>{{ .Repo }}</title>
+<link rel="stylesheet" href="{{ .CSS.URL }}"{{ with .CSS.Integrity }} integrity="{{ . }}" crossorigin="anonymous"{{ end }}>
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="{{ .JS.URL }}"{{ with .JS.Integrity }} integrity="{{ . }}" crossorigin="anonymous"{{ end }}></script>
+<script>
+const sandbox = {{ .Sandbox }};
+const ui = SwaggerUIBundle({
+  url: {{ .SpecURL }},
+  dom_id: "#swagger-ui",
+  requestInterceptor: (req) => {
+    // API credentials for the "Try it" proxy travel in their own header, the
+    // proxy never forwards the portal's Authorization.
+    const proxied = new URL(req.url, window.location.href).pathname.startsWith({{ .ProxyPrefix }});
+    if (proxied && req.headers.Authorization) {
+      req.headers[{{ .ProxyAuthHeader }}] = req.headers.Authorization;
+      delete req.headers.Authorization;
+    }
+    return req;
+  },
+  oauth2RedirectUrl: new URL({{ .OAuthRedirect }}, window.location.href).href,
+  onComplete: () => {
+    for (const [name, value] of Object.entries((sandbox && sandbox.api_keys) || {})) {
+      ui.preauthorizeApiKey(name, value);
+    }
+  },
+});
+const oauth = {{ .OAuth }};
+if (oauth) {
+  ui.initOAuth(oauth);
+}
+</script>
+</body>
+</html>
+`))
+
+func (s *Server) handleDocs(w http.ResponseWriter, r *http.Request) {
+	repo := r.PathValue("repo")
+	specURL := "spec"
+	if v := r.URL.Query().Get("version"); v != "" {
+		specURL += "?version=" + url.QueryEscape(v)
+	}
+	css, err := s.pageAsset("swagger-ui.css")
+	var js pageAsset
+	if err == nil {
+		js, err = s.pageAsset("swagger-ui-bundle.js")
+	}
+	if err != nil {
+		s.logf("страница документации %s: %v", repo, err)
+		http.Error(w, "ресурсы Swagger UI недоступны", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	env := s.Sandbox.For(r).pick(r.URL.Query().Get("env"))
+	var oauth *SandboxOAuth
+	if env != nil && env.OAuth != nil {
+		oauth = env.OAuth
+	} else if s.OAuthClientID != "" {
+		oauth = &SandboxOAuth{ClientID: s.OAuthClientID, UsePKCE: true}
+	}
+	docsPage.Execute(w, struct {
+		Repo            string
+		SpecURL         string
+		OAuthRedirect   string
+		Sandbox         *SandboxEnvironment
+		OAuth           *SandboxOAuth
+		CSS, JS         pageAsset
+		ProxyPrefix     string
+		ProxyAuthHeader string
+	}{repo, specURL, s.BasePath + "/oauth2-redirect.html", env, oauth, css, js, s.BasePath + proxyPrefix, ProxyAuthorizationHeader})
+}
+
+type pageAsset struct {
+	URL       string
+	Integrity string
+}
+
+// pageAsset points the docs page at a vendored asset served by handleAsset,
+// or at its pinned CDN copy checked with Subresource Integrity.
+func (s *Server) pageAsset(name string) (pageAsset, error) {
+	a, data, err := render.Lookup(name)
+	if err != nil {
+		return pageAsset{}, err
+	}
+	if data != nil {
+		return pageAsset{URL: s.BasePath + "/assets/" + name}, nil
+	}
+	return pageAsset{URL: a.CDN(), Integrity: a.Integrity}, nil
+}
+
+func handleAsset(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("file")
+	_, data, err := render.Lookup(name)
+	if err != nil || data == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if ct := mime.TypeByExtension(path.Ext(name)); ct != "" {
+		w.Header().Set("Content-Type", ct)
+	}
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	w.Write(data)
+}
+
+func (s *Server) handleSandbox(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(s.Sandbox.For(r))
+}
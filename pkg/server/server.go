@@ -0,0 +1,221 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/RastBast/docs12121/pkg/spec"
+	"github.com/RastBast/docs12121/pkg/subscribe"
+)
+
+type Server struct {
+	Dir      string
+	BasePath string
+	Access   AccessConfig
+	Proxy    map[string]*url.URL
+	Sandbox  Sandbox
+
+	ProxyOrigins     []string
+	ProxyCredentials []string
+
+	OAuthClientID string
+	Subscriptions *subscribe.Store
+	Jobs          *Jobs
+	Logf          func(format string, args ...any)
+}
+
+func (s *Server) logf(format string, args ...any) {
+	if s.Logf != nil {
+		s.Logf(format, args...)
+	}
+}
+
+// loadDocuments reads the served specifications, logging and skipping the
+// ones that fail to parse so one broken file does not take down the portal.
+func (s *Server) loadDocuments() (map[string]*spec.Document, error) {
+	docs, err := spec.LoadDocuments(s.Dir)
+	var bad spec.DocumentErrors
+	if errors.As(err, &bad) {
+		for _, err := range bad {
+			s.logf("спецификация пропущена: %v", err)
+		}
+		return docs, nil
+	}
+	return docs, err
+}
+
+func (s *Server) Handler() http.Handler {
+	return s.withPreflight(WithAccessControl(s.Access, s.routes()))
+}
+
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /robots.txt", robotsHandler(s.Access))
+	mux.HandleFunc("GET /{$}", s.handlePortal)
+	mux.HandleFunc("GET /apis", s.handleAPIs)
+	mux.HandleFunc("GET /apis/{repo}/versions", s.handleVersions)
+	mux.HandleFunc("GET /apis/{repo}/spec", s.handleSpec)
+	mux.HandleFunc("GET /apis/{repo}/openapi.yaml", s.handleSpecFile(formatYAML))
+	mux.HandleFunc("GET /apis/{repo}/openapi.json", s.handleSpecFile(formatJSON))
+	mux.HandleFunc("GET /apis/{repo}/versions/{version}/openapi.yaml", s.handleSpecFile(formatYAML))
+	mux.HandleFunc("GET /apis/{repo}/versions/{version}/openapi.json", s.handleSpecFile(formatJSON))
+	mux.HandleFunc("GET /apis/{repo}/docs", s.handleDocs)
+	mux.HandleFunc("GET /apis/{repo}/history", s.handleHistory)
+	mux.HandleFunc("GET /sandbox", s.handleSandbox)
+	mux.HandleFunc("GET /oauth2-redirect.html", handleOAuthRedirect)
+	mux.HandleFunc("GET /assets/{file}", handleAsset)
+	mux.HandleFunc("GET /sunset.ics", s.handleSunsetCalendar)
+	mux.HandleFunc("GET /webhooks", s.handleWebhooks)
+	mux.HandleFunc("GET /sdks", s.handleSDKs)
+	if s.Subscriptions != nil {
+		mux.HandleFunc("GET /subscriptions", s.handleListSubscriptions)
+		mux.HandleFunc("POST /subscriptions", s.handleAddSubscription)
+		mux.HandleFunc("DELETE /subscriptions/{id}", s.handleDeleteSubscription)
+	}
+	if s.Jobs != nil {
+		mux.HandleFunc("GET /jobs", s.handleListJobs)
+		mux.HandleFunc("POST /jobs", s.handleSubmitJob)
+		mux.HandleFunc("GET /jobs/{id}", s.handleGetJob)
+		mux.HandleFunc("GET /jobs/{id}/log", s.handleJobLog)
+		mux.HandleFunc("GET /jobs/{id}/events", s.handleJobEvents)
+		mux.HandleFunc("GET /admin", s.handleAdmin)
+	}
+	if len(s.Proxy) > 0 {
+		mux.HandleFunc(proxyPrefix+"{target}/{path...}", s.tryItProxy())
+	}
+	return mux
+}
+
+func (s *Server) handleVersions(w http.ResponseWriter, r *http.Request) {
+	history, err := spec.History(r.Context(), s.Dir, r.PathValue("repo"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(history) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	repo := r.PathValue("repo")
+	h, err := spec.BuildReleaseHistory(r.Context(), s.Dir, repo, spec.ReleaseLinks{
+		Download: func(repo, version string) string {
+			return s.BasePath + "/apis/" + url.PathEscape(repo) + "/spec?version=" + url.QueryEscape(version)
+		},
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(h.Releases) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	h.WriteHTML(w)
+}
+
+func (s *Server) handleSpec(w http.ResponseWriter, r *http.Request) {
+	s.serveSpec(w, r, r.URL.Query().Get("version"), negotiateFormat(r))
+}
+
+func (s *Server) handleSpecFile(format string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.serveSpec(w, r, r.PathValue("version"), format)
+	}
+}
+
+func (s *Server) serveSpec(w http.ResponseWriter, r *http.Request, version, format string) {
+	repo := r.PathValue("repo")
+	if _, err := spec.ParseRange(version); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	history, err := spec.History(r.Context(), s.Dir, repo)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	v, ok := spec.Resolve(history, version)
+	if !ok {
+		http.Error(w, "подходящая версия не найдена", http.StatusNotFound)
+		return
+	}
+	data, err := spec.AtCommit(r.Context(), s.Dir, v.Commit, repo)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if annotated, _, err := spec.AnnotateSLO(data); err != nil {
+		s.logf("%s@%s: SLO не добавлены, отдаётся исходная спецификация: %v", repo, v.Version, err)
+	} else {
+		data = annotated
+	}
+	if r.URL.Query().Has("samples") {
+		langs := spec.SampleLanguages
+		if list := r.URL.Query().Get("samples"); list != "" {
+			if langs, err = spec.ParseSampleLanguages(list); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		if data, _, err = spec.AddCodeSamples(data, langs); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	contentType := "application/yaml"
+	if format == formatJSON {
+		if data, err = spec.ToJSON(data); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		contentType = "application/json"
+	}
+	w.Header().Set("X-Spec-Version", v.Version)
+	w.Header().Set("X-Spec-Commit", v.Commit)
+	writeContract(w, r, contentType, data)
+}
+
+func (s *Server) handleSunsetCalendar(w http.ResponseWriter, r *http.Request) {
+	sunsets, err := spec.Sunsets(s.Dir)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	now := time.Now()
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	spec.WriteICS(w, spec.Upcoming(sunsets, now), now)
+}
+
+func (s *Server) handleWebhooks(w http.ResponseWriter, r *http.Request) {
+	docs, err := s.loadDocuments()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	spec.CollectEvents(docs).WriteHTML(w)
+}
+
+func (s *Server) handleSDKs(w http.ResponseWriter, r *http.Request) {
+	docs, err := s.loadDocuments()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	report, err := spec.BuildSDKReport(s.Dir, docs)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+
+	"github.com/RastBast/docs12121/pkg/subscribe"
+)
+
+const SubscriptionSecretHeader = "X-Subscription-Secret"
+
+// handleListSubscriptions returns only the caller's own subscriptions:
+// webhook URLs of chat integrations are secrets.
+func (s *Server) handleListSubscriptions(w http.ResponseWriter, r *http.Request) {
+	p, ok := PrincipalFrom(r.Context())
+	if !ok {
+		http.Error(w, "управление подписками через API доступно только при включённой авторизации", http.StatusForbidden)
+		return
+	}
+	own := []subscribe.Subscription{}
+	for _, sub := range s.Subscriptions.List() {
+		if sub.Owner != "" && sub.Owner == p.Name {
+			own = append(own, sub)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(own)
+}
+
+func (s *Server) handleAddSubscription(w http.ResponseWriter, r *http.Request) {
+	p, ok := PrincipalFrom(r.Context())
+	if !ok {
+		http.Error(w, "управление подписками через API доступно только при включённой авторизации", http.StatusForbidden)
+		return
+	}
+	if mt, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mt != "application/json" {
+		http.Error(w, "ожидается Content-Type: application/json", http.StatusUnsupportedMediaType)
+		return
+	}
+	var sub subscribe.Subscription
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16)).Decode(&sub); err != nil {
+		http.Error(w, "некорректный JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	created, secret, err := s.Subscriptions.Add(sub, p.Name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(struct {
+		subscribe.Subscription
+		Secret string `json:"secret"`
+	}{created, secret})
+}
+
+func (s *Server) handleDeleteSubscription(w http.ResponseWriter, r *http.Request) {
+	if _, ok := PrincipalFrom(r.Context()); !ok {
+		http.Error(w, "управление подписками через API доступно только при включённой авторизации", http.StatusForbidden)
+		return
+	}
+	found, err := s.Subscriptions.Remove(r.PathValue("id"), r.Header.Get(SubscriptionSecretHeader))
+	switch {
+	case errors.Is(err, subscribe.ErrForbidden):
+		http.Error(w, "для удаления нужен секрет подписки в заголовке "+SubscriptionSecretHeader, http.StatusForbidden)
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	case !found:
+		http.NotFound(w, r)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
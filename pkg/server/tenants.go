@@ -0,0 +1,116 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+var tenantName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// envRef matches ${NAME} references and the $$ escape for a literal dollar.
+var envRef = regexp.MustCompile(`\$\$|\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+type Tenant struct {
+	Dir              string `yaml:"dir"`
+	BasicAuth        string `yaml:"basic_auth"`
+	Allow            string `yaml:"allow"`
+	OIDCIssuer       string `yaml:"oidc_issuer"`
+	OIDCAudience     string `yaml:"oidc_audience"`
+	OAuthClientID    string `yaml:"oauth_client_id"`
+	Proxy            string `yaml:"proxy"`
+	ProxyOrigins     string `yaml:"proxy_origins"`
+	ProxyCredentials string `yaml:"proxy_credentials"`
+	Sandbox          string `yaml:"sandbox"`
+	Subscriptions    string `yaml:"subscriptions"`
+	Config           string `yaml:"config"`
+	Organization     string `yaml:"organization"`
+	DocsRepo         string `yaml:"docs_repo"`
+	Token            string `yaml:"token"`
+	JobsToken        string `yaml:"jobs_token"`
+	Push             bool   `yaml:"push"`
+	Glossary         string `yaml:"glossary"`
+}
+
+func LoadTenants(path string) (map[string]Tenant, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Tenants map[string]Tenant `yaml:"tenants"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("разбор %s: %w", path, err)
+	}
+	if len(file.Tenants) == 0 {
+		return nil, fmt.Errorf("%s: не описано ни одного арендатора", path)
+	}
+	dirs, subscriptions := map[string]string{}, map[string]string{}
+	for name, t := range file.Tenants {
+		if !tenantName.MatchString(name) {
+			return nil, fmt.Errorf("некорректное имя арендатора %q (допустимы строчные латинские буквы, цифры, - и _)", name)
+		}
+		if t.Dir == "" {
+			return nil, fmt.Errorf("арендатор %s: не указан каталог документации (dir)", name)
+		}
+		if other, ok := dirs[t.Dir]; ok {
+			return nil, fmt.Errorf("арендаторы %s и %s используют один каталог %s", other, name, t.Dir)
+		}
+		dirs[t.Dir] = name
+		if other, ok := subscriptions[t.Subscriptions]; ok && t.Subscriptions != "" {
+			return nil, fmt.Errorf("арендаторы %s и %s используют один файл подписок %s", other, name, t.Subscriptions)
+		}
+		subscriptions[t.Subscriptions] = name
+		for _, field := range []*string{&t.BasicAuth, &t.ProxyCredentials, &t.Token, &t.JobsToken} {
+			*field = expandEnv(*field)
+		}
+		file.Tenants[name] = t
+	}
+	return file.Tenants, nil
+}
+
+// expandEnv substitutes ${NAME} with the environment variable so secrets can
+// stay out of the tenants file. Other dollar signs are kept as they are, and
+// $$ stands for a literal $.
+func expandEnv(s string) string {
+	return envRef.ReplaceAllStringFunc(s, func(m string) string {
+		if m == "$$" {
+			return "$"
+		}
+		return os.Getenv(m[2 : len(m)-1])
+	})
+}
+
+func (t Tenant) Access() (AccessConfig, error) {
+	access := AccessConfig{OIDCIssuer: t.OIDCIssuer, OIDCAudience: t.OIDCAudience}
+	// Without an audience any token of the issuer, issued to any client,
+	// would be accepted.
+	if access.OIDCIssuer != "" && access.OIDCAudience == "" {
+		return AccessConfig{}, errors.New("oidc_issuer задан без oidc_audience")
+	}
+	var err error
+	if access.BasicAuth, err = ParseBasicAuth(t.BasicAuth); err != nil {
+		return AccessConfig{}, fmt.Errorf("basic_auth: %w", err)
+	}
+	if access.AllowedNets, err = ParseAllowList(t.Allow); err != nil {
+		return AccessConfig{}, fmt.Errorf("allow: %w", err)
+	}
+	return access, nil
+}
+
+func TenantsHandler(sites map[string]http.Handler) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte("User-agent: *\nDisallow: /\n"))
+	})
+	for name, h := range sites {
+		mux.Handle("/"+name+"/", http.StripPrefix("/"+name, h))
+	}
+	return mux
+}
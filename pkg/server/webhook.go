@@ -0,0 +1,112 @@
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+)
+
+type PushEvent struct {
+	Ref        string `json:"ref"`
+	Before     string `json:"before"`
+	After      string `json:"after"`
+	Repository struct {
+		Name  string `json:"name"`
+		Fork  bool   `json:"fork"`
+		Owner struct {
+			Login string `json:"login"`
+		} `json:"owner"`
+	} `json:"repository"`
+	Pusher struct {
+		Login string `json:"login"`
+	} `json:"pusher"`
+}
+
+func (e *PushEvent) Branch() (string, bool) {
+	return strings.CutPrefix(e.Ref, "refs/heads/")
+}
+
+func (e *PushEvent) Created() bool {
+	return strings.Trim(e.Before, "0") == ""
+}
+
+func (e *PushEvent) Deleted() bool {
+	return strings.Trim(e.After, "0") == ""
+}
+
+type WebhookReceiver struct {
+	Secret string
+	Jobs   *Jobs
+	Route  func(ctx context.Context, ev *PushEvent) (repo, reason string)
+	Logf   func(format string, args ...any)
+}
+
+func (h *WebhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 5<<20))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if h.Secret != "" && !validSignature(h.Secret, body, r.Header) {
+		http.Error(w, "неверная подпись вебхука", http.StatusUnauthorized)
+		return
+	}
+	event := r.Header.Get("X-Gitea-Event")
+	if event == "" {
+		event = r.Header.Get("X-GitHub-Event")
+	}
+	if event != "push" {
+		writeIgnored(w, "событие "+event+" не обрабатывается")
+		return
+	}
+	var ev PushEvent
+	if err := json.Unmarshal(body, &ev); err != nil {
+		http.Error(w, "некорректный JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	repo, reason := h.Route(r.Context(), &ev)
+	if repo == "" {
+		writeIgnored(w, reason)
+		return
+	}
+	requestedBy := ev.Pusher.Login
+	if requestedBy == "" {
+		requestedBy = "webhook"
+	}
+	branch, _ := ev.Branch()
+	job, err := h.Jobs.Submit(repo, branch, ev.After, requestedBy)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if h.Logf != nil {
+		h.Logf("Push в %s (%s): поставлена задача %s", repo, ev.Ref, job.ID)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job)
+}
+
+func validSignature(secret string, body []byte, header http.Header) bool {
+	sig := header.Get("X-Gitea-Signature")
+	if sig == "" {
+		sig, _ = strings.CutPrefix(header.Get("X-Hub-Signature-256"), "sha256=")
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil || len(got) == 0 {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+func writeIgnored(w http.ResponseWriter, reason string) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ignored", "reason": reason})
+}
@@ -0,0 +1,207 @@
+package spec
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	SharedVersionKey = "x-shared-version"
+	SharedBundledKey = "x-shared-bundled"
+)
+
+type SharedSource func(expr string) (data []byte, version string, err error)
+
+func SharedFromDocs(ctx context.Context, dir, repo string) SharedSource {
+	return func(expr string) ([]byte, string, error) {
+		if expr == "" {
+			data, err := os.ReadFile(filepath.Join(dir, repo, "openapi.yaml"))
+			if err != nil {
+				return nil, "", err
+			}
+			info, err := ParseInfo(data)
+			return data, info.Version, err
+		}
+		history, err := History(ctx, dir, repo)
+		if err != nil {
+			return nil, "", err
+		}
+		v, ok := Resolve(history, expr)
+		if !ok {
+			return nil, "", fmt.Errorf("в истории %s нет версии, подходящей под %q", repo, expr)
+		}
+		data, err := AtCommit(ctx, dir, v.Commit, repo)
+		return data, v.Version, err
+	}
+}
+
+type componentRef struct {
+	kind string
+	name string
+}
+
+func sharedTarget(ref, sharedRepo string) (componentRef, bool) {
+	file, fragment, ok := strings.Cut(ref, "#")
+	if !ok || file == "" || path.Base(file) != "openapi.yaml" || path.Base(path.Dir(file)) != sharedRepo {
+		return componentRef{}, false
+	}
+	return localTarget("#" + fragment)
+}
+
+func localTarget(ref string) (componentRef, bool) {
+	rest, ok := strings.CutPrefix(ref, "#/components/")
+	if !ok {
+		return componentRef{}, false
+	}
+	kind, name, ok := strings.Cut(rest, "/")
+	if !ok || name == "" || strings.Contains(name, "/") {
+		return componentRef{}, false
+	}
+	return componentRef{kind, name}, true
+}
+
+func walkRefs(n *yaml.Node, fn func(value *yaml.Node)) {
+	if n.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if n.Content[i].Value == "$ref" && n.Content[i+1].Kind == yaml.ScalarNode {
+				fn(n.Content[i+1])
+			}
+		}
+	}
+	for _, c := range n.Content {
+		walkRefs(c, fn)
+	}
+}
+
+func nodeValue(m *yaml.Node, key string) *yaml.Node {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func ensureMapping(m *yaml.Node, key string) *yaml.Node {
+	if v := nodeValue(m, key); v != nil {
+		return v
+	}
+	v := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, v)
+	return v
+}
+
+func sameNode(a, b *yaml.Node) bool {
+	ea, err1 := yaml.Marshal(a)
+	eb, err2 := yaml.Marshal(b)
+	return err1 == nil && err2 == nil && bytes.Equal(ea, eb)
+}
+
+func Bundle(data []byte, sharedRepo string, source SharedSource) ([]byte, string, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, "", fmt.Errorf("разбор спецификации: %w", err)
+	}
+	if len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return nil, "", fmt.Errorf("спецификация должна быть YAML-объектом")
+	}
+	doc := root.Content[0]
+
+	var queue []componentRef
+	walkRefs(doc, func(v *yaml.Node) {
+		if target, ok := sharedTarget(v.Value, sharedRepo); ok {
+			queue = append(queue, target)
+			v.Value = "#/components/" + target.kind + "/" + target.name
+		}
+	})
+	if len(queue) == 0 {
+		return data, "", nil
+	}
+
+	expr := ""
+	if v := nodeValue(doc, SharedVersionKey); v != nil {
+		expr = v.Value
+	}
+	sharedData, version, err := source(expr)
+	if err != nil {
+		return nil, "", fmt.Errorf("загрузка общих компонентов %s: %w", sharedRepo, err)
+	}
+	var shared yaml.Node
+	if err := yaml.Unmarshal(sharedData, &shared); err != nil || len(shared.Content) == 0 {
+		return nil, "", fmt.Errorf("разбор общих компонентов %s: %v", sharedRepo, err)
+	}
+	sharedComponents := nodeValue(shared.Content[0], "components")
+
+	components := ensureMapping(doc, "components")
+	done := map[componentRef]bool{}
+	for len(queue) > 0 {
+		ref := queue[0]
+		queue = queue[1:]
+		if done[ref] {
+			continue
+		}
+		done[ref] = true
+
+		src := nodeValue(nodeValue(sharedComponents, ref.kind), ref.name)
+		if src == nil {
+			return nil, "", fmt.Errorf("в %s %s нет компонента %s/%s", sharedRepo, version, ref.kind, ref.name)
+		}
+		var copied yaml.Node
+		raw, err := yaml.Marshal(src)
+		if err != nil {
+			return nil, "", err
+		}
+		if err := yaml.Unmarshal(raw, &copied); err != nil {
+			return nil, "", err
+		}
+		node := copied.Content[0]
+		walkRefs(node, func(v *yaml.Node) {
+			if target, ok := localTarget(v.Value); ok {
+				queue = append(queue, target)
+			}
+		})
+
+		kind := ensureMapping(components, ref.kind)
+		if existing := nodeValue(kind, ref.name); existing != nil {
+			if !sameNode(existing, node) {
+				return nil, "", fmt.Errorf("компонент %s/%s уже определён в сервисе и отличается от общего", ref.kind, ref.name)
+			}
+			continue
+		}
+		kind.Content = append(kind.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: ref.name}, node)
+	}
+
+	if v := nodeValue(doc, SharedBundledKey); v != nil {
+		v.Value = sharedRepo + "@" + version
+	} else {
+		doc.Content = append(doc.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: SharedBundledKey},
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: sharedRepo + "@" + version})
+	}
+
+	out, err := encodeNode(&root)
+	return out, version, err
+}
+
+func encodeNode(root *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(root); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
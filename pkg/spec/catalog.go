@@ -0,0 +1,91 @@
+package spec
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+func CatalogFiles(dir string) ([]string, error) {
+	files, err := SpecFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	sdks, err := filepath.Glob(filepath.Join(dir, "*", SDKFile))
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, sdks...)
+	for i, f := range files {
+		files[i], _ = filepath.Rel(dir, f)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ManifestFile)); err == nil {
+		files = append(files, ManifestFile)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func WriteZip(w io.Writer, dir string, files []string) error {
+	zw := zip.NewWriter(w)
+	for _, name := range files {
+		path := filepath.Join(dir, name)
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		hdr, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(name)
+		hdr.Method = zip.Deflate
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		f, err := zw.CreateHeader(hdr)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func WriteTarGz(w io.Writer, dir string, files []string) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	for _, name := range files {
+		path := filepath.Join(dir, name)
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(name)
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
@@ -0,0 +1,256 @@
+package spec
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/RastBast/docs12121/pkg/textdiff"
+)
+
+const compareContext = 3
+
+var unsafeSourceID = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+type CompareSource struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+	Data  []byte `json:"-"`
+}
+
+func NewCompareSource(kind, name, label string, data []byte) CompareSource {
+	return CompareSource{ID: kind + "-" + unsafeSourceID.ReplaceAllString(name, "_"), Label: label, Data: data}
+}
+
+type Comparison struct {
+	Repo       string                    `json:"repo"`
+	From       string                    `json:"from"`
+	To         string                    `json:"to"`
+	Changes    []Change                  `json:"changes"`
+	Counts     map[string]int            `json:"counts,omitempty"`
+	Rows       []textdiff.Row            `json:"rows"`
+	Operations map[string][]textdiff.Row `json:"operations,omitempty"`
+}
+
+func (c *Comparison) File() string {
+	return c.From + ".." + c.To + ".json"
+}
+
+func Compare(repo string, from, to CompareSource) (*Comparison, error) {
+	a, err := ParseDocument(from.Data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", from.Label, err)
+	}
+	b, err := ParseDocument(to.Data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", to.Label, err)
+	}
+	c := &Comparison{Repo: repo, From: from.ID, To: to.ID, Changes: Diff(a, b)}
+	c.Counts = CountChanges(c.Changes)
+	c.Rows = textdiff.SideBySide(string(from.Data), string(to.Data), compareContext)
+
+	left, err := OperationFragments(from.Data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", from.Label, err)
+	}
+	right, err := OperationFragments(to.Data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", to.Label, err)
+	}
+	c.Operations = map[string][]textdiff.Row{}
+	for key, text := range left {
+		c.Operations[key] = textdiff.SideBySide(text, right[key], -1)
+	}
+	for key, text := range right {
+		if _, ok := left[key]; !ok {
+			c.Operations[key] = textdiff.SideBySide("", text, -1)
+		}
+	}
+	return c, nil
+}
+
+func OperationFragments(data []byte) (map[string]string, error) {
+	doc, err := ParseDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("разбор спецификации: %w", err)
+	}
+	if len(root.Content) == 0 {
+		return nil, nil
+	}
+	paths := nodeValue(root.Content[0], "paths")
+	fragments := map[string]string{}
+	for _, op := range doc.Operations() {
+		node := nodeValue(nodeValue(paths, op.Path), strings.ToLower(op.Method))
+		if node == nil {
+			continue
+		}
+		out, err := encodeNode(node)
+		if err != nil {
+			return nil, err
+		}
+		fragments[op.String()] = string(out)
+	}
+	return fragments, nil
+}
+
+type CompareService struct {
+	Repo    string          `json:"repo"`
+	Sources []CompareSource `json:"sources"`
+	Pairs   []string        `json:"pairs"`
+}
+
+type ComparePage struct {
+	Services []CompareService `json:"services"`
+}
+
+var comparePage = template.Must(template.New("compare").Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<title>Сравнение версий API</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table.diff { border-collapse: collapse; width: 100%; font-family: monospace; font-size: 0.85em; }
+table.diff td { padding: 0 0.5em; white-space: pre-wrap; vertical-align: top; width: 50%; }
+tr.removed td.left, tr.changed td.left { background: #fdecea; }
+tr.added td.right, tr.changed td.right { background: #e8f5e9; }
+tr.skipped td { color: #888; text-align: center; }
+.change-breaking { color: #c62828; }
+</style>
+</head>
+<body>
+<h1>Сравнение версий API</h1>
+{{- if not .Services }}
+<p>Нет сервисов с несколькими версиями для сравнения.</p>
+{{- else }}
+<form id="compare">
+  <label>Сервис <select name="repo"></select></label>
+  <label>Слева <select name="from"></select></label>
+  <label>Справа <select name="to"></select></label>
+  <label>Операция <select name="operation"><option value="">вся спецификация</option></select></label>
+</form>
+<ul id="changes"></ul>
+<table class="diff"><tbody id="rows"></tbody></table>
+<script id="compare-data" type="application/json">{{ .Services }}</script>
+<script>
+var services = JSON.parse(document.getElementById("compare-data").textContent);
+var form = document.getElementById("compare");
+var current = null;
+
+function fill(select, items, value, label) {
+  select.innerHTML = "";
+  items.forEach(function (item) {
+    var opt = document.createElement("option");
+    opt.value = value(item);
+    opt.textContent = label(item);
+    select.appendChild(opt);
+  });
+}
+
+function service() {
+  return services.find(function (s) { return s.repo === form.repo.value; });
+}
+
+function selectService() {
+  var sources = service().sources;
+  fill(form.from, sources, function (s) { return s.id; }, function (s) { return s.label; });
+  fill(form.to, sources, function (s) { return s.id; }, function (s) { return s.label; });
+  if (sources.length > 1) {
+    form.from.value = sources[1].id;
+  }
+  load();
+}
+
+function load() {
+  var rows = document.getElementById("rows");
+  var changes = document.getElementById("changes");
+  if (form.from.value === form.to.value) {
+    current = null;
+    changes.innerHTML = "<li>Выберите разные версии.</li>";
+    rows.innerHTML = "";
+    return;
+  }
+  var file = form.from.value + ".." + form.to.value + ".json";
+  if (service().pairs.indexOf(file) < 0) {
+    current = null;
+    changes.innerHTML = "<li>Для этой пары сравнение не подготовлено: выберите соседние версии или сравните с текущей.</li>";
+    rows.innerHTML = "";
+    return;
+  }
+  fetch(encodeURI(form.repo.value) + "/" + file)
+    .then(function (r) { return r.json(); })
+    .then(function (c) {
+      current = c;
+      var op = form.operation.value;
+      var ops = Object.keys(c.operations || {}).sort();
+      form.operation.length = 1;
+      ops.forEach(function (key) { form.operation.add(new Option(key, key)); });
+      form.operation.value = ops.indexOf(op) >= 0 ? op : "";
+      render();
+    });
+}
+
+function render() {
+  var c = current;
+  var op = form.operation.value;
+  var changes = document.getElementById("changes");
+  var rows = document.getElementById("rows");
+  changes.innerHTML = "";
+  rows.innerHTML = "";
+  var list = (c.changes || []).filter(function (ch) { return !op || ch.operation === op; });
+  if (!list.length) {
+    changes.innerHTML = "<li>Изменений контракта нет.</li>";
+  }
+  list.forEach(function (ch) {
+    var li = document.createElement("li");
+    li.className = "change-" + ch.category;
+    li.textContent = (ch.operation ? ch.operation + ": " : "") + ch.message;
+    changes.appendChild(li);
+  });
+  (op ? c.operations[op] : c.rows).forEach(function (row) {
+    var tr = document.createElement("tr");
+    tr.className = row.kind;
+    if (row.kind === "skipped") {
+      var td = document.createElement("td");
+      td.colSpan = 2;
+      td.textContent = "… строк без изменений: " + row.lines;
+      tr.appendChild(td);
+    } else {
+      ["left", "right"].forEach(function (side) {
+        var td = document.createElement("td");
+        td.className = side;
+        td.textContent = row[side] || "";
+        tr.appendChild(td);
+      });
+    }
+    rows.appendChild(tr);
+  });
+}
+
+fill(form.repo, services, function (s) { return s.repo; }, function (s) { return s.repo; });
+var requested = new URLSearchParams(location.search).get("repo");
+if (requested && services.some(function (s) { return s.repo === requested; })) {
+  form.repo.value = requested;
+}
+form.repo.addEventListener("change", selectService);
+form.from.addEventListener("change", load);
+form.to.addEventListener("change", load);
+form.operation.addEventListener("change", function () { if (current) render(); });
+selectService();
+</script>
+{{- end }}
+</body>
+</html>
+`))
+
+func (p *ComparePage) WriteHTML(w io.Writer) error {
+	return comparePage.Execute(w, p)
+}
@@ -0,0 +1,298 @@
+package spec
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const (
+	ChangeBreaking    = "breaking"
+	ChangeAddition    = "addition"
+	ChangeDeprecation = "deprecation"
+	ChangeModified    = "change"
+)
+
+var ChangeCategories = []string{ChangeBreaking, ChangeAddition, ChangeDeprecation, ChangeModified}
+
+type Change struct {
+	Category  string `json:"category"`
+	Operation string `json:"operation,omitempty"`
+	Message   string `json:"message"`
+}
+
+func (c Change) String() string {
+	if c.Operation == "" {
+		return c.Message
+	}
+	return c.Operation + ": " + c.Message
+}
+
+func operationIndex(d *Document) map[string]OperationRef {
+	index := map[string]OperationRef{}
+	for _, op := range d.Operations() {
+		index[op.String()] = op
+	}
+	return index
+}
+
+func Diff(from, to *Document) []Change {
+	var changes []Change
+	add := func(category, op, format string, args ...any) {
+		changes = append(changes, Change{Category: category, Operation: op, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if from.Info.Version != to.Info.Version {
+		add(ChangeModified, "", "версия %s → %s", orDash(from.Info.Version), orDash(to.Info.Version))
+	}
+
+	before, after := operationIndex(from), operationIndex(to)
+	keys := make([]string, 0, len(before)+len(after))
+	for k := range before {
+		keys = append(keys, k)
+	}
+	for k := range after {
+		if _, ok := before[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		a, inOld := before[key]
+		b, inNew := after[key]
+		switch {
+		case !inNew:
+			add(ChangeBreaking, key, "операция удалена")
+			continue
+		case !inOld:
+			add(ChangeAddition, key, "новая операция")
+			continue
+		}
+		if b.Operation.Deprecated && !a.Operation.Deprecated {
+			add(ChangeDeprecation, key, "операция помечена deprecated")
+		}
+
+		oldParams := map[string]*Parameter{}
+		for _, p := range from.Parameters(a) {
+			oldParams[p.In+"/"+p.Name] = p
+		}
+		for _, p := range to.Parameters(b) {
+			prev, ok := oldParams[p.In+"/"+p.Name]
+			delete(oldParams, p.In+"/"+p.Name)
+			switch {
+			case !ok && p.Required:
+				add(ChangeBreaking, key, "новый обязательный параметр %s (%s)", p.Name, p.In)
+			case !ok:
+				add(ChangeAddition, key, "новый параметр %s (%s)", p.Name, p.In)
+			case p.Required && !prev.Required:
+				add(ChangeBreaking, key, "параметр %s стал обязательным", p.Name)
+			default:
+				before, after := from.ResolveSchema(prev.Schema), to.ResolveSchema(p.Schema)
+				if changed, was, now := typeChanged(before, after); changed {
+					add(ChangeBreaking, key, "параметр %s: тип %s → %s", p.Name, was, now)
+				}
+				if removed := removedEnum(before, after); len(removed) > 0 {
+					add(ChangeBreaking, key, "параметр %s: из enum удалены %s", p.Name, strings.Join(removed, ", "))
+				}
+			}
+		}
+		for _, name := range sortedParamKeys(oldParams) {
+			p := oldParams[name]
+			add(ChangeModified, key, "параметр %s (%s) удалён", p.Name, p.In)
+		}
+
+		oldBody, newBody := from.ResolveRequestBody(a.Operation.RequestBody), to.ResolveRequestBody(b.Operation.RequestBody)
+		if newBody != nil && newBody.Required && (oldBody == nil || !oldBody.Required) {
+			add(ChangeBreaking, key, "тело запроса стало обязательным")
+		}
+		oldReq, newReq := from.requiredBodyFields(a.Operation), to.requiredBodyFields(b.Operation)
+		for _, f := range newReq {
+			if !slices.Contains(oldReq, f) {
+				add(ChangeBreaking, key, "в теле запроса появилось обязательное поле %s", f)
+			}
+		}
+		oldProps, newProps := from.bodyProperties(a.Operation), to.bodyProperties(b.Operation)
+		for _, name := range sortedSchemaKeys(newProps) {
+			prev, ok := oldProps[name]
+			if !ok {
+				continue
+			}
+			if changed, was, now := typeChanged(prev, newProps[name]); changed {
+				add(ChangeBreaking, key, "поле тела запроса %s: тип %s → %s", name, was, now)
+			}
+			if removed := removedEnum(prev, newProps[name]); len(removed) > 0 {
+				add(ChangeBreaking, key, "поле тела запроса %s: из enum удалены %s", name, strings.Join(removed, ", "))
+			}
+		}
+
+		for _, code := range sortedResponseCodes(a.Operation.Responses) {
+			if _, ok := b.Operation.Responses[code]; !ok && strings.HasPrefix(code, "2") {
+				add(ChangeBreaking, key, "удалён ответ %s", code)
+			}
+		}
+
+		oldResp, newResp := from.successFields(a.Operation), to.successFields(b.Operation)
+		for _, f := range oldResp {
+			if !slices.Contains(newResp, f) {
+				add(ChangeBreaking, key, "из успешного ответа удалено поле %s", f)
+			}
+		}
+		for _, f := range newResp {
+			if !slices.Contains(oldResp, f) {
+				add(ChangeAddition, key, "в успешном ответе новое поле %s", f)
+			}
+		}
+	}
+	return changes
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "—"
+	}
+	return s
+}
+
+func sortedParamKeys(m map[string]*Parameter) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func removedEnum(a, b *Schema) []string {
+	if a == nil || b == nil || len(a.Enum) == 0 || len(b.Enum) == 0 {
+		return nil
+	}
+	kept := map[string]bool{}
+	for _, v := range b.Enum {
+		kept[fmt.Sprint(v)] = true
+	}
+	var removed []string
+	for _, v := range a.Enum {
+		if !kept[fmt.Sprint(v)] {
+			removed = append(removed, fmt.Sprint(v))
+		}
+	}
+	return removed
+}
+
+func typeChanged(a, b *Schema) (bool, string, string) {
+	if a == nil || b == nil || a.Type == "" || b.Type == "" || (a.Type == "integer" && b.Type == "number") {
+		return false, "", ""
+	}
+	return a.Type != b.Type, string(a.Type), string(b.Type)
+}
+
+func sortedSchemaKeys(m map[string]*Schema) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedResponseCodes(m map[string]*Response) []string {
+	codes := make([]string, 0, len(m))
+	for code := range m {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+func (d *Document) bodyProperties(op *Operation) map[string]*Schema {
+	body := d.ResolveRequestBody(op.RequestBody)
+	if body == nil {
+		return nil
+	}
+	props := map[string]*Schema{}
+	var walk func(s *Schema, depth int)
+	walk = func(s *Schema, depth int) {
+		s = d.ResolveSchema(s)
+		if s == nil || depth > 10 {
+			return
+		}
+		for name, p := range s.Properties {
+			props[name] = d.ResolveSchema(p)
+		}
+		for _, sub := range s.AllOf {
+			walk(sub, depth+1)
+		}
+	}
+	walk(jsonSchema(body.Content), 0)
+	return props
+}
+
+func jsonSchema(content map[string]*MediaType) *Schema {
+	types := make([]string, 0, len(content))
+	for ct := range content {
+		types = append(types, ct)
+	}
+	sort.Strings(types)
+	for _, ct := range types {
+		if mt := content[ct]; mt != nil && strings.Contains(ct, "json") {
+			return mt.Schema
+		}
+	}
+	return nil
+}
+
+func (d *Document) requiredBodyFields(op *Operation) []string {
+	body := d.ResolveRequestBody(op.RequestBody)
+	if body == nil {
+		return nil
+	}
+	set := map[string]bool{}
+	var walk func(s *Schema, depth int)
+	walk = func(s *Schema, depth int) {
+		s = d.ResolveSchema(s)
+		if s == nil || depth > 10 {
+			return
+		}
+		for _, name := range s.Required {
+			set[name] = true
+		}
+		for _, sub := range s.AllOf {
+			walk(sub, depth+1)
+		}
+	}
+	walk(jsonSchema(body.Content), 0)
+	fields := make([]string, 0, len(set))
+	for f := range set {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func (d *Document) successFields(op *Operation) []string {
+	codes := make([]string, 0, len(op.Responses))
+	for code := range op.Responses {
+		if strings.HasPrefix(code, "2") {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	if len(codes) == 0 {
+		return nil
+	}
+	r := d.ResolveResponse(op.Responses[codes[0]])
+	if r == nil {
+		return nil
+	}
+	return d.PropertyNames(jsonSchema(r.Content))
+}
+
+func CountChanges(changes []Change) map[string]int {
+	counts := map[string]int{}
+	for _, c := range changes {
+		counts[c.Category]++
+	}
+	return counts
+}
@@ -0,0 +1,403 @@
+package spec
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Document struct {
+	OpenAPI string `yaml:"openapi"`
+	Info    struct {
+		Title       string `yaml:"title"`
+		Version     string `yaml:"version"`
+		Description string `yaml:"description"`
+		Contact     struct {
+			Name  string `yaml:"name"`
+			URL   string `yaml:"url"`
+			Email string `yaml:"email"`
+		} `yaml:"contact"`
+	} `yaml:"info"`
+	Servers []struct {
+		URL         string `yaml:"url"`
+		Description string `yaml:"description"`
+	} `yaml:"servers"`
+	Security   []map[string][]string `yaml:"security"`
+	Paths      map[string]*PathItem  `yaml:"paths"`
+	Webhooks   map[string]*PathItem  `yaml:"webhooks"`
+	Components struct {
+		Schemas         map[string]*Schema         `yaml:"schemas"`
+		Responses       map[string]*Response       `yaml:"responses"`
+		Parameters      map[string]*Parameter      `yaml:"parameters"`
+		RequestBodies   map[string]*RequestBody    `yaml:"requestBodies"`
+		SecuritySchemes map[string]*SecurityScheme `yaml:"securitySchemes"`
+	} `yaml:"components"`
+}
+
+type PathItem struct {
+	Parameters []*Parameter `yaml:"parameters"`
+	Get        *Operation   `yaml:"get"`
+	Put        *Operation   `yaml:"put"`
+	Post       *Operation   `yaml:"post"`
+	Delete     *Operation   `yaml:"delete"`
+	Options    *Operation   `yaml:"options"`
+	Head       *Operation   `yaml:"head"`
+	Patch      *Operation   `yaml:"patch"`
+	Trace      *Operation   `yaml:"trace"`
+}
+
+type Operation struct {
+	OperationID string                `yaml:"operationId"`
+	Summary     string                `yaml:"summary"`
+	Description string                `yaml:"description"`
+	Tags        []string              `yaml:"tags"`
+	Deprecated  bool                  `yaml:"deprecated"`
+	Parameters  []*Parameter          `yaml:"parameters"`
+	RequestBody *RequestBody          `yaml:"requestBody"`
+	Responses   map[string]*Response  `yaml:"responses"`
+	Callbacks   map[string]Callback   `yaml:"callbacks"`
+	Security    []map[string][]string `yaml:"security"`
+	SLO         yaml.Node             `yaml:"x-slo"`
+}
+
+type Callback map[string]*PathItem
+
+func (c *Callback) UnmarshalYAML(n *yaml.Node) error {
+	*c = Callback{}
+	if n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i+1].Kind != yaml.MappingNode {
+			continue
+		}
+		var item PathItem
+		if err := n.Content[i+1].Decode(&item); err != nil {
+			return err
+		}
+		(*c)[n.Content[i].Value] = &item
+	}
+	return nil
+}
+
+type RequestBody struct {
+	Ref         string                `yaml:"$ref"`
+	Description string                `yaml:"description"`
+	Required    bool                  `yaml:"required"`
+	Content     map[string]*MediaType `yaml:"content"`
+}
+
+type SecurityScheme struct {
+	Type             string                `yaml:"type"`
+	Scheme           string                `yaml:"scheme"`
+	Name             string                `yaml:"name"`
+	In               string                `yaml:"in"`
+	OpenIDConnectURL string                `yaml:"openIdConnectUrl"`
+	Flows            map[string]*OAuthFlow `yaml:"flows"`
+}
+
+type OAuthFlow struct {
+	AuthorizationURL string            `yaml:"authorizationUrl"`
+	TokenURL         string            `yaml:"tokenUrl"`
+	RefreshURL       string            `yaml:"refreshUrl"`
+	Scopes           map[string]string `yaml:"scopes"`
+}
+
+type Parameter struct {
+	Ref         string  `yaml:"$ref"`
+	Name        string  `yaml:"name"`
+	In          string  `yaml:"in"`
+	Description string  `yaml:"description"`
+	Required    bool    `yaml:"required"`
+	Schema      *Schema `yaml:"schema"`
+	Example     any     `yaml:"example"`
+}
+
+type Response struct {
+	Ref         string                `yaml:"$ref"`
+	Description string                `yaml:"description"`
+	Content     map[string]*MediaType `yaml:"content"`
+}
+
+type MediaType struct {
+	Schema  *Schema `yaml:"schema"`
+	Example any     `yaml:"example"`
+}
+
+// SchemaType is the schema "type": a single name in OAS 3.0 or a list such
+// as [string, "null"] in OAS 3.1, which is reduced to its first non-null type.
+type SchemaType string
+
+func (t *SchemaType) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind != yaml.SequenceNode {
+		var s string
+		if err := n.Decode(&s); err != nil {
+			return err
+		}
+		*t = SchemaType(s)
+		return nil
+	}
+	var types []string
+	if err := n.Decode(&types); err != nil {
+		return err
+	}
+	*t = ""
+	for _, name := range types {
+		if name != "null" {
+			*t = SchemaType(name)
+			break
+		}
+	}
+	if *t == "" && len(types) > 0 {
+		*t = SchemaType(types[0])
+	}
+	return nil
+}
+
+type Schema struct {
+	Ref         string             `yaml:"$ref"`
+	Type        SchemaType         `yaml:"type"`
+	Format      string             `yaml:"format"`
+	Description string             `yaml:"description"`
+	Properties  map[string]*Schema `yaml:"properties"`
+	Required    []string           `yaml:"required"`
+	Items       *Schema            `yaml:"items"`
+	AllOf       []*Schema          `yaml:"allOf"`
+	OneOf       []*Schema          `yaml:"oneOf"`
+	AnyOf       []*Schema          `yaml:"anyOf"`
+	Enum        []any              `yaml:"enum"`
+	Example     any                `yaml:"example"`
+	Default     any                `yaml:"default"`
+}
+
+type OperationRef struct {
+	Method    string
+	Path      string
+	Operation *Operation
+	Shared    []*Parameter
+}
+
+func (o OperationRef) String() string {
+	return o.Method + " " + o.Path
+}
+
+func ParseDocument(data []byte) (*Document, error) {
+	var doc Document
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("разбор спецификации: %w", err)
+	}
+	return &doc, nil
+}
+
+var EnvironmentDirs []string
+
+func SpecFiles(dir string) ([]string, error) {
+	var files []string
+	patterns := []string{"*/openapi.yaml", "*/*/openapi.yaml"}
+	for _, env := range EnvironmentDirs {
+		patterns = append(patterns, env+"/*/*/openapi.yaml")
+	}
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(filepath.Join(dir, filepath.FromSlash(pattern)))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func SpecName(dir, file string) string {
+	rel, err := filepath.Rel(dir, filepath.Dir(file))
+	if err != nil {
+		return filepath.Base(filepath.Dir(file))
+	}
+	return filepath.ToSlash(rel)
+}
+
+func SplitEnvironment(name string) (env, rest string) {
+	if first, rest, ok := strings.Cut(name, "/"); ok && slices.Contains(EnvironmentDirs, first) {
+		return first, rest
+	}
+	return "", name
+}
+
+func SpecRepo(name string) string {
+	_, rest := SplitEnvironment(name)
+	repo, _, _ := strings.Cut(rest, "/")
+	return repo
+}
+
+// DocumentErrors lists the specifications LoadDocuments could not read;
+// the documents that did parse are returned alongside it.
+type DocumentErrors []error
+
+func (e DocumentErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func LoadDocuments(dir string) (map[string]*Document, error) {
+	files, err := SpecFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	docs := map[string]*Document{}
+	var errs DocumentErrors
+	for _, f := range files {
+		repo := SpecName(dir, f)
+		data, err := os.ReadFile(f)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", repo, err))
+			continue
+		}
+		doc, err := ParseDocument(data)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", repo, err))
+			continue
+		}
+		docs[repo] = doc
+	}
+	if len(errs) > 0 {
+		return docs, errs
+	}
+	return docs, nil
+}
+
+func SortedRepos(docs map[string]*Document) []string {
+	repos := make([]string, 0, len(docs))
+	for repo := range docs {
+		repos = append(repos, repo)
+	}
+	sort.Strings(repos)
+	return repos
+}
+
+func (d *Document) Operations() []OperationRef {
+	return operations(d.Paths)
+}
+
+func operations(items map[string]*PathItem) []OperationRef {
+	paths := make([]string, 0, len(items))
+	for p := range items {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var ops []OperationRef
+	for _, p := range paths {
+		item := items[p]
+		if item == nil {
+			continue
+		}
+		for _, m := range []struct {
+			name string
+			op   *Operation
+		}{
+			{"GET", item.Get}, {"PUT", item.Put}, {"POST", item.Post}, {"DELETE", item.Delete},
+			{"OPTIONS", item.Options}, {"HEAD", item.Head}, {"PATCH", item.Patch}, {"TRACE", item.Trace},
+		} {
+			if m.op != nil {
+				ops = append(ops, OperationRef{Method: m.name, Path: p, Operation: m.op, Shared: item.Parameters})
+			}
+		}
+	}
+	return ops
+}
+
+func (d *Document) Parameters(op OperationRef) []*Parameter {
+	var out []*Parameter
+	seen := map[string]bool{}
+	for _, list := range [][]*Parameter{op.Operation.Parameters, op.Shared} {
+		for _, p := range list {
+			p = d.ResolveParameter(p)
+			if p == nil || seen[p.In+"/"+p.Name] {
+				continue
+			}
+			seen[p.In+"/"+p.Name] = true
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func refName(ref, prefix string) (string, bool) {
+	return strings.CutPrefix(ref, prefix)
+}
+
+func (d *Document) ResolveParameter(p *Parameter) *Parameter {
+	for i := 0; p != nil && p.Ref != "" && i < 10; i++ {
+		name, ok := refName(p.Ref, "#/components/parameters/")
+		if !ok {
+			return nil
+		}
+		p = d.Components.Parameters[name]
+	}
+	return p
+}
+
+func (d *Document) ResolveResponse(r *Response) *Response {
+	for i := 0; r != nil && r.Ref != "" && i < 10; i++ {
+		name, ok := refName(r.Ref, "#/components/responses/")
+		if !ok {
+			return nil
+		}
+		r = d.Components.Responses[name]
+	}
+	return r
+}
+
+func (d *Document) ResolveRequestBody(b *RequestBody) *RequestBody {
+	for i := 0; b != nil && b.Ref != "" && i < 10; i++ {
+		name, ok := refName(b.Ref, "#/components/requestBodies/")
+		if !ok {
+			return nil
+		}
+		b = d.Components.RequestBodies[name]
+	}
+	return b
+}
+
+func (d *Document) ResolveSchema(s *Schema) *Schema {
+	for i := 0; s != nil && s.Ref != "" && i < 10; i++ {
+		name, ok := refName(s.Ref, "#/components/schemas/")
+		if !ok {
+			return nil
+		}
+		s = d.Components.Schemas[name]
+	}
+	return s
+}
+
+func (d *Document) PropertyNames(s *Schema) []string {
+	set := map[string]bool{}
+	var walk func(s *Schema, depth int)
+	walk = func(s *Schema, depth int) {
+		s = d.ResolveSchema(s)
+		if s == nil || depth > 10 {
+			return
+		}
+		for name := range s.Properties {
+			set[name] = true
+		}
+		for _, sub := range s.AllOf {
+			walk(sub, depth+1)
+		}
+	}
+	walk(s, 0)
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
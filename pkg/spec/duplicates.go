@@ -0,0 +1,170 @@
+package spec
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+type SchemaRef struct {
+	Repo   string `json:"repo"`
+	Schema string `json:"schema"`
+}
+
+func (r SchemaRef) String() string {
+	return r.Repo + "#" + r.Schema
+}
+
+type DuplicatePair struct {
+	A     SchemaRef `json:"a"`
+	B     SchemaRef `json:"b"`
+	Score float64   `json:"score"`
+}
+
+type DuplicateReport struct {
+	Threshold float64         `json:"threshold"`
+	Pairs     []DuplicatePair `json:"pairs"`
+	Groups    [][]SchemaRef   `json:"identical_groups"`
+}
+
+func (d *Document) schemaFeatures(s *Schema, prefix string, depth int, out map[string]bool) {
+	s = d.ResolveSchema(s)
+	if s == nil || depth > 5 {
+		return
+	}
+	if s.Type != "" {
+		out[prefix+":"+string(s.Type)] = true
+	}
+	for _, sub := range s.AllOf {
+		d.schemaFeatures(sub, prefix, depth+1, out)
+	}
+	for _, name := range s.Required {
+		out[prefix+"."+name+"!"] = true
+	}
+	for name, prop := range s.Properties {
+		out[prefix+"."+name] = true
+		d.schemaFeatures(prop, prefix+"."+name, depth+1, out)
+	}
+	if s.Items != nil {
+		d.schemaFeatures(s.Items, prefix+"[]", depth+1, out)
+	}
+	if len(s.Enum) > 0 {
+		values := make([]string, len(s.Enum))
+		for i, v := range s.Enum {
+			values[i] = fmt.Sprint(v)
+		}
+		sort.Strings(values)
+		out[prefix+"=enum("+strings.Join(values, ",")+")"] = true
+	}
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	inter := 0
+	for f := range a {
+		if b[f] {
+			inter++
+		}
+	}
+	union := len(a) + len(b) - inter
+	if union == 0 {
+		return 0
+	}
+	return float64(inter) / float64(union)
+}
+
+func FindDuplicateSchemas(docs map[string]*Document, threshold float64) *DuplicateReport {
+	type entry struct {
+		ref      SchemaRef
+		features map[string]bool
+	}
+	var entries []entry
+	for _, repo := range SortedRepos(docs) {
+		doc := docs[repo]
+		names := make([]string, 0, len(doc.Components.Schemas))
+		for name := range doc.Components.Schemas {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			features := map[string]bool{}
+			doc.schemaFeatures(doc.Components.Schemas[name], "", 0, features)
+			if len(features) < 3 {
+				continue
+			}
+			entries = append(entries, entry{SchemaRef{repo, name}, features})
+		}
+	}
+
+	r := &DuplicateReport{Threshold: threshold}
+	parent := make([]int, len(entries))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for i := range entries {
+		for j := i + 1; j < len(entries); j++ {
+			if entries[i].ref.Repo == entries[j].ref.Repo {
+				continue
+			}
+			score := jaccard(entries[i].features, entries[j].features)
+			if score < threshold {
+				continue
+			}
+			r.Pairs = append(r.Pairs, DuplicatePair{A: entries[i].ref, B: entries[j].ref, Score: score})
+			if score == 1 {
+				parent[find(j)] = find(i)
+			}
+		}
+	}
+	sort.SliceStable(r.Pairs, func(i, j int) bool { return r.Pairs[i].Score > r.Pairs[j].Score })
+
+	groups := map[int][]SchemaRef{}
+	var roots []int
+	for i := range entries {
+		root := find(i)
+		if _, ok := groups[root]; !ok {
+			roots = append(roots, root)
+		}
+		groups[root] = append(groups[root], entries[i].ref)
+	}
+	for _, root := range roots {
+		if len(groups[root]) > 1 {
+			r.Groups = append(r.Groups, groups[root])
+		}
+	}
+	return r
+}
+
+func (r *DuplicateReport) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("# Дублирующиеся схемы\n\n")
+	if len(r.Pairs) == 0 {
+		fmt.Fprintf(&b, "Похожих схем в разных сервисах не найдено (порог сходства %.0f%%).\n", r.Threshold*100)
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+	if len(r.Groups) > 0 {
+		b.WriteString("## Кандидаты в общую библиотеку компонентов\n\nСтруктурно идентичные схемы:\n\n")
+		for _, g := range r.Groups {
+			names := make([]string, len(g))
+			for i, ref := range g {
+				names[i] = "`" + ref.String() + "`"
+			}
+			fmt.Fprintf(&b, "- %s\n", strings.Join(names, ", "))
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "## Похожие схемы (сходство от %.0f%%)\n\n| Схема | Схема | Сходство |\n|---|---|---|\n", r.Threshold*100)
+	for _, p := range r.Pairs {
+		fmt.Fprintf(&b, "| `%s` | `%s` | %.0f%% |\n", p.A, p.B, p.Score*100)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,162 @@
+package spec
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+type ErrorResponse struct {
+	Repo        string `json:"repo"`
+	Operation   string `json:"operation"`
+	Status      string `json:"status"`
+	Description string `json:"description"`
+	Envelope    string `json:"envelope"`
+}
+
+type ErrorCatalog struct {
+	Responses []ErrorResponse `json:"responses"`
+	Canonical string          `json:"canonical"`
+}
+
+func isErrorStatus(code string) bool {
+	return code == "default" || strings.HasPrefix(code, "4") || strings.HasPrefix(code, "5")
+}
+
+func (d *Document) envelope(r *Response) string {
+	for _, mt := range []string{"application/problem+json", "application/json"} {
+		if c, ok := r.Content[mt]; ok && c != nil && c.Schema != nil {
+			return strings.Join(d.PropertyNames(c.Schema), ", ")
+		}
+	}
+	for _, c := range r.Content {
+		if c != nil && c.Schema != nil {
+			return strings.Join(d.PropertyNames(c.Schema), ", ")
+		}
+	}
+	return ""
+}
+
+func BuildErrorCatalog(docs map[string]*Document) *ErrorCatalog {
+	c := &ErrorCatalog{}
+	for _, repo := range SortedRepos(docs) {
+		doc := docs[repo]
+		for _, op := range doc.Operations() {
+			codes := make([]string, 0, len(op.Operation.Responses))
+			for code := range op.Operation.Responses {
+				if isErrorStatus(code) {
+					codes = append(codes, code)
+				}
+			}
+			sort.Strings(codes)
+			for _, code := range codes {
+				r := doc.ResolveResponse(op.Operation.Responses[code])
+				if r == nil {
+					continue
+				}
+				c.Responses = append(c.Responses, ErrorResponse{
+					Repo:        repo,
+					Operation:   op.String(),
+					Status:      code,
+					Description: r.Description,
+					Envelope:    doc.envelope(r),
+				})
+			}
+		}
+	}
+
+	repos := c.EnvelopeRepos()
+	for env, list := range repos {
+		if env == "" {
+			continue
+		}
+		if c.Canonical == "" || len(list) > len(repos[c.Canonical]) ||
+			(len(list) == len(repos[c.Canonical]) && env < c.Canonical) {
+			c.Canonical = env
+		}
+	}
+	return c
+}
+
+func (c *ErrorCatalog) EnvelopeRepos() map[string][]string {
+	seen := map[string]map[string]bool{}
+	for _, r := range c.Responses {
+		if seen[r.Envelope] == nil {
+			seen[r.Envelope] = map[string]bool{}
+		}
+		seen[r.Envelope][r.Repo] = true
+	}
+	out := map[string][]string{}
+	for env, set := range seen {
+		for repo := range set {
+			out[env] = append(out[env], repo)
+		}
+		sort.Strings(out[env])
+	}
+	return out
+}
+
+func (c *ErrorCatalog) Inconsistent() []ErrorResponse {
+	var out []ErrorResponse
+	for _, r := range c.Responses {
+		if r.Envelope != c.Canonical {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func (c *ErrorCatalog) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("# Каталог ошибок\n\n")
+	if len(c.Responses) == 0 {
+		b.WriteString("Ни одна спецификация не описывает ответы об ошибках.\n")
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+
+	b.WriteString("## Форматы ответов\n\n| Поля | Сервисы | |\n|---|---|---|\n")
+	envs := c.EnvelopeRepos()
+	keys := make([]string, 0, len(envs))
+	for env := range envs {
+		keys = append(keys, env)
+	}
+	sort.Strings(keys)
+	for _, env := range keys {
+		mark := ""
+		if env == c.Canonical {
+			mark = "основной"
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s |\n", envelopeLabel(env), strings.Join(envs[env], ", "), mark)
+	}
+
+	if bad := c.Inconsistent(); len(bad) > 0 {
+		fmt.Fprintf(&b, "\n## Расхождения с основным форматом\n\nОсновной формат: %s.\n\n", envelopeLabel(c.Canonical))
+		for _, r := range bad {
+			fmt.Fprintf(&b, "- %s: `%s` %s — %s\n", r.Repo, r.Operation, r.Status, envelopeLabel(r.Envelope))
+		}
+	}
+
+	repo := ""
+	for _, r := range c.Responses {
+		if r.Repo != repo {
+			repo = r.Repo
+			fmt.Fprintf(&b, "\n## %s\n\n| Код | Операция | Описание | Поля |\n|---|---|---|---|\n", repo)
+		}
+		fmt.Fprintf(&b, "| %s | `%s` | %s | %s |\n", r.Status, r.Operation, markdownCell(r.Description), envelopeLabel(r.Envelope))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func envelopeLabel(env string) string {
+	if env == "" {
+		return "без схемы"
+	}
+	return "`" + env + "`"
+}
+
+func markdownCell(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(strings.TrimSpace(s), "\n", " "), "|", `\|`)
+}
@@ -0,0 +1,68 @@
+package spec
+
+import (
+	"context"
+	"os/exec"
+	"path"
+	"strings"
+	"time"
+)
+
+type Version struct {
+	Version string    `json:"version"`
+	Commit  string    `json:"commit"`
+	Date    time.Time `json:"date"`
+}
+
+func History(ctx context.Context, dir, repo string) ([]Version, error) {
+	file := path.Join(repo, "openapi.yaml")
+	out, err := exec.CommandContext(ctx, "git", "-C", dir, "log", "--format=%H %cI", "--", file).Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []Version
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		commit, date, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		data, err := AtCommit(ctx, dir, commit, repo)
+		if err != nil {
+			continue
+		}
+		info, err := ParseInfo(data)
+		if err != nil || info.Version == "" || seen[info.Version] {
+			continue
+		}
+		seen[info.Version] = true
+		t, _ := time.Parse(time.RFC3339, date)
+		versions = append(versions, Version{Version: info.Version, Commit: commit, Date: t})
+	}
+	return versions, nil
+}
+
+func AtCommit(ctx context.Context, dir, commit, repo string) ([]byte, error) {
+	return exec.CommandContext(ctx, "git", "-C", dir, "show", commit+":"+path.Join(repo, "openapi.yaml")).Output()
+}
+
+func Resolve(history []Version, expr string) (Version, bool) {
+	r, err := ParseRange(expr)
+	if err != nil {
+		return Version{}, false
+	}
+	var best Version
+	var bestV Semver
+	found := false
+	for _, h := range history {
+		v, err := ParseSemver(h.Version)
+		if err != nil || !r.Match(v) {
+			continue
+		}
+		if !found || v.Compare(bestV) > 0 {
+			best, bestV, found = h, v, true
+		}
+	}
+	return best, found
+}
@@ -0,0 +1,115 @@
+package spec
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+	"time"
+)
+
+type Release struct {
+	Version   string         `json:"version"`
+	Commit    string         `json:"commit"`
+	Date      time.Time      `json:"date"`
+	CommitURL string         `json:"commit_url,omitempty"`
+	Download  string         `json:"download"`
+	Changes   []Change       `json:"changes,omitempty"`
+	Counts    map[string]int `json:"counts,omitempty"`
+	First     bool           `json:"first,omitempty"`
+}
+
+type ReleaseHistory struct {
+	Repo     string    `json:"repo"`
+	Releases []Release `json:"releases"`
+}
+
+type ReleaseLinks struct {
+	Commit   func(commit string) string
+	Download func(repo, version string) string
+}
+
+func BuildReleaseHistory(ctx context.Context, dir, repo string, links ReleaseLinks) (*ReleaseHistory, error) {
+	history, err := History(ctx, dir, repo)
+	if err != nil {
+		return nil, err
+	}
+	h := &ReleaseHistory{Repo: repo}
+	var newer *Document
+	for i, v := range history {
+		doc, err := DocumentAt(ctx, dir, v.Commit, repo)
+		if err != nil {
+			return nil, fmt.Errorf("%s@%s: %w", repo, v.Version, err)
+		}
+		if i > 0 {
+			prev := &h.Releases[i-1]
+			prev.Changes = Diff(doc, newer)
+			prev.Counts = CountChanges(prev.Changes)
+		}
+		r := Release{Version: v.Version, Commit: v.Commit, Date: v.Date, First: i == len(history)-1}
+		if links.Commit != nil {
+			r.CommitURL = links.Commit(v.Commit)
+		}
+		if links.Download != nil {
+			r.Download = links.Download(repo, v.Version)
+		}
+		h.Releases = append(h.Releases, r)
+		newer = doc
+	}
+	return h, nil
+}
+
+var historyPage = template.Must(template.New("history").Funcs(template.FuncMap{
+	"short": func(s string) string {
+		if len(s) > 8 {
+			return s[:8]
+		}
+		return s
+	},
+	"datetime":   func(t time.Time) string { return t.Format("2006-01-02 15:04 MST") },
+	"categories": func() []string { return ChangeCategories },
+}).Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<title>{{ .Repo }}: история версий</title>
+</head>
+<body>
+<h1>{{ .Repo }}: история версий</h1>
+{{- if not .Releases }}
+<p>Опубликованных версий нет.</p>
+{{- end }}
+<table class="history">
+  <thead><tr><th>Версия</th><th>Опубликована</th><th>Коммит</th><th>Изменения</th><th>Спецификация</th></tr></thead>
+  <tbody>
+  {{- range .Releases }}
+  <tr id="v{{ .Version }}">
+    <td>{{ .Version }}</td>
+    <td>{{ datetime .Date }}</td>
+    <td>{{ if .CommitURL }}<a href="{{ .CommitURL }}"><code>{{ short .Commit }}</code></a>{{ else }}<code>{{ short .Commit }}</code>{{ end }}</td>
+    <td>
+      {{- if .First }}первая версия
+      {{- else if not .Changes }}без изменений контракта
+      {{- else }}
+      <details>
+        <summary>{{ $r := . }}{{ range $i, $c := categories }}{{ with index $r.Counts $c }}{{ if $i }} {{ end }}<span class="change-{{ $c }}">{{ $c }}: {{ . }}</span>{{ end }}{{ end }}</summary>
+        <ul>
+          {{- range .Changes }}
+          <li class="change-{{ .Category }}">{{ .String }}</li>
+          {{- end }}
+        </ul>
+      </details>
+      {{- end }}
+    </td>
+    <td><a href="{{ .Download }}" download>openapi.yaml</a></td>
+  </tr>
+  {{- end }}
+  </tbody>
+</table>
+</body>
+</html>
+`))
+
+func (h *ReleaseHistory) WriteHTML(w io.Writer) error {
+	return historyPage.Execute(w, h)
+}
@@ -0,0 +1,99 @@
+package spec
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+type APIResource struct {
+	Name       string   `json:"name"`
+	Title      string   `json:"title"`
+	Version    string   `json:"version"`
+	DocsURL    string   `json:"docs_url"`
+	SpecURL    string   `json:"spec_url"`
+	Servers    []string `json:"servers"`
+	BasePath   string   `json:"base_path"`
+	Operations int      `json:"operations"`
+}
+
+func BuildAPIResources(docs map[string]*Document, portalBase string) []APIResource {
+	var apis []APIResource
+	for _, repo := range SortedRepos(docs) {
+		doc := docs[repo]
+		api := APIResource{
+			Name:       repo,
+			Title:      doc.Info.Title,
+			Version:    doc.Info.Version,
+			DocsURL:    portalBase + "interactive/" + repo + "/index.html",
+			SpecURL:    portalBase + repo + "/openapi.yaml",
+			Servers:    []string{},
+			BasePath:   "/",
+			Operations: len(doc.Operations()),
+		}
+		for _, s := range doc.Servers {
+			api.Servers = append(api.Servers, s.URL)
+		}
+		if len(doc.Servers) > 0 {
+			if u, err := url.Parse(doc.Servers[0].URL); err == nil && u.Path != "" {
+				api.BasePath = u.Path
+			}
+		}
+		apis = append(apis, api)
+	}
+	return apis
+}
+
+func WriteTerraformJSON(w io.Writer, apis []APIResource) error {
+	byName := make(map[string]APIResource, len(apis))
+	for _, a := range apis {
+		a.Title, a.Version, a.DocsURL, a.SpecURL, a.BasePath =
+			escapeTemplate(a.Title), escapeTemplate(a.Version), escapeTemplate(a.DocsURL), escapeTemplate(a.SpecURL), escapeTemplate(a.BasePath)
+		servers := make([]string, len(a.Servers))
+		for i, srv := range a.Servers {
+			servers[i] = escapeTemplate(srv)
+		}
+		a.Servers = servers
+		byName[a.Name] = a
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(map[string]any{
+		"locals": map[string]any{"documented_apis": byName},
+	})
+}
+
+func escapeTemplate(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "${", "$${"), "%{", "%%{")
+}
+
+func hclString(s string) string {
+	return escapeTemplate(strconv.Quote(s))
+}
+
+func WriteHCL(w io.Writer, apis []APIResource) error {
+	var b strings.Builder
+	b.WriteString("locals {\n  documented_apis = {\n")
+	for _, a := range apis {
+		servers := make([]string, len(a.Servers))
+		for i, s := range a.Servers {
+			servers[i] = hclString(s)
+		}
+		fmt.Fprintf(&b, "    %s = {\n", hclString(a.Name))
+		fmt.Fprintf(&b, "      title      = %s\n", hclString(a.Title))
+		fmt.Fprintf(&b, "      version    = %s\n", hclString(a.Version))
+		fmt.Fprintf(&b, "      docs_url   = %s\n", hclString(a.DocsURL))
+		fmt.Fprintf(&b, "      spec_url   = %s\n", hclString(a.SpecURL))
+		fmt.Fprintf(&b, "      servers    = [%s]\n", strings.Join(servers, ", "))
+		fmt.Fprintf(&b, "      base_path  = %s\n", hclString(a.BasePath))
+		fmt.Fprintf(&b, "      operations = %d\n", a.Operations)
+		b.WriteString("    }\n")
+	}
+	b.WriteString("  }\n}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
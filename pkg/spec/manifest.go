@@ -0,0 +1,146 @@
+package spec
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const ManifestFile = "manifest.sha256"
+
+type Issue struct {
+	Path   string
+	Reason string
+}
+
+func Verify(dir string) (checked int, issues []Issue, err error) {
+	manifest, err := ReadManifest(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return 0, nil, err
+	}
+
+	for path, want := range manifest {
+		got, err := FileSHA256(filepath.Join(dir, filepath.FromSlash(path)))
+		switch {
+		case os.IsNotExist(err):
+			issues = append(issues, Issue{path, "отсутствует"})
+		case err != nil:
+			return 0, nil, fmt.Errorf("чтение %s: %w", path, err)
+		case got != want:
+			issues = append(issues, Issue{path, "изменён вручную"})
+		}
+	}
+
+	tracked := trackedDirs(manifest)
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if _, ok := manifest[rel]; ok {
+			return nil
+		}
+		if tracked[filepath.ToSlash(filepath.Dir(rel))] {
+			issues = append(issues, Issue{rel, "не учтён в манифесте"})
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	sort.Slice(issues, func(i, j int) bool { return issues[i].Path < issues[j].Path })
+	return len(manifest), issues, nil
+}
+
+func ReadManifest(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseManifest(f)
+}
+
+func ParseManifest(r io.Reader) (map[string]string, error) {
+	manifest := make(map[string]string)
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		hash, name, ok := strings.Cut(line, "  ")
+		if !ok {
+			return nil, fmt.Errorf("некорректная строка манифеста: %q", line)
+		}
+		manifest[strings.TrimPrefix(name, "./")] = hash
+	}
+	return manifest, sc.Err()
+}
+
+func trackedDirs(manifest map[string]string) map[string]bool {
+	dirs := make(map[string]bool)
+	for path := range manifest {
+		if dir := filepath.ToSlash(filepath.Dir(path)); dir != "." {
+			dirs[dir] = true
+		}
+	}
+	return dirs
+}
+
+func FileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func UpdateManifest(dir string, paths []string) error {
+	manifest, err := ReadManifest(filepath.Join(dir, ManifestFile))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if manifest == nil {
+		manifest = make(map[string]string)
+	}
+	for _, p := range paths {
+		sum, err := FileSHA256(filepath.Join(dir, filepath.FromSlash(p)))
+		if err != nil {
+			return err
+		}
+		manifest[p] = sum
+	}
+
+	names := make([]string, 0, len(manifest))
+	for name := range manifest {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s  %s\n", manifest[name], name)
+	}
+	return os.WriteFile(filepath.Join(dir, ManifestFile), []byte(b.String()), 0o644)
+}
@@ -0,0 +1,328 @@
+package spec
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const ServiceKey = "x-service"
+
+var componentKinds = []string{"schemas", "responses", "parameters", "examples", "requestBodies", "headers", "securitySchemes", "links", "callbacks"}
+
+type MergeSource struct {
+	Name string
+	Data []byte
+}
+
+type MergeOptions struct {
+	Title    string
+	Version  string
+	Servers  []string
+	NoPrefix bool
+}
+
+type MergeResult struct {
+	Services     int
+	Paths        int
+	Shared       []string
+	Namespaced   int
+	OperationIDs int
+}
+
+type mergeService struct {
+	name string
+	doc  *yaml.Node
+}
+
+func strNode(v string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v}
+}
+
+func setValue(m *yaml.Node, key string, v *yaml.Node) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content[i+1] = v
+			return
+		}
+	}
+	m.Content = append(m.Content, strNode(key), v)
+}
+
+func copyNode(n *yaml.Node) *yaml.Node {
+	c := *n
+	c.Content = make([]*yaml.Node, len(n.Content))
+	for i, child := range n.Content {
+		c.Content[i] = copyNode(child)
+	}
+	return &c
+}
+
+func servicePrefix(name string) string {
+	return "/" + strings.Trim(name, "/")
+}
+
+func serviceNamespace(name string) string {
+	return strings.ReplaceAll(strings.Trim(name, "/"), "/", ".")
+}
+
+func Merge(sources []MergeSource, opts MergeOptions) ([]byte, *MergeResult, error) {
+	openapi := ""
+	var services []mergeService
+	for _, src := range sources {
+		var root yaml.Node
+		if err := yaml.Unmarshal(src.Data, &root); err != nil {
+			return nil, nil, fmt.Errorf("%s: разбор спецификации: %w", src.Name, err)
+		}
+		if len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+			return nil, nil, fmt.Errorf("%s: спецификация должна быть YAML-объектом", src.Name)
+		}
+		doc := root.Content[0]
+		v := nodeValue(doc, "openapi")
+		if v == nil || !strings.HasPrefix(v.Value, "3.") {
+			return nil, nil, fmt.Errorf("%s: объединяются только спецификации OpenAPI 3.x", src.Name)
+		}
+		if v.Value > openapi {
+			openapi = v.Value
+		}
+		services = append(services, mergeService{src.Name, doc})
+	}
+
+	type definition struct {
+		service string
+		node    *yaml.Node
+	}
+	defs := map[componentRef][]definition{}
+	for _, s := range services {
+		components := nodeValue(s.doc, "components")
+		for _, kind := range componentKinds {
+			m := nodeValue(components, kind)
+			if m == nil || m.Kind != yaml.MappingNode {
+				continue
+			}
+			for i := 0; i+1 < len(m.Content); i += 2 {
+				ref := componentRef{kind, m.Content[i].Value}
+				defs[ref] = append(defs[ref], definition{s.name, m.Content[i+1]})
+			}
+		}
+	}
+	shared := map[componentRef]bool{}
+	for ref, ds := range defs {
+		if len(ds) < 2 {
+			continue
+		}
+		same := true
+		for _, d := range ds[1:] {
+			same = same && sameNode(ds[0].node, d.node)
+		}
+		shared[ref] = same
+	}
+	for changed := true; changed; {
+		changed = false
+		for ref, ok := range shared {
+			if !ok {
+				continue
+			}
+			walkRefs(defs[ref][0].node, func(v *yaml.Node) {
+				if target, local := localTarget(v.Value); local && !shared[target] && shared[ref] {
+					shared[ref] = false
+					changed = true
+				}
+			})
+		}
+	}
+	rename := func(service string, ref componentRef) string {
+		if shared[ref] {
+			return ref.name
+		}
+		return serviceNamespace(service) + "." + ref.name
+	}
+
+	result := &MergeResult{Services: len(services)}
+	paths := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	webhooks := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	components := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	tags := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	tagNames := map[string]bool{}
+	addTag := func(tag *yaml.Node) {
+		if name := nodeValue(tag, "name"); name != nil && !tagNames[name.Value] {
+			tagNames[name.Value] = true
+			tags.Content = append(tags.Content, tag)
+		}
+	}
+	emitted := map[componentRef]bool{}
+	operations := map[string][]*yaml.Node{}
+	var summary []string
+
+	for _, s := range services {
+		walkRefs(s.doc, func(v *yaml.Node) {
+			if target, ok := localTarget(v.Value); ok {
+				v.Value = "#/components/" + target.kind + "/" + rename(s.name, target)
+			}
+		})
+		renameSecurity := func(req *yaml.Node) {
+			if req == nil || req.Kind != yaml.SequenceNode {
+				return
+			}
+			for _, m := range req.Content {
+				for i := 0; i+1 < len(m.Content); i += 2 {
+					m.Content[i].Value = rename(s.name, componentRef{"securitySchemes", m.Content[i].Value})
+				}
+			}
+		}
+		security := nodeValue(s.doc, "security")
+		renameSecurity(security)
+
+		title, version := s.name, ""
+		if info := nodeValue(s.doc, "info"); info != nil {
+			if v := nodeValue(info, "title"); v != nil && v.Value != "" {
+				title = v.Value
+			}
+			if v := nodeValue(info, "version"); v != nil {
+				version = v.Value
+			}
+		}
+		prefix := ""
+		if !opts.NoPrefix {
+			prefix = servicePrefix(s.name)
+		}
+		line := fmt.Sprintf("- **%s**", s.name)
+		if prefix != "" {
+			line += fmt.Sprintf(" (`%s`)", prefix)
+		}
+		summary = append(summary, strings.TrimSpace(line+": "+title+" "+version))
+		if list := nodeValue(s.doc, "tags"); list != nil && list.Kind == yaml.SequenceNode {
+			for _, tag := range list.Content {
+				addTag(tag)
+			}
+		}
+
+		mergeItems := func(items, into *yaml.Node, key func(string) string) error {
+			if items == nil || items.Kind != yaml.MappingNode {
+				return nil
+			}
+			for i := 0; i+1 < len(items.Content); i += 2 {
+				name := key(items.Content[i].Value)
+				if nodeValue(into, name) != nil {
+					return fmt.Errorf("%s: путь %s уже определён другим сервисом", s.name, name)
+				}
+				item := items.Content[i+1]
+				for _, method := range httpMethods {
+					op := nodeValue(item, method)
+					if op == nil || op.Kind != yaml.MappingNode {
+						continue
+					}
+					setValue(op, ServiceKey, strNode(s.name))
+					if nodeValue(op, "security") == nil && security != nil {
+						setValue(op, "security", copyNode(security))
+					} else {
+						renameSecurity(nodeValue(op, "security"))
+					}
+					if nodeValue(op, "tags") == nil {
+						setValue(op, "tags", &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: []*yaml.Node{strNode(s.name)}})
+						addTag(&yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{
+							strNode("name"), strNode(s.name), strNode("description"), strNode(title),
+						}})
+					}
+					if id := nodeValue(op, "operationId"); id != nil {
+						operations[id.Value] = append(operations[id.Value], op)
+					}
+				}
+				into.Content = append(into.Content, strNode(name), item)
+				if into == paths {
+					result.Paths++
+				}
+			}
+			return nil
+		}
+		err := mergeItems(nodeValue(s.doc, "paths"), paths, func(p string) string {
+			if p == "/" && prefix != "" {
+				return prefix
+			}
+			return prefix + p
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		err = mergeItems(nodeValue(s.doc, "webhooks"), webhooks, func(name string) string {
+			return serviceNamespace(s.name) + "." + name
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		own := nodeValue(s.doc, "components")
+		for _, kind := range componentKinds {
+			m := nodeValue(own, kind)
+			if m == nil || m.Kind != yaml.MappingNode {
+				continue
+			}
+			for i := 0; i+1 < len(m.Content); i += 2 {
+				ref := componentRef{kind, m.Content[i].Value}
+				if emitted[ref] {
+					continue
+				}
+				if shared[ref] {
+					emitted[ref] = true
+					result.Shared = append(result.Shared, kind+"/"+ref.name)
+				} else {
+					result.Namespaced++
+				}
+				target := ensureMapping(components, kind)
+				target.Content = append(target.Content, strNode(rename(s.name, ref)), m.Content[i+1])
+			}
+		}
+	}
+
+	for id, ops := range operations {
+		if len(ops) < 2 {
+			continue
+		}
+		for _, op := range ops {
+			service := nodeValue(op, ServiceKey).Value
+			setValue(op, "operationId", strNode(strings.NewReplacer("/", "_", ".", "_", "-", "_").Replace(service)+"_"+id))
+			result.OperationIDs++
+		}
+	}
+
+	if openapi == "" {
+		openapi = "3.0.3"
+	}
+	title := opts.Title
+	if title == "" {
+		title = "API"
+	}
+	version := opts.Version
+	if version == "" {
+		version = "1.0.0"
+	}
+	info := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{
+		strNode("title"), strNode(title),
+		strNode("version"), strNode(version),
+		strNode("description"), strNode(fmt.Sprintf("Unified API of %d services:\n\n%s\n", len(services), strings.Join(summary, "\n"))),
+	}}
+	out := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{
+		strNode("openapi"), strNode(openapi),
+		strNode("info"), info,
+	}}
+	if len(opts.Servers) > 0 {
+		servers := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, u := range opts.Servers {
+			servers.Content = append(servers.Content, &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{strNode("url"), strNode(u)}})
+		}
+		setValue(out, "servers", servers)
+	}
+	if len(tags.Content) > 0 {
+		setValue(out, "tags", tags)
+	}
+	setValue(out, "paths", paths)
+	if len(webhooks.Content) > 0 {
+		setValue(out, "webhooks", webhooks)
+	}
+	if len(components.Content) > 0 {
+		setValue(out, "components", components)
+	}
+	data, err := encodeNode(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{out}})
+	return data, result, err
+}
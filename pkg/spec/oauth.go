@@ -0,0 +1,143 @@
+package spec
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+type OAuthScheme struct {
+	Repo             string   `json:"repo"`
+	Scheme           string   `json:"scheme"`
+	Flow             string   `json:"flow"`
+	AuthorizationURL string   `json:"authorization_url,omitempty"`
+	TokenURL         string   `json:"token_url,omitempty"`
+	Scopes           []string `json:"scopes,omitempty"`
+	RedirectURL      string   `json:"redirect_url"`
+	Problems         []string `json:"problems,omitempty"`
+}
+
+type OAuthReport struct {
+	ClientID string        `json:"client_id,omitempty"`
+	Issuer   string        `json:"issuer,omitempty"`
+	Schemes  []OAuthScheme `json:"schemes"`
+}
+
+func sameIssuer(link, issuer string) bool {
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	i, err := url.Parse(issuer)
+	if err != nil {
+		return false
+	}
+	return u.Scheme == i.Scheme && u.Host == i.Host && strings.HasPrefix(u.Path, strings.TrimSuffix(i.Path, "/"))
+}
+
+func BuildOAuthReport(docs map[string]*Document, portalBase, clientID, issuer string) *OAuthReport {
+	r := &OAuthReport{ClientID: clientID, Issuer: issuer}
+	for _, repo := range SortedRepos(docs) {
+		schemes := docs[repo].Components.SecuritySchemes
+		names := make([]string, 0, len(schemes))
+		for name := range schemes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			s := schemes[name]
+			if s == nil {
+				continue
+			}
+			redirect := portalBase + "interactive/" + repo + "/oauth2-redirect.html"
+			switch s.Type {
+			case "openIdConnect":
+				o := OAuthScheme{Repo: repo, Scheme: name, Flow: "openIdConnect", AuthorizationURL: s.OpenIDConnectURL, RedirectURL: redirect}
+				if issuer != "" && !sameIssuer(s.OpenIDConnectURL, issuer) {
+					o.Problems = append(o.Problems, "openIdConnectUrl указывает не на корпоративный провайдер")
+				}
+				r.Schemes = append(r.Schemes, o)
+			case "oauth2":
+				flows := make([]string, 0, len(s.Flows))
+				for flow := range s.Flows {
+					flows = append(flows, flow)
+				}
+				sort.Strings(flows)
+				for _, flow := range flows {
+					f := s.Flows[flow]
+					if f == nil {
+						continue
+					}
+					o := OAuthScheme{Repo: repo, Scheme: name, Flow: flow, AuthorizationURL: f.AuthorizationURL, TokenURL: f.TokenURL, RedirectURL: redirect}
+					for scope := range f.Scopes {
+						o.Scopes = append(o.Scopes, scope)
+					}
+					sort.Strings(o.Scopes)
+					switch flow {
+					case "implicit", "password":
+						o.Problems = append(o.Problems, fmt.Sprintf("поток %s устарел, используйте authorizationCode с PKCE", flow))
+					}
+					if flow != "clientCredentials" && flow != "password" && f.AuthorizationURL == "" {
+						o.Problems = append(o.Problems, "не указан authorizationUrl")
+					}
+					if issuer != "" {
+						for _, link := range []string{f.AuthorizationURL, f.TokenURL} {
+							if link != "" && !sameIssuer(link, issuer) {
+								o.Problems = append(o.Problems, fmt.Sprintf("%s указывает не на корпоративный провайдер", link))
+							}
+						}
+					}
+					r.Schemes = append(r.Schemes, o)
+				}
+			}
+		}
+	}
+	return r
+}
+
+func (r *OAuthReport) Problems() []OAuthScheme {
+	var out []OAuthScheme
+	for _, s := range r.Schemes {
+		if len(s.Problems) > 0 {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func (r *OAuthReport) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("# OAuth в интерактивной документации\n\n")
+	if len(r.Schemes) == 0 {
+		b.WriteString("Ни одна спецификация не описывает схемы oauth2 или openIdConnect.\n")
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+	if r.ClientID != "" {
+		fmt.Fprintf(&b, "Клиент портала: `%s`\n\n", r.ClientID)
+	}
+	b.WriteString("## Redirect URI для регистрации у провайдера\n\n")
+	seen := map[string]bool{}
+	for _, s := range r.Schemes {
+		if s.Flow != "clientCredentials" && !seen[s.RedirectURL] {
+			seen[s.RedirectURL] = true
+			fmt.Fprintf(&b, "- `%s`\n", s.RedirectURL)
+		}
+	}
+	b.WriteString("\n## Схемы\n\n| Сервис | Схема | Поток | Authorize URL | Token URL | Scopes |\n|---|---|---|---|---|---|\n")
+	for _, s := range r.Schemes {
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s |\n", s.Repo, s.Scheme, s.Flow, s.AuthorizationURL, s.TokenURL, strings.Join(s.Scopes, ", "))
+	}
+	if bad := r.Problems(); len(bad) > 0 {
+		b.WriteString("\n## Проблемы\n\n")
+		for _, s := range bad {
+			for _, p := range s.Problems {
+				fmt.Fprintf(&b, "- %s / %s (%s): %s\n", s.Repo, s.Scheme, s.Flow, p)
+			}
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
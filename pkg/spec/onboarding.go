@@ -0,0 +1,251 @@
+package spec
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+)
+
+type OnboardingFragments struct {
+	BaseURLs   map[string]string
+	RateLimits string
+	Support    string
+	Auth       string
+	SpecURL    string
+	DocsURL    string
+}
+
+type Environment struct {
+	Name string
+	URL  string
+}
+
+type AuthMethod struct {
+	Name         string
+	Instructions []string
+	Header       string
+}
+
+type Onboarding struct {
+	Repo         string
+	Title        string
+	Version      string
+	Description  string
+	Environments []Environment
+	Auth         []AuthMethod
+	AuthNote     string
+	Example      string
+	RateLimits   string
+	Support      string
+	SpecURL      string
+	DocsURL      string
+}
+
+func BuildOnboarding(repo string, doc *Document, f OnboardingFragments) *Onboarding {
+	o := &Onboarding{
+		Repo:        repo,
+		Title:       doc.Info.Title,
+		Version:     doc.Info.Version,
+		Description: doc.Info.Description,
+		AuthNote:    f.Auth,
+		RateLimits:  f.RateLimits,
+		Support:     f.Support,
+		SpecURL:     f.SpecURL,
+		DocsURL:     f.DocsURL,
+	}
+	if o.Title == "" {
+		o.Title = repo
+	}
+
+	if len(f.BaseURLs) > 0 {
+		names := make([]string, 0, len(f.BaseURLs))
+		for name := range f.BaseURLs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			o.Environments = append(o.Environments, Environment{Name: name, URL: strings.ReplaceAll(f.BaseURLs[name], "{repo}", repo)})
+		}
+	} else {
+		for _, s := range doc.Servers {
+			name := s.Description
+			if name == "" {
+				name = s.URL
+			}
+			o.Environments = append(o.Environments, Environment{Name: name, URL: s.URL})
+		}
+	}
+
+	if o.Support == "" {
+		c := doc.Info.Contact
+		var parts []string
+		for _, v := range []string{c.Name, c.Email, c.URL} {
+			if v != "" {
+				parts = append(parts, v)
+			}
+		}
+		o.Support = strings.Join(parts, ", ")
+	}
+
+	names := make([]string, 0, len(doc.Components.SecuritySchemes))
+	for name := range doc.Components.SecuritySchemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if s := doc.Components.SecuritySchemes[name]; s != nil {
+			o.Auth = append(o.Auth, authMethod(name, s))
+		}
+	}
+	o.Example = onboardingExample(doc, o)
+	return o
+}
+
+func authMethod(name string, s *SecurityScheme) AuthMethod {
+	m := AuthMethod{Name: name}
+	switch s.Type {
+	case "apiKey":
+		switch s.In {
+		case "query":
+			m.Instructions = append(m.Instructions, fmt.Sprintf("Передайте ключ API в параметре запроса %s.", s.Name))
+		case "cookie":
+			m.Instructions = append(m.Instructions, fmt.Sprintf("Передайте ключ API в cookie %s.", s.Name))
+		default:
+			m.Instructions = append(m.Instructions, fmt.Sprintf("Передайте ключ API в заголовке %s.", s.Name))
+			m.Header = s.Name + ": <ключ API>"
+		}
+	case "http":
+		switch strings.ToLower(s.Scheme) {
+		case "bearer":
+			m.Instructions = append(m.Instructions, "Передайте токен в заголовке Authorization: Bearer <токен>.")
+			m.Header = "Authorization: Bearer <токен>"
+		case "basic":
+			m.Instructions = append(m.Instructions, "Передайте логин и пароль в заголовке Authorization (HTTP Basic).")
+			m.Header = "Authorization: Basic <base64(логин:пароль)>"
+		default:
+			m.Instructions = append(m.Instructions, fmt.Sprintf("HTTP-аутентификация по схеме %s.", s.Scheme))
+		}
+	case "oauth2":
+		flows := make([]string, 0, len(s.Flows))
+		for flow := range s.Flows {
+			flows = append(flows, flow)
+		}
+		sort.Strings(flows)
+		for _, flow := range flows {
+			f := s.Flows[flow]
+			if f == nil {
+				continue
+			}
+			line := "OAuth 2.0, " + flow + ":"
+			if f.AuthorizationURL != "" {
+				line += " авторизация — " + f.AuthorizationURL + ";"
+			}
+			if f.TokenURL != "" {
+				line += " токен — " + f.TokenURL + ";"
+			}
+			if len(f.Scopes) > 0 {
+				scopes := make([]string, 0, len(f.Scopes))
+				for scope := range f.Scopes {
+					scopes = append(scopes, scope)
+				}
+				sort.Strings(scopes)
+				line += " области: " + strings.Join(scopes, ", ") + ";"
+			}
+			m.Instructions = append(m.Instructions, strings.TrimSuffix(line, ";")+".")
+		}
+		m.Header = "Authorization: Bearer <токен>"
+	case "openIdConnect":
+		m.Instructions = append(m.Instructions, "OpenID Connect: параметры провайдера — "+s.OpenIDConnectURL+".")
+		m.Header = "Authorization: Bearer <токен>"
+	default:
+		m.Instructions = append(m.Instructions, "Схема "+s.Type+".")
+	}
+	return m
+}
+
+func onboardingExample(doc *Document, o *Onboarding) string {
+	if len(o.Environments) == 0 {
+		return ""
+	}
+	var path string
+	for _, op := range doc.Operations() {
+		if op.Method == "GET" && !strings.Contains(op.Path, "{") {
+			path = op.Path
+			break
+		}
+	}
+	if path == "" {
+		return ""
+	}
+	cmd := "curl " + strings.TrimSuffix(o.Environments[0].URL, "/") + path
+	for _, m := range o.Auth {
+		if m.Header != "" {
+			cmd += ` \` + "\n  -H '" + m.Header + "'"
+			break
+		}
+	}
+	return cmd
+}
+
+var onboardingPage = template.Must(template.New("onboarding").Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<title>{{ .Title }}: начало работы</title>
+</head>
+<body>
+<h1>{{ .Title }}: начало работы</h1>
+{{- with .Description }}
+<p>{{ . }}</p>
+{{- end }}
+<p>Сервис <code>{{ .Repo }}</code>{{ with .Version }}, версия API {{ . }}{{ end }}.
+{{- with .SpecURL }} <a href="{{ . }}" download>openapi.yaml</a>{{ end }}
+{{- with .DocsURL }} · <a href="{{ . }}">справочник</a>{{ end }}</p>
+
+<h2>Адреса</h2>
+{{- if .Environments }}
+<table class="environments">
+  <thead><tr><th>Окружение</th><th>Базовый адрес</th></tr></thead>
+  <tbody>
+  {{- range .Environments }}
+  <tr><td>{{ .Name }}</td><td><code>{{ .URL }}</code></td></tr>
+  {{- end }}
+  </tbody>
+</table>
+{{- else }}
+<p>Базовые адреса не указаны.</p>
+{{- end }}
+
+<h2>Аутентификация</h2>
+{{- if .Auth }}
+<ul>
+  {{- range .Auth }}
+  <li><strong>{{ .Name }}</strong>{{ range .Instructions }} {{ . }}{{ end }}</li>
+  {{- end }}
+</ul>
+{{- else }}
+<p>Спецификация не описывает схем аутентификации.</p>
+{{- end }}
+{{- with .AuthNote }}
+<p>{{ . }}</p>
+{{- end }}
+{{- with .Example }}
+
+<h2>Первый запрос</h2>
+<pre><code>{{ . }}</code></pre>
+{{- end }}
+
+<h2>Ограничения</h2>
+<p>{{ if .RateLimits }}{{ .RateLimits }}{{ else }}Ограничения частоты запросов не указаны.{{ end }}</p>
+
+<h2>Поддержка</h2>
+<p>{{ if .Support }}{{ .Support }}{{ else }}Контакты поддержки не указаны.{{ end }}</p>
+</body>
+</html>
+`))
+
+func (o *Onboarding) WriteHTML(w io.Writer) error {
+	return onboardingPage.Execute(w, o)
+}
@@ -0,0 +1,227 @@
+package spec
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+const (
+	PaginationPage   = "page/limit"
+	PaginationOffset = "offset/limit"
+	PaginationCursor = "cursor"
+	PaginationMixed  = "смешанный"
+)
+
+var (
+	pageParams   = map[string]bool{"page": true, "page_number": true, "pagenumber": true}
+	offsetParams = map[string]bool{"offset": true, "skip": true, "start": true}
+	cursorParams = map[string]bool{"cursor": true, "after": true, "before": true, "page_token": true, "pagetoken": true, "continuation_token": true, "next": true}
+	sizeParams   = map[string]bool{"limit": true, "size": true, "page_size": true, "pagesize": true, "per_page": true, "perpage": true, "count": true, "top": true, "max_results": true}
+	sortParams   = map[string]bool{"sort": true, "sort_by": true, "sortby": true, "order": true, "order_by": true, "orderby": true}
+)
+
+type PaginatedOperation struct {
+	Repo      string   `json:"repo"`
+	Operation string   `json:"operation"`
+	Style     string   `json:"style"`
+	Params    []string `json:"params"`
+	Sort      []string `json:"sort,omitempty"`
+	Filters   []string `json:"filters,omitempty"`
+}
+
+type PaginationReport struct {
+	Operations  []PaginatedOperation `json:"operations"`
+	Recommended string               `json:"recommended"`
+}
+
+func normalizeParam(name string) string {
+	return strings.ReplaceAll(strings.ToLower(name), "-", "_")
+}
+
+func filterStyle(name string) string {
+	switch {
+	case strings.HasPrefix(name, "filter["):
+		return "filter[поле]"
+	case strings.HasPrefix(name, "filter."):
+		return "filter.поле"
+	case name == "filter" || name == "q" || name == "query" || name == "search":
+		return name
+	}
+	return ""
+}
+
+func BuildPaginationReport(docs map[string]*Document) *PaginationReport {
+	r := &PaginationReport{}
+	for _, repo := range SortedRepos(docs) {
+		doc := docs[repo]
+		for _, op := range doc.Operations() {
+			if op.Method != "GET" {
+				continue
+			}
+			po := PaginatedOperation{Repo: repo, Operation: op.String()}
+			var page, offset, cursor, size bool
+			for _, p := range doc.Parameters(op) {
+				if p.In != "query" {
+					continue
+				}
+				n := normalizeParam(p.Name)
+				switch {
+				case pageParams[n]:
+					page = true
+				case offsetParams[n]:
+					offset = true
+				case cursorParams[n]:
+					cursor = true
+				case sizeParams[n]:
+					size = true
+				case sortParams[n]:
+					po.Sort = append(po.Sort, p.Name)
+					continue
+				default:
+					if style := filterStyle(n); style != "" {
+						po.Filters = append(po.Filters, style)
+					}
+					continue
+				}
+				po.Params = append(po.Params, p.Name)
+			}
+			switch {
+			case len(po.Params) == 0:
+				if len(po.Sort) == 0 && len(po.Filters) == 0 {
+					continue
+				}
+			case btoi(page)+btoi(offset)+btoi(cursor) > 1:
+				po.Style = PaginationMixed
+			case cursor:
+				po.Style = PaginationCursor
+			case offset:
+				po.Style = PaginationOffset
+			case page || size:
+				po.Style = PaginationPage
+			}
+			sort.Strings(po.Params)
+			r.Operations = append(r.Operations, po)
+		}
+	}
+
+	counts := r.StyleCounts()
+	for style, n := range counts {
+		if style == PaginationMixed {
+			continue
+		}
+		if r.Recommended == "" || n > counts[r.Recommended] || (n == counts[r.Recommended] && style < r.Recommended) {
+			r.Recommended = style
+		}
+	}
+	return r
+}
+
+func btoi(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func (r *PaginationReport) StyleCounts() map[string]int {
+	counts := map[string]int{}
+	for _, op := range r.Operations {
+		if op.Style != "" {
+			counts[op.Style]++
+		}
+	}
+	return counts
+}
+
+func (r *PaginationReport) Divergent() []PaginatedOperation {
+	var out []PaginatedOperation
+	for _, op := range r.Operations {
+		if op.Style != "" && op.Style != r.Recommended {
+			out = append(out, op)
+		}
+	}
+	return out
+}
+
+func (r *PaginationReport) conventionRepos(pick func(PaginatedOperation) []string) map[string][]string {
+	seen := map[string]map[string]bool{}
+	for _, op := range r.Operations {
+		for _, name := range pick(op) {
+			if seen[name] == nil {
+				seen[name] = map[string]bool{}
+			}
+			seen[name][op.Repo] = true
+		}
+	}
+	out := map[string][]string{}
+	for name, set := range seen {
+		for repo := range set {
+			out[name] = append(out[name], repo)
+		}
+		sort.Strings(out[name])
+	}
+	return out
+}
+
+func (r *PaginationReport) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("# Пагинация и фильтрация\n\n")
+	if len(r.Operations) == 0 {
+		b.WriteString("Ни одна GET-операция не принимает параметров пагинации, сортировки или фильтрации.\n")
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+
+	b.WriteString("## Пагинация\n\n| Стиль | Операций | Сервисы |\n|---|---|---|\n")
+	styles := r.conventionRepos(func(op PaginatedOperation) []string {
+		if op.Style == "" {
+			return nil
+		}
+		return []string{op.Style}
+	})
+	counts := r.StyleCounts()
+	for _, style := range sortedKeys(styles) {
+		label := style
+		if style == r.Recommended {
+			label += " (преобладает)"
+		}
+		fmt.Fprintf(&b, "| %s | %d | %s |\n", label, counts[style], strings.Join(styles[style], ", "))
+	}
+
+	for _, section := range []struct {
+		title string
+		pick  func(PaginatedOperation) []string
+	}{
+		{"Сортировка", func(op PaginatedOperation) []string { return op.Sort }},
+		{"Фильтрация", func(op PaginatedOperation) []string { return op.Filters }},
+	} {
+		names := r.conventionRepos(section.pick)
+		if len(names) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n| Параметр | Сервисы |\n|---|---|\n", section.title)
+		for _, name := range sortedKeys(names) {
+			fmt.Fprintf(&b, "| `%s` | %s |\n", name, strings.Join(names[name], ", "))
+		}
+	}
+
+	if bad := r.Divergent(); len(bad) > 0 {
+		fmt.Fprintf(&b, "\n## Отклонения от стиля %s\n\n", r.Recommended)
+		for _, op := range bad {
+			fmt.Fprintf(&b, "- %s: `%s` — %s (%s)\n", op.Repo, op.Operation, op.Style, strings.Join(op.Params, ", "))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
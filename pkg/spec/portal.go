@@ -0,0 +1,226 @@
+package spec
+
+import (
+	"html/template"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type PortalCard struct {
+	Name        string   `json:"name"`
+	Title       string   `json:"title"`
+	Version     string   `json:"version"`
+	Description string   `json:"description,omitempty"`
+	SpecURL     string   `json:"spec_url"`
+	Interactive string   `json:"interactive,omitempty"`
+	Static      string   `json:"static,omitempty"`
+	History     string   `json:"history,omitempty"`
+	Compare     string   `json:"compare,omitempty"`
+	Status      string   `json:"status,omitempty"`
+	Languages   []string `json:"languages,omitempty"`
+}
+
+type Portal struct {
+	Title   string       `json:"title"`
+	Catalog string       `json:"catalog,omitempty"`
+	Search  string       `json:"search,omitempty"`
+	Cards   []PortalCard `json:"cards"`
+}
+
+func BuildPortal(dir string, docs map[string]*Document, base string, statusPages map[string]string) *Portal {
+	p := &Portal{Title: "API Documentation", Cards: []PortalCard{}}
+	exists := func(rel string) bool {
+		_, err := os.Stat(filepath.Join(dir, filepath.FromSlash(rel)))
+		return err == nil
+	}
+	for _, name := range SortedRepos(docs) {
+		doc := docs[name]
+		card := PortalCard{
+			Name:        name,
+			Title:       doc.Info.Title,
+			Version:     doc.Info.Version,
+			Description: doc.Info.Description,
+			SpecURL:     base + name + "/openapi.yaml",
+		}
+		if card.Title == "" {
+			card.Title = name
+		}
+		for _, page := range []struct {
+			dir    string
+			target *string
+		}{
+			{"interactive", &card.Interactive},
+			{"static", &card.Static},
+			{"history", &card.History},
+		} {
+			rel := page.dir + "/" + name + "/index.html"
+			if exists(rel) {
+				*page.target = base + rel
+			}
+		}
+		if exists("compare/" + name) {
+			card.Compare = base + "compare/index.html?repo=" + url.QueryEscape(name)
+		}
+		localized, _ := filepath.Glob(filepath.Join(dir, filepath.FromSlash(name), "openapi.*.yaml"))
+		for _, f := range localized {
+			card.Languages = append(card.Languages, strings.TrimSuffix(strings.TrimPrefix(filepath.Base(f), "openapi."), ".yaml"))
+		}
+		card.Status = statusPages[SpecRepo(name)]
+		p.Cards = append(p.Cards, card)
+	}
+	if exists("catalog.tar.gz") {
+		p.Catalog = base + "catalog.tar.gz"
+	}
+	return p
+}
+
+var portalPage = template.Must(template.New("portal").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{ .Title }}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+.api-cards { display: flex; flex-wrap: wrap; gap: 1em; }
+.api-card { border: 1px solid #ddd; border-radius: 6px; padding: 1em; width: 20em; }
+.api-card h3 { margin-top: 0; }
+.api-card .api-name { color: #666; font-size: 0.9em; }
+.api-card a { margin-right: 0.5em; }
+.api-status.up { color: #2e7d32; }
+.api-status.down { color: #c62828; }
+.api-search input { width: 30em; max-width: 100%; padding: 0.4em; font-size: 1em; }
+.api-search-results { list-style: none; padding: 0; }
+.api-search-results li { margin: 0.3em 0; }
+.api-search-results .method { font-family: monospace; font-weight: bold; margin-right: 0.3em; }
+.api-search-results .path { font-family: monospace; }
+.api-search-results .api, .api-search-results .summary { color: #666; margin-left: 0.5em; }
+.api-search-results .deprecated { text-decoration: line-through; }
+</style>
+</head>
+<body>
+<h1>{{ .Title }}</h1>
+{{- with .Search }}
+<div class="api-search">
+  <input type="search" id="api-search" placeholder="Search endpoints, operations and schemas" autocomplete="off" data-index="{{ . }}">
+  <ul class="api-search-results" id="api-search-results"></ul>
+</div>
+{{- end }}
+{{- if not .Cards }}
+<p>No published APIs yet.</p>
+{{- end }}
+<div class="api-cards">
+{{- range .Cards }}
+<div class="api-card" id="api-{{ .Name }}">
+  <h3>{{ .Title }}</h3>
+  <p class="api-name">{{ .Name }}{{ with .Version }} · v{{ . }}{{ end }}</p>
+  {{- with .Description }}
+  <p>{{ . }}</p>
+  {{- end }}
+  {{- with .Languages }}
+  <p class="api-languages">Languages: original{{ range . }}, {{ . }}{{ end }}</p>
+  {{- end }}
+  {{- with .Status }}
+  <a class="api-status" data-health="{{ . }}" href="{{ . }}">Status</a>
+  {{- end }}
+  <p>
+    {{- with .Interactive }}
+    <a href="{{ . }}">Interactive</a>
+    {{- end }}
+    {{- with .Static }}
+    <a href="{{ . }}">Static</a>
+    {{- end }}
+    {{- with .History }}
+    <a href="{{ . }}">History</a>
+    {{- end }}
+    {{- with .Compare }}
+    <a href="{{ . }}">Compare</a>
+    {{- end }}
+    <a href="{{ .SpecURL }}" download>openapi.yaml</a>
+  </p>
+</div>
+{{- end }}
+</div>
+{{- with .Catalog }}
+<a class="catalog-download" href="{{ . }}">Download all specs</a>
+{{- end }}
+<script id="api-status-script">
+document.querySelectorAll(".api-status[data-health]").forEach(function (el) {
+  fetch(el.dataset.health, { mode: "no-cors", cache: "no-store" })
+    .then(function () { el.classList.add("up"); el.textContent = "● up"; })
+    .catch(function () { el.classList.add("down"); el.textContent = "● down"; });
+});
+</script>
+{{- if .Search }}
+<script id="api-search-script">
+(function () {
+  var input = document.getElementById("api-search");
+  var results = document.getElementById("api-search-results");
+  var cards = document.querySelector(".api-cards");
+  var entries = null;
+  function load() {
+    if (entries) return Promise.resolve(entries);
+    return fetch(input.dataset.index).then(function (r) { return r.json(); }).then(function (idx) {
+      entries = idx.entries.map(function (e) {
+        e.text = [e.api, e.method, e.path, e.name, e.summary].join(" ").toLowerCase();
+        return e;
+      });
+      return entries;
+    });
+  }
+  function show(found) {
+    results.textContent = "";
+    found.slice(0, 50).forEach(function (e) {
+      var li = document.createElement("li");
+      var a = document.createElement("a");
+      a.href = e.url;
+      if (e.deprecated) a.className = "deprecated";
+      if (e.kind === "operation") {
+        a.innerHTML = '<span class="method"></span><span class="path"></span>';
+        a.querySelector(".method").textContent = e.method;
+        a.querySelector(".path").textContent = e.path;
+      } else {
+        a.textContent = (e.kind === "schema" ? "Schema " : "API ") + e.name;
+      }
+      li.appendChild(a);
+      [["api", e.api], ["summary", e.summary || (e.kind === "operation" ? e.name : "")]].forEach(function (part) {
+        if (!part[1]) return;
+        var span = document.createElement("span");
+        span.className = part[0];
+        span.textContent = part[1];
+        li.appendChild(span);
+      });
+      results.appendChild(li);
+    });
+    if (found.length > 50) {
+      var more = document.createElement("li");
+      more.textContent = "… " + (found.length - 50) + " more, refine the query";
+      results.appendChild(more);
+    }
+  }
+  input.addEventListener("input", function () {
+    var words = input.value.toLowerCase().split(/\s+/).filter(Boolean);
+    if (!words.length) {
+      results.textContent = "";
+      cards.style.display = "";
+      return;
+    }
+    load().then(function (all) {
+      show(all.filter(function (e) {
+        return words.every(function (w) { return e.text.indexOf(w) >= 0; });
+      }));
+      cards.style.display = "none";
+    });
+  });
+})();
+</script>
+{{- end }}
+</body>
+</html>
+`))
+
+func (p *Portal) WriteHTML(w io.Writer) error {
+	return portalPage.Execute(w, p)
+}
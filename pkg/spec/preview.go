@@ -0,0 +1,86 @@
+package spec
+
+import (
+	"bytes"
+	"html/template"
+	"io"
+	"sort"
+)
+
+type Preview struct {
+	Repo      string   `json:"repo"`
+	Version   string   `json:"version"`
+	Published string   `json:"published,omitempty"`
+	Problems  []string `json:"problems,omitempty"`
+	Warnings  []string `json:"warnings,omitempty"`
+	Changes   []Change `json:"changes,omitempty"`
+	spec      template.JS
+}
+
+func NewPreview(repo string, doc, published *Document, data []byte) (*Preview, error) {
+	js, err := ToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	js = bytes.ReplaceAll(js, []byte("<"), []byte(`\u003c`))
+	p := &Preview{Repo: repo, Version: doc.Info.Version, spec: template.JS(js)}
+	for _, issue := range Validate(repo, data) {
+		p.Problems = append(p.Problems, issue.Error())
+	}
+	if published != nil {
+		p.Published = published.Info.Version
+		p.Changes = Diff(published, doc)
+		sort.SliceStable(p.Changes, func(i, j int) bool {
+			return p.Changes[i].Category == ChangeBreaking && p.Changes[j].Category != ChangeBreaking
+		})
+	}
+	return p, nil
+}
+
+func (p *Preview) Breaking() int {
+	return CountChanges(p.Changes)[ChangeBreaking]
+}
+
+var previewPage = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<title>Предпросмотр: {{ .Repo }} {{ .Version }}</title>
+<link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+<header class="preview">
+  <h1>Предпросмотр: {{ .Repo }} {{ .Version }}</h1>
+  <p>{{ if .Published }}Опубликована версия {{ .Published }}.{{ else }}Сервис ещё не опубликован.{{ end }} Эта страница не публикуется.</p>
+  {{- with .Problems }}
+  <h2>Ошибки</h2>
+  <ul>{{ range . }}<li>{{ . }}</li>{{ end }}</ul>
+  {{- end }}
+  {{- with .Warnings }}
+  <h2>Замечания</h2>
+  <ul>{{ range . }}<li>{{ . }}</li>{{ end }}</ul>
+  {{- end }}
+  {{- if .Published }}
+  <h2>Изменения относительно {{ .Published }}</h2>
+  {{- if .Changes }}
+  <ul>{{ range .Changes }}<li class="change-{{ .Category }}">[{{ .Category }}] {{ .String }}</li>{{ end }}</ul>
+  {{- else }}
+  <p>Контракт не изменился.</p>
+  {{- end }}
+  {{- end }}
+</header>
+<div id="swagger-ui"></div>
+<script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+<script>
+SwaggerUIBundle({ spec: {{ .Spec }}, dom_id: "#swagger-ui" });
+</script>
+</body>
+</html>
+`))
+
+func (p *Preview) WriteHTML(w io.Writer) error {
+	return previewPage.Execute(w, struct {
+		*Preview
+		Spec template.JS
+	}{p, p.spec})
+}
@@ -0,0 +1,80 @@
+package spec
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+)
+
+type ReadmeRow struct {
+	PortalCard
+	Updated time.Time
+	Badge   string
+}
+
+type Readme struct {
+	Title   string
+	Portal  string
+	Catalog string
+	Rows    []ReadmeRow
+}
+
+func BuildReadme(p *Portal, updated map[string]time.Time, badges map[string]string) *Readme {
+	r := &Readme{Title: p.Title, Catalog: p.Catalog}
+	for _, card := range p.Cards {
+		r.Rows = append(r.Rows, ReadmeRow{PortalCard: card, Updated: updated[card.Name], Badge: badges[card.Name]})
+	}
+	return r
+}
+
+var readmePage = template.Must(template.New("readme").Funcs(template.FuncMap{"cell": markdownCell}).Parse(`# {{ .Title }}
+
+This file is generated by openapi-aggregator from the published specs. Do not edit it by hand.
+{{- with .Portal }}
+
+Portal: {{ . }}
+{{- end }}
+{{ if not .Rows }}
+No published APIs yet.
+{{- else }}
+| API | Version | Updated | Docs |
+|-----|---------|---------|------|
+{{- range .Rows }}
+| **{{ cell .Title }}**<br>` + "`{{ .Name }}`" + ` | {{ with .Version }}{{ cell . }}{{ else }}—{{ end }} | {{ if .Updated.IsZero }}—{{ else }}{{ .Updated.Format "2006-01-02" }}{{ end }} | {{ with .Interactive }}[Interactive]({{ . }}) · {{ end }}{{ with .Static }}[Static]({{ . }}) · {{ end }}[openapi.yaml]({{ .SpecURL }}) |
+{{- end }}
+{{- end }}
+{{- with .Catalog }}
+
+[Download all specs]({{ . }})
+{{- end }}
+{{- if .Rows }}
+
+## Version badges
+
+Add the badge to the service README to show the published API version:
+{{ range .Rows }}{{ if .Badge }}
+- ![{{ .Name }}]({{ .Badge }}) ` + "`![{{ .Name }}]({{ .Badge }})`" + `
+{{- end }}{{ end }}
+{{- end }}
+`))
+
+func (r *Readme) Write(w io.Writer) error {
+	return readmePage.Execute(w, r)
+}
+
+func Badge(label, value string) []byte {
+	lw, vw := 6*len([]rune(label))+10, 6*len([]rune(value))+10
+	esc := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+	label, value = esc.Replace(label), esc.Replace(value)
+	return []byte(fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[2]s: %[3]s">
+<rect width="%[4]d" height="20" fill="#555"/>
+<rect x="%[4]d" width="%[5]d" height="20" fill="#007ec6"/>
+<g fill="#fff" font-family="Verdana,DejaVu Sans,sans-serif" font-size="11" text-anchor="middle">
+<text x="%[6]d" y="14">%[2]s</text>
+<text x="%[7]d" y="14">%[3]s</text>
+</g>
+</svg>
+`, lw+vw, label, value, lw, vw, lw/2, lw+vw/2))
+}
@@ -0,0 +1,398 @@
+package spec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const CodeSamplesKey = "x-codeSamples"
+
+var SampleLanguages = []string{"curl", "go", "python", "javascript"}
+
+var sampleLabels = map[string][2]string{
+	"curl":       {"Shell", "cURL"},
+	"go":         {"Go", "Go"},
+	"python":     {"Python", "Python"},
+	"javascript": {"JavaScript", "JavaScript"},
+}
+
+type sampleRequest struct {
+	Method  string
+	URL     string
+	Headers [][2]string
+	Body    any
+}
+
+func exampleValue(s *Schema, d *Document, depth int) any {
+	s = d.ResolveSchema(s)
+	if s == nil || depth > 5 {
+		return nil
+	}
+	switch {
+	case s.Example != nil:
+		return s.Example
+	case s.Default != nil:
+		return s.Default
+	case len(s.Enum) > 0:
+		return s.Enum[0]
+	}
+	if len(s.Properties) > 0 || len(s.AllOf) > 0 || s.Type == "object" {
+		obj := map[string]any{}
+		for _, sub := range s.AllOf {
+			if m, ok := exampleValue(sub, d, depth+1).(map[string]any); ok {
+				for k, v := range m {
+					obj[k] = v
+				}
+			}
+		}
+		for name, prop := range s.Properties {
+			obj[name] = exampleValue(prop, d, depth+1)
+		}
+		return obj
+	}
+	for _, alt := range [][]*Schema{s.OneOf, s.AnyOf} {
+		if len(alt) > 0 {
+			return exampleValue(alt[0], d, depth+1)
+		}
+	}
+	switch s.Type {
+	case "array":
+		return []any{exampleValue(s.Items, d, depth+1)}
+	case "integer":
+		return 0
+	case "number":
+		return 0.0
+	case "boolean":
+		return true
+	case "string":
+		switch s.Format {
+		case "date-time":
+			return "2024-01-01T00:00:00Z"
+		case "date":
+			return "2024-01-01"
+		case "uuid":
+			return "00000000-0000-0000-0000-000000000000"
+		case "email":
+			return "user@example.com"
+		}
+		return "string"
+	}
+	return nil
+}
+
+func paramValue(p *Parameter, d *Document) (string, bool) {
+	if p.Example != nil {
+		return fmt.Sprint(p.Example), true
+	}
+	if s := d.ResolveSchema(p.Schema); s != nil && (s.Example != nil || s.Default != nil || len(s.Enum) > 0) {
+		return fmt.Sprint(exampleValue(s, d, 0)), true
+	}
+	return "{" + p.Name + "}", false
+}
+
+func (d *Document) securityHeaders(op *Operation) [][2]string {
+	reqs := d.Security
+	if op.Security != nil {
+		reqs = op.Security
+	}
+	if len(reqs) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(reqs[0]))
+	for name := range reqs[0] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var headers [][2]string
+	for _, name := range names {
+		s := d.Components.SecuritySchemes[name]
+		if s == nil {
+			continue
+		}
+		switch {
+		case s.Type == "apiKey" && s.In == "header":
+			headers = append(headers, [2]string{s.Name, "<API_KEY>"})
+		case s.Type == "http" && strings.EqualFold(s.Scheme, "basic"):
+			headers = append(headers, [2]string{"Authorization", "Basic <CREDENTIALS>"})
+		case s.Type == "http" || s.Type == "oauth2" || s.Type == "openIdConnect":
+			headers = append(headers, [2]string{"Authorization", "Bearer <TOKEN>"})
+		}
+	}
+	return headers
+}
+
+func (d *Document) sampleRequest(op OperationRef) sampleRequest {
+	base := "https://api.example.com"
+	if len(d.Servers) > 0 && d.Servers[0].URL != "" {
+		base = d.Servers[0].URL
+	}
+	path := op.Path
+	var query []string
+	var headers [][2]string
+	for _, p := range d.Parameters(op) {
+		value, known := paramValue(p, d)
+		switch p.In {
+		case "path":
+			if known {
+				value = url.PathEscape(value)
+			}
+			path = strings.ReplaceAll(path, "{"+p.Name+"}", value)
+		case "query":
+			if p.Required {
+				if known {
+					value = url.QueryEscape(value)
+				}
+				query = append(query, url.QueryEscape(p.Name)+"="+value)
+			}
+		case "header":
+			if p.Required {
+				headers = append(headers, [2]string{p.Name, value})
+			}
+		}
+	}
+	req := sampleRequest{Method: op.Method, URL: strings.TrimSuffix(base, "/") + path}
+	if len(query) > 0 {
+		req.URL += "?" + strings.Join(query, "&")
+	}
+	req.Headers = append(d.securityHeaders(op.Operation), headers...)
+
+	if body := d.ResolveRequestBody(op.Operation.RequestBody); body != nil {
+		for _, ct := range []string{"application/json", "application/merge-patch+json"} {
+			mt := body.Content[ct]
+			if mt == nil {
+				continue
+			}
+			req.Headers = append(req.Headers, [2]string{"Content-Type", ct})
+			if mt.Example != nil {
+				req.Body = mt.Example
+			} else {
+				req.Body = exampleValue(mt.Schema, d, 0)
+			}
+			break
+		}
+	}
+	return req
+}
+
+func jsonText(v any, indent string) (string, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", indent)
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+func (r sampleRequest) bodyJSON(indent string) string {
+	if r.Body == nil {
+		return ""
+	}
+	text, err := jsonText(r.Body, indent)
+	if err != nil {
+		return ""
+	}
+	return text
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func (r sampleRequest) curl() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "curl -X %s %s", r.Method, shellQuote(r.URL))
+	for _, h := range r.Headers {
+		fmt.Fprintf(&b, " \\\n  -H %s", shellQuote(h[0]+": "+h[1]))
+	}
+	if body := r.bodyJSON("  "); body != "" {
+		fmt.Fprintf(&b, " \\\n  -d %s", shellQuote(body))
+	}
+	return b.String()
+}
+
+func (r sampleRequest) golang() string {
+	var b strings.Builder
+	body := r.bodyJSON("\t")
+	b.WriteString("package main\n\nimport (\n\t\"fmt\"\n\t\"io\"\n\t\"net/http\"\n")
+	if body != "" {
+		b.WriteString("\t\"strings\"\n")
+	}
+	b.WriteString(")\n\nfunc main() {\n")
+	reader := "nil"
+	if body != "" {
+		quoted := "`" + body + "`"
+		if strings.Contains(body, "`") {
+			quoted = strconv.Quote(body)
+		}
+		fmt.Fprintf(&b, "\tbody := strings.NewReader(%s)\n", quoted)
+		reader = "body"
+	}
+	fmt.Fprintf(&b, "\treq, err := http.NewRequest(%q, %q, %s)\n\tif err != nil {\n\t\tpanic(err)\n\t}\n", r.Method, r.URL, reader)
+	for _, h := range r.Headers {
+		fmt.Fprintf(&b, "\treq.Header.Set(%q, %q)\n", h[0], h[1])
+	}
+	b.WriteString("\tresp, err := http.DefaultClient.Do(req)\n\tif err != nil {\n\t\tpanic(err)\n\t}\n\tdefer resp.Body.Close()\n")
+	b.WriteString("\tdata, _ := io.ReadAll(resp.Body)\n\tfmt.Println(resp.Status, string(data))\n}")
+	return b.String()
+}
+
+func pythonLiteral(v any, indent string) string {
+	switch v := v.(type) {
+	case nil:
+		return "None"
+	case bool:
+		if v {
+			return "True"
+		}
+		return "False"
+	case string:
+		text, _ := jsonText(v, "")
+		return text
+	case map[string]any:
+		if len(v) == 0 {
+			return "{}"
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var b strings.Builder
+		b.WriteString("{\n")
+		for _, k := range keys {
+			fmt.Fprintf(&b, "%s    %s: %s,\n", indent, pythonLiteral(k, ""), pythonLiteral(v[k], indent+"    "))
+		}
+		return b.String() + indent + "}"
+	case []any:
+		if len(v) == 0 {
+			return "[]"
+		}
+		var b strings.Builder
+		b.WriteString("[\n")
+		for _, item := range v {
+			fmt.Fprintf(&b, "%s    %s,\n", indent, pythonLiteral(item, indent+"    "))
+		}
+		return b.String() + indent + "]"
+	}
+	text, err := jsonText(v, "")
+	if err != nil {
+		return "None"
+	}
+	return text
+}
+
+func (r sampleRequest) python() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "import requests\n\nresponse = requests.request(\n    %q,\n    %q,\n", r.Method, r.URL)
+	if len(r.Headers) > 0 {
+		b.WriteString("    headers={\n")
+		for _, h := range r.Headers {
+			fmt.Fprintf(&b, "        %q: %q,\n", h[0], h[1])
+		}
+		b.WriteString("    },\n")
+	}
+	if r.Body != nil {
+		fmt.Fprintf(&b, "    json=%s,\n", pythonLiteral(r.Body, "    "))
+	}
+	b.WriteString(")\nprint(response.status_code, response.text)")
+	return b.String()
+}
+
+func (r sampleRequest) javascript() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "const response = await fetch(%q, {\n  method: %q,\n", r.URL, r.Method)
+	if len(r.Headers) > 0 {
+		b.WriteString("  headers: {\n")
+		for _, h := range r.Headers {
+			fmt.Fprintf(&b, "    %q: %q,\n", h[0], h[1])
+		}
+		b.WriteString("  },\n")
+	}
+	if body := r.bodyJSON("  "); body != "" {
+		fmt.Fprintf(&b, "  body: JSON.stringify(%s),\n", strings.ReplaceAll(body, "\n", "\n  "))
+	}
+	b.WriteString("});\nconsole.log(response.status, await response.text());")
+	return b.String()
+}
+
+func (r sampleRequest) render(lang string) string {
+	switch lang {
+	case "curl":
+		return r.curl()
+	case "go":
+		return r.golang()
+	case "python":
+		return r.python()
+	default:
+		return r.javascript()
+	}
+}
+
+func ParseSampleLanguages(s string) ([]string, error) {
+	var langs []string
+	for _, lang := range strings.Split(s, ",") {
+		if lang = strings.ToLower(strings.TrimSpace(lang)); lang == "" {
+			continue
+		}
+		if _, ok := sampleLabels[lang]; !ok {
+			return nil, fmt.Errorf("неизвестный язык %q (доступны: %s)", lang, strings.Join(SampleLanguages, ", "))
+		}
+		langs = append(langs, lang)
+	}
+	return langs, nil
+}
+
+func scalar(v string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v}
+}
+
+func AddCodeSamples(data []byte, languages []string) ([]byte, int, error) {
+	doc, err := ParseDocument(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, 0, fmt.Errorf("разбор спецификации: %w", err)
+	}
+	if len(root.Content) == 0 {
+		return data, 0, nil
+	}
+	paths := nodeValue(root.Content[0], "paths")
+
+	added := 0
+	for _, op := range doc.Operations() {
+		node := nodeValue(nodeValue(paths, op.Path), strings.ToLower(op.Method))
+		if node == nil || node.Kind != yaml.MappingNode || nodeValue(node, CodeSamplesKey) != nil {
+			continue
+		}
+		req := doc.sampleRequest(op)
+		samples := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, lang := range languages {
+			source := scalar(req.render(lang))
+			source.Style = yaml.LiteralStyle
+			samples.Content = append(samples.Content, &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{
+				scalar("lang"), scalar(sampleLabels[lang][0]),
+				scalar("label"), scalar(sampleLabels[lang][1]),
+				scalar("source"), source,
+			}})
+		}
+		node.Content = append(node.Content, scalar(CodeSamplesKey), samples)
+		added++
+	}
+	if added == 0 {
+		return data, 0, nil
+	}
+
+	out, err := encodeNode(&root)
+	return out, added, err
+}
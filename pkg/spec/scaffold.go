@@ -0,0 +1,285 @@
+package spec
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type scaffoldInfo struct {
+	Title   string `yaml:"title"`
+	Version string `yaml:"version"`
+}
+
+type scaffold struct {
+	OpenAPI string         `yaml:"openapi"`
+	Info    scaffoldInfo   `yaml:"info"`
+	Paths   map[string]any `yaml:"paths"`
+}
+
+type scaffoldOperation struct {
+	OperationID string                      `yaml:"operationId"`
+	Summary     string                      `yaml:"summary,omitempty"`
+	Parameters  []scaffoldParameter         `yaml:"parameters,omitempty"`
+	Responses   map[string]scaffoldResponse `yaml:"responses"`
+}
+
+type scaffoldParameter struct {
+	Name     string            `yaml:"name"`
+	In       string            `yaml:"in"`
+	Required bool              `yaml:"required"`
+	Schema   map[string]string `yaml:"schema"`
+}
+
+type scaffoldResponse struct {
+	Description string `yaml:"description"`
+}
+
+type Route struct {
+	Method  string
+	Path    string
+	Summary string
+}
+
+func Scaffold(title string) ([]byte, error) {
+	return ScaffoldRoutes(title, nil)
+}
+
+func ScaffoldRoutes(title string, routes []Route) ([]byte, error) {
+	paths := map[string]any{}
+	ids := map[string]bool{}
+	for _, r := range routes {
+		item, _ := paths[r.Path].(map[string]scaffoldOperation)
+		if item == nil {
+			item = map[string]scaffoldOperation{}
+			paths[r.Path] = item
+		}
+		method := strings.ToLower(r.Method)
+		if _, ok := item[method]; ok {
+			continue
+		}
+		op := scaffoldOperation{
+			OperationID: uniqueID(ids, operationID(method, r.Path)),
+			Summary:     r.Summary,
+			Responses:   map[string]scaffoldResponse{"200": {Description: "OK"}},
+		}
+		for _, name := range pathParams(r.Path) {
+			op.Parameters = append(op.Parameters, scaffoldParameter{
+				Name: name, In: "path", Required: true, Schema: map[string]string{"type": "string"},
+			})
+		}
+		item[method] = op
+	}
+
+	var root yaml.Node
+	err := root.Encode(scaffold{
+		OpenAPI: "3.0.3",
+		Info:    scaffoldInfo{Title: title, Version: "0.1.0"},
+		Paths:   paths,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return encodeNode(&root)
+}
+
+var (
+	routeLine = regexp.MustCompile(`\b(GET|HEAD|POST|PUT|PATCH|DELETE|OPTIONS|TRACE)\s+(/\S*)`)
+	pathParam = regexp.MustCompile(`\{([^}]+)\}`)
+	optional  = regexp.MustCompile(`\([^)]*\)`)
+	paramName = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+	idSegment = regexp.MustCompile(`^([0-9]+|[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}|[0-9a-fA-F]{16,})$`)
+)
+
+func ParseRoutes(data []byte) ([]Route, error) {
+	var raw any
+	var routes []Route
+	if err := yaml.Unmarshal(data, &raw); err == nil {
+		switch v := raw.(type) {
+		case []any:
+			routes = routesFromList(v)
+		case map[string]any:
+			if list, ok := v["routes"].([]any); ok {
+				routes = routesFromList(list)
+			}
+			services, _ := v["services"].([]any)
+			for _, s := range services {
+				if svc, ok := s.(map[string]any); ok {
+					list, _ := svc["routes"].([]any)
+					routes = append(routes, routesFromList(list)...)
+				}
+			}
+		}
+	}
+	if routes == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if m := routeLine.FindStringSubmatch(line); m != nil {
+				routes = append(routes, Route{Method: m[1], Path: m[2]})
+			}
+		}
+	}
+
+	seen := map[string]bool{}
+	var out []Route
+	for _, r := range routes {
+		r.Method = strings.ToLower(r.Method)
+		if !slices.Contains(httpMethods, r.Method) || !strings.HasPrefix(r.Path, "/") {
+			continue
+		}
+		r.Path = normalizeRoutePath(r.Path)
+		if key := r.Method + " " + r.Path; !seen[key] {
+			seen[key] = true
+			out = append(out, r)
+		}
+	}
+	if len(out) == 0 {
+		return nil, errors.New("не найдено ни одного маршрута вида METHOD /path")
+	}
+	return out, nil
+}
+
+func routesFromList(list []any) []Route {
+	var routes []Route
+	for _, item := range list {
+		switch v := item.(type) {
+		case string:
+			if m := routeLine.FindStringSubmatch(v); m != nil {
+				routes = append(routes, Route{Method: m[1], Path: m[2]})
+			}
+		case map[string]any:
+			summary := firstString(v, "summary", "description", "name", "handler")
+			methods := stringList(v["methods"])
+			if m := firstString(v, "method", "verb"); m != "" {
+				methods = append(methods, m)
+			}
+			if len(methods) == 0 {
+				methods = []string{"GET"}
+			}
+			paths := stringList(v["paths"])
+			if p := firstString(v, "path", "uri", "route", "url", "pattern"); p != "" {
+				paths = append(paths, p)
+			}
+			for _, p := range paths {
+				for _, m := range methods {
+					routes = append(routes, Route{Method: m, Path: p, Summary: summary})
+				}
+			}
+		}
+	}
+	return routes
+}
+
+func firstString(m map[string]any, keys ...string) string {
+	for _, k := range keys {
+		if s, ok := m[k].(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+func stringList(v any) []string {
+	list, _ := v.([]any)
+	var out []string
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func normalizeRoutePath(p string) string {
+	if i := strings.IndexAny(p, "?#"); i >= 0 {
+		p = p[:i]
+	}
+	p = optional.ReplaceAllString(p, "")
+	used := map[string]bool{}
+	param := func(name string) string {
+		name = paramName.ReplaceAllString(name, "")
+		if name == "" {
+			name = "id"
+		}
+		unique := name
+		for n := 2; used[unique]; n++ {
+			unique = name + strconv.Itoa(n)
+		}
+		used[unique] = true
+		return "{" + unique + "}"
+	}
+	var segments []string
+	for _, seg := range strings.Split(p, "/") {
+		if seg == "" {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(seg, ":"):
+			seg = param(seg[1:])
+		case strings.HasPrefix(seg, "<") && strings.HasSuffix(seg, ">"):
+			name := strings.TrimSuffix(seg[1:], ">")
+			if i := strings.LastIndex(name, ":"); i >= 0 {
+				name = name[i+1:]
+			}
+			seg = param(name)
+		case strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}"):
+			name, _, _ := strings.Cut(seg[1:len(seg)-1], ":")
+			seg = param(name)
+		case strings.HasPrefix(seg, "*"):
+			name := seg[1:]
+			if name == "" {
+				name = "path"
+			}
+			seg = param(name)
+		case idSegment.MatchString(seg):
+			seg = param("id")
+		}
+		segments = append(segments, seg)
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+func pathParams(p string) []string {
+	var names []string
+	for _, m := range pathParam.FindAllStringSubmatch(p, -1) {
+		names = append(names, m[1])
+	}
+	return names
+}
+
+func operationID(method, p string) string {
+	var b strings.Builder
+	b.WriteString(method)
+	words := 0
+	for _, seg := range strings.Split(p, "/") {
+		if seg == "" {
+			continue
+		}
+		if m := pathParam.FindStringSubmatch(seg); m != nil {
+			b.WriteString("By")
+			seg = m[1]
+		}
+		for _, w := range strings.FieldsFunc(seg, func(r rune) bool {
+			return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+		}) {
+			b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+			words++
+		}
+	}
+	if words == 0 {
+		b.WriteString("Root")
+	}
+	return b.String()
+}
+
+func uniqueID(ids map[string]bool, id string) string {
+	unique := id
+	for n := 2; ids[unique]; n++ {
+		unique = id + strconv.Itoa(n)
+	}
+	ids[unique] = true
+	return unique
+}
@@ -0,0 +1,169 @@
+package spec
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const SDKFile = "sdks.yaml"
+
+const (
+	LagNone    = ""
+	LagPatch   = "patch"
+	LagMinor   = "minor"
+	LagMajor   = "major"
+	LagUnknown = "unknown"
+)
+
+type SDKRelease struct {
+	Language    string    `yaml:"language" json:"language"`
+	Package     string    `yaml:"package" json:"package"`
+	Version     string    `yaml:"version" json:"version"`
+	SpecVersion string    `yaml:"spec_version" json:"spec_version"`
+	Published   time.Time `yaml:"published" json:"published"`
+}
+
+func LoadSDKs(dir, repo string) ([]SDKRelease, error) {
+	path := filepath.Join(dir, repo, SDKFile)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		SDKs []SDKRelease `yaml:"sdks"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("разбор %s: %w", path, err)
+	}
+	return file.SDKs, nil
+}
+
+func RecordSDK(dir, repo string, rel SDKRelease) error {
+	if rel.Language == "" || rel.Package == "" || rel.Version == "" || rel.SpecVersion == "" {
+		return errors.New("нужно указать язык, пакет, версию SDK и версию спецификации")
+	}
+	sdks, err := LoadSDKs(dir, repo)
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i, s := range sdks {
+		if s.Language == rel.Language && s.Package == rel.Package {
+			sdks[i], replaced = rel, true
+		}
+	}
+	if !replaced {
+		sdks = append(sdks, rel)
+	}
+	sort.Slice(sdks, func(i, j int) bool {
+		if sdks[i].Language != sdks[j].Language {
+			return sdks[i].Language < sdks[j].Language
+		}
+		return sdks[i].Package < sdks[j].Package
+	})
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(struct {
+		SDKs []SDKRelease `yaml:"sdks"`
+	}{sdks}); err != nil {
+		return err
+	}
+	enc.Close()
+	return os.WriteFile(filepath.Join(dir, repo, SDKFile), buf.Bytes(), 0o644)
+}
+
+func SpecLag(sdkSpec, latest string) string {
+	if sdkSpec == latest {
+		return LagNone
+	}
+	a, errA := ParseSemver(sdkSpec)
+	b, errB := ParseSemver(latest)
+	switch {
+	case errA != nil || errB != nil:
+		return LagUnknown
+	case a.Compare(b) >= 0:
+		return LagNone
+	case a.Major != b.Major:
+		return LagMajor
+	case a.Minor != b.Minor:
+		return LagMinor
+	default:
+		return LagPatch
+	}
+}
+
+type SDKStatus struct {
+	Repo       string `json:"repo"`
+	LatestSpec string `json:"latest_spec"`
+	Lag        string `json:"lag,omitempty"`
+	SDKRelease
+}
+
+type SDKReport struct {
+	SDKs    []SDKStatus `json:"sdks"`
+	Without []string    `json:"without_sdk,omitempty"`
+}
+
+func BuildSDKReport(dir string, docs map[string]*Document) (*SDKReport, error) {
+	r := &SDKReport{}
+	for _, repo := range SortedRepos(docs) {
+		sdks, err := LoadSDKs(dir, repo)
+		if err != nil {
+			return nil, err
+		}
+		if len(sdks) == 0 {
+			r.Without = append(r.Without, repo)
+			continue
+		}
+		latest := docs[repo].Info.Version
+		for _, s := range sdks {
+			r.SDKs = append(r.SDKs, SDKStatus{Repo: repo, LatestSpec: latest, Lag: SpecLag(s.SpecVersion, latest), SDKRelease: s})
+		}
+	}
+	return r, nil
+}
+
+func (r *SDKReport) Lagging() []SDKStatus {
+	var out []SDKStatus
+	for _, s := range r.SDKs {
+		if s.Lag != LagNone {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func (r *SDKReport) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("# Актуальность SDK\n\n")
+	fmt.Fprintf(&b, "SDK: %d, отстают от опубликованного контракта: %d.\n", len(r.SDKs), len(r.Lagging()))
+	if len(r.SDKs) > 0 {
+		b.WriteString("\n| Сервис | Язык | Пакет | Версия SDK | Контракт SDK | Последний контракт | Отставание |\n|---|---|---|---|---|---|---|\n")
+		for _, s := range r.SDKs {
+			lag := "—"
+			if s.Lag != LagNone {
+				lag = "**" + s.Lag + "**"
+			}
+			fmt.Fprintf(&b, "| %s | %s | `%s` | %s | %s | %s | %s |\n", s.Repo, s.Language, s.Package, s.Version, s.SpecVersion, s.LatestSpec, lag)
+		}
+	}
+	if len(r.Without) > 0 {
+		fmt.Fprintf(&b, "\nСервисы без опубликованных SDK: %s\n", strings.Join(r.Without, ", "))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,122 @@
+package spec
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+const SearchIndexFile = "search-index.json"
+
+const (
+	SearchAPI       = "api"
+	SearchOperation = "operation"
+	SearchSchema    = "schema"
+)
+
+type SearchEntry struct {
+	Kind       string `json:"kind"`
+	API        string `json:"api"`
+	Method     string `json:"method,omitempty"`
+	Path       string `json:"path,omitempty"`
+	Name       string `json:"name,omitempty"`
+	Summary    string `json:"summary,omitempty"`
+	Deprecated bool   `json:"deprecated,omitempty"`
+	URL        string `json:"url"`
+}
+
+type SearchIndex struct {
+	Entries []SearchEntry `json:"entries"`
+}
+
+func BuildSearchIndex(p *Portal, docs map[string]*Document) *SearchIndex {
+	idx := &SearchIndex{Entries: []SearchEntry{}}
+	for _, card := range p.Cards {
+		doc := docs[card.Name]
+		if doc == nil {
+			continue
+		}
+		link, anchor := card.Static, redocAnchor
+		if link == "" {
+			link, anchor = card.Interactive, swaggerAnchor
+		}
+		if link == "" {
+			link, anchor = card.SpecURL, func(*Operation) string { return "" }
+		}
+		idx.Entries = append(idx.Entries, SearchEntry{
+			Kind:    SearchAPI,
+			API:     card.Name,
+			Name:    card.Title,
+			Summary: card.Description,
+			URL:     link,
+		})
+		for _, op := range doc.Operations() {
+			summary := op.Operation.Summary
+			if summary == "" {
+				summary = firstLine(op.Operation.Description)
+			}
+			idx.Entries = append(idx.Entries, SearchEntry{
+				Kind:       SearchOperation,
+				API:        card.Name,
+				Method:     op.Method,
+				Path:       op.Path,
+				Name:       op.Operation.OperationID,
+				Summary:    summary,
+				Deprecated: op.Operation.Deprecated,
+				URL:        link + anchor(op.Operation),
+			})
+		}
+		names := make([]string, 0, len(doc.Components.Schemas))
+		for name := range doc.Components.Schemas {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			entry := SearchEntry{Kind: SearchSchema, API: card.Name, Name: name, URL: link}
+			if s := doc.Components.Schemas[name]; s != nil {
+				entry.Summary = firstLine(s.Description)
+			}
+			idx.Entries = append(idx.Entries, entry)
+		}
+	}
+	return idx
+}
+
+// redocAnchor and swaggerAnchor build the fragment each renderer uses for an
+// operation, so a search hit opens the operation itself.
+func redocAnchor(op *Operation) string {
+	if op.OperationID != "" {
+		return "#operation/" + url.PathEscape(op.OperationID)
+	}
+	if len(op.Tags) > 0 {
+		return "#tag/" + url.PathEscape(strings.Join(strings.Fields(op.Tags[0]), "-"))
+	}
+	return ""
+}
+
+func swaggerAnchor(op *Operation) string {
+	tag := "default"
+	if len(op.Tags) > 0 {
+		tag = op.Tags[0]
+	}
+	anchor := "#/" + swaggerEscape(tag)
+	if op.OperationID != "" {
+		anchor += "/" + swaggerEscape(op.OperationID)
+	}
+	return anchor
+}
+
+func swaggerEscape(s string) string {
+	return url.PathEscape(strings.Join(strings.Fields(s), "_"))
+}
+
+func (idx *SearchIndex) WriteJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(idx)
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(line)
+}
@@ -0,0 +1,200 @@
+package spec
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var versionSegment = regexp.MustCompile(`^[0-9A-Za-z][0-9A-Za-z.+_-]*$`)
+
+// SafeVersion reports whether info.version can be used as a directory name.
+func SafeVersion(v string) bool {
+	return len(v) <= 64 && versionSegment.MatchString(v) && !strings.Contains(v, "..")
+}
+
+type Semver struct {
+	Major, Minor, Patch int
+	Pre                 string
+}
+
+func ParseSemver(s string) (Semver, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		s = s[:i]
+	}
+	var v Semver
+	s, v.Pre, _ = strings.Cut(s, "-")
+	parts := strings.Split(s, ".")
+	if len(parts) == 0 || len(parts) > 3 {
+		return Semver{}, fmt.Errorf("некорректная версия %q", s)
+	}
+	nums := []*int{&v.Major, &v.Minor, &v.Patch}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return Semver{}, fmt.Errorf("некорректная версия %q", s)
+		}
+		*nums[i] = n
+	}
+	return v, nil
+}
+
+func (v Semver) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Pre != "" {
+		s += "-" + v.Pre
+	}
+	return s
+}
+
+func (v Semver) Compare(o Semver) int {
+	for _, d := range []int{v.Major - o.Major, v.Minor - o.Minor, v.Patch - o.Patch} {
+		if d != 0 {
+			return sign(d)
+		}
+	}
+	switch {
+	case v.Pre == o.Pre:
+		return 0
+	case v.Pre == "":
+		return 1
+	case o.Pre == "":
+		return -1
+	}
+	return strings.Compare(v.Pre, o.Pre)
+}
+
+func sign(n int) int {
+	if n < 0 {
+		return -1
+	}
+	return 1
+}
+
+type Range []func(Semver) bool
+
+func ParseRange(expr string) (Range, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" || expr == "*" || expr == "latest" {
+		return Range{func(v Semver) bool { return v.Pre == "" }}, nil
+	}
+
+	var r Range
+	for _, part := range strings.Fields(expr) {
+		raw := strings.TrimLeft(part, "^~<>=")
+		op := part[:len(part)-len(raw)]
+		dots := strings.Count(raw, ".")
+		wild := dots < 2 || strings.ContainsAny(raw, "xX*")
+		v, err := ParseSemver(strings.NewReplacer("x", "0", "X", "0", "*", "0").Replace(raw))
+		if err != nil {
+			return nil, err
+		}
+
+		switch op {
+		case "^":
+			upper := Semver{Major: v.Major + 1}
+			if v.Major == 0 {
+				upper = Semver{Minor: v.Minor + 1}
+			}
+			r = append(r, between(v, upper))
+		case "~":
+			r = append(r, between(v, Semver{Major: v.Major, Minor: v.Minor + 1}))
+		case ">=":
+			r = append(r, func(x Semver) bool { return x.Compare(v) >= 0 })
+		case ">":
+			r = append(r, func(x Semver) bool { return x.Compare(v) > 0 })
+		case "<=":
+			r = append(r, func(x Semver) bool { return x.Compare(v) <= 0 })
+		case "<":
+			r = append(r, func(x Semver) bool { return x.Compare(v) < 0 })
+		case "", "=":
+			switch {
+			case !wild:
+				r = append(r, func(x Semver) bool { return x.Compare(v) == 0 })
+			case dots == 0:
+				r = append(r, between(v, Semver{Major: v.Major + 1}))
+			default:
+				r = append(r, between(v, Semver{Major: v.Major, Minor: v.Minor + 1}))
+			}
+		default:
+			return nil, fmt.Errorf("неизвестный оператор %q в %q", op, expr)
+		}
+	}
+	return r, nil
+}
+
+func between(lo, hi Semver) func(Semver) bool {
+	return func(x Semver) bool { return x.Compare(lo) >= 0 && x.Compare(hi) < 0 && x.Pre == "" }
+}
+
+func (r Range) Match(v Semver) bool {
+	for _, f := range r {
+		if !f(v) {
+			return false
+		}
+	}
+	return true
+}
+
+const (
+	BumpNone      = "none"
+	BumpPatch     = "patch"
+	BumpMinor     = "minor"
+	BumpMajor     = "major"
+	BumpDowngrade = "downgrade"
+)
+
+var bumpRank = map[string]int{BumpDowngrade: -1, BumpNone: 0, BumpPatch: 1, BumpMinor: 2, BumpMajor: 3}
+
+type VersionCheck struct {
+	From     string   `json:"from"`
+	To       string   `json:"to"`
+	Required string   `json:"required"`
+	Actual   string   `json:"actual"`
+	OK       bool     `json:"ok"`
+	Changes  []Change `json:"changes"`
+}
+
+func RequiredBump(from Semver, changes []Change) string {
+	counts := CountChanges(changes)
+	switch {
+	case counts[ChangeBreaking] > 0 && from.Major > 0:
+		return BumpMajor
+	case counts[ChangeBreaking] > 0 || counts[ChangeAddition] > 0:
+		return BumpMinor
+	case len(changes) > 0:
+		return BumpPatch
+	}
+	return BumpNone
+}
+
+func VersionBump(from, to Semver) string {
+	switch c := to.Compare(from); {
+	case c < 0:
+		return BumpDowngrade
+	case c == 0:
+		return BumpNone
+	case to.Major > from.Major:
+		return BumpMajor
+	case to.Minor > from.Minor:
+		return BumpMinor
+	}
+	return BumpPatch
+}
+
+func CheckVersion(before, after *Document) (VersionCheck, error) {
+	from, err := ParseSemver(before.Info.Version)
+	if err != nil {
+		return VersionCheck{}, fmt.Errorf("опубликованная версия: %w", err)
+	}
+	to, err := ParseSemver(after.Info.Version)
+	if err != nil {
+		return VersionCheck{}, fmt.Errorf("новая версия: %w", err)
+	}
+	changes := Diff(before, after)
+	vc := VersionCheck{From: from.String(), To: to.String(), Required: RequiredBump(from, changes), Actual: VersionBump(from, to), Changes: changes}
+	vc.OK = bumpRank[vc.Actual] >= bumpRank[vc.Required]
+	return vc, nil
+}
@@ -0,0 +1,242 @@
+package spec
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const SLOKey = "x-slo"
+
+type Percentile struct {
+	Name    string `json:"name"`
+	Target  string `json:"target"`
+	rank    float64
+	latency time.Duration
+}
+
+type OperationSLO struct {
+	Repo         string       `json:"repo"`
+	Operation    string       `json:"operation"`
+	Latency      []Percentile `json:"latency,omitempty"`
+	Availability float64      `json:"availability,omitempty"`
+	Window       string       `json:"window,omitempty"`
+	Problems     []string     `json:"problems,omitempty"`
+}
+
+func parsePercent(s string) (float64, error) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s), "%")), 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q не число", s)
+	}
+	return v, nil
+}
+
+func percentileRank(name string) (float64, bool) {
+	if name == "p999" {
+		return 99.9, true
+	}
+	rest, ok := strings.CutPrefix(name, "p")
+	if !ok {
+		return 0, false
+	}
+	rank, err := strconv.ParseFloat(rest, 64)
+	return rank, err == nil && rank > 0 && rank < 100
+}
+
+func parseWindow(s string) error {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n > 0 {
+			return nil
+		}
+		return fmt.Errorf("некорректное окно %q", s)
+	}
+	if d, err := time.ParseDuration(s); err != nil || d <= 0 {
+		return fmt.Errorf("некорректное окно %q (ожидается, например, 30d или 24h)", s)
+	}
+	return nil
+}
+
+func ParseSLO(repo, operation string, n *yaml.Node) OperationSLO {
+	o := OperationSLO{Repo: repo, Operation: operation}
+	var raw struct {
+		Latency      map[string]string `yaml:"latency"`
+		Availability string            `yaml:"availability"`
+		Window       string            `yaml:"window"`
+	}
+	if n.Kind != yaml.MappingNode || n.Decode(&raw) != nil {
+		o.Problems = append(o.Problems, SLOKey+" должен быть объектом с полями latency, availability, window")
+		return o
+	}
+	if len(raw.Latency) == 0 && raw.Availability == "" {
+		o.Problems = append(o.Problems, "не указаны ни latency, ни availability")
+	}
+	names := make([]string, 0, len(raw.Latency))
+	for name := range raw.Latency {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p := Percentile{Name: name, Target: raw.Latency[name]}
+		var ok bool
+		if p.rank, ok = percentileRank(name); !ok {
+			o.Problems = append(o.Problems, fmt.Sprintf("некорректный перцентиль %q (ожидается p50, p95, p99, p99.9)", name))
+			continue
+		}
+		var err error
+		if p.latency, err = time.ParseDuration(p.Target); err != nil || p.latency <= 0 {
+			o.Problems = append(o.Problems, fmt.Sprintf("%s: некорректная задержка %q (ожидается, например, 200ms)", name, p.Target))
+			continue
+		}
+		o.Latency = append(o.Latency, p)
+	}
+	sort.Slice(o.Latency, func(i, j int) bool { return o.Latency[i].rank < o.Latency[j].rank })
+	for i := 1; i < len(o.Latency); i++ {
+		if o.Latency[i].latency < o.Latency[i-1].latency {
+			o.Problems = append(o.Problems, fmt.Sprintf("%s (%s) меньше %s (%s)", o.Latency[i].Name, o.Latency[i].Target, o.Latency[i-1].Name, o.Latency[i-1].Target))
+		}
+	}
+	if raw.Availability != "" {
+		v, err := parsePercent(raw.Availability)
+		switch {
+		case err != nil:
+			o.Problems = append(o.Problems, "availability: "+err.Error())
+		case v <= 0 || v > 100:
+			o.Problems = append(o.Problems, fmt.Sprintf("availability %v вне диапазона (0, 100]", v))
+		default:
+			o.Availability = v
+		}
+	}
+	if raw.Window != "" {
+		if err := parseWindow(raw.Window); err != nil {
+			o.Problems = append(o.Problems, err.Error())
+		} else {
+			o.Window = raw.Window
+		}
+	}
+	return o
+}
+
+func (o OperationSLO) String() string {
+	var parts []string
+	for _, p := range o.Latency {
+		parts = append(parts, fmt.Sprintf("%s ≤ %s", p.Name, p.Target))
+	}
+	if o.Availability > 0 {
+		a := fmt.Sprintf("доступность ≥ %s%%", strconv.FormatFloat(o.Availability, 'f', -1, 64))
+		if o.Window != "" {
+			a += " за " + o.Window
+		}
+		parts = append(parts, a)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (d *Document) SLOs(repo string) []OperationSLO {
+	var out []OperationSLO
+	for _, op := range d.Operations() {
+		if op.Operation.SLO.Kind != 0 {
+			out = append(out, ParseSLO(repo, op.String(), &op.Operation.SLO))
+		}
+	}
+	return out
+}
+
+type SLOReport struct {
+	Operations []OperationSLO `json:"operations"`
+	Missing    int            `json:"missing"`
+	Total      int            `json:"total"`
+}
+
+func BuildSLOReport(docs map[string]*Document) *SLOReport {
+	r := &SLOReport{}
+	for _, repo := range SortedRepos(docs) {
+		doc := docs[repo]
+		slos := doc.SLOs(repo)
+		r.Operations = append(r.Operations, slos...)
+		r.Total += len(doc.Operations())
+	}
+	r.Missing = r.Total - len(r.Operations)
+	return r
+}
+
+func (r *SLOReport) Invalid() []OperationSLO {
+	var out []OperationSLO
+	for _, o := range r.Operations {
+		if len(o.Problems) > 0 {
+			out = append(out, o)
+		}
+	}
+	return out
+}
+
+func (r *SLOReport) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("# SLO операций\n\n")
+	fmt.Fprintf(&b, "Операций с %s: %d из %d.\n", SLOKey, len(r.Operations), r.Total)
+	if len(r.Operations) > 0 {
+		b.WriteString("\n| Сервис | Операция | Цели |\n|---|---|---|\n")
+		for _, o := range r.Operations {
+			fmt.Fprintf(&b, "| %s | `%s` | %s |\n", o.Repo, o.Operation, o.String())
+		}
+	}
+	if bad := r.Invalid(); len(bad) > 0 {
+		fmt.Fprintf(&b, "\n## Ошибки в %s\n\n", SLOKey)
+		for _, o := range bad {
+			for _, p := range o.Problems {
+				fmt.Fprintf(&b, "- %s: `%s` — %s\n", o.Repo, o.Operation, p)
+			}
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func AnnotateSLO(data []byte) ([]byte, int, error) {
+	doc, err := ParseDocument(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, 0, fmt.Errorf("разбор спецификации: %w", err)
+	}
+	if len(root.Content) == 0 {
+		return data, 0, nil
+	}
+	paths := nodeValue(root.Content[0], "paths")
+
+	annotated := 0
+	for _, op := range doc.Operations() {
+		if op.Operation.SLO.Kind == 0 {
+			continue
+		}
+		slo := ParseSLO("", op.String(), &op.Operation.SLO)
+		node := nodeValue(nodeValue(paths, op.Path), strings.ToLower(op.Method))
+		if len(slo.Problems) > 0 || slo.String() == "" || node == nil || node.Kind != yaml.MappingNode {
+			continue
+		}
+		banner := "**SLO:** " + slo.String()
+		if desc := nodeValue(node, "description"); desc != nil {
+			if strings.HasPrefix(desc.Value, "**SLO:**") {
+				continue
+			}
+			desc.Value = banner + "\n\n" + desc.Value
+			desc.Style = yaml.LiteralStyle
+		} else {
+			node.Content = append(node.Content, scalar("description"), scalar(banner))
+		}
+		annotated++
+	}
+	if annotated == 0 {
+		return data, 0, nil
+	}
+
+	out, err := encodeNode(&root)
+	return out, annotated, err
+}
@@ -0,0 +1,32 @@
+package spec
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Info struct {
+	Title       string `yaml:"title"`
+	Version     string `yaml:"version"`
+	Description string `yaml:"description"`
+}
+
+func ReadInfo(path string) (Info, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Info{}, err
+	}
+	return ParseInfo(data)
+}
+
+func ParseInfo(data []byte) (Info, error) {
+	var doc struct {
+		Info Info `yaml:"info"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return Info{}, fmt.Errorf("разбор спецификации: %w", err)
+	}
+	return doc.Info, nil
+}
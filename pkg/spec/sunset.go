@@ -0,0 +1,165 @@
+package spec
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Sunset struct {
+	Repo      string    `json:"repo"`
+	Title     string    `json:"title"`
+	Operation string    `json:"operation,omitempty"`
+	Date      time.Time `json:"date"`
+}
+
+var httpMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+func ParseSunsets(repo string, data []byte) ([]Sunset, error) {
+	var doc struct {
+		Info struct {
+			Title  string `yaml:"title"`
+			Sunset string `yaml:"x-sunset"`
+		} `yaml:"info"`
+		Paths map[string]map[string]yaml.Node `yaml:"paths"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("разбор спецификации: %w", err)
+	}
+	title := doc.Info.Title
+	if title == "" {
+		title = repo
+	}
+
+	var out []Sunset
+	if doc.Info.Sunset != "" {
+		date, err := parseSunsetDate(doc.Info.Sunset)
+		if err != nil {
+			return nil, fmt.Errorf("info.x-sunset: %w", err)
+		}
+		out = append(out, Sunset{Repo: repo, Title: title, Date: date})
+	}
+	for path, item := range doc.Paths {
+		for _, method := range httpMethods {
+			node, ok := item[method]
+			if !ok {
+				continue
+			}
+			var op struct {
+				Sunset string `yaml:"x-sunset"`
+			}
+			if err := node.Decode(&op); err != nil || op.Sunset == "" {
+				continue
+			}
+			date, err := parseSunsetDate(op.Sunset)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s x-sunset: %w", strings.ToUpper(method), path, err)
+			}
+			out = append(out, Sunset{Repo: repo, Title: title, Operation: strings.ToUpper(method) + " " + path, Date: date})
+		}
+	}
+	return out, nil
+}
+
+func parseSunsetDate(s string) (time.Time, error) {
+	for _, layout := range []string{time.DateOnly, time.RFC3339, http.TimeFormat} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("не удалось разобрать дату %q", s)
+}
+
+func Sunsets(dir string) ([]Sunset, error) {
+	files, err := SpecFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	var all []Sunset
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		repo := SpecName(dir, f)
+		sunsets, err := ParseSunsets(repo, data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", repo, err)
+		}
+		all = append(all, sunsets...)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if !all[i].Date.Equal(all[j].Date) {
+			return all[i].Date.Before(all[j].Date)
+		}
+		if all[i].Repo != all[j].Repo {
+			return all[i].Repo < all[j].Repo
+		}
+		return all[i].Operation < all[j].Operation
+	})
+	return all, nil
+}
+
+func Upcoming(sunsets []Sunset, now time.Time) []Sunset {
+	today := now.UTC().Truncate(24 * time.Hour)
+	var out []Sunset
+	for _, s := range sunsets {
+		if !s.Date.Before(today) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func WriteICS(w io.Writer, sunsets []Sunset, now time.Time) error {
+	var b strings.Builder
+	line := func(s string) {
+		for len(s) > 75 {
+			cut := 75
+			for cut > 0 && s[cut]&0xC0 == 0x80 {
+				cut--
+			}
+			b.WriteString(s[:cut] + "\r\n")
+			s = " " + s[cut:]
+		}
+		b.WriteString(s + "\r\n")
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//openapi-aggregator//sunset calendar//RU")
+	line("CALSCALE:GREGORIAN")
+	line("X-WR-CALNAME:API sunset")
+	stamp := now.UTC().Format("20060102T150405Z")
+	for _, s := range sunsets {
+		subject := s.Title
+		if s.Operation != "" {
+			subject += ": " + s.Operation
+		}
+		uid := sha256.Sum256([]byte(s.Repo + "\x00" + s.Operation + "\x00" + s.Date.Format(time.DateOnly)))
+		line("BEGIN:VEVENT")
+		line("UID:" + hex.EncodeToString(uid[:12]) + "@openapi-aggregator")
+		line("DTSTAMP:" + stamp)
+		line("DTSTART;VALUE=DATE:" + s.Date.Format("20060102"))
+		line("DTEND;VALUE=DATE:" + s.Date.AddDate(0, 0, 1).Format("20060102"))
+		line("SUMMARY:" + icsEscape("Sunset "+subject))
+		line("DESCRIPTION:" + icsEscape(fmt.Sprintf("%s (%s) перестаёт поддерживаться %s", subject, s.Repo, s.Date.Format(time.DateOnly))))
+		line("CATEGORIES:" + icsEscape(s.Repo))
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func icsEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
@@ -0,0 +1,556 @@
+package spec
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var ErrSwagger2 = errors.New("спецификация в формате Swagger 2.0")
+
+var swaggerRefs = strings.NewReplacer(
+	"#/definitions/", "#/components/schemas/",
+	"#/responses/", "#/components/responses/",
+)
+
+var schemaKeys = []string{"type", "format", "items", "enum", "default", "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum",
+	"minLength", "maxLength", "pattern", "minItems", "maxItems", "uniqueItems", "multipleOf"}
+
+var oauthFlows = map[string]string{"implicit": "implicit", "password": "password", "application": "clientCredentials", "accessCode": "authorizationCode"}
+
+func IsSwagger2(data []byte) bool {
+	var head struct {
+		Swagger string `yaml:"swagger"`
+	}
+	return yaml.Unmarshal(data, &head) == nil && strings.HasPrefix(head.Swagger, "2.")
+}
+
+type swaggerConverter struct {
+	consumes, produces []string
+	bodyParams         map[string]bool
+	formParams         map[string]*yaml.Node
+}
+
+func ConvertSwagger2(data []byte) ([]byte, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("разбор спецификации: %w", err)
+	}
+	if len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("спецификация должна быть YAML-объектом")
+	}
+	src := root.Content[0]
+	if v := nodeValue(src, "swagger"); v == nil || !strings.HasPrefix(v.Value, "2.") {
+		return nil, fmt.Errorf("ожидается swagger: \"2.0\"")
+	}
+
+	c := &swaggerConverter{
+		consumes:   scalarList(nodeValue(src, "consumes"), "application/json"),
+		produces:   scalarList(nodeValue(src, "produces"), "application/json"),
+		bodyParams: map[string]bool{},
+		formParams: map[string]*yaml.Node{},
+	}
+	components := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	if params := nodeValue(src, "parameters"); params != nil {
+		for i := 0; i+1 < len(params.Content); i += 2 {
+			name, p := params.Content[i].Value, params.Content[i+1]
+			switch in := nodeValue(p, "in"); {
+			case in != nil && in.Value == "body":
+				c.bodyParams[name] = true
+				setValue(ensureMapping(components, "requestBodies"), name, c.requestBody(p, c.consumes))
+			case in != nil && in.Value == "formData":
+				c.formParams[name] = p
+			default:
+				setValue(ensureMapping(components, "parameters"), name, c.parameter(p))
+			}
+		}
+	}
+
+	out := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, v := src.Content[i].Value, src.Content[i+1]
+		switch key {
+		case "swagger":
+			setValue(out, "openapi", strNode("3.0.3"))
+		case "host", "basePath", "schemes", "consumes", "produces", "parameters":
+		case "paths":
+			paths := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			for j := 0; j+1 < len(v.Content); j += 2 {
+				item, err := c.pathItem(v.Content[j+1])
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", v.Content[j].Value, err)
+				}
+				setValue(paths, v.Content[j].Value, item)
+			}
+			setValue(out, "paths", paths)
+		case "definitions":
+			schemas := ensureMapping(components, "schemas")
+			for j := 0; j+1 < len(v.Content); j += 2 {
+				schemas.Content = append(schemas.Content, v.Content[j], c.schema(v.Content[j+1]))
+			}
+		case "responses":
+			responses := ensureMapping(components, "responses")
+			for j := 0; j+1 < len(v.Content); j += 2 {
+				setValue(responses, v.Content[j].Value, c.response(v.Content[j+1], c.produces))
+			}
+		case "securityDefinitions":
+			schemes := ensureMapping(components, "securitySchemes")
+			for j := 0; j+1 < len(v.Content); j += 2 {
+				setValue(schemes, v.Content[j].Value, securityScheme(v.Content[j+1]))
+			}
+		default:
+			setValue(out, key, v)
+		}
+		if key == "info" {
+			if servers := swaggerServers(src); servers != nil {
+				setValue(out, "servers", servers)
+			}
+		}
+	}
+	if len(components.Content) > 0 {
+		setValue(out, "components", components)
+	}
+	walkRefs(out, func(v *yaml.Node) {
+		if name, ok := strings.CutPrefix(v.Value, "#/parameters/"); ok {
+			kind := "parameters"
+			if c.bodyParams[name] {
+				kind = "requestBodies"
+			}
+			v.Value = "#/components/" + kind + "/" + name
+			return
+		}
+		v.Value = swaggerRefs.Replace(v.Value)
+	})
+	return encodeNode(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{out}})
+}
+
+func scalarList(n *yaml.Node, def string) []string {
+	if n == nil || n.Kind != yaml.SequenceNode || len(n.Content) == 0 {
+		return []string{def}
+	}
+	list := make([]string, 0, len(n.Content))
+	for _, item := range n.Content {
+		list = append(list, item.Value)
+	}
+	return list
+}
+
+func swaggerServers(src *yaml.Node) *yaml.Node {
+	host, base := "", ""
+	if v := nodeValue(src, "host"); v != nil {
+		host = v.Value
+	}
+	if v := nodeValue(src, "basePath"); v != nil {
+		base = strings.TrimSuffix(v.Value, "/")
+	}
+	if host == "" && base == "" {
+		return nil
+	}
+	servers := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	add := func(url string) {
+		servers.Content = append(servers.Content, &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{strNode("url"), strNode(url)}})
+	}
+	if host == "" {
+		add(base)
+		return servers
+	}
+	for _, scheme := range scalarList(nodeValue(src, "schemes"), "https") {
+		add(scheme + "://" + host + base)
+	}
+	return servers
+}
+
+func (c *swaggerConverter) pathItem(item *yaml.Node) (*yaml.Node, error) {
+	if item.Kind != yaml.MappingNode {
+		return item, nil
+	}
+	out := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	shared := nodeValue(item, "parameters")
+	for i := 0; i+1 < len(item.Content); i += 2 {
+		key, v := item.Content[i].Value, item.Content[i+1]
+		switch {
+		case key == "parameters":
+			var params []*yaml.Node
+			for _, p := range v.Content {
+				if c.inBody(p) == "" {
+					params = append(params, c.parameter(p))
+				}
+			}
+			if len(params) > 0 {
+				setValue(out, key, &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: params})
+			}
+		case slices.Contains(httpMethods, key):
+			op, err := c.operation(v, shared)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			setValue(out, key, op)
+		default:
+			setValue(out, key, v)
+		}
+	}
+	return out, nil
+}
+
+func (c *swaggerConverter) inBody(p *yaml.Node) string {
+	if ref := nodeValue(p, "$ref"); ref != nil {
+		name, _ := strings.CutPrefix(ref.Value, "#/parameters/")
+		if c.bodyParams[name] {
+			return "body"
+		}
+		if form, ok := c.formParams[name]; ok {
+			return c.inBody(form)
+		}
+		return ""
+	}
+	if in := nodeValue(p, "in"); in != nil && (in.Value == "body" || in.Value == "formData") {
+		return in.Value
+	}
+	return ""
+}
+
+func (c *swaggerConverter) operation(op, shared *yaml.Node) (*yaml.Node, error) {
+	consumes, produces := c.consumes, c.produces
+	if v := nodeValue(op, "consumes"); v != nil {
+		consumes = scalarList(v, c.consumes[0])
+	}
+	if v := nodeValue(op, "produces"); v != nil {
+		produces = scalarList(v, c.produces[0])
+	}
+
+	var all []*yaml.Node
+	if shared != nil {
+		for _, p := range shared.Content {
+			if c.inBody(p) != "" {
+				all = append(all, p)
+			}
+		}
+	}
+	if own := nodeValue(op, "parameters"); own != nil {
+		all = append(all, own.Content...)
+	}
+	var params, form []*yaml.Node
+	var body *yaml.Node
+	for _, p := range all {
+		switch c.inBody(p) {
+		case "body":
+			if ref := nodeValue(p, "$ref"); ref != nil {
+				body = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{strNode("$ref"), strNode(ref.Value)}}
+			} else {
+				body = c.requestBody(p, consumes)
+			}
+		case "formData":
+			if ref := nodeValue(p, "$ref"); ref != nil {
+				name, _ := strings.CutPrefix(ref.Value, "#/parameters/")
+				p = c.formParams[name]
+			}
+			form = append(form, p)
+		default:
+			params = append(params, c.parameter(p))
+		}
+	}
+	if body != nil && len(form) > 0 {
+		return nil, fmt.Errorf("параметры body и formData в одной операции")
+	}
+	if len(form) > 0 {
+		body = c.formBody(form, consumes)
+	}
+
+	out := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for i := 0; i+1 < len(op.Content); i += 2 {
+		key, v := op.Content[i].Value, op.Content[i+1]
+		switch key {
+		case "consumes", "produces", "schemes":
+		case "parameters":
+			if len(params) > 0 {
+				setValue(out, key, &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: params})
+			}
+			if body != nil {
+				setValue(out, "requestBody", body)
+			}
+		case "responses":
+			responses := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			for j := 0; j+1 < len(v.Content); j += 2 {
+				setValue(responses, v.Content[j].Value, c.response(v.Content[j+1], produces))
+			}
+			setValue(out, key, responses)
+		default:
+			setValue(out, key, v)
+		}
+	}
+	if nodeValue(op, "parameters") == nil && body != nil {
+		setValue(out, "requestBody", body)
+	}
+	return out, nil
+}
+
+func (c *swaggerConverter) parameter(p *yaml.Node) *yaml.Node {
+	if nodeValue(p, "$ref") != nil {
+		return p
+	}
+	out := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	schema := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	in := ""
+	if v := nodeValue(p, "in"); v != nil {
+		in = v.Value
+	}
+	for i := 0; i+1 < len(p.Content); i += 2 {
+		key, v := p.Content[i].Value, p.Content[i+1]
+		switch {
+		case key == "collectionFormat":
+			style, explode := collectionStyle(v.Value, in)
+			if style != "" {
+				setValue(out, "style", strNode(style))
+				setValue(out, "explode", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: explode})
+			}
+		case key == "items":
+			setValue(schema, key, c.schema(v))
+		case slices.Contains(schemaKeys, key):
+			setValue(schema, key, v)
+		default:
+			setValue(out, key, v)
+		}
+	}
+	if len(schema.Content) > 0 {
+		setValue(out, "schema", schema)
+	}
+	return out
+}
+
+func collectionStyle(format, in string) (style, explode string) {
+	switch format {
+	case "csv", "":
+		if in == "query" || in == "cookie" {
+			return "form", "false"
+		}
+		return "simple", "false"
+	case "multi":
+		return "form", "true"
+	case "ssv":
+		return "spaceDelimited", "false"
+	case "pipes":
+		return "pipeDelimited", "false"
+	}
+	return "", ""
+}
+
+func mediaContent(types []string, media func() *yaml.Node) *yaml.Node {
+	content := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, t := range types {
+		setValue(content, t, media())
+	}
+	return content
+}
+
+func (c *swaggerConverter) requestBody(p *yaml.Node, consumes []string) *yaml.Node {
+	out := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	if v := nodeValue(p, "description"); v != nil {
+		setValue(out, "description", v)
+	}
+	schema := nodeValue(p, "schema")
+	if schema == nil {
+		schema = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	}
+	schema = c.schema(schema)
+	setValue(out, "content", mediaContent(consumes, func() *yaml.Node {
+		return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{strNode("schema"), copyNode(schema)}}
+	}))
+	if v := nodeValue(p, "required"); v != nil {
+		setValue(out, "required", v)
+	}
+	return out
+}
+
+func (c *swaggerConverter) formBody(params []*yaml.Node, consumes []string) *yaml.Node {
+	schema := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{strNode("type"), strNode("object")}}
+	props := ensureMapping(schema, "properties")
+	var required []*yaml.Node
+	multipart := false
+	for _, p := range params {
+		name := nodeValue(p, "name")
+		if name == nil {
+			continue
+		}
+		prop := nodeValue(c.parameter(p), "schema")
+		if prop == nil {
+			prop = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}
+		if t := nodeValue(prop, "type"); t != nil && t.Value == "file" {
+			t.Value = "string"
+			setValue(prop, "format", strNode("binary"))
+			multipart = true
+		}
+		if d := nodeValue(p, "description"); d != nil {
+			setValue(prop, "description", d)
+		}
+		setValue(props, name.Value, prop)
+		if r := nodeValue(p, "required"); r != nil && r.Value == "true" {
+			required = append(required, strNode(name.Value))
+		}
+	}
+	if len(required) > 0 {
+		setValue(schema, "required", &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: required})
+	}
+	var types []string
+	for _, t := range consumes {
+		if t == "multipart/form-data" || t == "application/x-www-form-urlencoded" {
+			types = append(types, t)
+		}
+	}
+	if len(types) == 0 {
+		types = []string{"application/x-www-form-urlencoded"}
+		if multipart {
+			types = []string{"multipart/form-data"}
+		}
+	}
+	return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{
+		strNode("content"), mediaContent(types, func() *yaml.Node {
+			return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{strNode("schema"), copyNode(schema)}}
+		}),
+	}}
+}
+
+func (c *swaggerConverter) response(r *yaml.Node, produces []string) *yaml.Node {
+	if nodeValue(r, "$ref") != nil {
+		return r
+	}
+	out := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	schema := nodeValue(r, "schema")
+	examples := nodeValue(r, "examples")
+	for i := 0; i+1 < len(r.Content); i += 2 {
+		key, v := r.Content[i].Value, r.Content[i+1]
+		switch key {
+		case "schema", "examples":
+		case "headers":
+			headers := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			for j := 0; j+1 < len(v.Content); j += 2 {
+				h := c.parameter(v.Content[j+1])
+				setValue(headers, v.Content[j].Value, h)
+			}
+			setValue(out, key, headers)
+		default:
+			setValue(out, key, v)
+		}
+	}
+	if nodeValue(out, "description") == nil {
+		setValue(out, "description", strNode(""))
+	}
+	if schema != nil || examples != nil {
+		types := produces
+		if examples != nil {
+			for i := 0; i+1 < len(examples.Content); i += 2 {
+				if t := examples.Content[i].Value; !slices.Contains(types, t) {
+					types = append(append([]string{}, types...), t)
+				}
+			}
+		}
+		var converted *yaml.Node
+		if schema != nil {
+			converted = c.schema(schema)
+		}
+		content := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, t := range types {
+			media := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			if converted != nil {
+				setValue(media, "schema", copyNode(converted))
+			}
+			if ex := nodeValue(examples, t); ex != nil {
+				setValue(media, "example", ex)
+			}
+			setValue(content, t, media)
+		}
+		setValue(out, "content", content)
+	}
+	return out
+}
+
+func (c *swaggerConverter) schema(s *yaml.Node) *yaml.Node {
+	switch s.Kind {
+	case yaml.SequenceNode:
+		out := *s
+		out.Content = make([]*yaml.Node, len(s.Content))
+		for i, item := range s.Content {
+			out.Content[i] = c.schema(item)
+		}
+		return &out
+	case yaml.MappingNode:
+	default:
+		return s
+	}
+	out := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for i := 0; i+1 < len(s.Content); i += 2 {
+		key, v := s.Content[i].Value, s.Content[i+1]
+		switch key {
+		case "x-nullable":
+			setValue(out, "nullable", v)
+		case "discriminator":
+			if v.Kind == yaml.ScalarNode {
+				v = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{strNode("propertyName"), v}}
+			}
+			setValue(out, key, v)
+		case "type":
+			if v.Value == "file" {
+				setValue(out, key, strNode("string"))
+				setValue(out, "format", strNode("binary"))
+				continue
+			}
+			setValue(out, key, v)
+		case "items", "additionalProperties", "not":
+			setValue(out, key, c.schema(v))
+		case "properties":
+			props := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			for j := 0; j+1 < len(v.Content); j += 2 {
+				props.Content = append(props.Content, v.Content[j], c.schema(v.Content[j+1]))
+			}
+			setValue(out, key, props)
+		case "allOf", "anyOf", "oneOf":
+			setValue(out, key, c.schema(v))
+		default:
+			setValue(out, key, v)
+		}
+	}
+	return out
+}
+
+func securityScheme(s *yaml.Node) *yaml.Node {
+	t := nodeValue(s, "type")
+	if t == nil {
+		return s
+	}
+	out := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	switch t.Value {
+	case "basic":
+		setValue(out, "type", strNode("http"))
+		setValue(out, "scheme", strNode("basic"))
+	case "oauth2":
+		setValue(out, "type", strNode("oauth2"))
+		flow := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, key := range []string{"authorizationUrl", "tokenUrl", "scopes"} {
+			if v := nodeValue(s, key); v != nil {
+				setValue(flow, key, v)
+			}
+		}
+		if nodeValue(flow, "scopes") == nil {
+			setValue(flow, "scopes", &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"})
+		}
+		name := "implicit"
+		if v := nodeValue(s, "flow"); v != nil && oauthFlows[v.Value] != "" {
+			name = oauthFlows[v.Value]
+		}
+		setValue(out, "flows", &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{strNode(name), flow}})
+	default:
+		setValue(out, "type", t)
+		for _, key := range []string{"name", "in"} {
+			if v := nodeValue(s, key); v != nil {
+				setValue(out, key, v)
+			}
+		}
+	}
+	if v := nodeValue(s, "description"); v != nil {
+		setValue(out, "description", v)
+	}
+	return out
+}
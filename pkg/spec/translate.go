@@ -0,0 +1,268 @@
+package spec
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const TranslationsDir = "translations"
+
+var languageCode = regexp.MustCompile(`^[a-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+type TextTranslation struct {
+	Summary     string `yaml:"summary,omitempty"`
+	Description string `yaml:"description,omitempty"`
+}
+
+type SchemaTranslation struct {
+	Title       string            `yaml:"title,omitempty"`
+	Description string            `yaml:"description,omitempty"`
+	Properties  map[string]string `yaml:"properties,omitempty"`
+}
+
+type Translation struct {
+	Lang string `yaml:"-"`
+	Info struct {
+		Title       string `yaml:"title,omitempty"`
+		Description string `yaml:"description,omitempty"`
+	} `yaml:"info,omitempty"`
+	Tags       map[string]string            `yaml:"tags,omitempty"`
+	Operations map[string]TextTranslation   `yaml:"operations,omitempty"`
+	Schemas    map[string]SchemaTranslation `yaml:"schemas,omitempty"`
+}
+
+type TranslationReport struct {
+	Lang       string
+	Total      int
+	Translated int
+	Stale      []string
+}
+
+func (r TranslationReport) Coverage() int {
+	if r.Total == 0 {
+		return 100
+	}
+	return r.Translated * 100 / r.Total
+}
+
+func ValidLanguage(code string) bool {
+	return languageCode.MatchString(code)
+}
+
+func LocalizedFile(lang string) string {
+	return "openapi." + lang + ".yaml"
+}
+
+func LoadTranslations(dir, repo string) ([]*Translation, error) {
+	files, err := filepath.Glob(filepath.Join(dir, filepath.FromSlash(repo), TranslationsDir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	var translations []*Translation
+	for _, f := range files {
+		lang := strings.TrimSuffix(filepath.Base(f), ".yaml")
+		if !ValidLanguage(lang) {
+			return nil, fmt.Errorf("%s: имя файла должно быть кодом языка (en, de, pt-BR)", f)
+		}
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		t := &Translation{Lang: lang}
+		if err := yaml.Unmarshal(data, t); err != nil {
+			return nil, fmt.Errorf("разбор %s: %w", f, err)
+		}
+		translations = append(translations, t)
+	}
+	return translations, nil
+}
+
+func Translate(data []byte, t *Translation) ([]byte, TranslationReport, error) {
+	rep := TranslationReport{Lang: t.Lang}
+	doc, err := ParseDocument(data)
+	if err != nil {
+		return nil, rep, err
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, rep, fmt.Errorf("разбор спецификации: %w", err)
+	}
+	if len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return nil, rep, fmt.Errorf("спецификация должна быть YAML-объектом")
+	}
+	top := root.Content[0]
+
+	info := nodeValue(top, "info")
+	rep.apply(info, "title", t.Info.Title)
+	rep.apply(info, "description", t.Info.Description)
+
+	tags := map[string]*yaml.Node{}
+	if list := nodeValue(top, "tags"); list != nil && list.Kind == yaml.SequenceNode {
+		for _, tag := range list.Content {
+			if name := nodeValue(tag, "name"); name != nil {
+				tags[name.Value] = tag
+			}
+		}
+	}
+	for _, name := range sortedNames(tags) {
+		rep.apply(tags[name], "description", t.Tags[name])
+	}
+	for _, name := range sortedNames(t.Tags) {
+		if tags[name] == nil {
+			rep.Stale = append(rep.Stale, "tags."+name)
+		}
+	}
+
+	paths := nodeValue(top, "paths")
+	known := map[string]bool{}
+	for _, op := range doc.Operations() {
+		key := op.Operation.OperationID
+		if key == "" {
+			key = op.String()
+		}
+		known[key] = true
+		node := nodeValue(nodeValue(paths, op.Path), strings.ToLower(op.Method))
+		tr := t.Operations[key]
+		rep.apply(node, "summary", tr.Summary)
+		rep.apply(node, "description", tr.Description)
+	}
+	for _, key := range sortedNames(t.Operations) {
+		if !known[key] {
+			rep.Stale = append(rep.Stale, "operations."+key)
+		}
+	}
+
+	schemas := nodeValue(nodeValue(top, "components"), "schemas")
+	for _, name := range sortedNames(doc.Components.Schemas) {
+		node := nodeValue(schemas, name)
+		tr := t.Schemas[name]
+		rep.apply(node, "title", tr.Title)
+		rep.apply(node, "description", tr.Description)
+		props := nodeValue(node, "properties")
+		if s := doc.Components.Schemas[name]; s != nil {
+			for _, prop := range sortedNames(s.Properties) {
+				rep.apply(nodeValue(props, prop), "description", tr.Properties[prop])
+			}
+			for _, prop := range sortedNames(tr.Properties) {
+				if _, ok := s.Properties[prop]; !ok {
+					rep.Stale = append(rep.Stale, "schemas."+name+".properties."+prop)
+				}
+			}
+		}
+	}
+	for _, name := range sortedNames(t.Schemas) {
+		if _, ok := doc.Components.Schemas[name]; !ok {
+			rep.Stale = append(rep.Stale, "schemas."+name)
+		}
+	}
+
+	out, err := encodeNode(&root)
+	return out, rep, err
+}
+
+func (r *TranslationReport) apply(m *yaml.Node, key, text string) {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return
+	}
+	current := nodeValue(m, key)
+	if current != nil && current.Value != "" {
+		r.Total++
+	}
+	if text == "" {
+		return
+	}
+	if current != nil && current.Value != "" {
+		r.Translated++
+	}
+	value := scalar(text)
+	if strings.Contains(text, "\n") {
+		value.Style = yaml.LiteralStyle
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content[i+1] = value
+			return
+		}
+	}
+	m.Content = append(m.Content, scalar(key), value)
+}
+
+func sortedNames[V any](m map[string]V) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TranslationSkeleton(data []byte) ([]byte, error) {
+	doc, err := ParseDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	var tags struct {
+		Tags []struct {
+			Name        string `yaml:"name"`
+			Description string `yaml:"description"`
+		} `yaml:"tags"`
+	}
+	if err := yaml.Unmarshal(data, &tags); err != nil {
+		return nil, fmt.Errorf("разбор спецификации: %w", err)
+	}
+	t := &Translation{}
+	t.Info.Title, t.Info.Description = doc.Info.Title, doc.Info.Description
+	for _, tag := range tags.Tags {
+		if tag.Description != "" {
+			if t.Tags == nil {
+				t.Tags = map[string]string{}
+			}
+			t.Tags[tag.Name] = tag.Description
+		}
+	}
+	for _, op := range doc.Operations() {
+		if op.Operation.Summary == "" && op.Operation.Description == "" {
+			continue
+		}
+		key := op.Operation.OperationID
+		if key == "" {
+			key = op.String()
+		}
+		if t.Operations == nil {
+			t.Operations = map[string]TextTranslation{}
+		}
+		t.Operations[key] = TextTranslation{Summary: op.Operation.Summary, Description: op.Operation.Description}
+	}
+	for name, s := range doc.Components.Schemas {
+		if s == nil {
+			continue
+		}
+		st := SchemaTranslation{Description: s.Description}
+		for prop, p := range s.Properties {
+			if p != nil && p.Description != "" {
+				if st.Properties == nil {
+					st.Properties = map[string]string{}
+				}
+				st.Properties[prop] = p.Description
+			}
+		}
+		if st.Description != "" || len(st.Properties) > 0 {
+			if t.Schemas == nil {
+				t.Schemas = map[string]SchemaTranslation{}
+			}
+			t.Schemas[name] = st
+		}
+	}
+	var node yaml.Node
+	if err := node.Encode(t); err != nil {
+		return nil, err
+	}
+	return encodeNode(&node)
+}
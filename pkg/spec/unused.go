@@ -0,0 +1,69 @@
+package spec
+
+import (
+	"fmt"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+func UnusedSchemas(data []byte) ([]string, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("разбор спецификации: %w", err)
+	}
+	if len(root.Content) == 0 {
+		return nil, nil
+	}
+	doc := root.Content[0]
+	schemas := nodeValue(nodeValue(doc, "components"), "schemas")
+	if schemas == nil || schemas.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	defs := map[string]*yaml.Node{}
+	for i := 0; i+1 < len(schemas.Content); i += 2 {
+		defs[schemas.Content[i].Value] = schemas.Content[i+1]
+	}
+
+	used := map[string]bool{}
+	var queue []string
+	mark := func(value *yaml.Node) {
+		if ref, ok := localTarget(value.Value); ok && ref.kind == "schemas" && !used[ref.name] {
+			used[ref.name] = true
+			queue = append(queue, ref.name)
+		}
+	}
+	var visit func(n *yaml.Node)
+	visit = func(n *yaml.Node) {
+		if n == schemas {
+			return
+		}
+		if n.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				if n.Content[i].Value == "$ref" && n.Content[i+1].Kind == yaml.ScalarNode {
+					mark(n.Content[i+1])
+				}
+			}
+		}
+		for _, c := range n.Content {
+			visit(c)
+		}
+	}
+	visit(doc)
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if def := defs[name]; def != nil {
+			walkRefs(def, mark)
+		}
+	}
+
+	var unused []string
+	for name := range defs {
+		if !used[name] {
+			unused = append(unused, name)
+		}
+	}
+	sort.Strings(unused)
+	return unused, nil
+}
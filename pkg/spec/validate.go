@@ -0,0 +1,252 @@
+package spec
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type ValidationError struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Pointer string `json:"pointer,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	s := fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Message)
+	if e.Pointer != "" {
+		s += " (" + e.Pointer + ")"
+	}
+	return s
+}
+
+var (
+	yamlErrorLine = regexp.MustCompile(`line (\d+)`)
+	pathTemplate  = regexp.MustCompile(`\{([^{}]+)\}`)
+	responseCode  = regexp.MustCompile(`^([1-5][0-9][0-9]|[1-5]XX|default)$`)
+)
+
+var (
+	pathItemKeys   = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+	parameterPlace = map[string]bool{"query": true, "header": true, "path": true, "cookie": true}
+)
+
+type validator struct {
+	file   string
+	root   *yaml.Node
+	errors []ValidationError
+}
+
+func (v *validator) report(n *yaml.Node, pointer, format string, args ...any) {
+	e := ValidationError{File: v.file, Pointer: pointer, Message: fmt.Sprintf(format, args...)}
+	if n != nil {
+		e.Line, e.Column = n.Line, n.Column
+	}
+	v.errors = append(v.errors, e)
+}
+
+func Validate(file string, data []byte) []ValidationError {
+	v := &validator{file: file}
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		e := ValidationError{File: file, Line: 1, Column: 1, Message: err.Error()}
+		if m := yamlErrorLine.FindStringSubmatch(err.Error()); m != nil {
+			e.Line, _ = strconv.Atoi(m[1])
+		}
+		return []ValidationError{e}
+	}
+	if len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return []ValidationError{{File: file, Line: 1, Column: 1, Message: "спецификация должна быть YAML- или JSON-объектом"}}
+	}
+	v.root = root.Content[0]
+
+	var doc Document
+	if err := v.root.Decode(&doc); err != nil {
+		v.report(v.root, "", "%v", err)
+		return v.errors
+	}
+	v.checkHeader()
+	v.checkPaths()
+	walkRefs(v.root, func(ref *yaml.Node) {
+		if strings.HasPrefix(ref.Value, "#") && v.resolve(ref.Value) == nil {
+			v.report(ref, "", "ссылка %s никуда не ведёт", ref.Value)
+		}
+	})
+	for _, slo := range doc.SLOs("") {
+		for _, op := range doc.Operations() {
+			if op.String() != slo.Operation {
+				continue
+			}
+			for _, p := range slo.Problems {
+				v.report(&op.Operation.SLO, "", "%s: %s: %s", slo.Operation, SLOKey, p)
+			}
+		}
+	}
+	sort.SliceStable(v.errors, func(i, j int) bool {
+		if v.errors[i].Line != v.errors[j].Line {
+			return v.errors[i].Line < v.errors[j].Line
+		}
+		return v.errors[i].Column < v.errors[j].Column
+	})
+	return v.errors
+}
+
+func (v *validator) checkHeader() {
+	version := nodeValue(v.root, "openapi")
+	switch {
+	case version == nil:
+		v.report(v.root, "/openapi", "не указано поле openapi")
+	case !strings.HasPrefix(version.Value, "3."):
+		v.report(version, "/openapi", "поле openapi должно указывать версию 3.x, указано %q", version.Value)
+	}
+
+	info := nodeValue(v.root, "info")
+	if info == nil || info.Kind != yaml.MappingNode {
+		v.report(v.root, "/info", "не заполнен раздел info")
+	} else {
+		for _, key := range []string{"title", "version"} {
+			if n := nodeValue(info, key); n == nil || n.Value == "" {
+				v.report(info, "/info/"+key, "не заполнено info.%s", key)
+			}
+		}
+	}
+
+	paths, webhooks := nodeValue(v.root, "paths"), nodeValue(v.root, "webhooks")
+	if (paths == nil || len(paths.Content) == 0) && (webhooks == nil || len(webhooks.Content) == 0) {
+		n := paths
+		if n == nil {
+			n = v.root
+		}
+		v.report(n, "/paths", "спецификация не описывает ни одной операции")
+	}
+}
+
+func (v *validator) checkPaths() {
+	paths := nodeValue(v.root, "paths")
+	if paths == nil || paths.Kind != yaml.MappingNode {
+		return
+	}
+	operationIDs := map[string]string{}
+	for i := 0; i+1 < len(paths.Content); i += 2 {
+		key, item := paths.Content[i], paths.Content[i+1]
+		pointer := "/paths/" + escapePointer(key.Value)
+		if !strings.HasPrefix(key.Value, "/") {
+			v.report(key, pointer, "путь %q должен начинаться с /", key.Value)
+		}
+		if item.Kind != yaml.MappingNode {
+			continue
+		}
+		shared := v.checkParameters(nodeValue(item, "parameters"), pointer+"/parameters")
+		for _, method := range pathItemKeys {
+			op := nodeValue(item, method)
+			if op == nil || op.Kind != yaml.MappingNode {
+				continue
+			}
+			opPointer := pointer + "/" + method
+			name := strings.ToUpper(method) + " " + key.Value
+
+			if id := nodeValue(op, "operationId"); id != nil && id.Value != "" {
+				if prev, ok := operationIDs[id.Value]; ok {
+					v.report(id, opPointer+"/operationId", "%s: operationId %q уже использован в %s", name, id.Value, prev)
+				}
+				operationIDs[id.Value] = name
+			}
+
+			declared := v.checkParameters(nodeValue(op, "parameters"), opPointer+"/parameters")
+			for _, m := range pathTemplate.FindAllStringSubmatch(key.Value, -1) {
+				if !declared[m[1]] && !shared[m[1]] {
+					v.report(op, opPointer, "%s: не описан параметр пути %s", name, m[1])
+				}
+			}
+
+			responses := nodeValue(op, "responses")
+			if responses == nil || len(responses.Content) == 0 {
+				v.report(op, opPointer+"/responses", "%s: не описаны ответы", name)
+				continue
+			}
+			for j := 0; j+1 < len(responses.Content); j += 2 {
+				code := responses.Content[j]
+				if !responseCode.MatchString(code.Value) {
+					v.report(code, opPointer+"/responses/"+escapePointer(code.Value), "%s: некорректный код ответа %q", name, code.Value)
+				}
+			}
+		}
+	}
+}
+
+func (v *validator) checkParameters(list *yaml.Node, pointer string) map[string]bool {
+	pathParams := map[string]bool{}
+	if list == nil || list.Kind != yaml.SequenceNode {
+		return pathParams
+	}
+	for i, p := range list.Content {
+		pp := fmt.Sprintf("%s/%d", pointer, i)
+		if ref := nodeValue(p, "$ref"); ref != nil {
+			if target := v.resolve(ref.Value); target != nil {
+				p = target
+			} else {
+				continue
+			}
+		}
+		if p.Kind != yaml.MappingNode {
+			continue
+		}
+		name, in := nodeValue(p, "name"), nodeValue(p, "in")
+		if name == nil || name.Value == "" {
+			v.report(p, pp, "у параметра не указано имя")
+			continue
+		}
+		if in == nil || !parameterPlace[in.Value] {
+			v.report(p, pp+"/in", "параметр %s: in должен быть query, header, path или cookie", name.Value)
+			continue
+		}
+		if in.Value != "path" {
+			continue
+		}
+		pathParams[name.Value] = true
+		if req := nodeValue(p, "required"); req == nil || req.Value != "true" {
+			v.report(p, pp+"/required", "параметр пути %s должен быть required", name.Value)
+		}
+	}
+	return pathParams
+}
+
+func (v *validator) resolve(ref string) *yaml.Node {
+	pointer, ok := strings.CutPrefix(ref, "#")
+	if !ok {
+		return nil
+	}
+	n := v.root
+	if pointer == "" {
+		return n
+	}
+	for _, part := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+		switch n.Kind {
+		case yaml.MappingNode:
+			n = nodeValue(n, part)
+		case yaml.SequenceNode:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(n.Content) {
+				return nil
+			}
+			n = n.Content[i]
+		default:
+			return nil
+		}
+		if n == nil {
+			return nil
+		}
+	}
+	return n
+}
+
+func escapePointer(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
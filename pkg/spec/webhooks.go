@@ -0,0 +1,142 @@
+package spec
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+)
+
+const (
+	EventWebhook  = "webhook"
+	EventCallback = "callback"
+)
+
+type Event struct {
+	Repo        string   `json:"repo"`
+	Kind        string   `json:"kind"`
+	Name        string   `json:"name"`
+	Method      string   `json:"method"`
+	Source      string   `json:"source,omitempty"`
+	Target      string   `json:"target,omitempty"`
+	Summary     string   `json:"summary,omitempty"`
+	Description string   `json:"description,omitempty"`
+	ContentType string   `json:"content_type,omitempty"`
+	Payload     []string `json:"payload,omitempty"`
+}
+
+func (d *Document) event(repo, kind, name string, op OperationRef) Event {
+	e := Event{
+		Repo:        repo,
+		Kind:        kind,
+		Name:        name,
+		Method:      op.Method,
+		Summary:     op.Operation.Summary,
+		Description: strings.TrimSpace(op.Operation.Description),
+	}
+	if body := d.ResolveRequestBody(op.Operation.RequestBody); body != nil {
+		types := make([]string, 0, len(body.Content))
+		for ct := range body.Content {
+			types = append(types, ct)
+		}
+		sort.Strings(types)
+		for _, ct := range types {
+			if mt := body.Content[ct]; mt != nil && mt.Schema != nil {
+				e.ContentType = ct
+				e.Payload = d.PropertyNames(mt.Schema)
+				break
+			}
+		}
+	}
+	return e
+}
+
+type EventCatalog struct {
+	Events []Event `json:"events"`
+}
+
+func CollectEvents(docs map[string]*Document) *EventCatalog {
+	c := &EventCatalog{}
+	for _, repo := range SortedRepos(docs) {
+		doc := docs[repo]
+		for _, op := range operations(doc.Webhooks) {
+			c.Events = append(c.Events, doc.event(repo, EventWebhook, op.Path, op))
+		}
+		for _, source := range doc.Operations() {
+			names := make([]string, 0, len(source.Operation.Callbacks))
+			for name := range source.Operation.Callbacks {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				for _, op := range operations(source.Operation.Callbacks[name]) {
+					e := doc.event(repo, EventCallback, name, op)
+					e.Source = source.String()
+					e.Target = op.Path
+					c.Events = append(c.Events, e)
+				}
+			}
+		}
+	}
+	return c
+}
+
+func (c *EventCatalog) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("# Исходящие события\n\n")
+	if len(c.Events) == 0 {
+		b.WriteString("Ни одна спецификация не описывает webhooks или callbacks.\n")
+	}
+	repo := ""
+	for _, e := range c.Events {
+		if e.Repo != repo {
+			repo = e.Repo
+			fmt.Fprintf(&b, "## %s\n\n", repo)
+		}
+		fmt.Fprintf(&b, "### %s `%s` (%s)\n\n", e.Method, e.Name, e.Kind)
+		if e.Summary != "" {
+			fmt.Fprintf(&b, "%s\n\n", e.Summary)
+		}
+		if e.Source != "" {
+			fmt.Fprintf(&b, "- Регистрируется через: `%s`\n- Адрес доставки: `%s`\n", e.Source, e.Target)
+		}
+		if len(e.Payload) > 0 {
+			fmt.Fprintf(&b, "- Тело (%s): `%s`\n", e.ContentType, strings.Join(e.Payload, "`, `"))
+		}
+		if e.Description != "" {
+			fmt.Fprintf(&b, "\n%s\n", e.Description)
+		}
+		b.WriteString("\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+var eventsPage = template.Must(template.New("events").Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<title>Исходящие события</title>
+</head>
+<body>
+<h1>Исходящие события</h1>
+{{- if not . }}
+<p>Ни одна спецификация не описывает webhooks или callbacks.</p>
+{{- end }}
+{{- range . }}
+<section class="event event-{{ .Kind }}" id="{{ .Repo }}-{{ .Name }}">
+  <h2><code>{{ .Method }}</code> {{ .Name }} <small>{{ .Repo }} · {{ .Kind }}</small></h2>
+  {{- if .Summary }}<p>{{ .Summary }}</p>{{ end }}
+  {{- if .Source }}<p>Регистрируется через <code>{{ .Source }}</code>, доставляется на <code>{{ .Target }}</code></p>{{ end }}
+  {{- if .Payload }}<p>Тело ({{ .ContentType }}): {{ range $i, $f := .Payload }}{{ if $i }}, {{ end }}<code>{{ $f }}</code>{{ end }}</p>{{ end }}
+  {{- if .Description }}<p>{{ .Description }}</p>{{ end }}
+</section>
+{{- end }}
+</body>
+</html>
+`))
+
+func (c *EventCatalog) WriteHTML(w io.Writer) error {
+	return eventsPage.Execute(w, c.Events)
+}
@@ -0,0 +1,326 @@
+package spec
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+type Quality struct {
+	Documented int `json:"documented"`
+	Total      int `json:"total"`
+}
+
+func (q Quality) Percent() float64 {
+	if q.Total == 0 {
+		return 100
+	}
+	return float64(q.Documented) * 100 / float64(q.Total)
+}
+
+func (q *Quality) count(documented bool) {
+	q.Total++
+	if documented {
+		q.Documented++
+	}
+}
+
+func (q *Quality) add(o Quality) {
+	q.Documented += o.Documented
+	q.Total += o.Total
+}
+
+func DocQuality(d *Document) Quality {
+	var q Quality
+	if d == nil {
+		return q
+	}
+	for _, op := range d.Operations() {
+		q.count(op.Operation.Summary != "" || op.Operation.Description != "")
+		for _, p := range d.Parameters(op) {
+			q.count(p.Description != "")
+		}
+	}
+	names := make([]string, 0, len(d.Components.Schemas))
+	for name := range d.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		s := d.Components.Schemas[name]
+		if s == nil {
+			continue
+		}
+		for _, prop := range s.Properties {
+			q.count(prop != nil && (prop.Description != "" || prop.Ref != ""))
+		}
+	}
+	return q
+}
+
+type ServiceDigest struct {
+	Repo     string   `json:"repo"`
+	New      bool     `json:"new,omitempty"`
+	From     string   `json:"from,omitempty"`
+	To       string   `json:"to"`
+	Versions []string `json:"versions"`
+	Changes  []Change `json:"changes,omitempty"`
+	Before   Quality  `json:"quality_before"`
+	After    Quality  `json:"quality_after"`
+}
+
+type WeeklyDigest struct {
+	Since    time.Time       `json:"since"`
+	Until    time.Time       `json:"until"`
+	Services []ServiceDigest `json:"services"`
+	Counts   map[string]int  `json:"counts"`
+	Before   Quality         `json:"quality_before"`
+	After    Quality         `json:"quality_after"`
+	Total    int             `json:"total_services"`
+}
+
+func (w *WeeklyDigest) NewServices() []string {
+	var repos []string
+	for _, s := range w.Services {
+		if s.New {
+			repos = append(repos, s.Repo)
+		}
+	}
+	return repos
+}
+
+func (w *WeeklyDigest) Breaking() []ServiceDigest {
+	var out []ServiceDigest
+	for _, s := range w.Services {
+		if CountChanges(s.Changes)[ChangeBreaking] > 0 {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func DocumentAt(ctx context.Context, dir, commit, repo string) (*Document, error) {
+	data, err := AtCommit(ctx, dir, commit, repo)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDocument(data)
+}
+
+func BuildWeeklyDigest(ctx context.Context, dir string, since, until time.Time) (*WeeklyDigest, error) {
+	files, err := SpecFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	w := &WeeklyDigest{Since: since, Until: until, Counts: map[string]int{}}
+	for _, f := range files {
+		repo := SpecName(dir, f)
+		history, err := History(ctx, dir, repo)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", repo, err)
+		}
+
+		var inWindow []Version
+		var base *Version
+		for i, v := range history {
+			if v.Date.After(until) {
+				continue
+			}
+			if v.Date.Before(since) {
+				base = &history[i]
+				break
+			}
+			inWindow = append(inWindow, v)
+		}
+		if base == nil && len(inWindow) == 0 {
+			continue
+		}
+		w.Total++
+
+		var before *Document
+		if base != nil {
+			if before, err = DocumentAt(ctx, dir, base.Commit, repo); err != nil {
+				return nil, fmt.Errorf("%s@%s: %w", repo, base.Version, err)
+			}
+		}
+		if len(inWindow) == 0 {
+			q := DocQuality(before)
+			w.Before.add(q)
+			w.After.add(q)
+			continue
+		}
+
+		latest := inWindow[0]
+		after, err := DocumentAt(ctx, dir, latest.Commit, repo)
+		if err != nil {
+			return nil, fmt.Errorf("%s@%s: %w", repo, latest.Version, err)
+		}
+		s := ServiceDigest{Repo: repo, To: latest.Version, Before: DocQuality(before), After: DocQuality(after)}
+		for i := len(inWindow) - 1; i >= 0; i-- {
+			s.Versions = append(s.Versions, inWindow[i].Version)
+		}
+		if base == nil {
+			s.New = true
+		} else {
+			s.From = base.Version
+			s.Changes = Diff(before, after)
+		}
+		for category, n := range CountChanges(s.Changes) {
+			w.Counts[category] += n
+		}
+		w.Before.add(s.Before)
+		w.After.add(s.After)
+		w.Services = append(w.Services, s)
+	}
+	sort.Slice(w.Services, func(i, j int) bool { return w.Services[i].Repo < w.Services[j].Repo })
+	return w, nil
+}
+
+func (w *WeeklyDigest) Title() string {
+	year, week := w.Until.ISOWeek()
+	return fmt.Sprintf("Дайджест изменений API: %d, неделя %02d", year, week)
+}
+
+func (w *WeeklyDigest) Summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (%s — %s)\n", w.Title(), w.Since.Format("2006-01-02"), w.Until.Format("2006-01-02"))
+	fmt.Fprintf(&b, "Обновлено сервисов: %d, новых: %d, ломающих изменений: %d\n",
+		len(w.Services), len(w.NewServices()), w.Counts[ChangeBreaking])
+	fmt.Fprintf(&b, "Покрытие документацией: %.1f%% → %.1f%%", w.Before.Percent(), w.After.Percent())
+	for _, s := range w.Breaking() {
+		fmt.Fprintf(&b, "\n• %s %s → %s", s.Repo, s.From, s.To)
+		for _, c := range s.Changes {
+			if c.Category == ChangeBreaking {
+				fmt.Fprintf(&b, "\n    %s", c)
+			}
+		}
+	}
+	return b.String()
+}
+
+func (w *WeeklyDigest) WriteMarkdown(out io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", w.Title())
+	fmt.Fprintf(&b, "Период: %s — %s\n\n", w.Since.Format("2006-01-02"), w.Until.Format("2006-01-02"))
+
+	b.WriteString("## Итоги\n\n")
+	fmt.Fprintf(&b, "- Обновлено сервисов: %d из %d\n", len(w.Services), w.Total)
+	fmt.Fprintf(&b, "- Новых сервисов: %d\n", len(w.NewServices()))
+	for _, category := range ChangeCategories {
+		fmt.Fprintf(&b, "- %s: %d\n", category, w.Counts[category])
+	}
+	fmt.Fprintf(&b, "- Покрытие документацией: %.1f%% → %.1f%% (%d из %d элементов описаны)\n\n",
+		w.Before.Percent(), w.After.Percent(), w.After.Documented, w.After.Total)
+
+	if repos := w.NewServices(); len(repos) > 0 {
+		b.WriteString("## Новые сервисы\n\n")
+		for _, s := range w.Services {
+			if s.New {
+				fmt.Fprintf(&b, "- **%s** %s\n", s.Repo, s.To)
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	if breaking := w.Breaking(); len(breaking) > 0 {
+		b.WriteString("## Ломающие изменения\n\n")
+		for _, s := range breaking {
+			fmt.Fprintf(&b, "### %s %s → %s\n\n", s.Repo, s.From, s.To)
+			for _, c := range s.Changes {
+				if c.Category == ChangeBreaking {
+					fmt.Fprintf(&b, "- %s\n", c)
+				}
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("## Все изменения\n\n")
+	if len(w.Services) == 0 {
+		b.WriteString("За период спецификации не менялись.\n")
+	}
+	for _, s := range w.Services {
+		if s.New {
+			fmt.Fprintf(&b, "### %s %s (новый)\n\n", s.Repo, s.To)
+		} else {
+			fmt.Fprintf(&b, "### %s %s → %s\n\n", s.Repo, s.From, s.To)
+		}
+		fmt.Fprintf(&b, "Версии: %s. Покрытие документацией: ", strings.Join(s.Versions, ", "))
+		if !s.New {
+			fmt.Fprintf(&b, "%.1f%% → ", s.Before.Percent())
+		}
+		fmt.Fprintf(&b, "%.1f%%\n\n", s.After.Percent())
+		for _, c := range s.Changes {
+			fmt.Fprintf(&b, "- [%s] %s\n", c.Category, c)
+		}
+		if len(s.Changes) > 0 {
+			b.WriteString("\n")
+		}
+	}
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+var weeklyPage = template.Must(template.New("weekly").Funcs(template.FuncMap{
+	"date":    func(t time.Time) string { return t.Format("2006-01-02") },
+	"percent": func(q Quality) string { return fmt.Sprintf("%.1f%%", q.Percent()) },
+}).Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<title>{{ .Title }}</title>
+</head>
+<body>
+<h1>{{ .Title }}</h1>
+<p>Период: {{ date .Since }} — {{ date .Until }}</p>
+<h2>Итоги</h2>
+<ul>
+  <li>Обновлено сервисов: {{ len .Services }} из {{ .Total }}</li>
+  <li>Новых сервисов: {{ len .NewServices }}</li>
+  {{- range $c := .Categories }}
+  <li>{{ $c }}: {{ index $.Counts $c }}</li>
+  {{- end }}
+  <li>Покрытие документацией: {{ percent .Before }} → {{ percent .After }}</li>
+</ul>
+{{- with .Breaking }}
+<h2>Ломающие изменения</h2>
+{{- range . }}
+<h3>{{ .Repo }} {{ .From }} → {{ .To }}</h3>
+<ul>
+  {{- range .Changes }}{{ if eq .Category "breaking" }}
+  <li>{{ .String }}</li>
+  {{- end }}{{ end }}
+</ul>
+{{- end }}
+{{- end }}
+<h2>Все изменения</h2>
+{{- if not .Services }}
+<p>За период спецификации не менялись.</p>
+{{- end }}
+{{- range .Services }}
+<section class="service{{ if .New }} service-new{{ end }}" id="{{ .Repo }}">
+  <h3>{{ .Repo }} {{ if .New }}{{ .To }} <small>новый</small>{{ else }}{{ .From }} → {{ .To }}{{ end }}</h3>
+  <p>Версии: {{ range $i, $v := .Versions }}{{ if $i }}, {{ end }}{{ $v }}{{ end }}. Покрытие документацией: {{ if not .New }}{{ percent .Before }} → {{ end }}{{ percent .After }}</p>
+  {{- if .Changes }}
+  <ul>
+    {{- range .Changes }}
+    <li class="change-{{ .Category }}">[{{ .Category }}] {{ .String }}</li>
+    {{- end }}
+  </ul>
+  {{- end }}
+</section>
+{{- end }}
+</body>
+</html>
+`))
+
+func (w *WeeklyDigest) WriteHTML(out io.Writer) error {
+	return weeklyPage.Execute(out, struct {
+		*WeeklyDigest
+		Categories []string
+	}{w, ChangeCategories})
+}
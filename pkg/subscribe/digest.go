@@ -0,0 +1,232 @@
+package subscribe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/RastBast/docs12121/pkg/notify"
+	"github.com/RastBast/docs12121/pkg/spec"
+)
+
+type Event struct {
+	Repo    string        `json:"repo"`
+	Version string        `json:"version"`
+	Commit  string        `json:"commit"`
+	Date    time.Time     `json:"date"`
+	Changes []spec.Change `json:"changes"`
+}
+
+type state struct {
+	Seen     map[string]string    `json:"seen"`
+	Pending  map[string][]Event   `json:"pending"`
+	LastSent map[string]time.Time `json:"last_sent"`
+}
+
+type Digest struct {
+	Team    string  `json:"team"`
+	Cadence string  `json:"cadence"`
+	Text    string  `json:"text"`
+	Events  []Event `json:"events"`
+}
+
+type Notifier struct {
+	Dir       string
+	Store     *Store
+	StatePath string
+	Logf      func(format string, args ...any)
+	Now       func() time.Time
+}
+
+func (n *Notifier) now() time.Time {
+	if n.Now != nil {
+		return n.Now()
+	}
+	return time.Now()
+}
+
+func (n *Notifier) logf(format string, args ...any) {
+	if n.Logf != nil {
+		n.Logf(format, args...)
+	}
+}
+
+func (n *Notifier) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := n.Tick(ctx); err != nil && ctx.Err() == nil {
+			n.logf("Ошибка рассылки дайджестов: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (n *Notifier) loadState() (*state, error) {
+	st := &state{Seen: map[string]string{}, Pending: map[string][]Event{}, LastSent: map[string]time.Time{}}
+	data, err := os.ReadFile(n.StatePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return st, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, st); err != nil {
+		return nil, fmt.Errorf("разбор %s: %w", n.StatePath, err)
+	}
+	return st, nil
+}
+
+func (n *Notifier) saveState(st *state) error {
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(n.StatePath), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(n.StatePath, data, 0o644)
+}
+
+func (n *Notifier) events(ctx context.Context, st *state) ([]Event, error) {
+	files, err := spec.SpecFiles(n.Dir)
+	if err != nil {
+		return nil, err
+	}
+	var events []Event
+	for _, f := range files {
+		repo := spec.SpecName(n.Dir, f)
+		history, err := spec.History(ctx, n.Dir, repo)
+		if err != nil || len(history) == 0 {
+			continue
+		}
+		seen, known := st.Seen[repo]
+		st.Seen[repo] = history[0].Commit
+		if !known {
+			continue
+		}
+		idx := -1
+		for i, v := range history {
+			if v.Commit == seen {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			n.logf("%s: коммит %s из состояния дайджеста не найден в истории (история переписана?), изменения берутся за всю историю", repo, seen)
+			idx = len(history) - 1
+		}
+		if idx == 0 {
+			continue
+		}
+		for i := idx - 1; i >= 0; i-- {
+			v := history[i]
+			from, err := spec.DocumentAt(ctx, n.Dir, history[i+1].Commit, repo)
+			if err != nil {
+				n.logf("%s@%s: версия пропущена, предыдущая не разбирается: %v", repo, v.Version, err)
+				continue
+			}
+			to, err := spec.DocumentAt(ctx, n.Dir, v.Commit, repo)
+			if err != nil {
+				n.logf("%s@%s: версия пропущена: %v", repo, v.Version, err)
+				continue
+			}
+			events = append(events, Event{Repo: repo, Version: v.Version, Commit: v.Commit, Date: v.Date, Changes: spec.Diff(from, to)})
+		}
+	}
+	return events, nil
+}
+
+func (n *Notifier) Tick(ctx context.Context) error {
+	st, err := n.loadState()
+	if err != nil {
+		return err
+	}
+	events, err := n.events(ctx, st)
+	if err != nil {
+		return err
+	}
+
+	now := n.now()
+	subs := n.Store.List()
+	for _, sub := range subs {
+		for _, e := range events {
+			var matched []spec.Change
+			for _, c := range e.Changes {
+				if sub.Matches(e.Repo, c) {
+					matched = append(matched, c)
+				}
+			}
+			if len(matched) > 0 {
+				e.Changes = matched
+				st.Pending[sub.ID] = append(st.Pending[sub.ID], e)
+			}
+		}
+
+		last, ok := st.LastSent[sub.ID]
+		if !ok {
+			st.LastSent[sub.ID] = now
+			if sub.Cadence != CadenceImmediate {
+				continue
+			}
+		}
+		if now.Sub(last) < cadencePeriods[sub.Cadence] {
+			continue
+		}
+		if pending := st.Pending[sub.ID]; len(pending) > 0 {
+			if err := n.deliver(ctx, sub, pending); err != nil {
+				n.logf("Дайджест для %s не доставлен: %v", sub.Team, err)
+				continue
+			}
+			n.logf("Дайджест для %s отправлен (событий: %d)", sub.Team, len(pending))
+		}
+		delete(st.Pending, sub.ID)
+		st.LastSent[sub.ID] = now
+	}
+
+	for id := range st.Pending {
+		if !hasSubscription(subs, id) {
+			delete(st.Pending, id)
+			delete(st.LastSent, id)
+		}
+	}
+	return n.saveState(st)
+}
+
+func hasSubscription(subs []Subscription, id string) bool {
+	for _, s := range subs {
+		if s.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func FormatDigest(sub Subscription, events []Event) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Изменения API для %s", sub.Team)
+	for _, e := range events {
+		fmt.Fprintf(&b, "\n\n*%s %s* (%s)", e.Repo, e.Version, e.Date.Format("2006-01-02"))
+		for _, c := range e.Changes {
+			fmt.Fprintf(&b, "\n• [%s] %s", c.Category, c)
+		}
+	}
+	return b.String()
+}
+
+func (n *Notifier) deliver(ctx context.Context, sub Subscription, events []Event) error {
+	digest := Digest{Team: sub.Team, Cadence: sub.Cadence, Text: FormatDigest(sub, events), Events: events}
+	if sub.Owner != "" {
+		return notify.PostPublic(ctx, sub.Webhook, digest)
+	}
+	return notify.Post(ctx, sub.Webhook, digest)
+}
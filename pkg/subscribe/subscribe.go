@@ -0,0 +1,229 @@
+package subscribe
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/RastBast/docs12121/pkg/notify"
+	"github.com/RastBast/docs12121/pkg/spec"
+)
+
+const (
+	CadenceImmediate = "immediate"
+	CadenceDaily     = "daily"
+	CadenceWeekly    = "weekly"
+)
+
+var cadencePeriods = map[string]time.Duration{
+	CadenceImmediate: 0,
+	CadenceDaily:     24 * time.Hour,
+	CadenceWeekly:    7 * 24 * time.Hour,
+}
+
+type Subscription struct {
+	ID         string   `yaml:"id" json:"id"`
+	Team       string   `yaml:"team" json:"team"`
+	Services   []string `yaml:"services,omitempty" json:"services,omitempty"`
+	Categories []string `yaml:"categories,omitempty" json:"categories,omitempty"`
+	Cadence    string   `yaml:"cadence" json:"cadence"`
+	Webhook    string   `yaml:"webhook" json:"webhook"`
+	Owner      string   `yaml:"owner,omitempty" json:"owner,omitempty"`
+	SecretHash string   `yaml:"secret_hash,omitempty" json:"-"`
+}
+
+var ErrForbidden = errors.New("неверный секрет подписки")
+
+func ValidateCallback(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" || u.User != nil {
+		return fmt.Errorf("адрес доставки должен быть https://хост/…, получено %q", raw)
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") || strings.HasSuffix(host, ".local") || strings.HasSuffix(host, ".internal") {
+		return fmt.Errorf("адрес доставки %q указывает на внутренний хост", raw)
+	}
+	if ip := net.ParseIP(host); ip != nil && !notify.PublicIP(ip) {
+		return fmt.Errorf("адрес доставки %q указывает на внутренний адрес", raw)
+	}
+	return nil
+}
+
+func (s *Subscription) Validate() error {
+	if s.Team == "" {
+		return errors.New("не указана команда")
+	}
+	if s.Cadence == "" {
+		s.Cadence = CadenceDaily
+	}
+	if _, ok := cadencePeriods[s.Cadence]; !ok {
+		return fmt.Errorf("неизвестная периодичность %q (доступны: immediate, daily, weekly)", s.Cadence)
+	}
+	for _, c := range s.Categories {
+		if !slices.Contains(spec.ChangeCategories, c) {
+			return fmt.Errorf("неизвестная категория изменений %q (доступны: breaking, addition, deprecation, change)", c)
+		}
+	}
+	u, err := url.Parse(s.Webhook)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("некорректный адрес доставки %q", s.Webhook)
+	}
+	return nil
+}
+
+func (s *Subscription) Matches(repo string, c spec.Change) bool {
+	if len(s.Services) > 0 && !slices.Contains(s.Services, repo) {
+		return false
+	}
+	return len(s.Categories) == 0 || slices.Contains(s.Categories, c.Category)
+}
+
+type Store struct {
+	path string
+
+	mu   sync.Mutex
+	subs []*Subscription
+}
+
+func Load(path string) (*Store, error) {
+	subs, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{path: path, subs: subs}, nil
+}
+
+func (s *Store) Reload() error {
+	subs, err := readFile(s.path)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subs = subs
+	return nil
+}
+
+func readFile(path string) ([]*Subscription, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Subscriptions []*Subscription `yaml:"subscriptions"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("разбор %s: %w", path, err)
+	}
+	for i, sub := range file.Subscriptions {
+		if err := sub.Validate(); err != nil {
+			return nil, fmt.Errorf("подписка %d (%s): %w", i+1, sub.Team, err)
+		}
+		if sub.ID == "" {
+			sub.ID = sub.derivedID()
+		}
+	}
+	return file.Subscriptions, nil
+}
+
+func (s *Subscription) derivedID() string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		s.Team, s.Cadence, s.Webhook, strings.Join(s.Services, ","), strings.Join(s.Categories, ","),
+	}, "\x00")))
+	return hex.EncodeToString(sum[:6])
+}
+
+func newID() string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func (s *Store) List() []Subscription {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Subscription, len(s.subs))
+	for i, sub := range s.subs {
+		out[i] = *sub
+	}
+	return out
+}
+
+func (s *Store) Add(sub Subscription, owner string) (Subscription, string, error) {
+	if err := sub.Validate(); err != nil {
+		return Subscription{}, "", err
+	}
+	if err := ValidateCallback(sub.Webhook); err != nil {
+		return Subscription{}, "", err
+	}
+	secret := make([]byte, 16)
+	rand.Read(secret)
+	sub.Owner, sub.SecretHash = owner, hashSecret(hex.EncodeToString(secret))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sub.ID = newID()
+	s.subs = append(s.subs, &sub)
+	if err := s.save(); err != nil {
+		s.subs = s.subs[:len(s.subs)-1]
+		return Subscription{}, "", err
+	}
+	return sub, hex.EncodeToString(secret), nil
+}
+
+func (s *Store) Remove(id, secret string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, sub := range s.subs {
+		if sub.ID != id {
+			continue
+		}
+		if sub.SecretHash == "" || subtle.ConstantTimeCompare([]byte(hashSecret(secret)), []byte(sub.SecretHash)) != 1 {
+			return true, ErrForbidden
+		}
+		s.subs = slices.Delete(s.subs, i, i+1)
+		return true, s.save()
+	}
+	return false, nil
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *Store) save() error {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(struct {
+		Subscriptions []*Subscription `yaml:"subscriptions"`
+	}{s.subs}); err != nil {
+		return err
+	}
+	enc.Close()
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
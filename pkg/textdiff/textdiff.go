@@ -0,0 +1,192 @@
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+type op struct {
+	kind byte
+	line string
+}
+
+func Unified(oldName, newName, a, b string, context int) string {
+	if a == b {
+		return ""
+	}
+	ops := diffLines(splitLines(a), splitLines(b))
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+	for start := 0; start < len(ops); {
+		first := -1
+		for i := start; i < len(ops); i++ {
+			if ops[i].kind != ' ' {
+				first = i
+				break
+			}
+		}
+		if first < 0 {
+			break
+		}
+		from := max(first-context, start)
+		to := first
+		for i := first; i < len(ops); i++ {
+			if ops[i].kind != ' ' {
+				to = i
+			} else if i-to > 2*context {
+				break
+			}
+		}
+		to = min(to+context+1, len(ops))
+
+		oldLine, newLine := 1, 1
+		for _, o := range ops[:from] {
+			if o.kind != '+' {
+				oldLine++
+			}
+			if o.kind != '-' {
+				newLine++
+			}
+		}
+		var oldCount, newCount int
+		for _, o := range ops[from:to] {
+			if o.kind != '+' {
+				oldCount++
+			}
+			if o.kind != '-' {
+				newCount++
+			}
+		}
+		fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n", oldLine, oldCount, newLine, newCount)
+		for _, o := range ops[from:to] {
+			out.WriteByte(o.kind)
+			out.WriteString(o.line)
+			out.WriteByte('\n')
+		}
+		start = to
+	}
+	return out.String()
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+func diffLines(a, b []string) []op {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var ops []op
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, op{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, op{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, op{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, op{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, op{'+', b[j]})
+	}
+	return ops
+}
+
+const (
+	RowSame    = "same"
+	RowRemoved = "removed"
+	RowAdded   = "added"
+	RowChanged = "changed"
+	RowSkipped = "skipped"
+)
+
+type Row struct {
+	Kind  string `json:"kind"`
+	Left  string `json:"left,omitempty"`
+	Right string `json:"right,omitempty"`
+	Lines int    `json:"lines,omitempty"`
+}
+
+func SideBySide(a, b string, context int) []Row {
+	ops := diffLines(splitLines(a), splitLines(b))
+	var rows []Row
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			rows = append(rows, Row{Kind: RowSame, Left: ops[i].line, Right: ops[i].line})
+			i++
+			continue
+		}
+		var removed, added []string
+		for ; i < len(ops) && ops[i].kind != ' '; i++ {
+			if ops[i].kind == '-' {
+				removed = append(removed, ops[i].line)
+			} else {
+				added = append(added, ops[i].line)
+			}
+		}
+		for j := 0; j < max(len(removed), len(added)); j++ {
+			switch {
+			case j >= len(removed):
+				rows = append(rows, Row{Kind: RowAdded, Right: added[j]})
+			case j >= len(added):
+				rows = append(rows, Row{Kind: RowRemoved, Left: removed[j]})
+			default:
+				rows = append(rows, Row{Kind: RowChanged, Left: removed[j], Right: added[j]})
+			}
+		}
+	}
+	if context < 0 {
+		return rows
+	}
+	return collapse(rows, context)
+}
+
+func collapse(rows []Row, context int) []Row {
+	keep := make([]bool, len(rows))
+	for i, r := range rows {
+		if r.Kind == RowSame {
+			continue
+		}
+		for j := max(i-context, 0); j <= min(i+context, len(rows)-1); j++ {
+			keep[j] = true
+		}
+	}
+	var out []Row
+	for i := 0; i < len(rows); {
+		if keep[i] {
+			out = append(out, rows[i])
+			i++
+			continue
+		}
+		start := i
+		for i < len(rows) && !keep[i] {
+			i++
+		}
+		out = append(out, Row{Kind: RowSkipped, Lines: i - start})
+	}
+	return out
+}